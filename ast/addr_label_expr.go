@@ -0,0 +1,34 @@
+package ast
+
+// AddrLabelExpr is the address of a label, like "&&end". It is a GNU extension
+// that is used by a computed goto.
+type AddrLabelExpr struct {
+	Address   string
+	Position  string
+	Type      string
+	Name      string
+	Position2 string
+	Children  []Node
+}
+
+func parseAddrLabelExpr(line string) *AddrLabelExpr {
+	groups := groupsFromRegex(
+		"<(?P<position>.*)> '(?P<type>.*?)' (?P<name>.*) (?P<position2>0x[0-9a-f]+)",
+		line,
+	)
+
+	return &AddrLabelExpr{
+		Address:   groups["address"],
+		Position:  groups["position"],
+		Type:      groups["type"],
+		Name:      groups["name"],
+		Position2: groups["position2"],
+		Children:  []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *AddrLabelExpr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,20 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestAddrLabelExpr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55dd7c8f6b10 <col:20, col:22> 'void *' next_op 0x55dd7c8f6a98`: &AddrLabelExpr{
+			Address:   "0x55dd7c8f6b10",
+			Position:  "col:20, col:22",
+			Type:      "void *",
+			Name:      "next_op",
+			Position2: "0x55dd7c8f6a98",
+			Children:  []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -18,3 +18,22 @@ func TestArrayFiller(t *testing.T) {
 			formatMultiLine(actual)))
 	}
 }
+
+func TestArrayFillerWithNode(t *testing.T) {
+	expected := &ArrayFiller{
+		Children: []Node{
+			&ImplicitValueInitExpr{
+				Address:  "0x7f9bf3",
+				Position: "<invalid sloc>",
+				Type1:    "int",
+				Children: []Node{},
+			},
+		},
+	}
+	actual := Parse(`array_filler: ImplicitValueInitExpr 0x7f9bf3 <<invalid sloc>> 'int'`)
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("%s", util.ShowDiff(formatMultiLine(expected),
+			formatMultiLine(actual)))
+	}
+}
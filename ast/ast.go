@@ -17,6 +17,8 @@ type Node interface {
 // position cannot be determined an empty string is returned.
 func Position(node Node) string {
 	switch n := node.(type) {
+	case *AddrLabelExpr:
+		return n.Position
 	case *AlignedAttr:
 		return n.Position
 	case *AlwaysInlineAttr:
@@ -27,6 +29,8 @@ func Position(node Node) string {
 		return n.Position
 	case *AvailabilityAttr:
 		return n.Position
+	case *BinaryConditionalOperator:
+		return n.Position
 	case *BinaryOperator:
 		return n.Position
 	case *BreakStmt:
@@ -39,6 +43,8 @@ func Position(node Node) string {
 		return n.Position
 	case *CharacterLiteral:
 		return n.Position
+	case *CleanupAttr:
+		return n.Position
 	case *CompoundStmt:
 		return n.Position
 	case *ConditionalOperator:
@@ -47,10 +53,14 @@ func Position(node Node) string {
 		return n.Position
 	case *ConstantArrayType:
 		return ""
+	case *ConstructorAttr:
+		return n.Position
 	case *ContinueStmt:
 		return n.Position
 	case *CompoundAssignOperator:
 		return n.Position
+	case *CompoundLiteralExpr:
+		return n.Position
 	case *CStyleCastExpr:
 		return n.Position
 	case *DeclRefExpr:
@@ -61,6 +71,10 @@ func Position(node Node) string {
 		return n.Position
 	case *DeprecatedAttr:
 		return n.Position
+	case *DestructorAttr:
+		return n.Position
+	case *DesignatedInitExpr:
+		return n.Position
 	case *DoStmt:
 		return n.Position
 	case *ElaboratedType:
@@ -73,6 +87,8 @@ func Position(node Node) string {
 		return n.Position
 	case *EnumType:
 		return ""
+	case *Field:
+		return ""
 	case *FieldDecl:
 		return n.Position
 	case *FloatingLiteral:
@@ -85,10 +101,16 @@ func Position(node Node) string {
 		return ""
 	case *ForStmt:
 		return n.Position
+	case *GenericAssociation:
+		return ""
+	case *GenericSelectionExpr:
+		return n.Position
 	case *GotoStmt:
 		return n.Position
 	case *IfStmt:
 		return n.Position
+	case *ImaginaryLiteral:
+		return n.Position
 	case *ImplicitCastExpr:
 		return n.Position
 	case *ImplicitValueInitExpr:
@@ -97,6 +119,8 @@ func Position(node Node) string {
 		return ""
 	case *IndirectFieldDecl:
 		return n.Position
+	case *IndirectGotoStmt:
+		return n.Position
 	case *InitListExpr:
 		return n.Position
 	case *IntegerLiteral:
@@ -119,6 +143,8 @@ func Position(node Node) string {
 		return n.Position
 	case *OffsetOfExpr:
 		return n.Position
+	case *OpaqueValueExpr:
+		return n.Position
 	case *PackedAttr:
 		return n.Position
 	case *ParenExpr:
@@ -147,6 +173,10 @@ func Position(node Node) string {
 		return n.Position
 	case *ReturnsTwiceAttr:
 		return n.Position
+	case *StaticAssertDecl:
+		return n.Position
+	case *StmtExpr:
+		return n.Position
 	case *StringLiteral:
 		return n.Position
 	case *SwitchStmt:
@@ -155,6 +185,8 @@ func Position(node Node) string {
 		return ""
 	case *TransparentUnionAttr:
 		return n.Position
+	case *TypeTraitExpr:
+		return n.Position
 	case *Typedef:
 		return ""
 	case *TypedefDecl:
@@ -165,6 +197,8 @@ func Position(node Node) string {
 		return n.Position
 	case *UnaryOperator:
 		return n.Position
+	case *UnknownNode:
+		return n.Position
 	case *VAArgExpr:
 		return n.Position
 	case *VarDecl:
@@ -229,9 +263,21 @@ func Parse(line string) Node {
 		return parseArrayFiller(line)
 	}
 
+	// Newer versions of clang put the filler on the same line, like:
+	//
+	//     array_filler: ImplicitValueInitExpr 0x7f9bf3 <<invalid sloc>> 'int'
+	if strings.HasPrefix(line, "array_filler: ") {
+		n := parseArrayFiller(line)
+		n.AddChild(Parse(strings.TrimPrefix(line, "array_filler: ")))
+
+		return n
+	}
+
 	nodeName := strings.SplitN(line, " ", 2)[0]
 
 	switch nodeName {
+	case "AddrLabelExpr":
+		return parseAddrLabelExpr(line)
 	case "AlignedAttr":
 		return parseAlignedAttr(line)
 	case "AlwaysInlineAttr":
@@ -242,6 +288,8 @@ func Parse(line string) Node {
 		return parseAsmLabelAttr(line)
 	case "AvailabilityAttr":
 		return parseAvailabilityAttr(line)
+	case "BinaryConditionalOperator":
+		return parseBinaryConditionalOperator(line)
 	case "BinaryOperator":
 		return parseBinaryOperator(line)
 	case "BreakStmt":
@@ -254,6 +302,8 @@ func Parse(line string) Node {
 		return parseCaseStmt(line)
 	case "CharacterLiteral":
 		return parseCharacterLiteral(line)
+	case "CleanupAttr":
+		return parseCleanupAttr(line)
 	case "CompoundStmt":
 		return parseCompoundStmt(line)
 	case "ConditionalOperator":
@@ -262,10 +312,14 @@ func Parse(line string) Node {
 		return parseConstAttr(line)
 	case "ConstantArrayType":
 		return parseConstantArrayType(line)
+	case "ConstructorAttr":
+		return parseConstructorAttr(line)
 	case "ContinueStmt":
 		return parseContinueStmt(line)
 	case "CompoundAssignOperator":
 		return parseCompoundAssignOperator(line)
+	case "CompoundLiteralExpr":
+		return parseCompoundLiteralExpr(line)
 	case "CStyleCastExpr":
 		return parseCStyleCastExpr(line)
 	case "DeclRefExpr":
@@ -276,6 +330,10 @@ func Parse(line string) Node {
 		return parseDefaultStmt(line)
 	case "DeprecatedAttr":
 		return parseDeprecatedAttr(line)
+	case "DestructorAttr":
+		return parseDestructorAttr(line)
+	case "DesignatedInitExpr":
+		return parseDesignatedInitExpr(line)
 	case "DoStmt":
 		return parseDoStmt(line)
 	case "ElaboratedType":
@@ -288,6 +346,8 @@ func Parse(line string) Node {
 		return parseEnumDecl(line)
 	case "EnumType":
 		return parseEnumType(line)
+	case "Field":
+		return parseField(line)
 	case "FieldDecl":
 		return parseFieldDecl(line)
 	case "FloatingLiteral":
@@ -300,10 +360,14 @@ func Parse(line string) Node {
 		return parseFunctionProtoType(line)
 	case "ForStmt":
 		return parseForStmt(line)
+	case "GenericSelectionExpr":
+		return parseGenericSelectionExpr(line)
 	case "GotoStmt":
 		return parseGotoStmt(line)
 	case "IfStmt":
 		return parseIfStmt(line)
+	case "ImaginaryLiteral":
+		return parseImaginaryLiteral(line)
 	case "ImplicitCastExpr":
 		return parseImplicitCastExpr(line)
 	case "ImplicitValueInitExpr":
@@ -312,6 +376,8 @@ func Parse(line string) Node {
 		return parseIncompleteArrayType(line)
 	case "IndirectFieldDecl":
 		return parseIndirectFieldDecl(line)
+	case "IndirectGotoStmt":
+		return parseIndirectGotoStmt(line)
 	case "InitListExpr":
 		return parseInitListExpr(line)
 	case "IntegerLiteral":
@@ -334,6 +400,8 @@ func Parse(line string) Node {
 		return parseNonNullAttr(line)
 	case "OffsetOfExpr":
 		return parseOffsetOfExpr(line)
+	case "OpaqueValueExpr":
+		return parseOpaqueValueExpr(line)
 	case "PackedAttr":
 		return parsePackedAttr(line)
 	case "ParenExpr":
@@ -362,6 +430,10 @@ func Parse(line string) Node {
 		return parseReturnStmt(line)
 	case "ReturnsTwiceAttr":
 		return parseReturnsTwiceAttr(line)
+	case "StaticAssertDecl":
+		return parseStaticAssertDecl(line)
+	case "StmtExpr":
+		return parseStmtExpr(line)
 	case "StringLiteral":
 		return parseStringLiteral(line)
 	case "SwitchStmt":
@@ -370,6 +442,8 @@ func Parse(line string) Node {
 		return parseTranslationUnitDecl(line)
 	case "TransparentUnionAttr":
 		return parseTransparentUnionAttr(line)
+	case "TypeTraitExpr":
+		return parseTypeTraitExpr(line)
 	case "Typedef":
 		return parseTypedef(line)
 	case "TypedefDecl":
@@ -392,8 +466,13 @@ func Parse(line string) Node {
 		return parseWhileStmt(line)
 	case "NullStmt":
 		return nil
+	case "case", "default":
+		// The associations of a _Generic() selection.
+		return parseGenericAssociation(line)
 	default:
-		panic("unknown node type: '" + line + "'")
+		// The transpiler panics on an unknown node, unless the unsupported
+		// nodes are reported.
+		return parseUnknownNode(nodeName, line)
 	}
 }
 
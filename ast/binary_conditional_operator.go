@@ -0,0 +1,28 @@
+package ast
+
+type BinaryConditionalOperator struct {
+	Address  string
+	Position string
+	Type     string
+	Children []Node
+}
+
+func parseBinaryConditionalOperator(line string) *BinaryConditionalOperator {
+	groups := groupsFromRegex(
+		`<(?P<position>.*)> '(?P<type>.*?)'`,
+		line,
+	)
+
+	return &BinaryConditionalOperator{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Type:     groups["type"],
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *BinaryConditionalOperator) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
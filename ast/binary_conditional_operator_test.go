@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestBinaryConditionalOperator(t *testing.T) {
+	nodes := map[string]Node{
+		`0x7fca2d8070e0 <col:11, col:23> 'char *'`: &BinaryConditionalOperator{
+			Address:  "0x7fca2d8070e0",
+			Position: "col:11, col:23",
+			Type:     "char *",
+			Children: []Node{},
+		},
+		`0x2a8d9f8 <line:7:9, col:16> 'int'`: &BinaryConditionalOperator{
+			Address:  "0x2a8d9f8",
+			Position: "line:7:9, col:16",
+			Type:     "int",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
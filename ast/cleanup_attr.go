@@ -0,0 +1,41 @@
+package ast
+
+// CleanupAttr is the cleanup attribute of a variable:
+//
+//     char *s __attribute__((cleanup(free_string))) = malloc(16);
+//
+// The function is called with the address of the variable when it goes out of
+// scope.
+type CleanupAttr struct {
+	Address         string
+	Position        string
+	FunctionAddress string
+	FunctionName    string
+	FunctionType    string
+	Children        []Node
+}
+
+func parseCleanupAttr(line string) *CleanupAttr {
+	groups := groupsFromRegex(
+		`<(?P<position>.*)>
+		 Function (?P<function_address>[0-9a-fx]+)
+		 '(?P<name>.+?)'
+		 '(?P<type>.+?)'`,
+		line,
+	)
+
+	return &CleanupAttr{
+		Address:         groups["address"],
+		Position:        groups["position"],
+		FunctionAddress: groups["function_address"],
+		FunctionName:    groups["name"],
+		FunctionType:    groups["type"],
+		Children:        []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *CleanupAttr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,20 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestCleanupAttr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55e3c2a1b4d8 <col:25, col:44> Function 0x55e3c2a1a2f0 'free_string' 'void (char **)'`: &CleanupAttr{
+			Address:         "0x55e3c2a1b4d8",
+			Position:        "col:25, col:44",
+			FunctionAddress: "0x55e3c2a1a2f0",
+			FunctionName:    "free_string",
+			FunctionType:    "void (char **)",
+			Children:        []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -4,6 +4,7 @@ type CompoundAssignOperator struct {
 	Address               string
 	Position              string
 	Type                  string
+	Type2                 string
 	Opcode                string
 	ComputationLHSType    string
 	ComputationResultType string
@@ -13,7 +14,7 @@ type CompoundAssignOperator struct {
 func parseCompoundAssignOperator(line string) *CompoundAssignOperator {
 	groups := groupsFromRegex(
 		`<(?P<position>.*)>
-		 '(?P<type>.+?)'
+		 '(?P<type>.+?)'(:'(?P<type2>.*?)')?
 		 '(?P<opcode>.+?)'
 		 ComputeLHSTy='(?P<clhstype>.+?)'
 		 ComputeResultTy='(?P<crestype>.+?)'`,
@@ -24,6 +25,7 @@ func parseCompoundAssignOperator(line string) *CompoundAssignOperator {
 		Address:               groups["address"],
 		Position:              groups["position"],
 		Type:                  groups["type"],
+		Type2:                 groups["type2"],
 		Opcode:                groups["opcode"],
 		ComputationLHSType:    groups["clhstype"],
 		ComputationResultType: groups["crestype"],
@@ -15,6 +15,16 @@ func TestCompoundAssignOperator(t *testing.T) {
 			ComputationResultType: "int",
 			Children:              []Node{},
 		},
+		`0x7f8a2c0a4e38 <line:9:5, col:10> 'size_t':'unsigned long' '+=' ComputeLHSTy='unsigned long' ComputeResultTy='unsigned long'`: &CompoundAssignOperator{
+			Address:               "0x7f8a2c0a4e38",
+			Position:              "line:9:5, col:10",
+			Type:                  "size_t",
+			Type2:                 "unsigned long",
+			Opcode:                "+=",
+			ComputationLHSType:    "unsigned long",
+			ComputationResultType: "unsigned long",
+			Children:              []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
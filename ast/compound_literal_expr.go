@@ -0,0 +1,32 @@
+package ast
+
+type CompoundLiteralExpr struct {
+	Address  string
+	Position string
+	Type     string
+	Type2    string
+	Lvalue   bool
+	Children []Node
+}
+
+func parseCompoundLiteralExpr(line string) *CompoundLiteralExpr {
+	groups := groupsFromRegex(
+		"<(?P<position>.*)> '(?P<type>.*?)'(:'(?P<type2>.*?)')?(?P<lvalue> lvalue)?",
+		line,
+	)
+
+	return &CompoundLiteralExpr{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Type:     groups["type"],
+		Type2:    groups["type2"],
+		Lvalue:   len(groups["lvalue"]) > 0,
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *CompoundLiteralExpr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,28 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestCompoundLiteralExpr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x7fd3e2836c58 <col:9, col:30> 'struct Point':'struct Point' lvalue`: &CompoundLiteralExpr{
+			Address:  "0x7fd3e2836c58",
+			Position: "col:9, col:30",
+			Type:     "struct Point",
+			Type2:    "struct Point",
+			Lvalue:   true,
+			Children: []Node{},
+		},
+		`0x2b4c7a8 <col:14, col:28> 'int [3]' lvalue`: &CompoundLiteralExpr{
+			Address:  "0x2b4c7a8",
+			Position: "col:14, col:28",
+			Type:     "int [3]",
+			Type2:    "",
+			Lvalue:   true,
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -0,0 +1,49 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/elliotchance/c2go/util"
+)
+
+// ConstructorAttr is the constructor attribute of a function:
+//
+//     void setup() __attribute__((constructor(101)));
+//
+// The function is called automatically before main(). The
+// Priority is 65535 (the lowest priority) if it is not specified.
+type ConstructorAttr struct {
+	Address   string
+	Position  string
+	Inherited bool
+	Priority  int
+	Children  []Node
+}
+
+func parseConstructorAttr(line string) *ConstructorAttr {
+	groups := groupsFromRegex(
+		`<(?P<position>.*)>
+		(?P<inherited> Inherited)?
+		(?P<priority> \d+)?`,
+		line,
+	)
+
+	priority := 65535
+	if groups["priority"] != "" {
+		priority = util.Atoi(strings.TrimSpace(groups["priority"]))
+	}
+
+	return &ConstructorAttr{
+		Address:   groups["address"],
+		Position:  groups["position"],
+		Inherited: len(groups["inherited"]) > 0,
+		Priority:  priority,
+		Children:  []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *ConstructorAttr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,33 @@
+package ast
+
+// DesignatedInitExpr is an initializer with a designator, like ".x = 1" or
+// "[3] = 5". The last child is the value. An array designator also has the
+// index expression as the first child.
+type DesignatedInitExpr struct {
+	Address  string
+	Position string
+	Type1    string
+	Type2    string
+	Children []Node
+}
+
+func parseDesignatedInitExpr(line string) *DesignatedInitExpr {
+	groups := groupsFromRegex(
+		"<(?P<position>.*)> '(?P<type1>.*?)'(:'(?P<type2>.*)')?",
+		line,
+	)
+
+	return &DesignatedInitExpr{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Type1:    groups["type1"],
+		Type2:    groups["type2"],
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *DesignatedInitExpr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
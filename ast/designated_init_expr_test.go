@@ -0,0 +1,26 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestDesignatedInitExpr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x7fd2b5042d80 <col:20, col:25> 'int'`: &DesignatedInitExpr{
+			Address:  "0x7fd2b5042d80",
+			Position: "col:20, col:25",
+			Type1:    "int",
+			Type2:    "",
+			Children: []Node{},
+		},
+		`0x7fd2b5042e28 <col:13, col:24> 'size_t':'unsigned long'`: &DesignatedInitExpr{
+			Address:  "0x7fd2b5042e28",
+			Position: "col:13, col:24",
+			Type1:    "size_t",
+			Type2:    "unsigned long",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
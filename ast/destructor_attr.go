@@ -0,0 +1,49 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/elliotchance/c2go/util"
+)
+
+// DestructorAttr is the destructor attribute of a function:
+//
+//     void cleanup() __attribute__((destructor(101)));
+//
+// The function is called automatically after main() returns or exit() is called. The
+// Priority is 65535 (the lowest priority) if it is not specified.
+type DestructorAttr struct {
+	Address   string
+	Position  string
+	Inherited bool
+	Priority  int
+	Children  []Node
+}
+
+func parseDestructorAttr(line string) *DestructorAttr {
+	groups := groupsFromRegex(
+		`<(?P<position>.*)>
+		(?P<inherited> Inherited)?
+		(?P<priority> \d+)?`,
+		line,
+	)
+
+	priority := 65535
+	if groups["priority"] != "" {
+		priority = util.Atoi(strings.TrimSpace(groups["priority"]))
+	}
+
+	return &DestructorAttr{
+		Address:   groups["address"],
+		Position:  groups["position"],
+		Inherited: len(groups["inherited"]) > 0,
+		Priority:  priority,
+		Children:  []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *DestructorAttr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
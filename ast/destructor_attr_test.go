@@ -0,0 +1,37 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestDestructorAttr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x7f8a1d8ccfd0 <col:33> 65535`: &DestructorAttr{
+			Address:  "0x7f8a1d8ccfd0",
+			Position: "col:33",
+			Priority: 65535,
+			Children: []Node{},
+		},
+		`0x7f8a1d8cd0e8 <col:33> Inherited 200`: &DestructorAttr{
+			Address:   "0x7f8a1d8cd0e8",
+			Position:  "col:33",
+			Inherited: true,
+			Priority:  200,
+			Children:  []Node{},
+		},
+		`0x55d0c5a2b3c8 <line:3:16, col:31> 101`: &DestructorAttr{
+			Address:  "0x55d0c5a2b3c8",
+			Position: "line:3:16, col:31",
+			Priority: 101,
+			Children: []Node{},
+		},
+		`0x55d0c5a2b3c8 <col:16>`: &DestructorAttr{
+			Address:  "0x55d0c5a2b3c8",
+			Position: "col:16",
+			Priority: 65535,
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -13,6 +13,20 @@ func TestEnumDecl(t *testing.T) {
 			Name:      "__codecvt_result",
 			Children:  []Node{},
 		},
+		`0x7f8a2b0222c0 <line:3:1, line:7:1> line:3:6 color`: &EnumDecl{
+			Address:   "0x7f8a2b0222c0",
+			Position:  "line:3:1, line:7:1",
+			Position2: "line:3:6",
+			Name:      "color",
+			Children:  []Node{},
+		},
+		`0x7f8a2b022500 <line:9:9, line:12:1> line:9:9`: &EnumDecl{
+			Address:   "0x7f8a2b022500",
+			Position:  "line:9:9, line:12:1",
+			Position2: "line:9:9",
+			Name:      "",
+			Children:  []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
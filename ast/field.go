@@ -0,0 +1,31 @@
+package ast
+
+// Field is the field of a struct that an IndirectFieldDecl refers to. The name
+// is empty for the anonymous struct or union that contains the field.
+type Field struct {
+	Address  string
+	Name     string
+	Type     string
+	Children []Node
+}
+
+func parseField(line string) *Field {
+	groups := groupsFromRegex(
+		`'(?P<name>.*?)'
+		 '(?P<type>.*)'`,
+		line,
+	)
+
+	return &Field{
+		Address:  groups["address"],
+		Name:     groups["name"],
+		Type:     groups["type"],
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *Field) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -8,6 +8,7 @@ type FieldDecl struct {
 	Address    string
 	Position   string
 	Position2  string
+	Implicit   bool
 	Name       string
 	Type       string
 	Referenced bool
@@ -18,6 +19,7 @@ func parseFieldDecl(line string) *FieldDecl {
 	groups := groupsFromRegex(
 		`<(?P<position>.*)>
 		(?P<position2> col:\d+| line:\d+:\d+)?
+		(?P<implicit> implicit)?
 		(?P<referenced> referenced)?
 		(?P<name> \w+?)?
 		 '(?P<type>.+?)'`,
@@ -28,6 +30,7 @@ func parseFieldDecl(line string) *FieldDecl {
 		Address:    groups["address"],
 		Position:   groups["position"],
 		Position2:  strings.TrimSpace(groups["position2"]),
+		Implicit:   len(groups["implicit"]) > 0,
 		Name:       strings.TrimSpace(groups["name"]),
 		Type:       groups["type"],
 		Referenced: len(groups["referenced"]) > 0,
@@ -6,6 +6,16 @@ import (
 
 func TestFieldDecl(t *testing.T) {
 	nodes := map[string]Node{
+		`0x7f9a2c0a66c8 <line:3:5> col:5 implicit 'union (anonymous union at main.c:3:5)':'union (anonymous at main.c:3:5)'`: &FieldDecl{
+			Address:    "0x7f9a2c0a66c8",
+			Position:   "line:3:5",
+			Position2:  "col:5",
+			Implicit:   true,
+			Name:       "",
+			Type:       "union (anonymous union at main.c:3:5)",
+			Referenced: false,
+			Children:   []Node{},
+		},
 		`0x7fef510c4848 <line:141:2, col:6> col:6 _ur 'int'`: &FieldDecl{
 			Address:    "0x7fef510c4848",
 			Position:   "line:141:2, col:6",
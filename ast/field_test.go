@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestField(t *testing.T) {
+	nodes := map[string]Node{
+		`0x7f9a2c0a66c8 '' 'union (anonymous union at main.c:3:5)'`: &Field{
+			Address:  "0x7f9a2c0a66c8",
+			Name:     "",
+			Type:     "union (anonymous union at main.c:3:5)",
+			Children: []Node{},
+		},
+		`0x7f9a2c0a6568 'i' 'int'`: &Field{
+			Address:  "0x7f9a2c0a6568",
+			Name:     "i",
+			Type:     "int",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
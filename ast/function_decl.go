@@ -12,6 +12,8 @@ type FunctionDecl struct {
 	Name         string
 	Type         string
 	IsExtern     bool
+	IsStatic     bool
+	IsInline     bool
 	IsImplicit   bool
 	IsUsed       bool
 	IsReferenced bool
@@ -28,7 +30,9 @@ func parseFunctionDecl(line string) *FunctionDecl {
 		(?P<referenced> referenced)?
 		 (?P<name>[_\w]+)
 		 '(?P<type>.*)
-		'(?P<extern> extern)?`,
+		'(?P<extern> extern)?
+		(?P<static> static)?
+		(?P<inline> inline)?`,
 		line,
 	)
 
@@ -45,6 +49,8 @@ func parseFunctionDecl(line string) *FunctionDecl {
 		Name:         groups["name"],
 		Type:         groups["type"],
 		IsExtern:     len(groups["extern"]) > 0,
+		IsStatic:     len(groups["static"]) > 0,
+		IsInline:     len(groups["inline"]) > 0,
 		IsImplicit:   len(groups["implicit"]) > 0,
 		IsUsed:       len(groups["used"]) > 0,
 		IsReferenced: len(groups["referenced"]) > 0,
@@ -97,6 +97,21 @@ func TestFunctionDecl(t *testing.T) {
 			IsReferenced: true,
 			Children:     []Node{},
 		},
+		`0x55d0c8e3a2b8 <util.h:1:1, line:4:1> line:1:19 used max 'int (int, int)' static inline`: &FunctionDecl{
+			Address:      "0x55d0c8e3a2b8",
+			Position:     "util.h:1:1, line:4:1",
+			Prev:         "",
+			Position2:    "line:1:19",
+			Name:         "max",
+			Type:         "int (int, int)",
+			IsExtern:     false,
+			IsStatic:     true,
+			IsInline:     true,
+			IsImplicit:   false,
+			IsUsed:       true,
+			IsReferenced: false,
+			Children:     []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
@@ -0,0 +1,76 @@
+package ast
+
+import (
+	"regexp"
+)
+
+// GenericSelectionExpr is a C11 _Generic() selection:
+//
+//     _Generic(x, int: f, double: g, default: h)
+//
+// The first child is the controlling expression. It is followed by a
+// GenericAssociation for each of the types.
+type GenericSelectionExpr struct {
+	Address  string
+	Position string
+	Type     string
+	Children []Node
+}
+
+func parseGenericSelectionExpr(line string) *GenericSelectionExpr {
+	groups := groupsFromRegex(
+		"<(?P<position>.*)> '(?P<type>.+?)'",
+		line,
+	)
+
+	return &GenericSelectionExpr{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Type:     groups["type"],
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *GenericSelectionExpr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
+
+// GenericAssociation is one of the "type: expression" pairs of a _Generic()
+// selection. clang does not give it an address:
+//
+//     case  'int' selected
+//     default
+//
+// The last child is the expression. The association of a type also has the
+// type as its first child.
+type GenericAssociation struct {
+	Type      string
+	IsDefault bool
+	Selected  bool
+	Children  []Node
+}
+
+var genericAssociationRegexp = regexp.MustCompile(
+	`^(?P<kind>case|default)(?: +'(?P<type>.+?)'(?::'.+?')?)?(?P<selected> selected)?$`)
+
+func parseGenericAssociation(line string) *GenericAssociation {
+	match := genericAssociationRegexp.FindStringSubmatch(line)
+	if match == nil {
+		panic("could not match generic association: '" + line + "'")
+	}
+
+	return &GenericAssociation{
+		Type:      match[2],
+		IsDefault: match[1] == "default",
+		Selected:  match[3] != "",
+		Children:  []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *GenericAssociation) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,59 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenericSelectionExpr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55c6e3a1d2b8 <col:12, col:45> 'int'`: &GenericSelectionExpr{
+			Address:  "0x55c6e3a1d2b8",
+			Position: "col:12, col:45",
+			Type:     "int",
+			Children: []Node{},
+		},
+		`0x55c6e3a1d3c0 <line:4:12, col:57> 'double (*)(double)'`: &GenericSelectionExpr{
+			Address:  "0x55c6e3a1d3c0",
+			Position: "line:4:12, col:57",
+			Type:     "double (*)(double)",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
+
+func TestGenericAssociation(t *testing.T) {
+	// The associations do not have the name of a node in front of them.
+	nodes := map[string]Node{
+		`case  'int' selected`: &GenericAssociation{
+			Type:     "int",
+			Selected: true,
+			Children: []Node{},
+		},
+		`case  'number':'int'`: &GenericAssociation{
+			Type:     "number",
+			Children: []Node{},
+		},
+		`case  'unsigned long'`: &GenericAssociation{
+			Type:     "unsigned long",
+			Children: []Node{},
+		},
+		`default`: &GenericAssociation{
+			IsDefault: true,
+			Children:  []Node{},
+		},
+		`default selected`: &GenericAssociation{
+			IsDefault: true,
+			Selected:  true,
+			Children:  []Node{},
+		},
+	}
+
+	for line, expected := range nodes {
+		if actual := Parse(line); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected %#v, got %#v", line, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package ast
+
+type ImaginaryLiteral struct {
+	Address  string
+	Position string
+	Type     string
+	Children []Node
+}
+
+func parseImaginaryLiteral(line string) *ImaginaryLiteral {
+	groups := groupsFromRegex(
+		"<(?P<position>.*)> '(?P<type>.*?)'",
+		line,
+	)
+
+	return &ImaginaryLiteral{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Type:     groups["type"],
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *ImaginaryLiteral) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,18 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestImaginaryLiteral(t *testing.T) {
+	nodes := map[string]Node{
+		`0x7fcc0b0837c8 <col:20> '_Complex double'`: &ImaginaryLiteral{
+			Address:  "0x7fcc0b0837c8",
+			Position: "col:20",
+			Type:     "_Complex double",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
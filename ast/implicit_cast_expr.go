@@ -4,13 +4,14 @@ type ImplicitCastExpr struct {
 	Address  string
 	Position string
 	Type     string
+	Type2    string
 	Kind     string
 	Children []Node
 }
 
 func parseImplicitCastExpr(line string) *ImplicitCastExpr {
 	groups := groupsFromRegex(
-		"<(?P<position>.*)> '(?P<type>.*)' <(?P<kind>.*)>",
+		"<(?P<position>.*)> '(?P<type>.*?)'(:'(?P<type2>.*?)')? <(?P<kind>.*)>",
 		line,
 	)
 
@@ -18,6 +19,7 @@ func parseImplicitCastExpr(line string) *ImplicitCastExpr {
 		Address:  groups["address"],
 		Position: groups["position"],
 		Type:     groups["type"],
+		Type2:    groups["type2"],
 		Kind:     groups["kind"],
 		Children: []Node{},
 	}
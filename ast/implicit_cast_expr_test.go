@@ -20,6 +20,14 @@ func TestImplicitCastExpr(t *testing.T) {
 			Kind:     "FunctionToPointerDecay",
 			Children: []Node{},
 		},
+		`0x7f9f5b0a9a10 <col:13> 'size_t':'unsigned long' <IntegralCast>`: &ImplicitCastExpr{
+			Address:  "0x7f9f5b0a9a10",
+			Position: "col:13",
+			Type:     "size_t",
+			Type2:    "unsigned long",
+			Kind:     "IntegralCast",
+			Children: []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
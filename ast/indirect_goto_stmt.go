@@ -0,0 +1,28 @@
+package ast
+
+// IndirectGotoStmt is a computed goto, like "goto *target;". It is a GNU
+// extension. The only child is the address of the label.
+type IndirectGotoStmt struct {
+	Address  string
+	Position string
+	Children []Node
+}
+
+func parseIndirectGotoStmt(line string) *IndirectGotoStmt {
+	groups := groupsFromRegex(
+		"<(?P<position>.*)>",
+		line,
+	)
+
+	return &IndirectGotoStmt{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *IndirectGotoStmt) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,17 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestIndirectGotoStmt(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55dd7c8f6d28 <line:12:5, col:16>`: &IndirectGotoStmt{
+			Address:  "0x55dd7c8f6d28",
+			Position: "line:12:5, col:16",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -4,12 +4,13 @@ type InitListExpr struct {
 	Address  string
 	Position string
 	Type     string
+	Type2    string
 	Children []Node
 }
 
 func parseInitListExpr(line string) *InitListExpr {
 	groups := groupsFromRegex(
-		"<(?P<position>.*)> '(?P<type>.*)'",
+		"<(?P<position>.*)> '(?P<type>.*?)'(:'(?P<type2>.*?)')?",
 		line,
 	)
 
@@ -17,6 +18,7 @@ func parseInitListExpr(line string) *InitListExpr {
 		Address:  groups["address"],
 		Position: groups["position"],
 		Type:     groups["type"],
+		Type2:    groups["type2"],
 		Children: []Node{},
 	}
 }
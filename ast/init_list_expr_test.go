@@ -10,6 +10,21 @@ func TestInitListExpr(t *testing.T) {
 			Address:  "0x7fbdd1906c20",
 			Position: "col:52, line:17160:1",
 			Type:     "const unsigned char [256]",
+			Type2:    "",
+			Children: []Node{},
+		},
+		`0x32017f0 <col:24, col:41> 'struct point':'struct point'`: &InitListExpr{
+			Address:  "0x32017f0",
+			Position: "col:24, col:41",
+			Type:     "struct point",
+			Type2:    "struct point",
+			Children: []Node{},
+		},
+		`0x7f9f0a8021c0 <col:23, col:26> 'Point':'struct Point'`: &InitListExpr{
+			Address:  "0x7f9f0a8021c0",
+			Position: "col:23, col:26",
+			Type:     "Point",
+			Type2:    "struct Point",
 			Children: []Node{},
 		},
 	}
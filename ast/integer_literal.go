@@ -10,7 +10,7 @@ type IntegerLiteral struct {
 
 func parseIntegerLiteral(line string) *IntegerLiteral {
 	groups := groupsFromRegex(
-		"<(?P<position>.*)> '(?P<type>.*?)' (?P<value>\\d+)",
+		"<(?P<position>.*)> '(?P<type>.*?)' (?P<value>-?[0-9][0-9a-fA-FxXoO']*[uUlL]*)",
 		line,
 	)
 
@@ -13,6 +13,27 @@ func TestIntegerLiteral(t *testing.T) {
 			Value:    "1",
 			Children: []Node{},
 		},
+		`0x7fbe9804bcd0 <col:14> 'unsigned long long' 18446744073709551615`: &IntegerLiteral{
+			Address:  "0x7fbe9804bcd0",
+			Position: "col:14",
+			Type:     "unsigned long long",
+			Value:    "18446744073709551615",
+			Children: []Node{},
+		},
+		`0x7fbe9804bcd8 <col:14> 'int' -1`: &IntegerLiteral{
+			Address:  "0x7fbe9804bcd8",
+			Position: "col:14",
+			Type:     "int",
+			Value:    "-1",
+			Children: []Node{},
+		},
+		`0x7fbe9804bce0 <col:14> 'unsigned int' 0xFFFFFFFFU`: &IntegerLiteral{
+			Address:  "0x7fbe9804bce0",
+			Position: "col:14",
+			Type:     "unsigned int",
+			Value:    "0xFFFFFFFFU",
+			Children: []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
@@ -1,10 +1,16 @@
 package ast
 
+import (
+	"strings"
+)
+
 type MemberExpr struct {
 	Address  string
 	Position string
 	Type     string
+	Type2    string
 	Lvalue   bool
+	Bitfield bool
 	Name     string
 	Address2 string
 	Children []Node
@@ -13,10 +19,10 @@ type MemberExpr struct {
 func parseMemberExpr(line string) *MemberExpr {
 	groups := groupsFromRegex(
 		`<(?P<position>.*)>
-		 '(?P<type>.*?)'
+		 '(?P<type>.*?)'(:'(?P<type2>.*?)')?
 		 (?P<tags>.*?)
-		(?P<name>\w+)
-		 (?P<address2>[0-9a-fx]+)`,
+		(?P<name>\w*)
+		 (?P<address2>[0-9a-fx]+)$`,
 		line,
 	)
 
@@ -24,7 +30,9 @@ func parseMemberExpr(line string) *MemberExpr {
 		Address:  groups["address"],
 		Position: groups["position"],
 		Type:     groups["type"],
+		Type2:    groups["type2"],
 		Lvalue:   true,
+		Bitfield: strings.Contains(groups["tags"], "bitfield"),
 		Name:     groups["name"],
 		Address2: groups["address2"],
 		Children: []Node{},
@@ -37,9 +45,25 @@ func (n *MemberExpr) AddChild(node Node) {
 	n.Children = append(n.Children, node)
 }
 
+// IsAnonymous returns true if the member is an anonymous struct or union. These
+// are the implicit members that clang adds to reach the fields of the anonymous
+// struct or union, like the ".i" in:
+//
+//     struct s { union { int i; }; } v;
+//     v.i = 3;
+func (n *MemberExpr) IsAnonymous() bool {
+	return n.Name == ""
+}
+
 // GetDeclRefExpr gets DeclRefExpr from MemberExpr, or nil if there is no DeclRefExpr
 func (n *MemberExpr) GetDeclRefExpr() *DeclRefExpr {
 	for _, child := range n.Children {
+		// The fields of an anonymous struct or union belong to the variable
+		// that contains it.
+		if member, ok := child.(*MemberExpr); ok && member.IsAnonymous() {
+			return member.GetDeclRefExpr()
+		}
+
 		res, ok := child.(*DeclRefExpr)
 		if ok {
 			return res
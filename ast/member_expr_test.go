@@ -6,6 +6,36 @@ import (
 
 func TestMemberExpr(t *testing.T) {
 	nodes := map[string]Node{
+		`0x7f9a2c0a6a18 <col:5> 'union (anonymous union at main.c:3:5)':'union (anonymous at main.c:3:5)' lvalue . 0x7f9a2c0a66c8`: &MemberExpr{
+			Address:  "0x7f9a2c0a6a18",
+			Position: "col:5",
+			Type:     "union (anonymous union at main.c:3:5)",
+			Type2:    "union (anonymous at main.c:3:5)",
+			Lvalue:   true,
+			Name:     "",
+			Address2: "0x7f9a2c0a66c8",
+			Children: []Node{},
+		},
+		`0x7fcc758e34b0 <col:8, col:10> 'Point':'struct Point' lvalue .p 0x7fcc758d60d8`: &MemberExpr{
+			Address:  "0x7fcc758e34b0",
+			Position: "col:8, col:10",
+			Type:     "Point",
+			Type2:    "struct Point",
+			Lvalue:   true,
+			Name:     "p",
+			Address2: "0x7fcc758d60d8",
+			Children: []Node{},
+		},
+		`0x7f8533832670 <col:4, col:6> 'unsigned int' lvalue bitfield .flags 0x7f85338322b8`: &MemberExpr{
+			Address:  "0x7f8533832670",
+			Position: "col:4, col:6",
+			Type:     "unsigned int",
+			Lvalue:   true,
+			Bitfield: true,
+			Name:     "flags",
+			Address2: "0x7f85338322b8",
+			Children: []Node{},
+		},
 		`0x7fcc758e34a0 <col:8, col:12> 'int' lvalue ->_w 0x7fcc758d60c8`: &MemberExpr{
 			Address:  "0x7fcc758e34a0",
 			Position: "col:8, col:12",
@@ -1,10 +1,18 @@
 package ast
 
+// OffsetOfExpr is an offsetof(). The index of each array in the member is a
+// child of the node.
 type OffsetOfExpr struct {
 	Address  string
 	Position string
 	Type     string
 	Children []Node
+
+	// The type and the member of the offsetof(), like "struct foo" and
+	// "bar[2].baz". They are not part of the clang AST, so they are read from
+	// the C code instead (see main.go).
+	StructType string
+	Member     string
 }
 
 func parseOffsetOfExpr(line string) *OffsetOfExpr {
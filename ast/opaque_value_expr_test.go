@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestOpaqueValueExpr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x7fca2d806f68 <col:11, col:23> 'char *'`: &OpaqueValueExpr{
+			Address:  "0x7fca2d806f68",
+			Position: "col:11, col:23",
+			Type:     "char *",
+			Children: []Node{},
+		},
+		`0x2a8d9c8 <col:9> 'int' lvalue`: &OpaqueValueExpr{
+			Address:  "0x2a8d9c8",
+			Position: "col:9",
+			Type:     "int",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
@@ -76,6 +76,16 @@ func TestParmVarDecl(t *testing.T) {
 			IsUsed:    true,
 			Children:  []Node{},
 		},
+		`0x7f97338356a0 <col:18, col:31> col:31 used n 'int' register`: &ParmVarDecl{
+			Address:   "0x7f97338356a0",
+			Position:  "col:18, col:31",
+			Position2: "col:31",
+			Type:      "int",
+			Name:      "n",
+			Type2:     "",
+			IsUsed:    true,
+			Children:  []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
@@ -14,6 +14,14 @@ func TestPredefinedExpr(t *testing.T) {
 			Name:     "__PRETTY_FUNCTION__",
 			Children: []Node{},
 		},
+		`0x7fe4e5814b20 <col:12> 'const char [5]' lvalue __func__`: &PredefinedExpr{
+			Address:  "0x7fe4e5814b20",
+			Position: "col:12",
+			Type:     "const char [5]",
+			Lvalue:   true,
+			Name:     "__func__",
+			Children: []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
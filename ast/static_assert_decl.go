@@ -0,0 +1,28 @@
+package ast
+
+// StaticAssertDecl is a C11 _Static_assert(). The first child is the condition
+// and the second child (if any) is the StringLiteral message.
+type StaticAssertDecl struct {
+	Address  string
+	Position string
+	Children []Node
+}
+
+func parseStaticAssertDecl(line string) *StaticAssertDecl {
+	groups := groupsFromRegex(
+		`<(?P<position>.*)>(?P<position2> [^ ]+)?`,
+		line,
+	)
+
+	return &StaticAssertDecl{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *StaticAssertDecl) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
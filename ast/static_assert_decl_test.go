@@ -0,0 +1,22 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestStaticAssertDecl(t *testing.T) {
+	nodes := map[string]Node{
+		`0x3526fd0 <line:4:1, col:53> col:1`: &StaticAssertDecl{
+			Address:  "0x3526fd0",
+			Position: "line:4:1, col:53",
+			Children: []Node{},
+		},
+		`0x7f9ba2862bf8 <tests/static_assert.c:7:5, col:45>`: &StaticAssertDecl{
+			Address:  "0x7f9ba2862bf8",
+			Position: "tests/static_assert.c:7:5, col:45",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
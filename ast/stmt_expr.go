@@ -0,0 +1,30 @@
+package ast
+
+// StmtExpr is a GNU statement expression, like "({ int y = x; y * 2; })". Its
+// value is the value of the last statement in the compound statement.
+type StmtExpr struct {
+	Address  string
+	Position string
+	Type     string
+	Children []Node
+}
+
+func parseStmtExpr(line string) *StmtExpr {
+	groups := groupsFromRegex(
+		"<(?P<position>.*)> '(?P<type>.*?)'",
+		line,
+	)
+
+	return &StmtExpr{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Type:     groups["type"],
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *StmtExpr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,18 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestStmtExpr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x7f9a2c0d7d40 <line:12:5, col:79> 'void'`: &StmtExpr{
+			Address:  "0x7f9a2c0d7d40",
+			Position: "line:12:5, col:79",
+			Type:     "void",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
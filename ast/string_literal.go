@@ -1,8 +1,11 @@
 package ast
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 type StringLiteral struct {
@@ -12,15 +15,27 @@ type StringLiteral struct {
 	Value    string
 	Lvalue   bool
 	Children []Node
+
+	// The encoding prefix of the literal: "L", "u8", "u", "U" or an empty
+	// string for a narrow string.
+	Prefix string
 }
 
 func parseStringLiteral(line string) *StringLiteral {
 	groups := groupsFromRegex(
-		`<(?P<position>.*)> '(?P<type>.*)' lvalue (?P<value>".*")`,
+		`<(?P<position>.*)> '(?P<type>.*)' lvalue (?P<prefix>L|u8|u|U)?(?P<value>".*")`,
 		line,
 	)
 
-	s, err := strconv.Unquote(groups["value"])
+	var s string
+	var err error
+	switch groups["prefix"] {
+	case "L", "u", "U":
+		s, err = unquoteWideString(groups["value"], groups["prefix"] == "u")
+	default:
+		s, err = strconv.Unquote(groups["value"])
+	}
+
 	if err != nil {
 		panic(fmt.Sprintf("Unable to unquote %s\n", groups["value"]))
 	}
@@ -32,9 +47,111 @@ func parseStringLiteral(line string) *StringLiteral {
 		Value:    s,
 		Lvalue:   true,
 		Children: []Node{},
+		Prefix:   groups["prefix"],
 	}
 }
 
+// unquoteWideString decodes a wide string literal. Each character of a wide
+// string is a code unit, not a byte, and clang prints the code units that are
+// not ASCII with a hex escape of any length:
+//
+//     L"\x3a9\x3b4"    // "Ωδ"
+//
+// A hex escape ends at the first character that is not a hex digit, so clang
+// splits the literal when a hex digit follows it, like L"\x3a9""a". The code
+// units of a UTF-16 string are decoded so that surrogate pairs become a single
+// character.
+func unquoteWideString(s string, isUTF16 bool) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", errors.New("invalid syntax")
+	}
+
+	s = s[1 : len(s)-1]
+	units := []rune{}
+
+	for i := 0; i < len(s); {
+		// The quotes where the literal was split.
+		if s[i] == '"' {
+			i++
+			continue
+		}
+
+		if s[i] != '\\' {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			units = append(units, r)
+			i += size
+			continue
+		}
+
+		if i+1 >= len(s) {
+			return "", errors.New("invalid syntax")
+		}
+
+		c := s[i+1]
+		i += 2
+
+		base, maxDigits := 16, 0
+		switch {
+		case c == 'x':
+			maxDigits = len(s)
+		case c == 'u':
+			maxDigits = 4
+		case c == 'U':
+			maxDigits = 8
+		case c >= '0' && c <= '7':
+			// The first digit is part of the octal number.
+			base, maxDigits = 8, 3
+			i--
+		case c == '\'' || c == '?':
+			units = append(units, rune(c))
+			continue
+		default:
+			escaped, err := strconv.Unquote(`"\` + string(c) + `"`)
+			if err != nil {
+				return "", err
+			}
+
+			units = append(units, []rune(escaped)...)
+			continue
+		}
+
+		start := i
+		for i < len(s) && i-start < maxDigits && isDigitInBase(s[i], base) {
+			i++
+		}
+
+		value, err := strconv.ParseUint(s[start:i], base, 32)
+		if err != nil {
+			return "", err
+		}
+
+		units = append(units, rune(value))
+	}
+
+	if isUTF16 {
+		u := make([]uint16, len(units))
+		for i, unit := range units {
+			u[i] = uint16(unit)
+		}
+
+		units = utf16.Decode(u)
+	}
+
+	return string(units), nil
+}
+
+func isDigitInBase(c byte, base int) bool {
+	if c >= '0' && c <= '7' {
+		return true
+	}
+
+	if base == 8 {
+		return false
+	}
+
+	return (c >= '8' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
 // AddChild adds a new child node. Child nodes can then be accessed with the
 // Children attribute.
 func (n *StringLiteral) AddChild(node Node) {
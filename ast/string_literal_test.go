@@ -22,6 +22,50 @@ func TestStringLiteral(t *testing.T) {
 			Value:    "x\vx\x00xxx\axx\tx\n",
 			Children: []Node{},
 		},
+		`0x22ac5a0 <col:15, col:22> 'char [6]' lvalue "ab\000\303\251"`: &StringLiteral{
+			Address:  "0x22ac5a0",
+			Position: "col:15, col:22",
+			Type:     "char [6]",
+			Lvalue:   true,
+			Value:    "ab\x00\xc3\xa9",
+			Children: []Node{},
+		},
+		`0x22ac5c8 <col:15> 'int [4]' lvalue L"a\x3a9""b"`: &StringLiteral{
+			Address:  "0x22ac5c8",
+			Position: "col:15",
+			Type:     "int [4]",
+			Lvalue:   true,
+			Value:    "aΩb",
+			Children: []Node{},
+			Prefix:   "L",
+		},
+		`0x22ac5f0 <col:15> 'char [4]' lvalue u8"\303\251x"`: &StringLiteral{
+			Address:  "0x22ac5f0",
+			Position: "col:15",
+			Type:     "char [4]",
+			Lvalue:   true,
+			Value:    "éx",
+			Children: []Node{},
+			Prefix:   "u8",
+		},
+		`0x22ac618 <col:15> 'unsigned short [4]' lvalue u"\xd83d\xde00\n"`: &StringLiteral{
+			Address:  "0x22ac618",
+			Position: "col:15",
+			Type:     "unsigned short [4]",
+			Lvalue:   true,
+			Value:    "\U0001f600\n",
+			Children: []Node{},
+			Prefix:   "u",
+		},
+		`0x22ac640 <col:15> 'unsigned int [4]' lvalue U"\x1f600\351\000"`: &StringLiteral{
+			Address:  "0x22ac640",
+			Position: "col:15",
+			Type:     "unsigned int [4]",
+			Lvalue:   true,
+			Value:    "\U0001f600é\x00",
+			Children: []Node{},
+			Prefix:   "U",
+		},
 	}
 
 	runNodeTests(t, nodes)
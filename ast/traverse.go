@@ -12,6 +12,11 @@ import (
 func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 	nodes := []Node{}
 
+	// Some children are nil, like the optional parts of an IfStmt.
+	if root == nil {
+		return nodes
+	}
+
 	if reflect.TypeOf(root) == t {
 		nodes = append(nodes, root)
 	}
@@ -24,6 +29,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 	// comprehensive switch statements. Code that exists now or in the future
 	// should try to traverse package instead.
 	switch n := root.(type) {
+	case *AddrLabelExpr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *AlignedAttr:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -32,6 +41,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *ArrayFiller:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *ArraySubscriptExpr:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -44,6 +57,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *BinaryConditionalOperator:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *BinaryOperator:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -68,6 +85,14 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *CompoundLiteralExpr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
+	case *CleanupAttr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *CompoundStmt:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -80,6 +105,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *ConstructorAttr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *ConstantArrayType:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -108,10 +137,18 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *DestructorAttr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *DeprecatedAttr:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *DesignatedInitExpr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *DoStmt:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -136,6 +173,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *Field:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *FieldDecl:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -160,6 +201,14 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *GenericAssociation:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
+	case *GenericSelectionExpr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *GotoStmt:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -168,6 +217,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *ImaginaryLiteral:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *ImplicitCastExpr:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -180,6 +233,14 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *IndirectFieldDecl:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
+	case *IndirectGotoStmt:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *InitListExpr:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -224,6 +285,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *OpaqueValueExpr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *PackedAttr:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -280,6 +345,14 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *StaticAssertDecl:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
+	case *StmtExpr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *StringLiteral:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -296,6 +369,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *TypeTraitExpr:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *Typedef:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
@@ -316,6 +393,10 @@ func GetAllNodesOfType(root Node, t reflect.Type) []Node {
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
 		}
+	case *UnknownNode:
+		for _, c := range n.Children {
+			nodes = append(nodes, GetAllNodesOfType(c, t)...)
+		}
 	case *VAArgExpr:
 		for _, c := range n.Children {
 			nodes = append(nodes, GetAllNodesOfType(c, t)...)
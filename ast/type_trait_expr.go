@@ -0,0 +1,38 @@
+package ast
+
+import (
+	"strings"
+)
+
+// TypeTraitExpr is a builtin that is evaluated with types, like
+// __builtin_types_compatible_p(). The types are the children.
+type TypeTraitExpr struct {
+	Address  string
+	Position string
+	Type     string
+	Trait    string
+	Children []Node
+}
+
+func parseTypeTraitExpr(line string) *TypeTraitExpr {
+	groups := groupsFromRegex(
+		`<(?P<position>.*)>
+		 '(?P<type>.+?)'
+		(?P<trait> [^ ]+)?`,
+		line,
+	)
+
+	return &TypeTraitExpr{
+		Address:  groups["address"],
+		Position: groups["position"],
+		Type:     groups["type"],
+		Trait:    strings.TrimSpace(groups["trait"]),
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *TypeTraitExpr) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,26 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestTypeTraitExpr(t *testing.T) {
+	nodes := map[string]Node{
+		`0x55d0a7a1c2f0 <col:12, col:46> 'int' __builtin_types_compatible_p`: &TypeTraitExpr{
+			Address:  "0x55d0a7a1c2f0",
+			Position: "col:12, col:46",
+			Type:     "int",
+			Trait:    "__builtin_types_compatible_p",
+			Children: []Node{},
+		},
+		`0x7f8f2b0268a8 <col:12, col:27> 'int'`: &TypeTraitExpr{
+			Address:  "0x7f8f2b0268a8",
+			Position: "col:12, col:27",
+			Type:     "int",
+			Trait:    "",
+			Children: []Node{},
+		},
+	}
+
+	runNodeTests(t, nodes)
+}
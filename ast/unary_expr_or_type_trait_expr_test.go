@@ -22,6 +22,14 @@ func TestUnaryExprOrTypeTraitExpr(t *testing.T) {
 			Type2:    "",
 			Children: []Node{},
 		},
+		`0x7f8f2b0268a8 <col:12, col:27> 'unsigned long' alignof 'double'`: &UnaryExprOrTypeTraitExpr{
+			Address:  "0x7f8f2b0268a8",
+			Position: "col:12, col:27",
+			Type1:    "unsigned long",
+			Function: "alignof",
+			Type2:    "double",
+			Children: []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
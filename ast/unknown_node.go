@@ -0,0 +1,40 @@
+package ast
+
+import (
+	"regexp"
+)
+
+// UnknownNode is a node of the clang AST that c2go does not know about, like:
+//
+//     BlockExpr 0x7fd8d3 <line:5:13, line:7:3> 'int (^)(int)'
+//
+// The Name is the type of node ("BlockExpr") and Line is the complete line. An
+// unknown node can only be transpiled when the unsupported nodes are reported.
+type UnknownNode struct {
+	Name     string
+	Address  string
+	Position string
+	Line     string
+	Children []Node
+}
+
+// The address and position of an unknown node are optional.
+var unknownNodeRegexp = regexp.MustCompile(`^\S+(?: (0x[0-9a-f]+))?(?: <(.*?)>)?`)
+
+func parseUnknownNode(name, line string) *UnknownNode {
+	match := unknownNodeRegexp.FindStringSubmatch(line)
+
+	return &UnknownNode{
+		Name:     name,
+		Address:  match[1],
+		Position: match[2],
+		Line:     line,
+		Children: []Node{},
+	}
+}
+
+// AddChild adds a new child node. Child nodes can then be accessed with the
+// Children attribute.
+func (n *UnknownNode) AddChild(node Node) {
+	n.Children = append(n.Children, node)
+}
@@ -0,0 +1,35 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnknownNode(t *testing.T) {
+	tests := map[string]*UnknownNode{
+		`BlockExpr 0x7fd8d3 <line:5:13, line:7:3> 'int (^)(int)'`: {
+			Name:     "BlockExpr",
+			Address:  "0x7fd8d3",
+			Position: "line:5:13, line:7:3",
+			Line:     `BlockExpr 0x7fd8d3 <line:5:13, line:7:3> 'int (^)(int)'`,
+			Children: []Node{},
+		},
+		`OMPCaptureKindAttr 0x55d4 Implicit 1`: {
+			Name:     "OMPCaptureKindAttr",
+			Address:  "0x55d4",
+			Line:     `OMPCaptureKindAttr 0x55d4 Implicit 1`,
+			Children: []Node{},
+		},
+		`capture`: {
+			Name:     "capture",
+			Line:     `capture`,
+			Children: []Node{},
+		},
+	}
+
+	for line, expected := range tests {
+		if actual := Parse(line); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected %#v, got %#v", line, expected, actual)
+		}
+	}
+}
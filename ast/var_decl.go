@@ -12,6 +12,7 @@ type VarDecl struct {
 	Type         string
 	Type2        string
 	IsExtern     bool
+	IsStatic     bool
 	IsUsed       bool
 	IsCInit      bool
 	IsReferenced bool
@@ -27,6 +28,8 @@ func parseVarDecl(line string) *VarDecl {
 		 '(?P<type>.+?)'
 		(?P<type2>:'.*?')?
 		(?P<extern> extern)?
+		(?P<static> static)?
+		(?: auto| register)?
 		(?P<cinit> cinit)?`,
 		line,
 	)
@@ -44,6 +47,7 @@ func parseVarDecl(line string) *VarDecl {
 		Type:         groups["type"],
 		Type2:        type2,
 		IsExtern:     len(groups["extern"]) > 0,
+		IsStatic:     len(groups["static"]) > 0,
 		IsUsed:       len(groups["used"]) > 0,
 		IsCInit:      len(groups["cinit"]) > 0,
 		IsReferenced: len(groups["referenced"]) > 0,
@@ -14,6 +14,7 @@ func TestVarDecl(t *testing.T) {
 			Type:         "int",
 			Type2:        "",
 			IsExtern:     false,
+			IsStatic:     false,
 			IsUsed:       false,
 			IsCInit:      false,
 			IsReferenced: false,
@@ -27,6 +28,7 @@ func TestVarDecl(t *testing.T) {
 			Type:         "FILE *",
 			Type2:        "",
 			IsExtern:     true,
+			IsStatic:     false,
 			IsUsed:       false,
 			IsCInit:      false,
 			IsReferenced: false,
@@ -40,6 +42,7 @@ func TestVarDecl(t *testing.T) {
 			Type:         "size_t",
 			Type2:        "unsigned long",
 			IsExtern:     false,
+			IsStatic:     false,
 			IsUsed:       false,
 			IsCInit:      false,
 			IsReferenced: false,
@@ -53,6 +56,7 @@ func TestVarDecl(t *testing.T) {
 			Type:         "int",
 			Type2:        "",
 			IsExtern:     false,
+			IsStatic:     false,
 			IsUsed:       true,
 			IsCInit:      false,
 			IsReferenced: false,
@@ -66,6 +70,7 @@ func TestVarDecl(t *testing.T) {
 			Type:         "int *",
 			Type2:        "",
 			IsExtern:     false,
+			IsStatic:     false,
 			IsUsed:       true,
 			IsCInit:      true,
 			IsReferenced: false,
@@ -79,11 +84,54 @@ func TestVarDecl(t *testing.T) {
 			Type:         "short",
 			Type2:        "",
 			IsExtern:     false,
+			IsStatic:     false,
 			IsUsed:       false,
 			IsCInit:      false,
 			IsReferenced: true,
 			Children:     []Node{},
 		},
+		`0x7f9a2b05e8e8 <col:5, col:24> col:16 used counter 'int' static cinit`: &VarDecl{
+			Address:      "0x7f9a2b05e8e8",
+			Position:     "col:5, col:24",
+			Position2:    "col:16",
+			Name:         "counter",
+			Type:         "int",
+			Type2:        "",
+			IsExtern:     false,
+			IsStatic:     true,
+			IsUsed:       true,
+			IsCInit:      true,
+			IsReferenced: false,
+			Children:     []Node{},
+		},
+		`0x7f9a2b05e9a0 <col:5, col:20> col:18 used i 'int' register cinit`: &VarDecl{
+			Address:      "0x7f9a2b05e9a0",
+			Position:     "col:5, col:20",
+			Position2:    "col:18",
+			Name:         "i",
+			Type:         "int",
+			Type2:        "",
+			IsExtern:     false,
+			IsStatic:     false,
+			IsUsed:       true,
+			IsCInit:      true,
+			IsReferenced: false,
+			Children:     []Node{},
+		},
+		`0x7f9a2b05ea58 <col:5, col:14> col:14 j 'int' auto`: &VarDecl{
+			Address:      "0x7f9a2b05ea58",
+			Position:     "col:5, col:14",
+			Position2:    "col:14",
+			Name:         "j",
+			Type:         "int",
+			Type2:        "",
+			IsExtern:     false,
+			IsStatic:     false,
+			IsUsed:       false,
+			IsCInit:      false,
+			IsReferenced: false,
+			Children:     []Node{},
+		},
 	}
 
 	runNodeTests(t, nodes)
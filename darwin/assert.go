@@ -8,6 +8,10 @@ import (
 )
 
 // BuiltinExpect handles __builtin_expect().
+//
+// Deprecated: __builtin_expect() is now transpiled into its first argument, so
+// this is no longer called by the transpiled code. It will be removed in a
+// future version.
 func BuiltinExpect(a, b int) int {
 	return noarch.BoolToInt(a != b)
 }
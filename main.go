@@ -20,11 +20,13 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/elliotchance/c2go/ast"
 	"github.com/elliotchance/c2go/program"
 	"github.com/elliotchance/c2go/transpiler"
+	"github.com/elliotchance/c2go/util"
 	"reflect"
 )
 
@@ -44,9 +46,36 @@ const Version = "0.13.7"
 type ProgramArgs struct {
 	verbose     bool
 	ast         bool
-	inputFile   string
 	outputFile  string
 	packageName string
+
+	// The C files to transpile. Each file is a Go file in the same package.
+	inputFiles []string
+
+	// Add a json struct tag to each struct field.
+	structTags bool
+
+	// Use Go strings for the string parameters that are never written to.
+	goStrings bool
+
+	// Generate a stub that panics for each function that is never defined.
+	stubs bool
+
+	// Use "sync/atomic" for reading and writing volatile integers.
+	volatileAtomic bool
+
+	// A plain char is signed on the target platform.
+	signedChar bool
+
+	// Declare a Go constant for each object-like macro that is a constant.
+	macros bool
+
+	// Print the nodes that cannot be transpiled instead of the Go files.
+	reportUnsupported bool
+
+	// The functions that set errno. Each one that is called has a Go wrapper
+	// that returns errno as an error.
+	errnoFunctions []string
 }
 
 func readAST(data []byte) []string {
@@ -54,6 +83,124 @@ func readAST(data []byte) []string {
 	return strings.Split(string(uncolored), "\n")
 }
 
+// readOffsetOfMembers sets the type and the member of each offsetof() of the
+// clang AST. They are not part of the AST, so they are read from the
+// preprocessed C code at the position of the node:
+//
+//     OffsetOfExpr 0x7f9a <line:12:9, col:38> 'unsigned long'
+//
+//     __builtin_offsetof(struct foo, bar[2].baz)
+//
+// The nodes are the nodes of the lines that are not empty. A location of the
+// AST leaves out the file and the line when they are the same as the location
+// before it, so every location is followed to know the line of each node.
+func readOffsetOfMembers(lines []string, nodes []treeNode, pp []byte, ppFilePath string) {
+	// The line markers of the preprocessed code give the file and the line
+	// of the lines after them, which is what the locations are.
+	ppLines := strings.Split(string(pp), "\n")
+	ppIndexes := map[string]int{}
+	file, line := ppFilePath, 1
+	for i, l := range ppLines {
+		if match := lineMarkerRegexp.FindStringSubmatch(l); match != nil {
+			file, line = match[2], util.Atoi(match[1])
+			continue
+		}
+
+		ppIndexes[fmt.Sprintf("%s:%d", file, line)] = i
+		line++
+	}
+
+	type location struct {
+		index, column int
+	}
+
+	file, line = ppFilePath, 0
+	i := 0
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+
+		node := nodes[i].node
+		i++
+
+		// The locations are before the name and the type of the node, which
+		// may have anything in them.
+		if end := strings.IndexAny(l, `'"`); end != -1 {
+			l = l[:end]
+		}
+
+		locations := []location{}
+		for _, match := range locationRegexp.FindAllStringSubmatch(l, -1) {
+			column := match[3]
+			switch {
+			case match[1] != "":
+				line, column = util.Atoi(match[1]), match[2]
+			case match[4] != "":
+				file, line, column = match[4], util.Atoi(match[5]), match[6]
+			}
+
+			index, ok := ppIndexes[fmt.Sprintf("%s:%d", file, line)]
+			if !ok {
+				index = -1
+			}
+			locations = append(locations, location{index, util.Atoi(column)})
+		}
+
+		n, ok := node.(*ast.OffsetOfExpr)
+		if !ok || len(locations) == 0 {
+			continue
+		}
+
+		// The end of the node is the start of the closing parenthesis.
+		start, end := locations[0], locations[len(locations)-1]
+		if start.index == -1 || end.index < start.index {
+			continue
+		}
+
+		code := strings.Join(ppLines[start.index:end.index+1], "\n")
+		first, last := start.column-1, len(code)-len(ppLines[end.index])+end.column
+		if first < 0 || first >= last || last > len(code) {
+			continue
+		}
+
+		n.StructType, n.Member = parseOffsetOf(code[first:last])
+	}
+}
+
+// locationRegexp matches a location of the clang AST, like "line:3:5",
+// "col:5" or "foo.c:3:5".
+var locationRegexp = regexp.MustCompile(`line:(\d+):(\d+)|col:(\d+)|([^\s<>,]+):(\d+):(\d+)`)
+
+// parseOffsetOf returns the type and the member of an offsetof(), like
+// "struct foo" and "bar[2].baz" for "__builtin_offsetof(struct foo, bar[2].baz)".
+// The type and the member are split by the first comma that is not inside of
+// parenthesis or the braces of a struct definition.
+func parseOffsetOf(code string) (string, string) {
+	code = strings.TrimPrefix(code, "__builtin_offsetof")
+	code = strings.TrimSpace(code)
+	if !strings.HasPrefix(code, "(") || !strings.HasSuffix(code, ")") {
+		return "", ""
+	}
+
+	depth := 0
+	for i, c := range code {
+		switch c {
+		case '(', '{', '[':
+			depth++
+		case ')', '}', ']':
+			depth--
+		case ',':
+			if depth == 1 {
+				member := strings.Join(strings.Fields(code[i+1:len(code)-1]), "")
+				return strings.TrimSpace(code[1:i]), member
+			}
+		}
+	}
+
+	return "", ""
+}
+
 type treeNode struct {
 	indent int
 	node   ast.Node
@@ -149,16 +296,159 @@ func check(prefix string, e error) {
 	}
 }
 
-// Start begins transpiling an input file.
+// Start begins transpiling the input files. Each C file is translated into a
+// Go file in the same package. The files share the types and functions that
+// have been defined, so a header that is included by more than one of the files
+// is only translated once.
 func Start(args ProgramArgs) error {
 	if os.Getenv("GOPATH") == "" {
 		return fmt.Errorf("The $GOPATH must be set")
 	}
 
+	if len(args.inputFiles) == 0 {
+		return fmt.Errorf("Input file is not found")
+	}
+
+	trees := []ast.Node{}
+	macros := [][]program.Macro{}
+	for _, inputFile := range args.inputFiles {
+		tree, err := parseAST(inputFile, args.ast)
+		if err != nil {
+			return err
+		}
+
+		trees = append(trees, tree)
+
+		if args.macros {
+			m, err := readMacros(inputFile)
+			if err != nil {
+				return err
+			}
+
+			macros = append(macros, m)
+		}
+	}
+
+	p := program.NewProgram()
+	p.Verbose = args.verbose
+	p.StructTags = args.structTags
+	p.GoStrings = args.goStrings
+	p.Stubs = args.stubs
+	p.VolatileAtomic = args.volatileAtomic
+	p.SignedChar = args.signedChar
+	p.ReportUnsupported = args.reportUnsupported
+
+	for _, name := range args.errnoFunctions {
+		p.ErrnoFunctions[name] = true
+	}
+
+	// There can only be one __init() function in the package.
+	p.GoInit = len(trees) > 1
+
+	// A file may call the functions that are defined in the files after it.
+	for _, tree := range trees {
+		transpiler.RegisterDefinitions(p, tree)
+	}
+
+	outputFiles := map[string]string{}
+	failedFiles := []string{}
+	for i, tree := range trees {
+		inputFile := args.inputFiles[i]
+
+		if args.macros {
+			p.Macros = macros[i]
+		}
+
+		reported := len(p.UnsupportedNodes)
+		err := transpiler.TranspileAST(inputFile, args.packageName, p, tree)
+
+		// Every file is reported, even if one of them cannot be transpiled.
+		if args.reportUnsupported {
+			if err != nil {
+				fmt.Printf("%s: %v\n", inputFile, err)
+				failedFiles = append(failedFiles, inputFile)
+			}
+
+			fmt.Print(formatUnsupportedNodes(inputFile, p.UnsupportedNodes[reported:]))
+			continue
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		outputFilePath := getOutputFilePath(args, inputFile)
+		if other, ok := outputFiles[outputFilePath]; ok {
+			return fmt.Errorf("%s and %s are both translated to %s",
+				other, inputFile, outputFilePath)
+		}
+		outputFiles[outputFilePath] = inputFile
+
+		err = ioutil.WriteFile(outputFilePath, []byte(p.String()), 0755)
+		if err != nil {
+			return fmt.Errorf("writing C output file failed: %v", err)
+		}
+	}
+
+	if len(failedFiles) > 0 {
+		return fmt.Errorf("cannot transpile %s", strings.Join(failedFiles, ", "))
+	}
+
+	if n := len(p.UnsupportedNodes); args.reportUnsupported && n > 0 {
+		return fmt.Errorf("%d unsupported nodes", n)
+	}
+
+	return nil
+}
+
+// formatUnsupportedNodes returns the report of the nodes of an input file that
+// cannot be transpiled (see -report-unsupported):
+//
+//     foo.c: 3 unsupported nodes
+//         BlockExpr (2)
+//             line:5:13, line:7:3
+//             col:3, col:10
+//         ObjCMessageExpr (1)
+//             line:9:1
+//
+// The most common type of node is first, since it is the most important one to
+// support.
+func formatUnsupportedNodes(inputFile string, nodes []program.UnsupportedNode) string {
+	if len(nodes) == 0 {
+		return fmt.Sprintf("%s: no unsupported nodes\n", inputFile)
+	}
+
+	names := []string{}
+	positions := map[string][]string{}
+	for _, node := range nodes {
+		if _, ok := positions[node.Name]; !ok {
+			names = append(names, node.Name)
+		}
+		positions[node.Name] = append(positions[node.Name], node.Position)
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		return len(positions[names[i]]) > len(positions[names[j]])
+	})
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s: %d unsupported nodes\n", inputFile, len(nodes))
+	for _, name := range names {
+		fmt.Fprintf(&out, "    %s (%d)\n", name, len(positions[name]))
+		for _, position := range positions[name] {
+			fmt.Fprintf(&out, "        %s\n", position)
+		}
+	}
+
+	return out.String()
+}
+
+// parseAST preprocesses an input file and returns the root of its clang AST.
+func parseAST(inputFile string, printAST bool) (ast.Node, error) {
 	// 1. Compile it first (checking for errors)
-	_, err := os.Stat(args.inputFile)
+	_, err := os.Stat(inputFile)
 	if err != nil {
-		return fmt.Errorf("Input file is not found")
+		return nil, fmt.Errorf("Input file is not found")
 	}
 
 	// 2. Preprocess
@@ -166,22 +456,22 @@ func Start(args ProgramArgs) error {
 	{
 		// See : https://clang.llvm.org/docs/CommandGuide/clang.html
 		// clang -E <file>    Run the preprocessor stage.
-		cmd := exec.Command("clang", "-E", args.inputFile)
+		cmd := exec.Command("clang", "-E", inputFile)
 		var out bytes.Buffer
 		var stderr bytes.Buffer
 		cmd.Stdout = &out
 		cmd.Stderr = &stderr
 		err = cmd.Run()
 		if err != nil {
-			return fmt.Errorf("preprocess failed: %v\nStdErr = %v", err, stderr.String())
+			return nil, fmt.Errorf("preprocess failed: %v\nStdErr = %v", err, stderr.String())
 		}
-		pp = []byte(out.String())
+		pp = out.Bytes()
 	}
 
 	ppFilePath := path.Join(os.TempDir(), "pp.c")
 	err = ioutil.WriteFile(ppFilePath, pp, 0644)
 	if err != nil {
-		return fmt.Errorf("writing to /tmp/pp.c failed: %v", err)
+		return nil, fmt.Errorf("writing to /tmp/pp.c failed: %v", err)
 	}
 
 	// 3. Generate JSON from AST
@@ -195,7 +485,7 @@ func Start(args ProgramArgs) error {
 	}
 
 	lines := readAST(astPP)
-	if args.ast {
+	if printAST {
 		for _, l := range lines {
 			fmt.Println(l)
 		}
@@ -203,31 +493,106 @@ func Start(args ProgramArgs) error {
 	}
 
 	nodes := convertLinesToNodes(lines)
+	readOffsetOfMembers(lines, nodes, pp, ppFilePath)
 	tree := buildTree(nodes, 0)
 
-	p := program.NewProgram()
-	p.Verbose = args.verbose
+	return tree[0].(ast.Node), nil
+}
 
-	err = transpiler.TranspileAST(args.inputFile, args.packageName, p, tree[0].(ast.Node))
+// readMacros returns the object-like macros that are defined by the input file
+// and the headers that it includes, except for the system headers.
+func readMacros(inputFile string) ([]program.Macro, error) {
+	// clang -E -dD <file>    Run the preprocessor stage and keep the macro
+	//                        definitions.
+	cmd := exec.Command("clang", "-E", "-dD", inputFile)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("preprocess failed: %v\nStdErr = %v", err, stderr.String())
 	}
 
-	outputFilePath := args.outputFile
+	return parseMacros(out.Bytes()), nil
+}
+
+var (
+	lineMarkerRegexp = regexp.MustCompile(`^# (\d+) "(.*)"((?: \d+)*)$`)
+	defineRegexp     = regexp.MustCompile(`^#define ([A-Za-z_]\w*)(?:\s+(.*))?$`)
+	undefRegexp      = regexp.MustCompile(`^#undef ([A-Za-z_]\w*)`)
+)
 
-	if outputFilePath == "" {
-		cleanFileName := filepath.Clean(filepath.Base(args.inputFile))
-		extension := filepath.Ext(args.inputFile)
+// parseMacros returns the object-like macros of the preprocessed code of
+// "clang -E -dD", in the order that they are defined. The line markers tell
+// which file each macro is defined in:
+//
+//     # 1 "/usr/include/stdio.h" 1 3 4
+//
+// The macros of clang ("<built-in>" and "<command line>") and of the system
+// headers (flag 3) are left out. The name of a function-like macro is followed
+// by a parenthesis, like "MAX(a, b)", so it is not matched.
+func parseMacros(pp []byte) []program.Macro {
+	macros := []program.Macro{}
+	isUserFile := true
+
+	for _, line := range strings.Split(string(pp), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
 
-		outputFilePath = cleanFileName[0:len(cleanFileName)-len(extension)] + ".go"
+		if match := lineMarkerRegexp.FindStringSubmatch(line); match != nil {
+			flags := strings.Fields(match[3])
+			isUserFile = !strings.HasPrefix(match[2], "<")
+			for _, flag := range flags {
+				if flag == "3" {
+					isUserFile = false
+				}
+			}
+			continue
+		}
+
+		if match := undefRegexp.FindStringSubmatch(line); match != nil {
+			macros = removeMacro(macros, match[1])
+			continue
+		}
+
+		if match := defineRegexp.FindStringSubmatch(line); match != nil && isUserFile {
+			macros = append(removeMacro(macros, match[1]), program.Macro{
+				Name:  match[1],
+				Value: strings.TrimSpace(match[2]),
+			})
+		}
 	}
 
-	err = ioutil.WriteFile(outputFilePath, []byte(p.String()), 0755)
-	if err != nil {
-		return fmt.Errorf("writing C output file failed: %v", err)
+	return macros
+}
+
+// removeMacro returns the macros without the one that has the name.
+func removeMacro(macros []program.Macro, name string) []program.Macro {
+	for i, macro := range macros {
+		if macro.Name == name {
+			return append(macros[:i], macros[i+1:]...)
+		}
 	}
 
-	return nil
+	return macros
+}
+
+// getOutputFilePath returns the path of the Go file for an input file. The
+// output file (-o) is the Go file when there is only one input file, otherwise
+// it is the directory of all the Go files. The name of a Go file is the name of
+// the C file, like "foo.go" for "src/foo.c".
+func getOutputFilePath(args ProgramArgs, inputFile string) string {
+	if len(args.inputFiles) == 1 && args.outputFile != "" {
+		return args.outputFile
+	}
+
+	cleanFileName := filepath.Clean(filepath.Base(inputFile))
+	extension := filepath.Ext(inputFile)
+
+	return filepath.Join(args.outputFile,
+		cleanFileName[0:len(cleanFileName)-len(extension)]+".go")
 }
 
 // newTempFile - returns temp file
@@ -243,16 +608,26 @@ func newTempFile(dir, prefix, suffix string) (*os.File, error) {
 
 func main() {
 	var (
-		versionFlag       = flag.Bool("v", false, "print the version and exit")
-		transpileCommand  = flag.NewFlagSet("transpile", flag.ContinueOnError)
-		verboseFlag       = transpileCommand.Bool("V", false, "print progress as comments")
-		outputFlag        = transpileCommand.String("o", "", "output Go generated code to the specified file")
-		packageFlag       = transpileCommand.String("p", "main", "set the name of the generated package")
-		transpileHelpFlag = transpileCommand.Bool("h", false, "print help information")
-		astCommand        = flag.NewFlagSet("ast", flag.ContinueOnError)
-		astHelpFlag       = astCommand.Bool("h", false, "print help information")
+		versionFlag        = flag.Bool("v", false, "print the version and exit")
+		transpileCommand   = flag.NewFlagSet("transpile", flag.ContinueOnError)
+		verboseFlag        = transpileCommand.Bool("V", false, "print progress as comments")
+		outputFlag         = transpileCommand.String("o", "", "output Go generated code to the specified file, or directory if there is more than one input file")
+		packageFlag        = transpileCommand.String("p", "main", "set the name of the generated package")
+		structTagsFlag     = transpileCommand.Bool("struct-tags", false, "export struct fields and add json tags with the C field names")
+		goStringsFlag      = transpileCommand.Bool("go-strings", false, "use Go strings for string parameters that are never written to")
+		stubsFlag          = transpileCommand.Bool("stubs", false, "generate a stub that panics for each function that is called but never defined")
+		volatileAtomicFlag = transpileCommand.Bool("volatile-atomic", false, "use sync/atomic to read and write volatile integers")
+		signedCharFlag     = transpileCommand.Bool("signed-char", false, "a plain char is signed on the target platform, like x86, so it is an int8 instead of a byte")
+		reportFlag         = transpileCommand.Bool("report-unsupported", false, "print the nodes that cannot be transpiled, instead of writing the Go files")
+		macrosFlag         = transpileCommand.Bool("macros", false, "declare a Go constant for each object-like macro that is a number or a string")
+		errnoFunctionsFlag = transpileCommand.String("errno-functions", "", "a comma-separated list of functions that set errno, each one that is called has a Go wrapper that returns errno as an error")
+		transpileHelpFlag  = transpileCommand.Bool("h", false, "print help information")
+		astCommand         = flag.NewFlagSet("ast", flag.ContinueOnError)
+		astHelpFlag        = astCommand.Bool("h", false, "print help information")
 	)
 
+	transpileCommand.StringVar(packageFlag, "package-name", "main", "the same as -p")
+
 	flag.Usage = func() {
 		usage := "Usage: %s [-v] [<command>] [<flags>] file.c\n\n"
 		usage += "Commands:\n"
@@ -294,7 +669,7 @@ func main() {
 		}
 
 		args.ast = true
-		args.inputFile = astCommand.Arg(0)
+		args.inputFiles = astCommand.Args()
 	case "transpile":
 		err := transpileCommand.Parse(os.Args[2:])
 		if err != nil {
@@ -303,14 +678,25 @@ func main() {
 		}
 
 		if *transpileHelpFlag || transpileCommand.NArg() == 0 {
-			fmt.Fprintf(os.Stderr, "Usage: %s transpile [-V] [-o file.go] [-p package] file.c\n", os.Args[0])
+			fmt.Fprintf(os.Stderr, "Usage: %s transpile [-V] [-o file.go] [-p package] [-struct-tags] [-go-strings] [-stubs] [-volatile-atomic] [-signed-char] [-macros] [-report-unsupported] [-errno-functions f1,f2] file.c [file.c ...]\n", os.Args[0])
 			transpileCommand.PrintDefaults()
 			os.Exit(1)
 		}
 
-		args.inputFile = transpileCommand.Arg(0)
+		args.inputFiles = transpileCommand.Args()
 		args.outputFile = *outputFlag
 		args.packageName = *packageFlag
+		args.structTags = *structTagsFlag
+		args.goStrings = *goStringsFlag
+		args.stubs = *stubsFlag
+		args.volatileAtomic = *volatileAtomicFlag
+		args.signedChar = *signedCharFlag
+		args.macros = *macrosFlag
+		args.reportUnsupported = *reportFlag
+
+		if *errnoFunctionsFlag != "" {
+			args.errnoFunctions = strings.Split(*errnoFunctionsFlag, ",")
+		}
 	default:
 		flag.Usage()
 		os.Exit(1)
@@ -9,12 +9,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"syscall"
 	"testing"
 
 	"regexp"
 
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/transpiler"
 	"github.com/elliotchance/c2go/util"
 )
 
@@ -95,7 +99,7 @@ func TestIntegrationScripts(t *testing.T) {
 			cProgram.isZero = err == nil
 
 			programArgs := ProgramArgs{
-				inputFile:   file,
+				inputFiles:  []string{file},
 				outputFile:  subFolder + separator + mainFileName,
 				packageName: "main",
 			}
@@ -189,7 +193,7 @@ func TestStartPreprocess(t *testing.T) {
 	}
 
 	var args ProgramArgs
-	args.inputFile = tempFile.Name()
+	args.inputFiles = []string{tempFile.Name()}
 
 	err = Start(args)
 	if err == nil {
@@ -225,3 +229,148 @@ func TestGoPath(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 }
+
+func TestGetOutputFilePath(t *testing.T) {
+	tests := []struct {
+		inputFiles []string
+		outputFile string
+		want       string
+	}{
+		{[]string{"src/foo.c"}, "", "foo.go"},
+		{[]string{"src/foo.c"}, "out/bar.go", "out/bar.go"},
+		{[]string{"src/foo.c", "src/bar.c"}, "", "foo.go"},
+		{[]string{"src/foo.c", "src/bar.c"}, "out", "out/foo.go"},
+	}
+
+	for _, tt := range tests {
+		args := ProgramArgs{inputFiles: tt.inputFiles, outputFile: tt.outputFile}
+		if got := getOutputFilePath(args, tt.inputFiles[0]); got != tt.want {
+			t.Errorf("%v -o %q: expected %s, got %s", tt.inputFiles, tt.outputFile, tt.want, got)
+		}
+	}
+}
+
+func TestParseMacros(t *testing.T) {
+	pp := `# 1 "defines.c"
+# 1 "<built-in>" 1
+#define __STDC__ 1
+# 1 "<command line>" 1
+#define DEBUG 1
+# 1 "defines.c" 2
+# 1 "/usr/include/limits.h" 1 3 4
+#define INT_MAX 2147483647
+# 2 "defines.c" 2
+# 1 "./defines.h" 1
+#define SIZE (4 * 1024)
+#define NAME "c2go"
+#define MAX(a, b) ((a) > (b) ? (a) : (b))
+#define EMPTY
+#define OLD 1
+#undef OLD
+#define SIZE 8
+# 3 "defines.c" 2
+int main() { return SIZE; }
+`
+
+	expected := []program.Macro{
+		{Name: "NAME", Value: `"c2go"`},
+		{Name: "EMPTY", Value: ""},
+		{Name: "SIZE", Value: "8"},
+	}
+
+	got := parseMacros([]byte(pp))
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestReadOffsetOfMembers(t *testing.T) {
+	pp := `# 1 "x.c"
+# 1 "<built-in>" 1
+# 1 "x.c" 2
+# 1 "./foo.h" 1
+struct foo { int bar[4]; struct { int baz; } qux; };
+# 2 "x.c" 2
+int main() {
+    return __builtin_offsetof(struct foo, bar[2]) +
+        __builtin_offsetof(struct foo,
+            qux.baz);
+}
+`
+
+	lines := []string{
+		"TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>",
+		"|-RecordDecl 0x2 <./foo.h:1:1, col:51> col:8 struct foo definition",
+		"`-FunctionDecl 0x3 <x.c:2:1, line:6:1> line:2:5 main 'int ()'",
+		"  `-CompoundStmt 0x4 <col:12, line:6:1>",
+		"    `-ReturnStmt 0x5 <line:3:5, line:5:20>",
+		"      `-BinaryOperator 0x6 <line:3:12, line:5:20> 'unsigned long' '+'",
+		"        |-OffsetOfExpr 0x7 <line:3:12, col:49> 'unsigned long'",
+		"        | `-IntegerLiteral 0x8 <col:47> 'int' 2",
+		"        `-OffsetOfExpr 0x9 <line:4:9, line:5:20> 'unsigned long'",
+	}
+
+	nodes := convertLinesToNodes(lines)
+	readOffsetOfMembers(lines, nodes, []byte(pp), "pp.c")
+
+	expected := [][]string{
+		{"struct foo", "bar[2]"},
+		{"struct foo", "qux.baz"},
+	}
+
+	got := [][]string{}
+	for _, n := range nodes {
+		if offsetOf, ok := n.node.(*ast.OffsetOfExpr); ok {
+			got = append(got, []string{offsetOf.StructType, offsetOf.Member})
+		}
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestReportUnsupported(t *testing.T) {
+	lines := []string{
+		"TranslationUnitDecl 0x1 <<invalid sloc>> <invalid sloc>",
+		"|-PragmaCommentDecl 0x2 <x.c:1:9, col:28> col:9 lib \"m\"",
+		"|-FunctionDecl 0x10 <line:2:1, line:6:1> line:2:5 f 'int (void)'",
+		"| `-CompoundStmt 0x11 <col:13, line:6:1>",
+		"|   |-BlockExpr 0x12 <line:3:5, col:20> 'void (^)(void)'",
+		"|   | `-BlockDecl 0x13 <col:5, col:20> col:5",
+		"|   |-DeclStmt 0x14 <line:4:5, col:25>",
+		"|   | `-VarDecl 0x19 <col:5, col:24> col:9 b 'int' cinit",
+		"|   |   `-BlockExpr 0x15 <col:5, col:23> 'int'",
+		"|   `-ReturnStmt 0x16 <line:5:5, col:40>",
+		"|     `-ImplicitCastExpr 0x17 <col:12, col:40> 'int' <IntegralCast>",
+		"|       `-StmtExpr 0x18 <col:12, col:40> 'int'",
+		"`-FunctionDecl 0x20 <line:7:1, col:27> col:5 main 'int (void)'",
+		"  `-CompoundStmt 0x21 <col:16, col:27>",
+		"    `-ReturnStmt 0x22 <col:18, col:25>",
+		"      `-IntegerLiteral 0x23 <col:25> 'int' 0",
+	}
+
+	tree := buildTree(convertLinesToNodes(lines), 0)
+
+	p := program.NewProgram()
+	p.ReportUnsupported = true
+	if err := transpiler.TranspileAST("x.c", "main", p, tree[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `x.c: 5 unsupported nodes
+    BlockExpr (2)
+        line:3:5, col:20
+        col:5, col:23
+    PragmaCommentDecl (1)
+        x.c:1:9, col:28
+    BlockDecl (1)
+        col:5, col:20
+    StmtExpr (1)
+        col:12, col:40
+`
+
+	if got := formatUnsupportedNodes("x.c", p.UnsupportedNodes); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,21 @@
+package noarch
+
+import "fmt"
+
+// Assert handles the assert() macro.
+//
+// If the condition is false it panics with the same message as the assert() of
+// C, which contains the expression that failed and where it is:
+//
+//     Assertion failed: (x > 0), file main.c, line 12.
+//
+// A program that is transpiled with NDEBUG defined does not call Assert at
+// all.
+func Assert(condition bool, expression, filePath string, lineNumber int) {
+	if condition {
+		return
+	}
+
+	panic(fmt.Sprintf("Assertion failed: (%s), file %s, line %d.",
+		expression, filePath, lineNumber))
+}
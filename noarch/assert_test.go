@@ -0,0 +1,19 @@
+package noarch
+
+import (
+	"testing"
+)
+
+func TestAssert(t *testing.T) {
+	Assert(true, "x > 0", "main.c", 12)
+
+	defer func() {
+		expected := "Assertion failed: (x > 0), file main.c, line 12."
+		if r := recover(); r != expected {
+			t.Errorf("expected panic %q, got %v", expected, r)
+		}
+	}()
+
+	Assert(false, "x > 0", "main.c", 12)
+	t.Error("Assert did not panic")
+}
@@ -0,0 +1,54 @@
+package noarch
+
+import "fmt"
+
+// These are the values of errno that are used by the noarch functions. They
+// are the same on Linux and macOS.
+const (
+	EINVAL = 22
+	ERANGE = 34
+)
+
+// errno is a slice (rather than an int) so that it can be returned by
+// ErrnoLocation as a pointer.
+var errno = []int{0}
+
+// ErrnoLocation handles __errno_location() on Linux and __error() on macOS.
+//
+// The errno macro is defined in terms of these functions, like:
+//
+//     #define errno (*__errno_location())
+func ErrnoLocation() []int {
+	return errno
+}
+
+// Errno is a value of errno as a Go error.
+type Errno int
+
+var errnoMessages = map[Errno]string{
+	EINVAL: "invalid argument",
+	ERANGE: "result out of range",
+}
+
+// Error returns the description of the errno value, like strerror().
+func (e Errno) Error() string {
+	if message, ok := errnoMessages[e]; ok {
+		return message
+	}
+
+	return fmt.Sprintf("errno %d", int(e))
+}
+
+// ClearErrno sets errno to 0 before calling a function that may set it.
+func ClearErrno() {
+	errno[0] = 0
+}
+
+// ErrnoError returns the value of errno as an Errno, or nil if errno is 0.
+func ErrnoError() error {
+	if errno[0] == 0 {
+		return nil
+	}
+
+	return Errno(errno[0])
+}
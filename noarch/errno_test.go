@@ -0,0 +1,29 @@
+package noarch
+
+import "testing"
+
+func TestErrnoError(t *testing.T) {
+	ClearErrno()
+	if err := ErrnoError(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	errno[0] = ERANGE
+	err := ErrnoError()
+	if err != Errno(ERANGE) {
+		t.Errorf("expected Errno(ERANGE), got %#v", err)
+	}
+
+	if err.Error() != "result out of range" {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+
+	if message := Errno(5).Error(); message != "errno 5" {
+		t.Errorf("unexpected message: %s", message)
+	}
+
+	ClearErrno()
+	if errno[0] != 0 {
+		t.Errorf("expected errno to be cleared, got %d", errno[0])
+	}
+}
@@ -0,0 +1,297 @@
+package noarch
+
+import (
+	"math/bits"
+)
+
+// Uint128 replaces "unsigned __int128". Go does not have a 128-bit integer so
+// the value is stored in two 64-bit words and each operator is a method:
+//
+//     a * b + c    ->    a.Mul(b).Add(c)
+//
+// The operations wrap around on overflow, the same as C.
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+// Int128 replaces "__int128". It is stored as two's complement, so it has the
+// same bits as the Uint128 with the same (wrapped around) value.
+type Int128 struct {
+	Hi, Lo uint64
+}
+
+// Int64ToInt128 converts a signed integer to an Int128.
+func Int64ToInt128(x int64) Int128 {
+	return Int128{Hi: uint64(x >> 63), Lo: uint64(x)}
+}
+
+// Uint64ToInt128 converts an unsigned integer to an Int128.
+func Uint64ToInt128(x uint64) Int128 {
+	return Int128{Lo: x}
+}
+
+// Int64ToUint128 converts a signed integer to a Uint128. Like C, a negative
+// value wraps around.
+func Int64ToUint128(x int64) Uint128 {
+	return Uint128{Hi: uint64(x >> 63), Lo: uint64(x)}
+}
+
+// Uint64ToUint128 converts an unsigned integer to a Uint128.
+func Uint64ToUint128(x uint64) Uint128 {
+	return Uint128{Lo: x}
+}
+
+// Int128ToInt64 returns the low 64 bits of x.
+func Int128ToInt64(x Int128) int64 {
+	return int64(x.Lo)
+}
+
+// Uint128ToUint64 returns the low 64 bits of x.
+func Uint128ToUint64(x Uint128) uint64 {
+	return x.Lo
+}
+
+// Int128ToUint128 converts an Int128 to a Uint128 with the same bits.
+func Int128ToUint128(x Int128) Uint128 {
+	return Uint128(x)
+}
+
+// Uint128ToInt128 converts a Uint128 to an Int128 with the same bits.
+func Uint128ToInt128(x Uint128) Int128 {
+	return Int128(x)
+}
+
+// Add returns x + y.
+func (x Uint128) Add(y Uint128) Uint128 {
+	lo, carry := bits.Add64(x.Lo, y.Lo, 0)
+	hi, _ := bits.Add64(x.Hi, y.Hi, carry)
+
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// Sub returns x - y.
+func (x Uint128) Sub(y Uint128) Uint128 {
+	lo, borrow := bits.Sub64(x.Lo, y.Lo, 0)
+	hi, _ := bits.Sub64(x.Hi, y.Hi, borrow)
+
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// Mul returns x * y.
+func (x Uint128) Mul(y Uint128) Uint128 {
+	hi, lo := bits.Mul64(x.Lo, y.Lo)
+	hi += x.Hi*y.Lo + x.Lo*y.Hi
+
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// DivMod returns x / y and x % y. It panics if y is zero.
+func (x Uint128) DivMod(y Uint128) (Uint128, Uint128) {
+	if y.Hi == 0 {
+		if y.Lo == 0 {
+			panic("integer divide by zero")
+		}
+
+		// The high word is divided first so that the remainder is smaller
+		// than the divisor for bits.Div64.
+		hi, r := x.Hi/y.Lo, x.Hi%y.Lo
+		lo, r := bits.Div64(r, x.Lo, y.Lo)
+
+		return Uint128{Hi: hi, Lo: lo}, Uint128{Lo: r}
+	}
+
+	// The quotient fits in 64 bits when the divisor does not, so it is found
+	// one bit at a time.
+	var q uint64
+	r := Uint128{}
+	for i := 127; i >= 0; i-- {
+		r = r.Shl(1)
+		r.Lo |= x.Shr(uint64(i)).Lo & 1
+
+		if r.Cmp(y) >= 0 {
+			r = r.Sub(y)
+			q |= 1 << uint(i)
+		}
+	}
+
+	return Uint128{Lo: q}, r
+}
+
+// Div returns x / y.
+func (x Uint128) Div(y Uint128) Uint128 {
+	q, _ := x.DivMod(y)
+	return q
+}
+
+// Rem returns x % y.
+func (x Uint128) Rem(y Uint128) Uint128 {
+	_, r := x.DivMod(y)
+	return r
+}
+
+// And returns x & y.
+func (x Uint128) And(y Uint128) Uint128 {
+	return Uint128{Hi: x.Hi & y.Hi, Lo: x.Lo & y.Lo}
+}
+
+// Or returns x | y.
+func (x Uint128) Or(y Uint128) Uint128 {
+	return Uint128{Hi: x.Hi | y.Hi, Lo: x.Lo | y.Lo}
+}
+
+// Xor returns x ^ y.
+func (x Uint128) Xor(y Uint128) Uint128 {
+	return Uint128{Hi: x.Hi ^ y.Hi, Lo: x.Lo ^ y.Lo}
+}
+
+// Not returns ~x.
+func (x Uint128) Not() Uint128 {
+	return Uint128{Hi: ^x.Hi, Lo: ^x.Lo}
+}
+
+// Neg returns -x.
+func (x Uint128) Neg() Uint128 {
+	return Uint128{}.Sub(x)
+}
+
+// Shl returns x << n.
+func (x Uint128) Shl(n uint64) Uint128 {
+	switch {
+	case n >= 128:
+		return Uint128{}
+	case n >= 64:
+		return Uint128{Hi: x.Lo << (n - 64)}
+	}
+
+	return Uint128{Hi: x.Hi<<n | x.Lo>>(64-n), Lo: x.Lo << n}
+}
+
+// Shr returns x >> n.
+func (x Uint128) Shr(n uint64) Uint128 {
+	switch {
+	case n >= 128:
+		return Uint128{}
+	case n >= 64:
+		return Uint128{Lo: x.Hi >> (n - 64)}
+	}
+
+	return Uint128{Hi: x.Hi >> n, Lo: x.Lo>>n | x.Hi<<(64-n)}
+}
+
+// Cmp returns -1, 0 or +1 if x is less than, equal to or greater than y.
+func (x Uint128) Cmp(y Uint128) int {
+	switch {
+	case x.Hi < y.Hi || (x.Hi == y.Hi && x.Lo < y.Lo):
+		return -1
+	case x == y:
+		return 0
+	}
+
+	return 1
+}
+
+// The arithmetic of an Int128 is the same as a Uint128, except for division,
+// the right shift and the comparison.
+
+// isNegative returns true if x is less than zero.
+func (x Int128) isNegative() bool {
+	return int64(x.Hi) < 0
+}
+
+// abs returns the absolute value of x. Like C, the absolute value of the
+// smallest Int128 is itself.
+func (x Int128) abs() Uint128 {
+	if x.isNegative() {
+		return Uint128(x).Neg()
+	}
+
+	return Uint128(x)
+}
+
+// Add returns x + y.
+func (x Int128) Add(y Int128) Int128 {
+	return Int128(Uint128(x).Add(Uint128(y)))
+}
+
+// Sub returns x - y.
+func (x Int128) Sub(y Int128) Int128 {
+	return Int128(Uint128(x).Sub(Uint128(y)))
+}
+
+// Mul returns x * y.
+func (x Int128) Mul(y Int128) Int128 {
+	return Int128(Uint128(x).Mul(Uint128(y)))
+}
+
+// Div returns x / y. The quotient is truncated towards zero, the same as C.
+func (x Int128) Div(y Int128) Int128 {
+	q := x.abs().Div(y.abs())
+	if x.isNegative() != y.isNegative() {
+		q = q.Neg()
+	}
+
+	return Int128(q)
+}
+
+// Rem returns x % y. The remainder has the same sign as x, the same as C.
+func (x Int128) Rem(y Int128) Int128 {
+	r := x.abs().Rem(y.abs())
+	if x.isNegative() {
+		r = r.Neg()
+	}
+
+	return Int128(r)
+}
+
+// And returns x & y.
+func (x Int128) And(y Int128) Int128 {
+	return Int128(Uint128(x).And(Uint128(y)))
+}
+
+// Or returns x | y.
+func (x Int128) Or(y Int128) Int128 {
+	return Int128(Uint128(x).Or(Uint128(y)))
+}
+
+// Xor returns x ^ y.
+func (x Int128) Xor(y Int128) Int128 {
+	return Int128(Uint128(x).Xor(Uint128(y)))
+}
+
+// Not returns ~x.
+func (x Int128) Not() Int128 {
+	return Int128(Uint128(x).Not())
+}
+
+// Neg returns -x.
+func (x Int128) Neg() Int128 {
+	return Int128(Uint128(x).Neg())
+}
+
+// Shl returns x << n.
+func (x Int128) Shl(n uint64) Int128 {
+	return Int128(Uint128(x).Shl(n))
+}
+
+// Shr returns x >> n. The sign bit is extended, like the right shift of a
+// negative number with gcc and clang.
+func (x Int128) Shr(n uint64) Int128 {
+	if !x.isNegative() {
+		return Int128(Uint128(x).Shr(n))
+	}
+
+	return Int128(Uint128(x).Not().Shr(n).Not())
+}
+
+// Cmp returns -1, 0 or +1 if x is less than, equal to or greater than y.
+func (x Int128) Cmp(y Int128) int {
+	if x.isNegative() != y.isNegative() {
+		if x.isNegative() {
+			return -1
+		}
+
+		return 1
+	}
+
+	return Uint128(x).Cmp(Uint128(y))
+}
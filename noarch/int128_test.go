@@ -0,0 +1,87 @@
+package noarch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUint128Mul(t *testing.T) {
+	// (2^64 - 1) * (2^64 - 1) = 2^128 - 2^65 + 1
+	x := Uint64ToUint128(math.MaxUint64)
+	got := x.Mul(x)
+	if got.Hi != math.MaxUint64-1 || got.Lo != 1 {
+		t.Errorf("expected {%d 1}, got %+v", uint64(math.MaxUint64-1), got)
+	}
+
+	// 0x123456789abcdef0 * 0xfedcba9876543210
+	got = Uint64ToUint128(0x123456789abcdef0).Mul(Uint64ToUint128(0xfedcba9876543210))
+	if got.Hi != 0x121fa00ad77d7422 || got.Lo != 0x236d88fe5618cf00 {
+		t.Errorf("expected {0x121fa00ad77d7422 0x236d88fe5618cf00}, got {%#x %#x}", got.Hi, got.Lo)
+	}
+}
+
+func TestUint128(t *testing.T) {
+	one := Uint64ToUint128(1)
+	max := Uint128{Hi: math.MaxUint64, Lo: math.MaxUint64}
+
+	tests := []struct {
+		name     string
+		got      Uint128
+		expected Uint128
+	}{
+		{"carry", Uint64ToUint128(math.MaxUint64).Add(one), Uint128{Hi: 1}},
+		{"overflow", max.Add(one), Uint128{}},
+		{"borrow", Uint128{Hi: 1}.Sub(one), Uint128{Lo: math.MaxUint64}},
+		{"wrap around", Uint128{}.Sub(one), max},
+		{"negative int", Int64ToUint128(-1), max},
+		{"shift left", one.Shl(100), Uint128{Hi: 1 << 36}},
+		{"shift left across words", Uint128{Lo: 3 << 62}.Shl(1), Uint128{Hi: 1, Lo: 1 << 63}},
+		{"shift right", Uint128{Hi: 1}.Shr(1), Uint128{Lo: 1 << 63}},
+		{"shift too far", max.Shr(128), Uint128{}},
+		{"divide", Uint128{Hi: 6, Lo: 4}.Div(Uint64ToUint128(2)), Uint128{Hi: 3, Lo: 2}},
+		{"remainder", Uint128{Hi: 1, Lo: 5}.Rem(Uint64ToUint128(1 << 32)), Uint128{Lo: 5}},
+		{"divide by a large number", max.Div(Uint128{Hi: 1 << 32}), Uint128{Lo: math.MaxUint32}},
+		{"not", Uint128{}.Not(), max},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, tt.got)
+		}
+	}
+
+	if one.Cmp(max) != -1 || max.Cmp(one) != 1 || max.Cmp(max) != 0 {
+		t.Errorf("incorrect comparison of %+v and %+v", one, max)
+	}
+}
+
+func TestInt128(t *testing.T) {
+	minusOne := Int64ToInt128(-1)
+	seven := Int64ToInt128(7)
+	minusSeven := Int64ToInt128(-7)
+	two := Int64ToInt128(2)
+
+	tests := []struct {
+		name     string
+		got      Int128
+		expected int64
+	}{
+		{"add", minusOne.Add(two), 1},
+		{"subtract", two.Sub(seven), -5},
+		{"multiply", minusSeven.Mul(two), -14},
+		{"divide", minusSeven.Div(two), -3},
+		{"remainder", minusSeven.Rem(two), -1},
+		{"shift right", minusSeven.Shr(1), -4},
+		{"negate", seven.Neg(), -7},
+	}
+
+	for _, tt := range tests {
+		if Int128ToInt64(tt.got) != tt.expected || tt.got != Int64ToInt128(tt.expected) {
+			t.Errorf("%s: expected %d, got %+v", tt.name, tt.expected, tt.got)
+		}
+	}
+
+	if minusOne.Cmp(two) != -1 || two.Cmp(minusOne) != 1 || minusSeven.Cmp(minusOne) != -1 {
+		t.Errorf("incorrect comparison of negative numbers")
+	}
+}
@@ -19,3 +19,72 @@ func Signbitl(x float64) int {
 func IsNaN(x float64) int {
 	return BoolToInt(math.IsNaN(x))
 }
+
+// Abs handles abs(). The absolute value of an integer is an integer, so it
+// cannot use math.Abs().
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// Labs handles labs().
+func Labs(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// Llabs handles llabs().
+func Llabs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// Fabsf handles fabsf(). The functions of the math package only use float64,
+// so the float versions of the math.h functions convert the value.
+func Fabsf(x float32) float32 {
+	return float32(math.Abs(float64(x)))
+}
+
+// Sqrtf handles sqrtf().
+func Sqrtf(x float32) float32 {
+	return float32(math.Sqrt(float64(x)))
+}
+
+// Powf handles powf().
+func Powf(x, y float32) float32 {
+	return float32(math.Pow(float64(x), float64(y)))
+}
+
+// Sinf handles sinf().
+func Sinf(x float32) float32 {
+	return float32(math.Sin(float64(x)))
+}
+
+// Cosf handles cosf().
+func Cosf(x float32) float32 {
+	return float32(math.Cos(float64(x)))
+}
+
+// Floorf handles floorf().
+func Floorf(x float32) float32 {
+	return float32(math.Floor(float64(x)))
+}
+
+// Ceilf handles ceilf().
+func Ceilf(x float32) float32 {
+	return float32(math.Ceil(float64(x)))
+}
+
+// Roundf handles roundf(). Like C, halfway values are rounded away from zero.
+func Roundf(x float32) float32 {
+	return float32(math.Round(float64(x)))
+}
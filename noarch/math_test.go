@@ -0,0 +1,48 @@
+package noarch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAbs(t *testing.T) {
+	if Abs(-3) != 3 || Abs(4) != 4 || Abs(0) != 0 {
+		t.Errorf("Abs is not correct")
+	}
+
+	if Labs(-5) != 5 || Labs(6) != 6 {
+		t.Errorf("Labs is not correct")
+	}
+
+	if Llabs(-1<<40) != 1<<40 || Llabs(7) != 7 {
+		t.Errorf("Llabs is not correct")
+	}
+}
+
+func TestFloatFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   float32
+		expected float32
+	}{
+		{"Fabsf", Fabsf(-1.5), 1.5},
+		{"Sqrtf", Sqrtf(2.25), 1.5},
+		{"Powf", Powf(2, 10), 1024},
+		{"Sinf", Sinf(0), 0},
+		{"Cosf", Cosf(0), 1},
+		{"Floorf", Floorf(-1.5), -2},
+		{"Ceilf", Ceilf(-1.5), -1},
+		{"Roundf", Roundf(2.5), 3},
+		{"Roundf", Roundf(-2.5), -3},
+	}
+
+	for _, tt := range tests {
+		if tt.actual != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, tt.actual)
+		}
+	}
+
+	if !math.IsNaN(float64(Sqrtf(-1))) {
+		t.Errorf("expected the square root of -1 to be NaN")
+	}
+}
@@ -0,0 +1,337 @@
+package noarch
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// The memory of a C variable does not exist in Go, so functions like fread()
+// and fwrite() that treat anything as an array of bytes cannot read or write
+// them directly. Instead, the Go value is converted to and from the bytes of
+// the same value in C. For example:
+//
+//     struct P { int x; double y; };    ->    type P struct { x int; y float64 }
+//
+// is 16 bytes: "x" as 4 bytes, 4 bytes of padding and "y" as 8 bytes. The bytes
+// are little-endian and each field is aligned to its own size, the same as
+// x86-64.
+//
+// Pointers cannot be stored in a file, so every pointer has 8 bytes of zeros
+// and is left as it is when it is read. A slice is converted as its elements,
+// like a C array. A field of a struct that is a pointer in C but a slice in Go
+// has a tag instead (see pointerFieldTag):
+//
+//     struct N { int id; char *name; };    ->    type N struct { id int; name []byte `c2go:"pointer"` }
+//
+// is 16 bytes: "id" as 4 bytes, 4 bytes of padding and 8 bytes for "name".
+
+// The values of the "c2go" tag of a field that is a pointer in C, or an array of
+// pointers, but a slice in Go.
+const (
+	pointerFieldTag  = "pointer"
+	pointersFieldTag = "pointers"
+)
+
+// memoryElements are the elements that the pointer argument of a function like
+// fread() points to. A pointer to a struct (or an array of them) in C is a
+// slice, but the address of a single variable may be a Go pointer.
+type memoryElements struct {
+	value reflect.Value
+	count int
+}
+
+// newMemoryElements returns the elements of ptr. A nil pointer has no
+// elements.
+func newMemoryElements(ptr interface{}) memoryElements {
+	v := reflect.ValueOf(ptr)
+
+	switch v.Kind() {
+	case reflect.Slice:
+		return memoryElements{value: v, count: v.Len()}
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return memoryElements{}
+		}
+
+		if v.Elem().Kind() == reflect.Slice {
+			return newMemoryElements(v.Elem().Interface())
+		}
+
+		return memoryElements{value: v, count: 1}
+	}
+
+	return memoryElements{}
+}
+
+// index returns an element. It can always be set.
+func (e memoryElements) index(i int) reflect.Value {
+	if e.value.Kind() == reflect.Ptr {
+		return e.value.Elem()
+	}
+
+	return e.value.Index(i)
+}
+
+// stride returns the number of bytes from the start of one element to the
+// start of the next. It is the size of the element in C, unless it is smaller
+// than the size that fread() or fwrite() was given.
+func (e memoryElements) stride(size int) int {
+	if e.count == 0 {
+		return size
+	}
+
+	if n := len(encodeMemory(nil, e.index(0))); n > size {
+		return n
+	}
+
+	return size
+}
+
+// memoryAlignment returns the alignment of a Go type in C.
+func memoryAlignment(t reflect.Type) int {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1
+
+	case reflect.Int16, reflect.Uint16:
+		return 2
+
+	case reflect.Int, reflect.Uint, reflect.Int32, reflect.Uint32,
+		reflect.Float32, reflect.Complex64:
+		return 4
+
+	case reflect.Array, reflect.Slice:
+		return memoryAlignment(t.Elem())
+
+	case reflect.Struct:
+		align := 1
+		for i := 0; i < t.NumField(); i++ {
+			a := 8
+			if t.Field(i).Tag.Get("c2go") == "" {
+				a = memoryAlignment(t.Field(i).Type)
+			}
+
+			if a > align {
+				align = a
+			}
+		}
+
+		return align
+	}
+
+	return 8
+}
+
+// memoryPointers returns the number of pointers of a field that has a "c2go"
+// tag, or -1 if the field does not have the tag.
+func memoryPointers(f reflect.StructField, v reflect.Value) int {
+	switch f.Tag.Get("c2go") {
+	case pointerFieldTag:
+		return 1
+
+	case pointersFieldTag:
+		return v.Len()
+	}
+
+	return -1
+}
+
+// alignMemory appends zeros to b until its length is a multiple of align.
+func alignMemory(b []byte, align int) []byte {
+	for len(b)%align != 0 {
+		b = append(b, 0)
+	}
+
+	return b
+}
+
+// encodeMemory appends the bytes of v in C to b.
+func encodeMemory(b []byte, v reflect.Value) []byte {
+	b = alignMemory(b, memoryAlignment(v.Type()))
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, 1)
+		}
+
+		return append(b, 0)
+
+	case reflect.Int8, reflect.Uint8:
+		return append(b, byte(memoryBits(v)))
+
+	case reflect.Int16, reflect.Uint16:
+		return appendUint(b, memoryBits(v), 2)
+
+	case reflect.Int, reflect.Uint, reflect.Int32, reflect.Uint32:
+		return appendUint(b, memoryBits(v), 4)
+
+	case reflect.Int64, reflect.Uint64, reflect.Uintptr:
+		return appendUint(b, memoryBits(v), 8)
+
+	case reflect.Float32:
+		return appendUint(b, uint64(math.Float32bits(float32(v.Float()))), 4)
+
+	case reflect.Float64:
+		return appendUint(b, math.Float64bits(v.Float()), 8)
+
+	case reflect.Complex64:
+		b = appendUint(b, uint64(math.Float32bits(float32(real(v.Complex())))), 4)
+		return appendUint(b, uint64(math.Float32bits(float32(imag(v.Complex())))), 4)
+
+	case reflect.Complex128:
+		b = appendUint(b, math.Float64bits(real(v.Complex())), 8)
+		return appendUint(b, math.Float64bits(imag(v.Complex())), 8)
+
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			b = encodeMemory(b, v.Index(i))
+		}
+
+		return b
+
+	case reflect.Struct:
+		start := len(b)
+		for i := 0; i < v.NumField(); i++ {
+			if n := memoryPointers(v.Type().Field(i), v.Field(i)); n != -1 {
+				b = append(alignMemory(b, 8), make([]byte, 8*n)...)
+				continue
+			}
+
+			b = encodeMemory(b, v.Field(i))
+		}
+
+		// The padding at the end is relative to the start of the struct.
+		for (len(b)-start)%memoryAlignment(v.Type()) != 0 {
+			b = append(b, 0)
+		}
+
+		return b
+	}
+
+	return append(b, make([]byte, 8)...)
+}
+
+// decodeMemory sets v from the bytes of it in C, starting at offset. It returns
+// the offset after v.
+func decodeMemory(b []byte, offset int, v reflect.Value) int {
+	align := memoryAlignment(v.Type())
+	if offset%align != 0 {
+		offset += align - offset%align
+	}
+
+	// The fields of a struct are not exported.
+	v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(b[offset] != 0)
+		return offset + 1
+
+	case reflect.Int8, reflect.Uint8:
+		setMemoryBits(v, uint64(b[offset]))
+		return offset + 1
+
+	case reflect.Int16, reflect.Uint16:
+		setMemoryBits(v, uint64(binary.LittleEndian.Uint16(b[offset:])))
+		return offset + 2
+
+	case reflect.Int, reflect.Uint, reflect.Int32, reflect.Uint32:
+		setMemoryBits(v, uint64(binary.LittleEndian.Uint32(b[offset:])))
+		return offset + 4
+
+	case reflect.Int64, reflect.Uint64, reflect.Uintptr:
+		setMemoryBits(v, binary.LittleEndian.Uint64(b[offset:]))
+		return offset + 8
+
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b[offset:]))))
+		return offset + 4
+
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(b[offset:])))
+		return offset + 8
+
+	case reflect.Complex64:
+		re := math.Float32frombits(binary.LittleEndian.Uint32(b[offset:]))
+		im := math.Float32frombits(binary.LittleEndian.Uint32(b[offset+4:]))
+		v.SetComplex(complex(float64(re), float64(im)))
+		return offset + 8
+
+	case reflect.Complex128:
+		re := math.Float64frombits(binary.LittleEndian.Uint64(b[offset:]))
+		im := math.Float64frombits(binary.LittleEndian.Uint64(b[offset+8:]))
+		v.SetComplex(complex(re, im))
+		return offset + 16
+
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			offset = decodeMemory(b, offset, v.Index(i))
+		}
+
+		return offset
+
+	case reflect.Struct:
+		start := offset
+		for i := 0; i < v.NumField(); i++ {
+			// A pointer is left as it is.
+			if n := memoryPointers(v.Type().Field(i), v.Field(i)); n != -1 {
+				if offset%8 != 0 {
+					offset += 8 - offset%8
+				}
+				offset += 8 * n
+				continue
+			}
+
+			offset = decodeMemory(b, offset, v.Field(i))
+		}
+
+		if size := offset - start; size%align != 0 {
+			offset += align - size%align
+		}
+
+		return offset
+	}
+
+	// A pointer is left as it is.
+	return offset + 8
+}
+
+// memoryBits returns the bits of an integer.
+func memoryBits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	}
+
+	return v.Uint()
+}
+
+// setMemoryBits sets an integer from its bits. The sign of a signed integer is
+// extended from the size of it in C.
+func setMemoryBits(v reflect.Value, bits uint64) {
+	switch v.Kind() {
+	case reflect.Int8:
+		v.SetInt(int64(int8(bits)))
+	case reflect.Int16:
+		v.SetInt(int64(int16(bits)))
+	case reflect.Int, reflect.Int32:
+		v.SetInt(int64(int32(bits)))
+	case reflect.Int64:
+		v.SetInt(int64(bits))
+	default:
+		v.SetUint(bits)
+	}
+}
+
+// appendUint appends the lowest n bytes of x to b.
+func appendUint(b []byte, x uint64, n int) []byte {
+	for i := 0; i < n; i++ {
+		b = append(b, byte(x>>uint(8*i)))
+	}
+
+	return b
+}
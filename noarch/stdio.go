@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // File represents the definition has been translated from the original
@@ -163,7 +165,7 @@ func Fputs(str []byte, stream *File) int {
 
 	n, err := stream.OsFile.WriteString(string(str[:length]))
 	if err != nil {
-		panic(err)
+		return -1
 	}
 
 	return n
@@ -203,31 +205,40 @@ func Tmpfile() *File {
 // Notice that fgets is quite different from gets: not only fgets accepts a
 // stream argument, but also allows to specify the maximum size of str and
 // includes in the string any ending newline character.
+//
+// If the end-of-file is reached before any characters are read, NULL is
+// returned and str is unchanged.
 func Fgets(str []byte, num int, stream *File) []byte {
-	buf := make([]byte, num)
-	n, err := stream.OsFile.Read(buf)
+	if num <= 0 || len(str) == 0 {
+		return nil
+	}
 
-	// FIXME: Is this the right thing to do in this case?
-	if err != nil {
-		return []byte{}
+	if num > len(str) {
+		num = len(str)
 	}
 
-	// TODO: Allow arguments to be passed by reference.
-	// https://github.com/elliotchance/c2go/issues/90
-	// This appears in multiple locations.
+	n := 0
+	for n < num-1 {
+		c := getc(stream.OsFile)
+		if c == -1 {
+			if n == 0 {
+				return nil
+			}
+
+			break
+		}
 
-	// Be careful to crop the buffer to the real number of bytes read.
-	//
-	// We do not trim off the NULL characters because we do not know if the file
-	// we are reading is in binary mode.
-	if n == num {
-		// If it is the case that we have read the entire buffer with this read
-		// we need to make sure we leave room for what would be the NULL
-		// character at the end of the string in C.
-		return buf[:n-1]
+		str[n] = byte(c)
+		n++
+
+		if c == '\n' {
+			break
+		}
 	}
 
-	return buf[:n]
+	str[n] = 0
+
+	return str
 }
 
 // Rewind handles rewind().
@@ -353,7 +364,7 @@ func Fflush(stream *File) int {
 // After the format parameter, the function expects at least as many additional
 // arguments as specified by format.
 func Fprintf(f *File, format []byte, args ...interface{}) int {
-	n, err := fmt.Fprintf(f.OsFile, string(format), args...)
+	n, err := fmt.Fprintf(f.OsFile, goFormat(format), args...)
 	if err != nil {
 		return -1
 	}
@@ -370,12 +381,10 @@ func Fprintf(f *File, format []byte, args ...interface{}) int {
 // type specified by their corresponding format specifier within the format
 // string.
 func Fscanf(f *File, format []byte, args ...interface{}) int {
-	n, err := fmt.Fscanf(f.OsFile, string(format), args...)
-	if err != nil {
-		return -1
-	}
+	r := &fileScanner{f: f.OsFile}
+	defer r.close()
 
-	return n
+	return scan(r, format, args)
 }
 
 func getc(f *os.File) int {
@@ -482,25 +491,40 @@ func Ftell(f *File) int32 {
 // The position indicator of the stream is advanced by the total amount of bytes
 // read.
 //
-// The total amount of bytes read if successful is (size*count).
-func Fread(ptr *[]byte, size1, size2 int, f *File) int {
-	// Create a new buffer so that we can ensure we read up to the correct
-	// number of bytes from the file.
-	newBuffer := make([]byte, size1*size2)
-	n, err := f.OsFile.Read(newBuffer)
+// The total amount of bytes read if successful is (size*count). The return
+// value is the number of elements that were read completely, which is less
+// than count if the end-of-file is reached. The bytes of an element that was
+// only partly read are still stored.
+//
+// ptr is a []byte for a char buffer. Anything else, like an array of structs,
+// is converted from the bytes of the same value in C (see memory.go).
+func Fread(ptr interface{}, size, count int, f *File) int {
+	if size <= 0 || count <= 0 {
+		return 0
+	}
 
-	// Despite any error we need to make sure the bytes read are copied to the
-	// destination buffer.
-	for i, b := range newBuffer {
-		(*ptr)[i] = b
+	if b, ok := ptr.([]byte); ok {
+		n, _ := io.ReadFull(f.OsFile, b[:minInt(size*count, len(b))])
+		return n / size
 	}
 
-	// Now we can handle the success or failure.
-	if err != nil {
-		return -1
+	elements := newMemoryElements(ptr)
+	stride := elements.stride(size)
+
+	buf := make([]byte, minInt(size*count, elements.count*stride))
+	n, _ := io.ReadFull(f.OsFile, buf)
+
+	for i := 0; i*stride < n; i++ {
+		// The read bytes replace the start of the element, so the rest of a
+		// partly read element is unchanged.
+		element := elements.index(i)
+		b := encodeMemory(nil, element)
+		copy(b, buf[i*stride:minInt((i+1)*stride, n)])
+
+		decodeMemory(b, 0, element)
 	}
 
-	return n
+	return n / size
 }
 
 // Fwrite handles fwrite().
@@ -513,14 +537,40 @@ func Fread(ptr *[]byte, size1, size2 int, f *File) int {
 //
 // Internally, the function interprets the block pointed by ptr as if it was an
 // array of (size*count) elements of type unsigned char, and writes them
-// sequentially to stream as if fputc was called for each byte.
-func Fwrite(str []byte, size1, size2 int, stream *File) int {
-	n, err := stream.OsFile.Write(str[:size1*size2])
-	if err != nil {
-		return -1
+// sequentially to stream as if fputc was called for each byte. The return
+// value is the number of elements that were written completely.
+//
+// Like Fread, ptr is a []byte or any other value that is converted to the bytes
+// of the same value in C.
+func Fwrite(ptr interface{}, size, count int, stream *File) int {
+	if size <= 0 || count <= 0 {
+		return 0
 	}
 
-	return n
+	b, ok := ptr.([]byte)
+	if !ok {
+		elements := newMemoryElements(ptr)
+		stride := elements.stride(size)
+
+		for i := 0; i < elements.count && len(b) < size*count; i++ {
+			start := len(b)
+			b = encodeMemory(b, elements.index(i))
+			b = append(b, make([]byte, stride-(len(b)-start))...)
+		}
+	}
+
+	n, _ := stream.OsFile.Write(b[:minInt(size*count, len(b))])
+
+	return n / size
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
 }
 
 // Fgetpos handles fgetpos().
@@ -571,9 +621,50 @@ func Fsetpos(stream *File, pos *int) int {
 // additional arguments following format are formatted and inserted in the
 // resulting string replacing their respective specifiers.
 func Printf(format []byte, args ...interface{}) int {
+	n, _ := fmt.Printf(goFormat(format), goFormatArgs(args)...)
+
+	return n
+}
+
+// goFormat returns the Go format of the C string format for one of the printf
+// functions. The length modifiers of C, like the "l" of "%ld" or the "L" of
+// "%Lf", are removed because Go formats each value by its type. A long double
+// is a float64, so "%Lf" is a "%f".
+func goFormat(format []byte) string {
+	s := NullTerminatedByteSlice(format)
+
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		out = append(out, s[i])
+		if s[i] != '%' {
+			continue
+		}
+
+		// The flags, width and precision are the same in Go.
+		for i+1 < len(s) && strings.IndexByte("-+ #0123456789.*", s[i+1]) != -1 {
+			i++
+			out = append(out, s[i])
+		}
+
+		for i+1 < len(s) && strings.IndexByte("hlLqjzt", s[i+1]) != -1 {
+			i++
+		}
+
+		// The verb, which may be the second "%" of "%%".
+		if i+1 < len(s) {
+			i++
+			out = append(out, s[i])
+		}
+	}
+
+	return string(out)
+}
+
+// goFormatArgs converts any C strings in the arguments for one of the printf
+// functions into Go strings.
+func goFormatArgs(args []interface{}) []interface{} {
 	realArgs := []interface{}{}
 
-	// Convert any C strings into Go strings.
 	typeOfByteSlice := reflect.TypeOf([]byte(nil))
 	for _, arg := range args {
 		if reflect.TypeOf(arg) == typeOfByteSlice {
@@ -583,9 +674,40 @@ func Printf(format []byte, args ...interface{}) int {
 		}
 	}
 
-	n, _ := fmt.Printf(NullTerminatedByteSlice(format), realArgs...)
+	return realArgs
+}
 
-	return n
+// Snprintf handles snprintf().
+//
+// Composes a string with the same text that would be printed if format was
+// used on printf, but instead of being printed, the content is stored as a C
+// string in the buffer pointed by buf (taking size as the maximum buffer
+// capacity to fill).
+//
+// If the resulting string would be longer than size-1 characters, the
+// remaining characters are discarded and not stored. A terminating null
+// character is always written after the content, unless size is zero, in which
+// case nothing is written at all.
+//
+// The number of characters that would have been written if size had been
+// sufficiently large is returned, not counting the terminating null character.
+func Snprintf(buf []byte, size int, format []byte, args ...interface{}) int {
+	s := fmt.Sprintf(goFormat(format), goFormatArgs(args)...)
+
+	if size > 0 {
+		n := copy(buf[:size-1], s)
+		buf[n] = 0
+	}
+
+	return len(s)
+}
+
+// Vsnprintf handles vsnprintf().
+//
+// This is the same as Snprintf except that the arguments are the remaining
+// arguments of a va_list.
+func Vsnprintf(buf []byte, size int, format []byte, ap *VaList) int {
+	return Snprintf(buf, size, format, ap.args[ap.position:]...)
 }
 
 // Puts handles puts().
@@ -615,9 +737,469 @@ func Puts(str []byte) int {
 // type specified by their corresponding format specifier within the format
 // string.
 func Scanf(format []byte, args ...interface{}) int {
-	n, _ := fmt.Scanf(NullTerminatedByteSlice(format), args...)
+	r := &fileScanner{f: os.Stdin}
+	defer r.close()
 
-	return n
+	return scan(r, format, args)
+}
+
+// Sscanf handles sscanf().
+//
+// Reads data from the C string src and stores them according to the
+// parameter format into the locations pointed by the additional arguments, as
+// if scanf was used, but reading from src instead of the standard input.
+//
+// The number of arguments that were successfully filled is returned. If the
+// end of the string is reached before the first conversion then -1 (EOF) is
+// returned.
+func Sscanf(src []byte, format []byte, args ...interface{}) int {
+	return scan(strings.NewReader(NullTerminatedByteSlice(src)), format, args)
+}
+
+// scan implements the scanf() family of functions. The supported conversions
+// are %d, %i, %u, %o, %x, %f (and %e, %g), %s and %c. They can have a maximum
+// field width and "*" to read the value without storing it. The length
+// modifiers (like "l" in "%ld") are ignored because the type of the argument
+// is already known.
+//
+// The arguments are pointers, like *int, or slices, like the []byte for a
+// %s. Where a slice is used for a number the value is written to the first
+// element.
+func scan(r io.ByteScanner, format []byte, args []interface{}) int {
+	f := NullTerminatedByteSlice(format)
+	assigned := 0
+	conversions := 0
+
+	// An input failure (the end of the input) before the first conversion
+	// returns EOF rather than the number of values assigned.
+	inputFailure := func() int {
+		if conversions == 0 {
+			return -1
+		}
+
+		return assigned
+	}
+
+	for i := 0; i < len(f); i++ {
+		c := f[i]
+
+		// Whitespace in the format matches any amount of whitespace
+		// (including none) in the input.
+		if isSpace(c) {
+			skipSpaces(r)
+			continue
+		}
+
+		// Any other character must be the next character of the input.
+		if c != '%' || (i+1 < len(f) && f[i+1] == '%') {
+			if c == '%' {
+				i++
+			}
+
+			b, err := r.ReadByte()
+			if err != nil {
+				return inputFailure()
+			}
+			if b != c {
+				r.UnreadByte()
+				return assigned
+			}
+
+			continue
+		}
+
+		i++
+		suppress := false
+		if i < len(f) && f[i] == '*' {
+			suppress = true
+			i++
+		}
+
+		width := 0
+		for i < len(f) && f[i] >= '0' && f[i] <= '9' {
+			width = width*10 + int(f[i]-'0')
+			i++
+		}
+
+		for i < len(f) && strings.IndexByte("hlLqjzt", f[i]) != -1 {
+			i++
+		}
+
+		if i >= len(f) {
+			return assigned
+		}
+
+		verb := f[i]
+		switch {
+		case verb == 'c' && width == 0:
+			width = 1
+		case verb != 'c':
+			skipSpaces(r)
+		}
+
+		if _, err := r.ReadByte(); err != nil {
+			return inputFailure()
+		}
+		r.UnreadByte()
+
+		in := &limitedScanner{r: r, remaining: width}
+		if width == 0 {
+			in.remaining = -1
+		}
+
+		var value interface{}
+		ok := false
+
+		switch verb {
+		case 'd':
+			value, ok = scanInteger(in, 10)
+		case 'i':
+			value, ok = scanInteger(in, 0)
+		case 'u':
+			value, ok = scanInteger(in, 10)
+		case 'o':
+			value, ok = scanInteger(in, 8)
+		case 'x', 'X':
+			value, ok = scanInteger(in, 16)
+		case 'f', 'F', 'e', 'E', 'g', 'G':
+			value, ok = scanFloat(in)
+		case 's':
+			value, ok = scanString(in)
+		case 'c':
+			value, ok = scanCharacters(in, width)
+		}
+
+		if !ok {
+			return assigned
+		}
+
+		conversions++
+		if suppress {
+			continue
+		}
+
+		if len(args) == 0 || !storeScanValue(args[0], value, verb == 's') {
+			return assigned
+		}
+
+		args = args[1:]
+		assigned++
+	}
+
+	return assigned
+}
+
+// skipSpaces reads all of the whitespace at the start of the input.
+func skipSpaces(r io.ByteScanner) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+
+		if !isSpace(b) {
+			r.UnreadByte()
+			return
+		}
+	}
+}
+
+// scanInteger reads an integer in the base, which may have a sign. A base of
+// 0 uses the prefix of the number (like strtol), a base of 16 allows the
+// optional "0x" prefix. The value is an int64, even if it was read as an
+// unsigned number.
+func scanInteger(r io.ByteScanner, base int) (int64, bool) {
+	s := readScanSign(r)
+
+	if base == 0 || base == 16 {
+		if b, err := r.ReadByte(); err == nil {
+			if b == '0' {
+				s += "0"
+				if b, err := r.ReadByte(); err == nil {
+					if b == 'x' || b == 'X' {
+						base = 16
+						s = s[:len(s)-1]
+					} else {
+						r.UnreadByte()
+						if base == 0 {
+							base = 8
+						}
+					}
+				}
+			} else {
+				r.UnreadByte()
+			}
+		}
+
+		if base == 0 {
+			base = 10
+		}
+	}
+
+	digits := readScanDigits(r, base)
+	if digits == "" {
+		// Only a "0" was read before the "x".
+		if strings.HasSuffix(s, "0") {
+			return 0, true
+		}
+
+		return 0, false
+	}
+
+	v, err := strconv.ParseInt(s+digits, base, 64)
+	if err != nil {
+		u, err := strconv.ParseUint(strings.TrimPrefix(s+digits, "+"), base, 64)
+		if err != nil {
+			return 0, false
+		}
+		v = int64(u)
+	}
+
+	return v, true
+}
+
+// scanFloat reads a floating-point number, which may have a sign, a fractional
+// part and an exponent.
+func scanFloat(r io.ByteScanner) (float64, bool) {
+	s := readScanSign(r) + readScanDigits(r, 10)
+
+	if b, err := r.ReadByte(); err == nil {
+		if b == '.' {
+			s += "." + readScanDigits(r, 10)
+		} else {
+			r.UnreadByte()
+		}
+	}
+
+	if strings.Trim(s, "+-.") == "" {
+		return 0, false
+	}
+
+	if b, err := r.ReadByte(); err == nil {
+		if b == 'e' || b == 'E' {
+			exponent := readScanSign(r) + readScanDigits(r, 10)
+			s += "e" + exponent
+		} else {
+			r.UnreadByte()
+		}
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// scanString reads the characters up to the next whitespace.
+func scanString(r io.ByteScanner) ([]byte, bool) {
+	s := []byte{}
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			break
+		}
+
+		if isSpace(b) {
+			r.UnreadByte()
+			break
+		}
+
+		s = append(s, b)
+	}
+
+	return s, len(s) > 0
+}
+
+// scanCharacters reads exactly n characters, including whitespace.
+func scanCharacters(r io.ByteScanner, n int) ([]byte, bool) {
+	s := []byte{}
+	for len(s) < n {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, false
+		}
+
+		s = append(s, b)
+	}
+
+	return s, true
+}
+
+func readScanSign(r io.ByteScanner) string {
+	b, err := r.ReadByte()
+	if err != nil {
+		return ""
+	}
+
+	if b == '-' || b == '+' {
+		return string(b)
+	}
+
+	r.UnreadByte()
+	return ""
+}
+
+func readScanDigits(r io.ByteScanner, base int) string {
+	s := []byte{}
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			break
+		}
+
+		if digitValue(b) >= base {
+			r.UnreadByte()
+			break
+		}
+
+		s = append(s, b)
+	}
+
+	return string(s)
+}
+
+// storeScanValue writes a value that has been read into the argument. The
+// string (or characters) are copied into a []byte. A %s is null terminated, if
+// there is space, but the characters of a %c are not.
+func storeScanValue(arg interface{}, value interface{}, terminate bool) bool {
+	v := reflect.ValueOf(arg)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+
+	if bytes, ok := value.([]byte); ok {
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			n := reflect.Copy(v, reflect.ValueOf(bytes))
+			if terminate && n < v.Len() {
+				v.Index(n).SetUint(0)
+			}
+
+			return true
+		}
+
+		// A single %c can be stored in a char.
+		value = int64(bytes[0])
+	}
+
+	if v.Kind() == reflect.Slice {
+		if v.Len() == 0 {
+			return false
+		}
+		v = v.Index(0)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := value.(type) {
+		case int64:
+			v.SetInt(n)
+		case float64:
+			v.SetInt(int64(n))
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch n := value.(type) {
+		case int64:
+			v.SetUint(uint64(n))
+		case float64:
+			v.SetUint(uint64(n))
+		}
+
+	case reflect.Float32, reflect.Float64:
+		switch n := value.(type) {
+		case int64:
+			v.SetFloat(float64(n))
+		case float64:
+			v.SetFloat(n)
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// limitedScanner stops reading after the maximum field width of a conversion.
+// A remaining value of -1 means that there is no limit.
+type limitedScanner struct {
+	r         io.ByteScanner
+	remaining int
+	read      bool
+}
+
+func (s *limitedScanner) ReadByte() (byte, error) {
+	s.read = false
+	if s.remaining == 0 {
+		return 0, io.EOF
+	}
+
+	b, err := s.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	s.read = true
+	if s.remaining > 0 {
+		s.remaining--
+	}
+
+	return b, nil
+}
+
+func (s *limitedScanner) UnreadByte() error {
+	// Nothing was read if the width was reached.
+	if !s.read {
+		return nil
+	}
+
+	s.read = false
+	if s.remaining >= 0 {
+		s.remaining++
+	}
+
+	return s.r.UnreadByte()
+}
+
+// fileScanner reads a file one character at a time, the same as getc(). The
+// character that ends a value is put back when the scan is finished. This is
+// only possible for files that can seek, so for the standard input the
+// character is lost.
+type fileScanner struct {
+	f      *os.File
+	last   byte
+	unread bool
+}
+
+func (s *fileScanner) ReadByte() (byte, error) {
+	if s.unread {
+		s.unread = false
+		return s.last, nil
+	}
+
+	c := getc(s.f)
+	if c == -1 {
+		return 0, io.EOF
+	}
+
+	s.last = byte(c)
+	return s.last, nil
+}
+
+func (s *fileScanner) UnreadByte() error {
+	s.unread = true
+	return nil
+}
+
+func (s *fileScanner) close() {
+	if s.unread {
+		s.f.Seek(-1, io.SeekCurrent)
+	}
 }
 
 // Putchar handles putchar().
@@ -0,0 +1,313 @@
+package noarch
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSnprintf(t *testing.T) {
+	tests := []struct {
+		size     int
+		format   string
+		args     []interface{}
+		expected string
+		n        int
+	}{
+		{10, "%d-%s", []interface{}{12, []byte("ab\x00cd")}, "12-ab", 5},
+		{4, "%d", []interface{}{123456}, "123", 6},
+		{1, "abc", nil, "", 3},
+		{6, "hello", nil, "hello", 5},
+	}
+
+	for _, test := range tests {
+		buf := []byte("XXXXXXXXXX")
+		n := Snprintf(buf, test.size, []byte(test.format+"\x00"), test.args...)
+
+		if n != test.n {
+			t.Errorf("%q: expected to return %d, got %d", test.format, test.n, n)
+		}
+
+		if s := NullTerminatedByteSlice(buf); s != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.format, test.expected, s)
+		}
+
+		// Nothing is written after the null character.
+		if rest := buf[len(test.expected)+1:]; string(rest) != "XXXXXXXXXX"[len(test.expected)+1:] {
+			t.Errorf("%q: the buffer was written past the null character: %q", test.format, buf)
+		}
+	}
+}
+
+func TestGoFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"%d %s\n", "%d %s\n"},
+		{"%ld %lld %hhd %zu", "%d %d %d %u"},
+		{"%Lf %5.2lf %-8Le", "%f %5.2f %-8e"},
+		{"100%% %l", "100%% %"},
+		{"%Lf\x00%ld", "%f"},
+	}
+
+	for _, test := range tests {
+		if s := goFormat([]byte(test.format)); s != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.format, test.expected, s)
+		}
+	}
+
+	buf := make([]byte, 20)
+	Snprintf(buf, len(buf), []byte("%Lf %ld\x00"), 1.5, int32(7))
+	if s := NullTerminatedByteSlice(buf); s != "1.500000 7" {
+		t.Errorf("expected \"1.500000 7\", got %q", s)
+	}
+}
+
+func TestSnprintfSizeZero(t *testing.T) {
+	buf := []byte("XX")
+	if n := Snprintf(buf, 0, []byte("abc"), nil...); n != 3 {
+		t.Errorf("expected to return 3, got %d", n)
+	}
+
+	if string(buf) != "XX" {
+		t.Errorf("expected the buffer to be unchanged, got %q", buf)
+	}
+}
+
+func TestVsnprintf(t *testing.T) {
+	var ap *VaList
+	VaStart(&ap, []interface{}{1, 2, 3})
+	ap.Int64()
+
+	buf := make([]byte, 10)
+	if n := Vsnprintf(buf, len(buf), []byte("%d,%d"), ap); n != 3 {
+		t.Errorf("expected to return 3, got %d", n)
+	}
+
+	if s := NullTerminatedByteSlice(buf); s != "2,3" {
+		t.Errorf("expected 2,3, got %q", s)
+	}
+}
+
+func TestSscanf(t *testing.T) {
+	var i, j int
+	var u uint32
+	var f float64
+	var c byte
+	s := make([]byte, 16)
+
+	tests := []struct {
+		src      string
+		format   string
+		args     []interface{}
+		n        int
+		expected []interface{}
+	}{
+		{"42 hello", "%d %s", []interface{}{&i, s}, 2, []interface{}{42, "hello"}},
+		{"  -17", "%d", []interface{}{&i}, 1, []interface{}{-17}},
+		{"99", "%u", []interface{}{&u}, 1, []interface{}{uint32(99)}},
+		{"3.25e2 x", "%f %c", []interface{}{&f, &c}, 2, []interface{}{325.0, byte('x')}},
+		{"ff 0x1A", "%x %x", []interface{}{&i, &j}, 2, []interface{}{255, 26}},
+		{"0x10 010", "%i %i", []interface{}{&i, &j}, 2, []interface{}{16, 8}},
+		{"abcdefgh", "%3s", []interface{}{s}, 1, []interface{}{"abc"}},
+		{"12345", "%2d%d", []interface{}{&i, &j}, 2, []interface{}{12, 345}},
+		{"1,2", "%d,%d", []interface{}{&i, &j}, 2, []interface{}{1, 2}},
+		{"1;2", "%d,%d", []interface{}{&i, &j}, 1, []interface{}{1}},
+		{"5 6", "%*d %d", []interface{}{&i}, 1, []interface{}{6}},
+		{"12 ab", "%ld %lx", []interface{}{&i, &j}, 2, []interface{}{12, 171}},
+		{"abc", "%d", []interface{}{&i}, 0, nil},
+		{"", "%d", []interface{}{&i}, -1, nil},
+		{"   ", "%s", []interface{}{s}, -1, nil},
+		{"7", "%d %d", []interface{}{&i, &j}, 1, []interface{}{7}},
+	}
+
+	for _, test := range tests {
+		i, j, u, f, c = 0, 0, 0, 0, 0
+		n := Sscanf([]byte(test.src+"\x00"), []byte(test.format+"\x00"), test.args...)
+
+		if n != test.n {
+			t.Errorf("%q, %q: expected to return %d, got %d", test.src, test.format, test.n, n)
+			continue
+		}
+
+		for k, expected := range test.expected {
+			var actual interface{}
+			switch arg := test.args[k].(type) {
+			case *int:
+				actual = *arg
+			case *uint32:
+				actual = *arg
+			case *float64:
+				actual = *arg
+			case *byte:
+				actual = *arg
+			case []byte:
+				actual = NullTerminatedByteSlice(arg)
+			}
+
+			if actual != expected {
+				t.Errorf("%q, %q: expected argument %d to be %v, got %v",
+					test.src, test.format, k, expected, actual)
+			}
+		}
+	}
+}
+
+func TestSscanfCharacters(t *testing.T) {
+	// %c does not skip whitespace or add a null character.
+	buf := []byte("XXXXX")
+	n := Sscanf([]byte("a bc\x00"), []byte("%4c\x00"), buf)
+
+	if n != 1 || string(buf) != "a bcX" {
+		t.Errorf("expected 1 and %q, got %d and %q", "a bcX", n, buf)
+	}
+}
+
+func TestFscanf(t *testing.T) {
+	f, err := ioutil.TempFile("", "fscanf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	f.WriteString("12 34;rest")
+	f.Seek(0, io.SeekStart)
+
+	var a, b int
+	n := Fscanf(NewFile(f), []byte("%d %d\x00"), &a, &b)
+	if n != 2 || a != 12 || b != 34 {
+		t.Errorf("expected 2, 12 and 34, got %d, %d and %d", n, a, b)
+	}
+
+	// The character after the last number has not been read.
+	if c := Fgetc(NewFile(f)); c != ';' {
+		t.Errorf("expected the next character to be ';', got %q", rune(c))
+	}
+}
+
+func TestFgets(t *testing.T) {
+	f, err := ioutil.TempFile("", "fgets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	f.WriteString("hello\nworld")
+	f.Seek(0, io.SeekStart)
+	stream := NewFile(f)
+
+	tests := []struct {
+		num      int
+		expected string
+	}{
+		{20, "hello\n"},
+		{3, "wo"},
+		{20, "rld"},
+	}
+
+	str := make([]byte, 20)
+	for _, tt := range tests {
+		got := Fgets(str, tt.num, stream)
+		if NullTerminatedByteSlice(got) != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, NullTerminatedByteSlice(got))
+		}
+	}
+
+	if got := Fgets(str, 20, stream); got != nil {
+		t.Errorf("expected NULL at the end-of-file, got %q", got)
+	}
+}
+
+func TestFreadFwrite(t *testing.T) {
+	type point struct {
+		x int
+		y float64
+		c byte
+	}
+
+	f, err := ioutil.TempFile("", "fread")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	stream := NewFile(f)
+
+	// Each element is 24 bytes in C: "x", 4 bytes of padding, "y", "c" and 7
+	// more bytes of padding.
+	points := []point{{1, 1.5, 'a'}, {-2, 2.5, 'b'}, {3, -3.5, 'c'}}
+	if n := Fwrite(points, 24, 3, stream); n != 3 {
+		t.Errorf("expected 3 elements to be written, got %d", n)
+	}
+
+	if size := Ftell(stream); size != 72 {
+		t.Errorf("expected 72 bytes to be written, got %d", size)
+	}
+
+	Rewind(stream)
+	got := make([]point, 3)
+	if n := Fread(got, 24, 3, stream); n != 3 {
+		t.Errorf("expected 3 elements to be read, got %d", n)
+	}
+
+	for i := range points {
+		if got[i] != points[i] {
+			t.Errorf("expected %+v, got %+v", points[i], got[i])
+		}
+	}
+
+	// Only the first 4 bytes of the second element can be read, which is the
+	// whole of "x".
+	if n := Fwrite(points, 1, 4, stream); n != 4 {
+		t.Errorf("expected 4 bytes to be written, got %d", n)
+	}
+
+	Fseek(stream, 48, 0)
+	got = []point{{}, {9, 9.5, 'z'}}
+	if n := Fread(got, 24, 2, stream); n != 1 {
+		t.Errorf("expected 1 element to be read, got %d", n)
+	}
+
+	if got[0] != points[2] || got[1] != (point{1, 9.5, 'z'}) {
+		t.Errorf("expected %+v and a partly read element, got %+v", points[2], got)
+	}
+
+	// A single value, rather than an array of them.
+	Rewind(stream)
+	var p point
+	if n := Fread(&p, 24, 1, stream); n != 1 || p != points[0] {
+		t.Errorf("expected 1 element of %+v, got %d of %+v", points[0], n, p)
+	}
+}
+
+func TestFwritePointerFields(t *testing.T) {
+	type node struct {
+		id    int
+		name  []byte   `c2go:"pointer"`
+		tags  [][]byte `c2go:"pointers"`
+		label []byte
+	}
+
+	// "id", 4 bytes of padding, "name" as 8 bytes, "tags" as 2 pointers and
+	// "label" as an array of 3 bytes, then 5 bytes of padding.
+	n := node{7, []byte("a long name"), [][]byte{[]byte("x"), nil}, []byte("abc")}
+	b := encodeMemory(nil, reflect.ValueOf(n))
+	if len(b) != 40 {
+		t.Fatalf("expected 40 bytes, got %d: %v", len(b), b)
+	}
+
+	if b[0] != 7 || b[16] != 0 || b[32] != 'a' || b[34] != 'c' {
+		t.Errorf("expected the fields at their C offsets, got %v", b)
+	}
+
+	got := node{name: []byte("kept"), tags: make([][]byte, 2), label: make([]byte, 3)}
+	if offset := decodeMemory(b, 0, reflect.ValueOf(&got).Elem()); offset != 40 {
+		t.Errorf("expected to read 40 bytes, got %d", offset)
+	}
+
+	if got.id != 7 || string(got.name) != "kept" || string(got.label) != "abc" {
+		t.Errorf("expected the pointers to be left as they are, got %+v", got)
+	}
+}
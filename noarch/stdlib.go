@@ -1,8 +1,14 @@
 package noarch
 
 import (
+	"math"
+	"os"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"unicode"
+	"unsafe"
 )
 
 // Atoi parses the C-string str interpreting its content as an integral number,
@@ -73,14 +79,480 @@ func Atoi(a []byte) int {
 // either str is empty or it contains only whitespace characters, no conversion
 // is performed.
 //
-// For locales other than the "C" locale, additional subject sequence forms may
-// be accepted.
-func Strtol(a, b []byte, c int) int32 {
-	// TODO: This is a bad implementation
-	return 65535
+// If the value is out of the range of a long int then errno is set to ERANGE
+// and the largest (or smallest) long int is returned.
+//
+// Pointers are represented by slices so endptr is set to the slice that starts
+// at the first character after the number. If endptr is nil it is ignored.
+func Strtol(str []byte, endptr *[]byte, base int) int64 {
+	negative, value, overflow := parseInteger(str, endptr, base)
+
+	if negative {
+		if overflow || value > -math.MinInt64 {
+			errno[0] = ERANGE
+			return math.MinInt64
+		}
+
+		return -int64(value)
+	}
+
+	if overflow || value > math.MaxInt64 {
+		errno[0] = ERANGE
+		return math.MaxInt64
+	}
+
+	return int64(value)
+}
+
+// Strtoul works the same way as Strtol except that the result is an unsigned
+// long int. If the value is out of range errno is set to ERANGE and the largest
+// unsigned long int is returned.
+//
+// Like C a negative number is still accepted and the result is negated as an
+// unsigned value.
+func Strtoul(str []byte, endptr *[]byte, base int) uint64 {
+	negative, value, overflow := parseInteger(str, endptr, base)
+
+	if overflow {
+		errno[0] = ERANGE
+		return math.MaxUint64
+	}
+
+	if negative {
+		return -value
+	}
+
+	return value
+}
+
+// parseInteger contains the common logic for Strtol and Strtoul. It returns the
+// absolute value of the number that was parsed and if the number was negative.
+// overflow will be true if the number does not fit into a uint64.
+func parseInteger(str []byte, endptr *[]byte, base int) (
+	negative bool, value uint64, overflow bool) {
+	// If there is no number endptr must point to the start of the string.
+	if endptr != nil {
+		*endptr = str
+	}
+
+	if base < 0 || base == 1 || base > 36 {
+		errno[0] = EINVAL
+		return
+	}
+
+	i := 0
+	for i < len(str) && isSpace(str[i]) {
+		i++
+	}
+
+	if i < len(str) && (str[i] == '+' || str[i] == '-') {
+		negative = str[i] == '-'
+		i++
+	}
+
+	// The "0x" prefix is only consumed if there is a hexadecimal digit after
+	// it. Otherwise the "0" is the whole number.
+	hasHexPrefix := i+2 < len(str) && str[i] == '0' &&
+		(str[i+1] == 'x' || str[i+1] == 'X') && digitValue(str[i+2]) < 16
+
+	switch {
+	case (base == 0 || base == 16) && hasHexPrefix:
+		base = 16
+		i += 2
+
+	case base == 0 && i < len(str) && str[i] == '0':
+		base = 8
+
+	case base == 0:
+		base = 10
+	}
+
+	start := i
+	for ; i < len(str); i++ {
+		digit := digitValue(str[i])
+		if digit >= base {
+			break
+		}
+
+		if value > (math.MaxUint64-uint64(digit))/uint64(base) {
+			overflow = true
+		}
+
+		value = value*uint64(base) + uint64(digit)
+	}
+
+	if i == start {
+		return false, 0, false
+	}
+
+	if endptr != nil {
+		*endptr = str[i:]
+	}
+
+	return
+}
+
+// digitValue returns the value of a digit or letter in any base up to 36. If c
+// is not a digit or letter then 36 is returned, which is not valid for any
+// base.
+func digitValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	}
+
+	return 36
+}
+
+// isSpace returns true for the same characters as isspace() in the "C" locale.
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+
+	return false
+}
+
+// Malloc handles malloc(). It allocates size bytes of memory. The memory is set
+// to zero, which is always the case for memory allocated by Go.
+//
+// A call to malloc() that is cast to a pointer, like
+// "(struct Foo *)malloc(sizeof(struct Foo))", is translated into the Go
+// allocation of that type instead. Malloc is only called for the memory that
+// is used as a void * or char *.
+func Malloc(size int) []byte {
+	return make([]byte, size)
+}
+
+// Calloc handles calloc(). It allocates memory for an array of nmemb elements
+// of size bytes each. Like C the memory is set to zero, which is always the
+// case for memory allocated by Go.
+func Calloc(nmemb, size int) []byte {
+	return make([]byte, nmemb*size)
+}
+
+// Realloc handles realloc(). It changes the size of the memory pointed to by
+// ptr to size bytes. The contents will be unchanged up to the minimum of the
+// old and new sizes, any new memory is set to zero.
+//
+// If ptr is nil (or empty) then it is the same as allocating new memory with
+// malloc().
+//
+// The new memory is always a different slice to ptr, even when the memory is
+// being reduced. This means that any other pointers to the old memory will not
+// see changes made to the new memory.
+func Realloc(ptr []byte, size int) []byte {
+	b := make([]byte, size)
+	copy(b, ptr)
+
+	return b
+}
+
+// ReallocElements handles realloc() of the memory for a pointer that is not a
+// char * (or void *). The transpiler allocates the new memory as the Go type of
+// the pointer, like "make([]int, n)" for an int *, and the elements of ptr are
+// copied into it up to the minimum of the old and new number of elements.
+//
+// A pointer to a struct is a Go pointer to a single struct. The fields are
+// copied, and a slice that was allocated for the last field (a flexible array
+// member) has its elements copied instead, like the slice of an int *.
+//
+// It returns destination. If ptr is nil (or not the same kind of pointer) it is
+// the same as allocating new memory with malloc().
+func ReallocElements(destination, ptr interface{}) interface{} {
+	d := reflect.ValueOf(destination)
+	s := reflect.ValueOf(ptr)
+
+	if !s.IsValid() || s.Type() != d.Type() || s.IsNil() {
+		return destination
+	}
+
+	switch d.Kind() {
+	case reflect.Slice:
+		reflect.Copy(d, s)
+
+	case reflect.Ptr:
+		reallocFields(d.Elem(), s.Elem())
+	}
+
+	return destination
+}
+
+// reallocFields copies the fields of the struct s to the struct d for
+// ReallocElements.
+func reallocFields(d, s reflect.Value) {
+	if d.Kind() != reflect.Struct {
+		d.Set(s)
+		return
+	}
+
+	for i := 0; i < d.NumField(); i++ {
+		// The fields of a struct are not exported.
+		df := reflect.NewAt(d.Field(i).Type(), unsafe.Pointer(d.Field(i).UnsafeAddr())).Elem()
+		sf := reflect.NewAt(s.Field(i).Type(), unsafe.Pointer(s.Field(i).UnsafeAddr())).Elem()
+
+		if df.Kind() == reflect.Slice && !df.IsNil() {
+			reflect.Copy(df, sf)
+			continue
+		}
+
+		df.Set(sf)
+	}
 }
 
 // Free doesn't do anything since memory is managed by the Go garbage collector.
-// However, I will leave it here as a placeholder for now.
+// The memory will be released when there are no longer any references to it.
 func Free(anything interface{}) {
 }
+
+// Getenv handles getenv(). It returns the value of the environment variable as
+// a C string, or nil (a NULL pointer) if the variable is not set. A variable
+// that is set to an empty string is not the same as a variable that is not set.
+func Getenv(name []byte) []byte {
+	value, ok := os.LookupEnv(NullTerminatedByteSlice(name))
+	if !ok {
+		return nil
+	}
+
+	return []byte(value + "\x00")
+}
+
+// Setenv handles setenv(). An existing variable is only changed if overwrite
+// is not zero. It returns 0 on success, otherwise -1 and errno is set to
+// EINVAL if the name is empty or contains a "=".
+func Setenv(name, value []byte, overwrite int) int {
+	n := NullTerminatedByteSlice(name)
+	if !isValidEnvironmentName(n) {
+		errno[0] = EINVAL
+		return -1
+	}
+
+	if _, ok := os.LookupEnv(n); ok && overwrite == 0 {
+		return 0
+	}
+
+	if os.Setenv(n, NullTerminatedByteSlice(value)) != nil {
+		errno[0] = EINVAL
+		return -1
+	}
+
+	return 0
+}
+
+// Unsetenv handles unsetenv(). It is not an error if the variable is not set.
+func Unsetenv(name []byte) int {
+	n := NullTerminatedByteSlice(name)
+	if !isValidEnvironmentName(n) {
+		errno[0] = EINVAL
+		return -1
+	}
+
+	os.Unsetenv(n)
+
+	return 0
+}
+
+// Putenv handles putenv(). The string is in the form "name=value". Like glibc,
+// a string without a "=" removes the variable.
+//
+// In C the string becomes part of the environment, so changing the string
+// later would change the environment. That is not possible in Go, the value is
+// copied instead.
+func Putenv(s []byte) int {
+	str := NullTerminatedByteSlice(s)
+	i := strings.IndexByte(str, '=')
+	if i == -1 {
+		return Unsetenv(s)
+	}
+
+	if i == 0 || os.Setenv(str[:i], str[i+1:]) != nil {
+		errno[0] = EINVAL
+		return -1
+	}
+
+	return 0
+}
+
+func isValidEnvironmentName(name string) bool {
+	return name != "" && !strings.Contains(name, "=")
+}
+
+// Qsort handles qsort().
+//
+// The elements are stored as a slice of bytes where each element is size bytes
+// long. The comparison function receives a pointer to each of the elements
+// being compared. Like C, the sort is not stable.
+func Qsort(base []byte, num, size int, compar func(a, b []byte) int) {
+	sort.Sort(&qsortElements{
+		base:    base,
+		num:     num,
+		size:    size,
+		compar:  compar,
+		swapped: make([]byte, size),
+	})
+}
+
+// qsortElements implements sort.Interface for Qsort.
+type qsortElements struct {
+	base    []byte
+	num     int
+	size    int
+	compar  func(a, b []byte) int
+	swapped []byte
+}
+
+func (e *qsortElements) Len() int {
+	return e.num
+}
+
+func (e *qsortElements) Less(i, j int) bool {
+	return e.compar(e.base[i*e.size:], e.base[j*e.size:]) < 0
+}
+
+func (e *qsortElements) Swap(i, j int) {
+	a := e.base[i*e.size : (i+1)*e.size]
+	b := e.base[j*e.size : (j+1)*e.size]
+
+	copy(e.swapped, a)
+	copy(a, b)
+	copy(b, e.swapped)
+}
+
+// Bsearch handles bsearch().
+//
+// The elements are stored like Qsort and must be sorted. The comparison
+// function receives the key and a pointer to one of the elements. The pointer
+// to an element that is equal to the key is returned, or nil if there is none.
+// If more than one element is equal to the key it is the same one as glibc.
+func Bsearch(key, base []byte, num, size int, compar func(a, b []byte) int) []byte {
+	low, high := 0, num
+	for low < high {
+		middle := (low + high) / 2
+		element := base[middle*size:]
+
+		c := compar(key, element)
+		switch {
+		case c < 0:
+			high = middle
+		case c > 0:
+			low = middle + 1
+		default:
+			return element
+		}
+	}
+
+	return nil
+}
+
+// exitHandlers are the functions that are called by Exit.
+var exitHandlers []func()
+
+// Atexit handles atexit(). It registers a function to be called when the
+// program exits with Exit. The functions are called in the reverse order that
+// they are registered. This is also used for the functions that have the
+// destructor attribute.
+func Atexit(f func()) int {
+	exitHandlers = append(exitHandlers, f)
+
+	return 0
+}
+
+// Exit handles exit(). The functions that were registered with Atexit are
+// called before the program exits with the status.
+func Exit(status int) {
+	runExitHandlers()
+	os.Exit(status)
+}
+
+// runExitHandlers calls and removes each of the functions registered with
+// Atexit, the last one first. A handler that registers another handler will
+// also have it called.
+func runExitHandlers() {
+	for len(exitHandlers) > 0 {
+		f := exitHandlers[len(exitHandlers)-1]
+		exitHandlers = exitHandlers[:len(exitHandlers)-1]
+		f()
+	}
+}
+
+// RandMax is RAND_MAX, the largest number that is returned by Rand. It is the
+// same as glibc.
+const RandMax = 2147483647
+
+// randState is the state of Rand. It is the additive feedback generator of
+// glibc's random(), which is also used by rand(), so the same seed gives the
+// same numbers as a C program that is compiled with glibc:
+//
+//     r[i] = r[i-3] + r[i-31]
+//
+// The numbers are r[i] >> 1. front and rear are the positions of r[i-3] and
+// r[i-31] in the state.
+var randState struct {
+	r           [31]int32
+	front, rear int
+	seeded      bool
+}
+
+// Srand handles srand(). It sets the seed of the numbers that are returned by
+// Rand. A seed of 0 is the same as 1.
+func Srand(seed uint32) {
+	if seed == 0 {
+		seed = 1
+	}
+
+	r := &randState.r
+	r[0] = int32(seed)
+
+	// The rest of the state is filled with a linear congruential generator.
+	// The product (16807 * r[i-1]) % 2147483647 is calculated without
+	// overflowing 32 bits.
+	for i := 1; i < len(r); i++ {
+		word := 16807*(r[i-1]%127773) - 2836*(r[i-1]/127773)
+		if word < 0 {
+			word += 2147483647
+		}
+		r[i] = word
+	}
+
+	randState.front = 3
+	randState.rear = 0
+	randState.seeded = true
+
+	// The first numbers are discarded because they are not random enough.
+	for i := 0; i < 10*len(r); i++ {
+		nextRand()
+	}
+}
+
+// Rand handles rand(). It returns a pseudo-random number between 0 and
+// RandMax. The numbers are the same as srand(1) until Srand is called.
+func Rand() int {
+	if !randState.seeded {
+		Srand(1)
+	}
+
+	return int(nextRand())
+}
+
+func nextRand() int32 {
+	s := &randState
+	s.r[s.front] += s.r[s.rear]
+	result := int32(uint32(s.r[s.front]) >> 1)
+
+	s.front++
+	if s.front >= len(s.r) {
+		s.front = 0
+	}
+
+	s.rear++
+	if s.rear >= len(s.r) {
+		s.rear = 0
+	}
+
+	return result
+}
@@ -0,0 +1,368 @@
+package noarch
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestStrtol(t *testing.T) {
+	tests := []struct {
+		name   string
+		str    string
+		base   int
+		result int64
+		end    int
+		errno  int
+	}{
+		{"decimal", "123", 10, 123, 3, 0},
+		{"leading whitespace", " \t\n42abc", 10, 42, 5, 0},
+		{"negative", "-17", 10, -17, 3, 0},
+		{"positive sign", "+17", 10, 17, 3, 0},
+		{"hexadecimal", "0x1aG", 16, 26, 4, 0},
+		{"hexadecimal without prefix", "ff", 16, 255, 2, 0},
+		{"automatic hexadecimal", "0X1F", 0, 31, 4, 0},
+		{"automatic octal", "017", 0, 15, 3, 0},
+		{"automatic decimal", "17", 0, 17, 2, 0},
+		{"prefix without digits", "0xg", 0, 0, 1, 0},
+		{"base 36", "zZ", 36, 35*36 + 35, 2, 0},
+		{"binary", "1012", 2, 5, 3, 0},
+		{"no digits", "  abc", 10, 0, 0, 0},
+		{"sign without digits", "-", 10, 0, 0, 0},
+		{"stops at NULL", "12\x0034", 10, 12, 2, 0},
+		{"largest", "9223372036854775807", 10, math.MaxInt64, 19, 0},
+		{"smallest", "-9223372036854775808", 10, math.MinInt64, 20, 0},
+		{"overflow", "9223372036854775808", 10, math.MaxInt64, 19, ERANGE},
+		{"underflow", "-9223372036854775809", 10, math.MinInt64, 20, ERANGE},
+		{"overflow uint64", "99999999999999999999999", 10, math.MaxInt64, 23, ERANGE},
+		{"invalid base", "123", 1, 0, 0, EINVAL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errno[0] = 0
+			str := []byte(tt.str + "\x00")
+			var end []byte
+
+			got := Strtol(str, &end, tt.base)
+
+			if got != tt.result {
+				t.Errorf("Strtol() = %d, want %d", got, tt.result)
+			}
+			if &end[0] != &str[tt.end] {
+				t.Errorf("Strtol() endptr = %q, want %q", end, str[tt.end:])
+			}
+			if errno[0] != tt.errno {
+				t.Errorf("Strtol() errno = %d, want %d", errno[0], tt.errno)
+			}
+		})
+	}
+}
+
+func TestStrtoul(t *testing.T) {
+	tests := []struct {
+		name   string
+		str    string
+		base   int
+		result uint64
+		end    int
+		errno  int
+	}{
+		{"decimal", "123", 10, 123, 3, 0},
+		{"largest", "18446744073709551615", 10, math.MaxUint64, 20, 0},
+		{"overflow", "18446744073709551616", 0, math.MaxUint64, 20, ERANGE},
+		{"negative", "-1", 10, math.MaxUint64, 2, 0},
+		{"hexadecimal", "0xffffffffffffffff", 0, math.MaxUint64, 18, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errno[0] = 0
+			str := []byte(tt.str + "\x00")
+			var end []byte
+
+			got := Strtoul(str, &end, tt.base)
+
+			if got != tt.result {
+				t.Errorf("Strtoul() = %d, want %d", got, tt.result)
+			}
+			if &end[0] != &str[tt.end] {
+				t.Errorf("Strtoul() endptr = %q, want %q", end, str[tt.end:])
+			}
+			if errno[0] != tt.errno {
+				t.Errorf("Strtoul() errno = %d, want %d", errno[0], tt.errno)
+			}
+		})
+	}
+}
+
+func TestStrtolWithoutEndptr(t *testing.T) {
+	if got := Strtol([]byte("55\x00"), nil, 10); got != 55 {
+		t.Errorf("Strtol() = %d, want 55", got)
+	}
+}
+
+func TestCalloc(t *testing.T) {
+	b := Calloc(3, 4)
+
+	if !bytes.Equal(b, make([]byte, 12)) {
+		t.Errorf("expected 12 zero bytes, got %v", b)
+	}
+}
+
+func TestRealloc(t *testing.T) {
+	tests := []struct {
+		name     string
+		ptr      []byte
+		size     int
+		expected []byte
+	}{
+		{"grow", []byte("abc"), 5, []byte("abc\x00\x00")},
+		{"shrink", []byte("abcdef"), 2, []byte("ab")},
+		{"same size", []byte("abc"), 3, []byte("abc")},
+		{"nil is malloc", nil, 4, make([]byte, 4)},
+		{"empty is malloc", []byte{}, 2, make([]byte, 2)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original := append([]byte{}, test.ptr...)
+			b := Realloc(test.ptr, test.size)
+
+			if !bytes.Equal(b, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, b)
+			}
+
+			// Writing to the new memory must not change the old memory.
+			if len(b) > 0 {
+				b[0] = 'z'
+			}
+			if !bytes.Equal(test.ptr, original) {
+				t.Errorf("the original memory was changed to %q", test.ptr)
+			}
+		})
+	}
+}
+
+func TestReallocElements(t *testing.T) {
+	a := []int32{1, 2, 3}
+
+	if b := ReallocElements(make([]int32, 5), a).([]int32); !reflect.DeepEqual(b, []int32{1, 2, 3, 0, 0}) {
+		t.Errorf("grow: expected [1 2 3 0 0], got %v", b)
+	}
+	if b := ReallocElements(make([]int32, 2), a).([]int32); !reflect.DeepEqual(b, []int32{1, 2}) {
+		t.Errorf("shrink: expected [1 2], got %v", b)
+	}
+	if b := ReallocElements(make([]int32, 2), nil).([]int32); !reflect.DeepEqual(b, []int32{0, 0}) {
+		t.Errorf("nil is malloc: expected [0 0], got %v", b)
+	}
+
+	type hdr struct {
+		len  int32
+		name []byte
+		data []int32
+	}
+
+	name := []byte("abc")
+	h := ReallocElements(&hdr{data: make([]int32, 3)}, &hdr{len: 2, name: name, data: []int32{4, 5}}).(*hdr)
+
+	if h.len != 2 || &h.name[0] != &name[0] || !reflect.DeepEqual(h.data, []int32{4, 5, 0}) {
+		t.Errorf("struct: expected {2 abc [4 5 0]}, got %v", *h)
+	}
+}
+
+func TestGetenv(t *testing.T) {
+	if Setenv([]byte("C2GO_TEST_GETENV\x00"), []byte("foo\x00"), 1) != 0 {
+		t.Fatal("setenv failed")
+	}
+
+	if v := Getenv([]byte("C2GO_TEST_GETENV\x00")); !bytes.Equal(v, []byte("foo\x00")) {
+		t.Errorf("expected foo, got %q", v)
+	}
+
+	// An existing variable is only replaced when overwrite is not zero.
+	Setenv([]byte("C2GO_TEST_GETENV\x00"), []byte("bar\x00"), 0)
+	if v := Getenv([]byte("C2GO_TEST_GETENV\x00")); !bytes.Equal(v, []byte("foo\x00")) {
+		t.Errorf("expected foo, got %q", v)
+	}
+
+	if Putenv([]byte("C2GO_TEST_GETENV=\x00")) != 0 {
+		t.Fatal("putenv failed")
+	}
+
+	// An empty value is not a NULL pointer.
+	if v := Getenv([]byte("C2GO_TEST_GETENV\x00")); !bytes.Equal(v, []byte("\x00")) {
+		t.Errorf("expected an empty string, got %q", v)
+	}
+
+	if Unsetenv([]byte("C2GO_TEST_GETENV\x00")) != 0 {
+		t.Fatal("unsetenv failed")
+	}
+
+	if v := Getenv([]byte("C2GO_TEST_GETENV\x00")); v != nil {
+		t.Errorf("expected nil, got %q", v)
+	}
+}
+
+func TestSetenvInvalidName(t *testing.T) {
+	for _, name := range []string{"\x00", "A=B\x00"} {
+		errno[0] = 0
+		if Setenv([]byte(name), []byte("foo\x00"), 1) != -1 || errno[0] != EINVAL {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestQsort(t *testing.T) {
+	type point struct {
+		x, y int32
+	}
+
+	points := []point{{3, 0}, {-1, 1}, {7, 2}, {0, 3}, {3, 4}, {2, 5}}
+	size := int(unsafe.Sizeof(points[0]))
+
+	// The elements are passed to Qsort as bytes, the same as a void *.
+	base := (*[1 << 20]byte)(unsafe.Pointer(&points[0]))[:len(points)*size]
+
+	Qsort(base, len(points), size, func(a, b []byte) int {
+		pa := (*point)(unsafe.Pointer(&a[0]))
+		pb := (*point)(unsafe.Pointer(&b[0]))
+
+		return int(pa.x - pb.x)
+	})
+
+	// The whole element must be moved, not only the field that is compared.
+	// Two of the elements are equal so they could be in either order.
+	expected := []point{{-1, 1}, {0, 3}, {2, 5}, {3, 0}, {3, 4}, {7, 2}}
+	if points[3].y == 4 {
+		expected[3], expected[4] = expected[4], expected[3]
+	}
+
+	for i := range points {
+		if points[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, points)
+		}
+	}
+}
+
+func TestQsortBytes(t *testing.T) {
+	base := []byte("qsort")
+
+	Qsort(base, len(base), 1, func(a, b []byte) int {
+		return int(a[0]) - int(b[0])
+	})
+
+	if string(base) != "oqrst" {
+		t.Errorf("expected oqrst, got %s", base)
+	}
+}
+
+func TestBsearch(t *testing.T) {
+	type point struct {
+		x, y int32
+	}
+
+	points := []point{{-1, 1}, {0, 3}, {2, 5}, {3, 0}, {7, 2}}
+	size := int(unsafe.Sizeof(points[0]))
+	base := (*[1 << 20]byte)(unsafe.Pointer(&points[0]))[:len(points)*size]
+
+	compar := func(a, b []byte) int {
+		pa := (*point)(unsafe.Pointer(&a[0]))
+		pb := (*point)(unsafe.Pointer(&b[0]))
+
+		return int(pa.x - pb.x)
+	}
+
+	search := func(x int32) []byte {
+		key := point{x, 0}
+		keyBytes := (*[1 << 20]byte)(unsafe.Pointer(&key))[:size]
+
+		return Bsearch(keyBytes, base, len(points), size, compar)
+	}
+
+	for i, p := range points {
+		found := search(p.x)
+		if found == nil {
+			t.Fatalf("expected to find %d", p.x)
+		}
+
+		// The result points to the element in the array.
+		if &found[0] != &base[i*size] {
+			t.Errorf("expected to find %d at element %d", p.x, i)
+		}
+
+		if (*point)(unsafe.Pointer(&found[0])).y != p.y {
+			t.Errorf("expected %v, got %v", p, *(*point)(unsafe.Pointer(&found[0])))
+		}
+	}
+
+	for _, x := range []int32{-5, 1, 4, 8} {
+		if found := search(x); found != nil {
+			t.Errorf("expected not to find %d, got %v", x, *(*point)(unsafe.Pointer(&found[0])))
+		}
+	}
+
+	if found := Bsearch([]byte("a"), nil, 0, 1, compar); found != nil {
+		t.Errorf("expected not to find anything in an empty array")
+	}
+}
+
+func TestAtexit(t *testing.T) {
+	calls := ""
+	Atexit(func() { calls += "a" })
+	Atexit(func() {
+		calls += "b"
+		Atexit(func() { calls += "c" })
+	})
+
+	runExitHandlers()
+
+	if calls != "bca" {
+		t.Errorf("Expected the handlers to be called in the order bca, got %s", calls)
+	}
+
+	runExitHandlers()
+
+	if calls != "bca" {
+		t.Errorf("Expected the handlers to only be called once, got %s", calls)
+	}
+}
+
+func TestRand(t *testing.T) {
+	// These are the numbers of glibc for the same seed.
+	tests := []struct {
+		seed    uint32
+		numbers []int
+	}{
+		{1, []int{1804289383, 846930886, 1681692777, 1714636915, 1957747793}},
+		{0, []int{1804289383, 846930886, 1681692777, 1714636915, 1957747793}},
+		{42, []int{71876166, 708592740, 1483128881, 907283241, 442951012}},
+	}
+
+	for _, tt := range tests {
+		Srand(tt.seed)
+
+		for i, expected := range tt.numbers {
+			if n := Rand(); n != expected {
+				t.Errorf("srand(%d): rand() %d: expected %d, got %d",
+					tt.seed, i+1, expected, n)
+			}
+		}
+	}
+
+	// The numbers are the same after seeding again.
+	Srand(7)
+	first := []int{Rand(), Rand(), Rand()}
+	Srand(7)
+	for i, expected := range first {
+		if n := Rand(); n != expected {
+			t.Errorf("rand() %d: expected %d after seeding again, got %d", i+1, expected, n)
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		if n := Rand(); n < 0 || n > RandMax {
+			t.Fatalf("rand() is outside of 0 to RAND_MAX: %d", n)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package noarch
 
+import "strings"
+
 // Strlen returns the length of a string.
 //
 // The length of a C string is determined by the terminating null-character: A
@@ -12,3 +14,228 @@ func Strlen(a []byte) int {
 
 	return len(NullTerminatedByteSlice(a))
 }
+
+// Memmove handles memmove().
+//
+// Copies the values of num bytes from the location pointed by source to the
+// memory block pointed by destination. Copying takes place as if an
+// intermediate buffer were used, allowing the destination and source to
+// overlap.
+//
+// Pointers are represented as slices so an offset into the same buffer (like
+// "buf + 2" in C) is a reslice of the same underlying array. Go's copy()
+// already handles overlapping slices in both directions.
+func Memmove(destination, source []byte, num int) []byte {
+	copy(destination[:num], source[:num])
+
+	return destination
+}
+
+// Memset handles memset().
+//
+// Sets the first num bytes of the block of memory pointed by destination to
+// the specified value (interpreted as an unsigned char).
+func Memset(destination []byte, value int, num int) []byte {
+	b := byte(value)
+	for i := range destination[:num] {
+		destination[i] = b
+	}
+
+	return destination
+}
+
+// Strncpy handles strncpy().
+//
+// Copies the first num characters of source to destination. If the end of the
+// source C string (which is signaled by a null-character) is found before num
+// characters have been copied, destination is padded with zeros until a total
+// of num characters have been written to it.
+//
+// No null-character is implicitly appended at the end of destination if source
+// is longer than num. Thus, in this case, destination shall not be considered a
+// null terminated C string.
+func Strncpy(destination, source []byte, num int) []byte {
+	i := 0
+	for ; i < num && i < len(source) && source[i] != 0; i++ {
+		destination[i] = source[i]
+	}
+
+	for ; i < num; i++ {
+		destination[i] = 0
+	}
+
+	return destination
+}
+
+// Strncat handles strncat().
+//
+// Appends the first num characters of source to destination, plus a terminating
+// null-character. If the length of the C string in source is less than num,
+// only the content up to the terminating null-character is copied.
+func Strncat(destination, source []byte, num int) []byte {
+	end := Strlen(destination)
+
+	i := 0
+	for ; i < num && i < len(source) && source[i] != 0; i++ {
+		destination[end+i] = source[i]
+	}
+
+	destination[end+i] = 0
+
+	return destination
+}
+
+// Strncmp handles strncmp().
+//
+// Compares up to num characters of the C string str1 to those of the C string
+// str2. The characters are compared as unsigned chars until they differ, until
+// a terminating null-character is reached, or until num characters match in
+// both strings, whichever happens first.
+//
+// The return value is negative if str1 is less than str2, zero if they are
+// equal and positive if str1 is greater than str2.
+func Strncmp(str1, str2 []byte, num int) int {
+	for i := 0; i < num; i++ {
+		a, b := characterAt(str1, i), characterAt(str2, i)
+		if a != b {
+			return int(a) - int(b)
+		}
+
+		if a == 0 {
+			break
+		}
+	}
+
+	return 0
+}
+
+// characterAt returns a character of a C string. The end of the slice is the
+// same as a null-character.
+func characterAt(s []byte, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+
+	return 0
+}
+
+// Strchr handles strchr().
+//
+// Returns a pointer to the first occurrence of character (converted to a char)
+// in the C string str, or NULL (nil) if it is not found. The terminating
+// null-character is considered part of the C string, so it can also be located
+// to get a pointer to the end of a string.
+func Strchr(str []byte, character int) []byte {
+	c := byte(character)
+	for i := 0; i < len(str); i++ {
+		if str[i] == c {
+			return str[i:]
+		}
+
+		if str[i] == 0 {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Strrchr handles strrchr().
+//
+// Returns a pointer to the last occurrence of character (converted to a char)
+// in the C string str, or NULL (nil) if it is not found. Like Strchr, the
+// terminating null-character is part of the string.
+func Strrchr(str []byte, character int) []byte {
+	c := byte(character)
+	last := -1
+	for i := 0; i < len(str); i++ {
+		if str[i] == c {
+			last = i
+		}
+
+		if str[i] == 0 {
+			break
+		}
+	}
+
+	if last == -1 {
+		return nil
+	}
+
+	return str[last:]
+}
+
+// Strstr handles strstr().
+//
+// Returns a pointer to the first occurrence of str2 in str1, or NULL (nil) if
+// str2 is not part of str1. The terminating null-characters are not compared.
+// An empty str2 is found at the start of str1.
+func Strstr(str1, str2 []byte) []byte {
+	i := strings.Index(NullTerminatedByteSlice(str1), NullTerminatedByteSlice(str2))
+	if i == -1 {
+		return nil
+	}
+
+	return str1[i:]
+}
+
+// strtokState is where the next call to Strtok continues from. Like C, Strtok
+// is not safe to use from more than one goroutine. Strtok_r should be used
+// instead.
+var strtokState []byte
+
+// Strtok handles strtok().
+//
+// A sequence of calls to this function split str into tokens, which are
+// sequences of contiguous characters separated by any of the characters that
+// are part of delim.
+//
+// On the first call the function expects a C string as argument for str. In
+// each subsequent call the function expects a NULL pointer and continues after
+// the end of the last token.
+//
+// The end of each token is replaced with a NULL character in str, so the token
+// that is returned is a slice of the same string. NULL (nil) is returned when
+// there are no tokens left.
+func Strtok(str, delim []byte) []byte {
+	return Strtok_r(str, delim, &strtokState)
+}
+
+// Strtok_r handles strtok_r(). It works the same way as Strtok except that the
+// position between calls is stored in saveptr instead of a static variable.
+//
+// A "char **" is a pointer to a slice, the same as the endptr of Strtol.
+func Strtok_r(str, delim []byte, saveptr *[]byte) []byte {
+	if str == nil {
+		str = *saveptr
+	}
+
+	delimiters := NullTerminatedByteSlice(delim)
+	isDelimiter := func(i int) bool {
+		return strings.IndexByte(delimiters, str[i]) != -1
+	}
+
+	start := 0
+	for start < len(str) && str[start] != 0 && isDelimiter(start) {
+		start++
+	}
+
+	if start == len(str) || str[start] == 0 {
+		*saveptr = nil
+		return nil
+	}
+
+	end := start
+	for end < len(str) && str[end] != 0 && !isDelimiter(end) {
+		end++
+	}
+
+	if end < len(str) && str[end] != 0 {
+		str[end] = 0
+		end++
+	}
+
+	*saveptr = str[end:]
+
+	return str[start:]
+}
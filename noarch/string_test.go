@@ -0,0 +1,253 @@
+package noarch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMemmove(t *testing.T) {
+	tests := []struct {
+		name   string
+		dst    int
+		src    int
+		num    int
+		result string
+	}{
+		{"no overlap", 0, 4, 4, "efghefgh"},
+		{"overlapping forward", 2, 0, 4, "ababcdgh"},
+		{"overlapping backward", 0, 2, 4, "cdefefgh"},
+		{"same location", 1, 1, 4, "abcdefgh"},
+		{"zero bytes", 0, 4, 0, "abcdefgh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := []byte("abcdefgh")
+			got := Memmove(buf[tt.dst:], buf[tt.src:], tt.num)
+
+			if string(buf) != tt.result {
+				t.Errorf("Memmove() buffer = %s, want %s", buf, tt.result)
+			}
+			if &got[0] != &buf[tt.dst] {
+				t.Errorf("Memmove() did not return the destination")
+			}
+		})
+	}
+}
+
+func TestMemset(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  int
+		num    int
+		result string
+	}{
+		{"character", 'A', 4, "AAAAefgh"},
+		{"zero", 0, 2, "\x00\x00cdefgh"},
+		{"truncated to a byte", 0x142, 3, "BBBdefgh"},
+		{"zero bytes", 'A', 0, "abcdefgh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := []byte("abcdefgh")
+			got := Memset(buf, tt.value, tt.num)
+
+			if string(buf) != tt.result {
+				t.Errorf("Memset() buffer = %q, want %q", buf, tt.result)
+			}
+			if &got[0] != &buf[0] {
+				t.Errorf("Memset() did not return the destination")
+			}
+		})
+	}
+}
+
+// tokens calls next until it returns nil and returns the tokens as Go strings.
+func tokens(next func() []byte) []string {
+	result := []string{}
+	for token := next(); token != nil; token = next() {
+		result = append(result, NullTerminatedByteSlice(token))
+	}
+
+	return result
+}
+
+func TestStrtok(t *testing.T) {
+	str := []byte(",,a,bc;;d,\x00")
+	delim := []byte(",;\x00")
+
+	s := str
+	got := tokens(func() []byte {
+		token := Strtok(s, delim)
+		s = nil
+		return token
+	})
+
+	if !reflect.DeepEqual(got, []string{"a", "bc", "d"}) {
+		t.Errorf("Strtok() = %v", got)
+	}
+
+	// The delimiters after the tokens are replaced in place.
+	if string(str) != ",,a\x00bc\x00;d\x00\x00" {
+		t.Errorf("Strtok() modified the string to %q", str)
+	}
+
+	if Strtok(nil, delim) != nil {
+		t.Errorf("Strtok() should return NULL after the last token")
+	}
+}
+
+func TestStrtokReturnsSlicesOfTheString(t *testing.T) {
+	str := []byte("ab cd\x00")
+
+	first := Strtok(str, []byte(" \x00"))
+	second := Strtok(nil, []byte(" \x00"))
+
+	if &first[0] != &str[0] || &second[0] != &str[3] {
+		t.Errorf("Strtok() did not return slices of the string")
+	}
+}
+
+func TestStrtok_r(t *testing.T) {
+	lines := []byte("a=1&b=2\x00")
+	var outer, inner []byte
+
+	s := lines
+	got := tokens(func() []byte {
+		pair := Strtok_r(s, []byte("&\x00"), &outer)
+		s = nil
+		if pair == nil {
+			return nil
+		}
+
+		// A second tokenizer can be used at the same time.
+		key := Strtok_r(pair, []byte("=\x00"), &inner)
+		value := Strtok_r(nil, []byte("=\x00"), &inner)
+
+		return []byte(NullTerminatedByteSlice(key) + ":" + NullTerminatedByteSlice(value))
+	})
+
+	if !reflect.DeepEqual(got, []string{"a:1", "b:2"}) {
+		t.Errorf("Strtok_r() = %v", got)
+	}
+
+	if outer != nil {
+		t.Errorf("Strtok_r() should set saveptr to NULL after the last token")
+	}
+}
+
+func TestStrncpy(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		num    int
+		result string
+	}{
+		{"shorter source is padded", "ab\x00", 5, "ab\x00\x00\x00fgh"},
+		{"source with the same length", "abcd\x00", 4, "abcdefgh"},
+		{"longer source is not terminated", "ABCDEF\x00", 3, "ABCdefgh"},
+		{"zero characters", "AB\x00", 0, "abcdefgh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := []byte("abcdefgh")
+			got := Strncpy(buf, []byte(tt.src), tt.num)
+
+			if string(buf) != tt.result {
+				t.Errorf("Strncpy() buffer = %q, want %q", buf, tt.result)
+			}
+			if &got[0] != &buf[0] {
+				t.Errorf("Strncpy() did not return the destination")
+			}
+		})
+	}
+}
+
+func TestStrncat(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		num    int
+		result string
+	}{
+		{"shorter source", "cd\x00", 4, "abcd\x00xxx"},
+		{"longer source is terminated", "cdef\x00", 2, "abcd\x00xxx"},
+		{"zero characters", "cd\x00", 0, "ab\x00xxxxx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := []byte("ab\x00xxxxx")
+			got := Strncat(buf, []byte(tt.src), tt.num)
+
+			if string(buf) != tt.result {
+				t.Errorf("Strncat() buffer = %q, want %q", buf, tt.result)
+			}
+			if &got[0] != &buf[0] {
+				t.Errorf("Strncat() did not return the destination")
+			}
+		})
+	}
+}
+
+func TestStrncmp(t *testing.T) {
+	tests := []struct {
+		str1, str2 string
+		num        int
+		sign       int
+	}{
+		{"abc\x00", "abc\x00", 10, 0},
+		{"abcd\x00", "abce\x00", 3, 0},
+		{"abcd\x00", "abce\x00", 4, -1},
+		{"abc\x00", "ab\x00", 10, 1},
+		{"ab\x00x", "ab\x00y", 10, 0},
+		{"\xff\x00", "a\x00", 1, 1},
+		{"a\x00", "b\x00", 0, 0},
+	}
+	for _, tt := range tests {
+		got := Strncmp([]byte(tt.str1), []byte(tt.str2), tt.num)
+
+		sign := 0
+		if got < 0 {
+			sign = -1
+		} else if got > 0 {
+			sign = 1
+		}
+
+		if sign != tt.sign {
+			t.Errorf("Strncmp(%q, %q, %d) = %d", tt.str1, tt.str2, tt.num, got)
+		}
+	}
+}
+
+func TestStrchrAndStrrchr(t *testing.T) {
+	str := []byte("a/b/c\x00/d")
+
+	if got := Strchr(str, '/'); &got[0] != &str[1] {
+		t.Errorf("Strchr() did not return the first '/'")
+	}
+	if got := Strrchr(str, '/'); &got[0] != &str[3] {
+		t.Errorf("Strrchr() did not return the last '/' before the terminator")
+	}
+	if got := Strchr(str, 0); &got[0] != &str[5] {
+		t.Errorf("Strchr() did not return the terminator")
+	}
+	if got := Strrchr(str, 0); &got[0] != &str[5] {
+		t.Errorf("Strrchr() did not return the terminator")
+	}
+	if Strchr(str, 'd') != nil || Strrchr(str, 'd') != nil {
+		t.Errorf("Strchr() and Strrchr() should return NULL after the terminator")
+	}
+}
+
+func TestStrstr(t *testing.T) {
+	str := []byte("hello world\x00")
+
+	if got := Strstr(str, []byte("wor\x00")); &got[0] != &str[6] {
+		t.Errorf("Strstr() did not return a slice of the string")
+	}
+	if got := Strstr(str, []byte("\x00")); &got[0] != &str[0] {
+		t.Errorf("Strstr() should find an empty string at the start")
+	}
+	if Strstr(str, []byte("worlds\x00")) != nil {
+		t.Errorf("Strstr() should return NULL")
+	}
+}
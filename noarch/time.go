@@ -0,0 +1,373 @@
+package noarch
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tm replaces "struct tm" from time.h. The fields are exported with the same
+// names as C, like Tm_year for tm_year.
+type Tm struct {
+	Tm_sec    int
+	Tm_min    int
+	Tm_hour   int
+	Tm_mday   int
+	Tm_mon    int
+	Tm_year   int
+	Tm_wday   int
+	Tm_yday   int
+	Tm_isdst  int
+	Tm_gmtoff int32
+	Tm_zone   []byte
+}
+
+// newTm returns the broken-down time of a Go time.
+func newTm(t time.Time) *Tm {
+	zone, offset := t.Zone()
+	isdst := 0
+	if t.IsDST() {
+		isdst = 1
+	}
+
+	return &Tm{
+		Tm_sec:    t.Second(),
+		Tm_min:    t.Minute(),
+		Tm_hour:   t.Hour(),
+		Tm_mday:   t.Day(),
+		Tm_mon:    int(t.Month()) - 1,
+		Tm_year:   t.Year() - 1900,
+		Tm_wday:   int(t.Weekday()),
+		Tm_yday:   t.YearDay() - 1,
+		Tm_isdst:  isdst,
+		Tm_gmtoff: int32(offset),
+		Tm_zone:   []byte(zone + "\x00"),
+	}
+}
+
+// goTime returns the Go time of the broken-down time in the location. Like C,
+// the fields may be outside of their normal ranges, like a tm_mday of 32, and
+// tm_wday and tm_yday are ignored.
+func (tm *Tm) goTime(loc *time.Location) time.Time {
+	return time.Date(tm.Tm_year+1900, time.Month(tm.Tm_mon+1), tm.Tm_mday,
+		tm.Tm_hour, tm.Tm_min, tm.Tm_sec, 0, loc)
+}
+
+// zoneTime returns the Go time of the broken-down time in its own time zone
+// (tm_zone and tm_gmtoff) so that it can be formatted.
+func (tm *Tm) zoneTime() time.Time {
+	return tm.goTime(time.FixedZone(NullTerminatedByteSlice(tm.Tm_zone), int(tm.Tm_gmtoff)))
+}
+
+// Time handles time(). The current time is also stored in tloc if it is not
+// NULL.
+func Time(tloc *int32) int32 {
+	now := int32(time.Now().Unix())
+	if tloc != nil {
+		*tloc = now
+	}
+
+	return now
+}
+
+// Difftime handles difftime(). It returns the number of seconds from start to
+// end.
+func Difftime(end, start int32) float64 {
+	return float64(end) - float64(start)
+}
+
+// Mktime handles mktime().
+//
+// It returns the calendar time of the broken-down local time in tm. The fields
+// of tm are normalized, so a tm_mday of 32 in January becomes the 1st of
+// February, and tm_wday and tm_yday are set.
+func Mktime(tm *Tm) int32 {
+	t := tm.goTime(time.Local)
+	*tm = *newTm(t)
+
+	return int32(t.Unix())
+}
+
+// Localtime handles localtime(). It returns the broken-down local time of the
+// calendar time in timer.
+//
+// C returns a pointer to the same static struct on every call. Each call
+// returns a new struct instead, which is still correct for a program that
+// copies the result before calling it again.
+func Localtime(timer *int32) *Tm {
+	return newTm(time.Unix(int64(*timer), 0).In(time.Local))
+}
+
+// Gmtime handles gmtime(). It is the same as Localtime except that the
+// broken-down time is in UTC.
+func Gmtime(timer *int32) *Tm {
+	return newTm(time.Unix(int64(*timer), 0).UTC())
+}
+
+// strftimeLayouts are the Go reference time layouts of the strftime()
+// conversion specifications.
+var strftimeLayouts = map[byte]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'B': "January",
+	'c': "Mon Jan _2 15:04:05 2006",
+	'd': "02",
+	'D': "01/02/06",
+	'e': "_2",
+	'F': "2006-01-02",
+	'h': "Jan",
+	'H': "15",
+	'I': "03",
+	'j': "002",
+	'm': "01",
+	'M': "04",
+	'p': "PM",
+	'r': "03:04:05 PM",
+	'R': "15:04",
+	'S': "05",
+	'T': "15:04:05",
+	'x': "01/02/06",
+	'X': "15:04:05",
+	'y': "06",
+	'Y': "2006",
+	'z': "-0700",
+	'Z': "MST",
+}
+
+// formatTm returns the broken-down time formatted like strftime().
+//
+// Each conversion specification is formatted by itself with its Go layout. The
+// format cannot be translated into a single Go layout because the other
+// characters, like the "2" in "%d/2", could be mistaken for a part of the
+// layout.
+func formatTm(format string, tm *Tm) string {
+	t := tm.zoneTime()
+
+	var out strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			out.WriteByte(format[i])
+			continue
+		}
+
+		i++
+		c := format[i]
+		if layout, ok := strftimeLayouts[c]; ok {
+			out.WriteString(t.Format(layout))
+			continue
+		}
+
+		switch c {
+		case 'n':
+			out.WriteByte('\n')
+		case 't':
+			out.WriteByte('\t')
+		case '%':
+			out.WriteByte('%')
+		case 'C':
+			out.WriteString(strconv.Itoa((tm.Tm_year + 1900) / 100))
+		case 's':
+			out.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'u':
+			out.WriteString(strconv.Itoa((int(t.Weekday())+6)%7 + 1))
+		case 'w':
+			out.WriteString(strconv.Itoa(int(t.Weekday())))
+		default:
+			// An unknown conversion specification is left as it is.
+			out.WriteByte('%')
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String()
+}
+
+// Strftime handles strftime().
+//
+// The broken-down time in tm is formatted into s, which has room for max
+// characters, including the terminating null character. The number of
+// characters (without the null character) is returned. Like C, 0 is returned if
+// the result does not fit.
+func Strftime(s []byte, max uint32, format []byte, tm *Tm) uint32 {
+	result := formatTm(NullTerminatedByteSlice(format), tm)
+	if uint32(len(result)) >= max {
+		return 0
+	}
+
+	copy(s, result)
+	s[len(result)] = 0
+
+	return uint32(len(result))
+}
+
+// strptimeNames are the names of the months and days that strptime() matches.
+// The full names start with the abbreviated names.
+var (
+	strptimeMonths = []string{"january", "february", "march", "april", "may",
+		"june", "july", "august", "september", "october", "november", "december"}
+	strptimeDays = []string{"sunday", "monday", "tuesday", "wednesday",
+		"thursday", "friday", "saturday"}
+)
+
+// tmParser reads the input of strptime().
+type tmParser struct {
+	s   string
+	pos int
+}
+
+// skipSpaces skips any white-space characters.
+func (p *tmParser) skipSpaces() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n\r\f\v", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// number reads a number of up to width digits that must be between min and
+// max.
+func (p *tmParser) number(width, min, max int) (int, bool) {
+	p.skipSpaces()
+
+	start := p.pos
+	for p.pos < len(p.s) && p.pos-start < width && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+
+	n, err := strconv.Atoi(p.s[start:p.pos])
+	if err != nil || n < min || n > max {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// name reads the full or abbreviated (the first three letters) name of a month,
+// day or AM/PM and returns its index.
+func (p *tmParser) name(names []string) (int, bool) {
+	p.skipSpaces()
+
+	rest := strings.ToLower(p.s[p.pos:])
+	for i, name := range names {
+		if strings.HasPrefix(rest, name) {
+			p.pos += len(name)
+			return i, true
+		}
+	}
+
+	for i, name := range names {
+		if len(name) > 3 && strings.HasPrefix(rest, name[:3]) {
+			p.pos += 3
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// parse reads the input with the format and sets the fields of tm that are in
+// the format.
+func (p *tmParser) parse(format string, tm *Tm) bool {
+	pm := -1
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == ' ' || c == '\t' || c == '\n' {
+			p.skipSpaces()
+			continue
+		}
+
+		if c != '%' || i+1 == len(format) {
+			if p.pos == len(p.s) || p.s[p.pos] != c {
+				return false
+			}
+
+			p.pos++
+			continue
+		}
+
+		i++
+
+		var ok bool
+		switch format[i] {
+		case 'Y':
+			var year int
+			year, ok = p.number(4, 0, 9999)
+			tm.Tm_year = year - 1900
+		case 'y':
+			var year int
+			year, ok = p.number(2, 0, 99)
+
+			// Like POSIX, 69 to 99 are the 20th century.
+			if year < 69 {
+				year += 100
+			}
+			tm.Tm_year = year
+		case 'm':
+			var month int
+			month, ok = p.number(2, 1, 12)
+			tm.Tm_mon = month - 1
+		case 'd', 'e':
+			tm.Tm_mday, ok = p.number(2, 1, 31)
+		case 'H':
+			tm.Tm_hour, ok = p.number(2, 0, 23)
+		case 'I':
+			tm.Tm_hour, ok = p.number(2, 1, 12)
+		case 'M':
+			tm.Tm_min, ok = p.number(2, 0, 59)
+		case 'S':
+			tm.Tm_sec, ok = p.number(2, 0, 60)
+		case 'j':
+			var day int
+			day, ok = p.number(3, 1, 366)
+			tm.Tm_yday = day - 1
+		case 'b', 'B', 'h':
+			tm.Tm_mon, ok = p.name(strptimeMonths)
+		case 'a', 'A':
+			tm.Tm_wday, ok = p.name(strptimeDays)
+		case 'p':
+			pm, ok = p.name([]string{"am", "pm"})
+		case 'F':
+			ok = p.parse("%Y-%m-%d", tm)
+		case 'T':
+			ok = p.parse("%H:%M:%S", tm)
+		case 'D':
+			ok = p.parse("%m/%d/%y", tm)
+		case 'R':
+			ok = p.parse("%H:%M", tm)
+		case 'n', 't':
+			p.skipSpaces()
+			ok = true
+		case '%':
+			ok = p.pos < len(p.s) && p.s[p.pos] == '%'
+			p.pos++
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	// The hour of %I is changed to 24-hour time by %p.
+	switch pm {
+	case 0:
+		tm.Tm_hour %= 12
+	case 1:
+		tm.Tm_hour = tm.Tm_hour%12 + 12
+	}
+
+	return true
+}
+
+// Strptime handles strptime().
+//
+// It reads the time in s with the format and sets the fields of tm that are in
+// the format. The other fields are not changed. It returns the rest of s after
+// the time, or NULL if s does not match the format.
+func Strptime(s []byte, format []byte, tm *Tm) []byte {
+	p := &tmParser{s: NullTerminatedByteSlice(s)}
+	if !p.parse(NullTerminatedByteSlice(format), tm) {
+		return nil
+	}
+
+	return s[p.pos:]
+}
@@ -0,0 +1,107 @@
+package noarch
+
+import (
+	"testing"
+)
+
+func newTestTm() *Tm {
+	return &Tm{
+		Tm_sec:   5,
+		Tm_min:   4,
+		Tm_hour:  15,
+		Tm_mday:  2,
+		Tm_mon:   0,
+		Tm_year:  106,
+		Tm_wday:  1,
+		Tm_yday:  1,
+		Tm_zone:  []byte("UTC\x00"),
+		Tm_isdst: 0,
+	}
+}
+
+func TestStrftime(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%Y-%m-%d", "2006-01-02"},
+		{"%H:%M:%S", "15:04:05"},
+		{"%d/2 %y", "02/2 06"},
+		{"%a %A %b %B", "Mon Monday Jan January"},
+		{"%I %p %e %j", "03 PM  2 002"},
+		{"%F %T %Z", "2006-01-02 15:04:05 UTC"},
+		{"%u %w %C %%", "1 1 20 %"},
+		{"%q", "%q"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buf := make([]byte, 64)
+			n := Strftime(buf, 64, []byte(tt.format+"\x00"), newTestTm())
+
+			if got := NullTerminatedByteSlice(buf); got != tt.want || int(n) != len(tt.want) {
+				t.Errorf("Strftime() = %q (%d), want %q", got, n, tt.want)
+			}
+		})
+	}
+
+	// The result does not fit.
+	if n := Strftime(make([]byte, 4), 4, []byte("%Y-%m-%d\x00"), newTestTm()); n != 0 {
+		t.Errorf("expected 0, got %d", n)
+	}
+}
+
+func TestMktime(t *testing.T) {
+	tm := newTestTm()
+	tm.Tm_mday = 32
+	tm.Tm_wday = 0
+	tm.Tm_yday = 0
+
+	timer := Mktime(tm)
+	if tm.Tm_mon != 1 || tm.Tm_mday != 1 || tm.Tm_wday != 3 || tm.Tm_yday != 31 {
+		t.Errorf("expected a normalized time, got %+v", tm)
+	}
+
+	local := Localtime(&timer)
+	if local.Tm_year != 106 || local.Tm_mon != 1 || local.Tm_mday != 1 ||
+		local.Tm_hour != 15 || local.Tm_min != 4 || local.Tm_sec != 5 {
+		t.Errorf("expected the same time, got %+v", local)
+	}
+
+	if Mktime(local) != timer {
+		t.Errorf("expected %d, got %d", timer, Mktime(local))
+	}
+}
+
+func TestGmtime(t *testing.T) {
+	timer := int32(86400 + 3661)
+	tm := Gmtime(&timer)
+	if tm.Tm_year != 70 || tm.Tm_mday != 2 || tm.Tm_hour != 1 ||
+		tm.Tm_min != 1 || tm.Tm_sec != 1 || tm.Tm_wday != 5 {
+		t.Errorf("unexpected time: %+v", tm)
+	}
+}
+
+func TestStrptime(t *testing.T) {
+	tm := &Tm{}
+	rest := Strptime([]byte("2024-03-09 07:30:15 rest\x00"), []byte("%Y-%m-%d %T\x00"), tm)
+
+	if NullTerminatedByteSlice(rest) != " rest" {
+		t.Errorf("unexpected rest: %q", rest)
+	}
+
+	if tm.Tm_year != 124 || tm.Tm_mon != 2 || tm.Tm_mday != 9 ||
+		tm.Tm_hour != 7 || tm.Tm_min != 30 || tm.Tm_sec != 15 {
+		t.Errorf("unexpected time: %+v", tm)
+	}
+
+	tm = &Tm{}
+	Strptime([]byte("Mar 9 11 pm\x00"), []byte("%b %d %I %p\x00"), tm)
+	if tm.Tm_mon != 2 || tm.Tm_mday != 9 || tm.Tm_hour != 23 {
+		t.Errorf("unexpected time: %+v", tm)
+	}
+
+	if Strptime([]byte("2024/03\x00"), []byte("%Y-%m\x00"), tm) != nil {
+		t.Errorf("expected NULL for a time that does not match")
+	}
+}
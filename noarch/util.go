@@ -1,5 +1,10 @@
 package noarch
 
+import (
+	"reflect"
+	"unsafe"
+)
+
 // NullTerminatedByteSlice returns a string that contains all the bytes in the
 // provided C string up until the first NULL character.
 func NullTerminatedByteSlice(s []byte) string {
@@ -35,3 +40,111 @@ func CStringIsNull(s []byte) bool {
 
 	return s[0] == 0
 }
+
+// PointerDiff handles the subtraction of two pointers, like "p - q". The
+// pointers are slices of the same array and the result is the number of
+// elements (not bytes) from the first element of q to the first element of p.
+//
+// Go does not move the start of a slice that has no capacity, so a pointer to
+// the end of an array cannot be subtracted.
+func PointerDiff(p, q interface{}) int {
+	a := reflect.ValueOf(p)
+	b := reflect.ValueOf(q)
+
+	size := int(a.Type().Elem().Size())
+	if size == 0 {
+		return 0
+	}
+
+	return (int(a.Pointer()) - int(b.Pointer())) / size
+}
+
+// UnsafePointer returns the address of the first byte of the memory, or nil if
+// there is no memory. It is used to cast a void * to a pointer to a struct that
+// does not contain any pointers or slices:
+//
+//     (struct Foo *)mem    ->    (*Foo)(noarch.UnsafePointer(&mem, unsafe.Sizeof(Foo{})))
+//
+// The struct is stored in the same memory as the bytes, so a change to one is
+// seen by the other. The garbage collector does not look for pointers in the
+// bytes, which is why the struct must not contain any.
+//
+// The size of the struct in Go is not always the same as C, an int is 8 bytes
+// rather than 4. If the memory is too small for the struct then *b is replaced
+// with a copy that has a capacity of exactly size bytes. The length is not
+// changed. Any other slice of the old memory will not see the changes made
+// through the struct.
+func UnsafePointer(b *[]byte, size uintptr) unsafe.Pointer {
+	if len(*b) == 0 {
+		return nil
+	}
+
+	if uintptr(cap(*b)) < size {
+		m := make([]byte, len(*b), size)
+		copy(m, *b)
+		*b = m
+	}
+
+	return unsafe.Pointer(&(*b)[0])
+}
+
+// UnsafeSlice returns a slice with the type of to that is in the same memory as
+// the slice from. It is used to cast a pointer to a pointer of numbers of
+// another size:
+//
+//     (uint32_t *)bytes    ->    noarch.UnsafeSlice(bytes, []uint32(nil)).([]uint32)
+//
+// A change to one slice is seen by the other, like C. The numbers are read from
+// the memory in the byte order of the host, so the value of each element
+// depends on the endianness (x86-64 and ARM are little-endian). Any bytes at
+// the end that are not a whole element are not in the new slice.
+func UnsafeSlice(from, to interface{}) interface{} {
+	src := reflect.ValueOf(from)
+	t := reflect.TypeOf(to)
+
+	size := src.Type().Elem().Size()
+	elementSize := t.Elem().Size()
+
+	n := int(uintptr(src.Cap()) * size / elementSize)
+	if n == 0 {
+		return reflect.Zero(t).Interface()
+	}
+
+	array := reflect.NewAt(reflect.ArrayOf(n, t.Elem()), unsafe.Pointer(src.Pointer()))
+	length := int(uintptr(src.Len()) * size / elementSize)
+
+	return array.Elem().Slice3(0, length, n).Convert(t).Interface()
+}
+
+// The addresses that have been returned by LabelAddress, by the number of the
+// label. Each address is a byte of its own so that LabelIndex can tell them
+// apart from any other pointer.
+var labelAddresses [][]byte
+
+// LabelAddress returns the address of a label, like "&&next" in C. A label
+// does not have an address in Go, so the address is the number of the label in
+// its function (counting from 1) that a computed goto jumps on (see
+// LabelIndex). The same number always has the same address.
+func LabelAddress(index int) []byte {
+	for len(labelAddresses) <= index {
+		labelAddresses = append(labelAddresses, make([]byte, 1))
+	}
+
+	return labelAddresses[index]
+}
+
+// LabelIndex returns the number of the label of an address that was returned by
+// LabelAddress. It returns 0 for any other pointer, including NULL.
+func LabelIndex(address []byte) int {
+	if len(address) == 0 {
+		return 0
+	}
+
+	for i, a := range labelAddresses {
+		if &a[0] == &address[0] {
+			return i
+		}
+	}
+
+	return 0
+}
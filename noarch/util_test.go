@@ -3,6 +3,7 @@ package noarch
 import (
 	"reflect"
 	"testing"
+	"unsafe"
 )
 
 func TestNullTerminatedByteSlice(t *testing.T) {
@@ -28,3 +29,91 @@ func TestNullTerminatedByteSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestPointerDiff(t *testing.T) {
+	ints := []int32{1, 2, 3, 4, 5}
+	if d := PointerDiff(ints[4:], ints[1:]); d != 3 {
+		t.Errorf("expected 3, got %d", d)
+	}
+
+	if d := PointerDiff(ints[1:], ints[3:]); d != -2 {
+		t.Errorf("expected -2, got %d", d)
+	}
+
+	s := []byte("hello")
+	if d := PointerDiff(s[2:], s); d != 2 {
+		t.Errorf("expected 2, got %d", d)
+	}
+}
+
+func TestUnsafePointer(t *testing.T) {
+	type point struct {
+		x, y int32
+	}
+
+	mem := make([]byte, 8)
+	p := (*point)(UnsafePointer(&mem, unsafe.Sizeof(point{})))
+	p.y = -1
+	if mem[0] != 0 || mem[4] != 0xff || mem[7] != 0xff {
+		t.Errorf("expected the struct to be stored in the memory, got %v", mem)
+	}
+
+	var null []byte
+	if UnsafePointer(&null, 8) != nil {
+		t.Errorf("expected a NULL pointer to be nil")
+	}
+
+	small := []byte{1, 2, 3, 4}
+	p = (*point)(UnsafePointer(&small, unsafe.Sizeof(point{})))
+	if len(small) != 4 || cap(small) != 8 {
+		t.Errorf("expected the memory to grow to 8 bytes, got %d of %d", len(small), cap(small))
+	}
+
+	p.y = 5
+	if p.x != 0x04030201 || small[:8][4] != 5 {
+		t.Errorf("expected the struct to be stored in the new memory, got %v", small[:8])
+	}
+}
+
+func TestUnsafeSlice(t *testing.T) {
+	mem := []byte{1, 0, 0, 0, 2, 0, 0, 0, 3}
+	words := UnsafeSlice(mem, []uint32(nil)).([]uint32)
+	if len(words) != 2 || words[0] != 1 || words[1] != 2 {
+		t.Errorf("expected the bytes to be read as []uint32{1, 2}, got %v", words)
+	}
+
+	words[1] = 0x01020304
+	if mem[4] != 4 || mem[7] != 1 {
+		t.Errorf("expected the words to be stored in the memory, got %v", mem)
+	}
+
+	bytes := UnsafeSlice(words, []byte(nil)).([]byte)
+	if len(bytes) != 8 || bytes[0] != 1 {
+		t.Errorf("expected 8 bytes, got %v", bytes)
+	}
+
+	if UnsafeSlice([]byte(nil), []uint32(nil)).([]uint32) != nil {
+		t.Errorf("expected a NULL pointer to be nil")
+	}
+}
+
+func TestLabelAddress(t *testing.T) {
+	for _, index := range []int{1, 2, 300} {
+		if i := LabelIndex(LabelAddress(index)); i != index {
+			t.Errorf("expected %d, got %d", index, i)
+		}
+	}
+
+	if i := LabelIndex(nil); i != 0 {
+		t.Errorf("expected 0 for NULL, got %d", i)
+	}
+
+	if i := LabelIndex([]byte("abc")); i != 0 {
+		t.Errorf("expected 0 for a string, got %d", i)
+	}
+
+	// A pointer to 8 bytes is not a label, even if the bytes are a number.
+	if i := LabelIndex([]byte{1, 0, 0, 0, 0, 0, 0, 0}); i != 0 {
+		t.Errorf("expected 0 for a pointer that is not a label, got %d", i)
+	}
+}
@@ -0,0 +1,97 @@
+package noarch
+
+import (
+	"reflect"
+)
+
+// VaList represents a C va_list. It holds the variadic arguments of a function
+// and the position of the next argument that will be read with va_arg().
+type VaList struct {
+	args     []interface{}
+	position int
+}
+
+// VaStart handles va_start().
+//
+// A va_list is always a pointer to a VaList so that it can be passed to other
+// functions and they will read from the same position, like C does. Starting
+// a va_list creates a new VaList so that any previous copies are unaffected.
+func VaStart(ap **VaList, args []interface{}) {
+	*ap = &VaList{
+		args: args,
+	}
+}
+
+// VaCopy handles va_copy().
+//
+// The destination will read the same arguments from the same position as the
+// source, but each can be read independently.
+func VaCopy(destination **VaList, source *VaList) {
+	c := *source
+	*destination = &c
+}
+
+// VaEnd handles va_end().
+func VaEnd(ap **VaList) {
+	*ap = nil
+}
+
+// Arg returns the next argument. This is used by va_arg() for types that are
+// not numbers, so the value must be asserted to its real type.
+func (ap *VaList) Arg() interface{} {
+	if ap.position >= len(ap.args) {
+		panic("va_arg: there are no more arguments")
+	}
+
+	arg := ap.args[ap.position]
+	ap.position++
+
+	return arg
+}
+
+// Int64 returns the next argument as an integer. The argument can be any
+// number type since C promotes all integer arguments to at least an int, but
+// in Go they keep the type of what was passed in.
+func (ap *VaList) Int64() int64 {
+	v := reflect.ValueOf(ap.Arg())
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return int64(v.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		return int64(v.Float())
+
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	}
+
+	panic("va_arg: argument is not an integer: " + v.Type().String())
+}
+
+// Float64 returns the next argument as a floating-point number. Like Int64, the
+// argument can be any number type.
+func (ap *VaList) Float64() float64 {
+	v := reflect.ValueOf(ap.Arg())
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+
+	panic("va_arg: argument is not a number: " + v.Type().String())
+}
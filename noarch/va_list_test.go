@@ -0,0 +1,57 @@
+package noarch
+
+import (
+	"testing"
+)
+
+func TestVaList(t *testing.T) {
+	var ap *VaList
+	VaStart(&ap, []interface{}{1, uint8(2), 3.5, []byte("abc")})
+
+	if v := ap.Int64(); v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+	if v := ap.Int64(); v != 2 {
+		t.Errorf("expected 2, got %d", v)
+	}
+	if v := ap.Float64(); v != 3.5 {
+		t.Errorf("expected 3.5, got %f", v)
+	}
+	if v := ap.Arg().([]byte); string(v) != "abc" {
+		t.Errorf("expected abc, got %s", v)
+	}
+
+	VaEnd(&ap)
+	if ap != nil {
+		t.Errorf("expected va_end to clear the va_list")
+	}
+}
+
+func TestVaCopy(t *testing.T) {
+	var ap, ap2 *VaList
+	VaStart(&ap, []interface{}{1, 2, 3})
+	ap.Int64()
+
+	VaCopy(&ap2, ap)
+	if v := ap.Int64(); v != 2 {
+		t.Errorf("expected 2, got %d", v)
+	}
+	if v := ap2.Int64(); v != 2 {
+		t.Errorf("expected the copy to read 2, got %d", v)
+	}
+	if v := ap2.Int64(); v != 3 {
+		t.Errorf("expected the copy to read 3, got %d", v)
+	}
+}
+
+func TestVaArgPastTheEnd(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+
+	var ap *VaList
+	VaStart(&ap, []interface{}{})
+	ap.Int64()
+}
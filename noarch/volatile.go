@@ -0,0 +1,27 @@
+package noarch
+
+import (
+	"strconv"
+	"sync/atomic"
+	"unsafe"
+)
+
+// LoadInt atomically reads an int. It is used for reading a "volatile int"
+// since the "sync/atomic" package only works on integers with a fixed size.
+func LoadInt(addr *int) int {
+	if strconv.IntSize == 32 {
+		return int(atomic.LoadInt32((*int32)(unsafe.Pointer(addr))))
+	}
+
+	return int(atomic.LoadInt64((*int64)(unsafe.Pointer(addr))))
+}
+
+// StoreInt atomically writes an int. See LoadInt.
+func StoreInt(addr *int, val int) {
+	if strconv.IntSize == 32 {
+		atomic.StoreInt32((*int32)(unsafe.Pointer(addr)), int32(val))
+		return
+	}
+
+	atomic.StoreInt64((*int64)(unsafe.Pointer(addr)), int64(val))
+}
@@ -0,0 +1,16 @@
+package noarch
+
+import "testing"
+
+func TestLoadAndStoreInt(t *testing.T) {
+	x := 5
+	StoreInt(&x, -123)
+
+	if x != -123 {
+		t.Errorf("Expected StoreInt to set -123, got %d", x)
+	}
+
+	if v := LoadInt(&x); v != -123 {
+		t.Errorf("Expected LoadInt to return -123, got %d", v)
+	}
+}
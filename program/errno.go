@@ -0,0 +1,57 @@
+package program
+
+import (
+	goast "go/ast"
+	"sort"
+)
+
+// ErrnoFunction is a function that sets errno and is called by the program.
+type ErrnoFunction struct {
+	// The name of the C function.
+	Name string
+
+	// The Go function that is called, like "noarch.Strtol". It is the same as
+	// the name if the function is not replaced by a Go function.
+	GoFunction string
+
+	// The Go type of the function.
+	Signature *goast.FuncType
+
+	// The name of the Go wrapper, like "strtolWithError".
+	Wrapper string
+}
+
+// AddErrnoFunction records a call to one of the ErrnoFunctions. The signature
+// of the first call is kept if the same function is added more than once.
+func (p *Program) AddErrnoFunction(f ErrnoFunction) {
+	if _, ok := p.errnoFunctions[f.Name]; ok {
+		return
+	}
+
+	p.errnoFunctions[f.Name] = f
+}
+
+// GetErrnoFunction returns the function that was added with AddErrnoFunction
+// by its C name.
+func (p *Program) GetErrnoFunction(name string) (ErrnoFunction, bool) {
+	f, ok := p.errnoFunctions[name]
+	return f, ok
+}
+
+// GetErrnoFunctions returns the functions that were added with
+// AddErrnoFunction in alphabetical order.
+func (p *Program) GetErrnoFunctions() []ErrnoFunction {
+	names := []string{}
+	for name := range p.errnoFunctions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	functions := []ErrnoFunction{}
+	for _, name := range names {
+		functions = append(functions, p.errnoFunctions[name])
+	}
+
+	return functions
+}
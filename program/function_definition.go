@@ -61,7 +61,6 @@ var builtInFunctionDefinitionsHaveBeenLoaded = false
 //
 var builtInFunctionDefinitions = []string{
 	// darwin/assert.h
-	"int __builtin_expect(int, int) -> darwin.BuiltinExpect",
 	"bool __assert_rtn(const char*, const char*, int, const char*) -> darwin.AssertRtn",
 
 	// darwin/ctype.h
@@ -114,6 +113,12 @@ var builtInFunctionDefinitions = []string{
 	"int _IO_getc(FILE*) -> noarch.Fgetc",
 	"int _IO_putc(int, FILE*) -> noarch.Fputc",
 
+	// darwin/errno.h
+	"int* __error() -> noarch.ErrnoLocation",
+
+	// linux/errno.h
+	"int* __errno_location() -> noarch.ErrnoLocation",
+
 	// math.h
 	"double acos(double) -> math.Acos",
 	"double asin(double) -> math.Asin",
@@ -130,11 +135,52 @@ var builtInFunctionDefinitions = []string{
 	"double log(double) -> math.Log",
 	"double log10(double) -> math.Log10",
 	"double pow(double, double) -> math.Pow",
+	"double round(double) -> math.Round",
 	"double sin(double) -> math.Sin",
 	"double sinh(double) -> math.Sinh",
 	"double sqrt(double) -> math.Sqrt",
 	"double tan(double) -> math.Tan",
 	"double tanh(double) -> math.Tanh",
+	"double trunc(double) -> math.Trunc",
+	// A long double is a float64 (see types.ResolveType).
+	"long double acosl(long double) -> math.Acos",
+	"long double asinl(long double) -> math.Asin",
+	"long double atanl(long double) -> math.Atan",
+	"long double atan2l(long double, long double) -> math.Atan2",
+	"long double ceill(long double) -> math.Ceil",
+	"long double cosl(long double) -> math.Cos",
+	"long double coshl(long double) -> math.Cosh",
+	"long double expl(long double) -> math.Exp",
+	"long double fabsl(long double) -> math.Abs",
+	"long double floorl(long double) -> math.Floor",
+	"long double fmodl(long double, long double) -> math.Mod",
+	"long double ldexpl(long double, int) -> math.Ldexp",
+	"long double logl(long double) -> math.Log",
+	"long double log10l(long double) -> math.Log10",
+	"long double powl(long double, long double) -> math.Pow",
+	"long double roundl(long double) -> math.Round",
+	"long double sinl(long double) -> math.Sin",
+	"long double sinhl(long double) -> math.Sinh",
+	"long double sqrtl(long double) -> math.Sqrt",
+	"long double tanl(long double) -> math.Tan",
+	"long double tanhl(long double) -> math.Tanh",
+	"long double truncl(long double) -> math.Trunc",
+	"float ceilf(float) -> noarch.Ceilf",
+	"float cosf(float) -> noarch.Cosf",
+	"float fabsf(float) -> noarch.Fabsf",
+	"float floorf(float) -> noarch.Floorf",
+	"float powf(float, float) -> noarch.Powf",
+	"float roundf(float) -> noarch.Roundf",
+	"float sinf(float) -> noarch.Sinf",
+	"float sqrtf(float) -> noarch.Sqrtf",
+
+	// complex.h
+	"double creal(_Complex double) -> real",
+	"float crealf(_Complex float) -> real",
+	"double cimag(_Complex double) -> imag",
+	"float cimagf(_Complex float) -> imag",
+	"double cabs(_Complex double) -> math/cmplx.Abs",
+	"_Complex double conj(_Complex double) -> math/cmplx.Conj",
 
 	// stdio.h
 	"int printf(const char*) -> noarch.Printf",
@@ -153,7 +199,10 @@ var builtInFunctionDefinitions = []string{
 	"char* tmpnam(char*) -> noarch.Tmpnam",
 	"int fflush(FILE*) -> noarch.Fflush",
 	"int fprintf(FILE*, const char*) -> noarch.Fprintf",
+	"int snprintf(char*, int, const char*) -> noarch.Snprintf",
+	"int vsnprintf(char*, int, const char*, va_list) -> noarch.Vsnprintf",
 	"int fscanf(FILE*, const char*) -> noarch.Fscanf",
+	"int sscanf(const char*, const char*) -> noarch.Sscanf",
 	"int fgetc(FILE*) -> noarch.Fgetc",
 	"int fputc(int, FILE*) -> noarch.Fputc",
 	"int getc(FILE*) -> noarch.Fgetc",
@@ -161,22 +210,73 @@ var builtInFunctionDefinitions = []string{
 	"int putc(int, FILE*) -> noarch.Fputc",
 	"int fseek(FILE*, long int, int) -> noarch.Fseek",
 	"long ftell(FILE*) -> noarch.Ftell",
-	"int fread(void*, int, int, FILE*) -> $0 = noarch.Fread(&1, $2, $3, $4)",
-	"int fwrite(char*, int, int, FILE*) -> noarch.Fwrite",
+	"int fread(void*, int, int, FILE*) -> noarch.Fread",
+	"int fwrite(const void*, int, int, FILE*) -> noarch.Fwrite",
 	"int fgetpos(FILE*, int*) -> noarch.Fgetpos",
 	"int fsetpos(FILE*, int*) -> noarch.Fsetpos",
 
 	// string.h
-	"size_t strlen(const char*) -> noarch.Strlen",
+	"int strlen(const char*) -> noarch.Strlen",
+	"void* memmove(void*, const void*, int) -> noarch.Memmove",
+	"void* memset(void*, int, int) -> noarch.Memset",
+	"char* strtok(char*, const char*) -> noarch.Strtok",
+	"char* strtok_r(char*, const char*, char**) -> noarch.Strtok_r",
+	"char* strncpy(char*, const char*, int) -> noarch.Strncpy",
+	"char* strncat(char*, const char*, int) -> noarch.Strncat",
+	"int strncmp(const char*, const char*, int) -> noarch.Strncmp",
+	"char* strchr(const char*, int) -> noarch.Strchr",
+	"char* strrchr(const char*, int) -> noarch.Strrchr",
+	"char* strstr(const char*, const char*) -> noarch.Strstr",
 
 	// stdlib.h
+	"int abs(int) -> noarch.Abs",
+	"long labs(long) -> noarch.Labs",
+	"long long llabs(long long) -> noarch.Llabs",
 	"int atoi(const char*) -> noarch.Atoi",
-	"long strtol(const char *, char **, int) -> noarch.Strtol",
+	"long long strtol(const char *, char **, int) -> noarch.Strtol",
+	"unsigned long long strtoul(const char *, char **, int) -> noarch.Strtoul",
+	"void* malloc(int) -> noarch.Malloc",
+	"void* calloc(int, int) -> noarch.Calloc",
+	"void* realloc(void*, int) -> noarch.Realloc",
 	"void free(void*) -> noarch.Free",
+	"void qsort(void*, int, int, int (*)(const void*, const void*)) -> noarch.Qsort",
+	"void* bsearch(const void*, const void*, int, int, int (*)(const void*, const void*)) -> noarch.Bsearch",
+	"char* getenv(const char*) -> noarch.Getenv",
+	"int setenv(const char*, const char*, int) -> noarch.Setenv",
+	"int unsetenv(const char*) -> noarch.Unsetenv",
+	"int putenv(char*) -> noarch.Putenv",
+	"int atexit(void (*)(void)) -> noarch.Atexit",
+	"int rand() -> noarch.Rand",
+	"void srand(unsigned int) -> noarch.Srand",
+	"void exit(int) -> noarch.Exit",
+
+	// time.h
+	"time_t time(time_t*) -> noarch.Time",
+	"double difftime(time_t, time_t) -> noarch.Difftime",
+	"time_t mktime(struct tm*) -> noarch.Mktime",
+	"struct tm* localtime(const time_t*) -> noarch.Localtime",
+	"struct tm* gmtime(const time_t*) -> noarch.Gmtime",
+	"size_t strftime(char*, size_t, const char*, const struct tm*) -> noarch.Strftime",
+	"char* strptime(const char*, const char*, struct tm*) -> noarch.Strptime",
 
 	// I'm not sure which header file these comes from?
 	"uint32 __builtin_bswap32(uint32) -> darwin.BSwap32",
 	"uint64 __builtin_bswap64(uint64) -> darwin.BSwap64",
+
+	// The bit counting builtins. The result of clz and ctz is undefined in C
+	// when the value is 0, but in Go it is the number of bits, like 32 for
+	// LeadingZeros32(0). A long is 32 bits in Go (see types.ResolveType), but
+	// the "l" builtins count the 64 bits of a long on 64-bit platforms, so
+	// __builtin_clzl(1) is 63 like C. The argument is converted to 64 bits.
+	"int __builtin_clz(unsigned int) -> math/bits.LeadingZeros32",
+	"int __builtin_clzl(unsigned long long) -> math/bits.LeadingZeros64",
+	"int __builtin_clzll(unsigned long long) -> math/bits.LeadingZeros64",
+	"int __builtin_ctz(unsigned int) -> math/bits.TrailingZeros32",
+	"int __builtin_ctzl(unsigned long long) -> math/bits.TrailingZeros64",
+	"int __builtin_ctzll(unsigned long long) -> math/bits.TrailingZeros64",
+	"int __builtin_popcount(unsigned int) -> math/bits.OnesCount32",
+	"int __builtin_popcountl(unsigned long long) -> math/bits.OnesCount64",
+	"int __builtin_popcountll(unsigned long long) -> math/bits.OnesCount64",
 }
 
 // GetFunctionDefinition will return nil if the function does not exist (is not
@@ -191,6 +291,36 @@ func GetFunctionDefinition(functionName string) *FunctionDefinition {
 	return nil
 }
 
+var functionPointerRegexp = regexp.MustCompile(`^(.+?) ?\(\*\)\((.*)\)$`)
+
+// GetFunctionPointerDefinition returns the definition of a function that is
+// called through a function pointer of the C type, like "int (*)(char *)". It
+// returns nil if the type is not a function pointer.
+func GetFunctionPointerDefinition(name, cType string) *FunctionDefinition {
+	match := functionPointerRegexp.FindStringSubmatch(cType)
+	if match == nil {
+		return nil
+	}
+
+	argumentTypes := []string{}
+	for _, t := range splitArgumentTypes(match[2]) {
+		// The varargs are not in the argument types.
+		if t == "..." {
+			break
+		}
+
+		if t != "void" {
+			argumentTypes = append(argumentTypes, t)
+		}
+	}
+
+	return &FunctionDefinition{
+		Name:          name,
+		ReturnType:    match[1],
+		ArgumentTypes: argumentTypes,
+	}
+}
+
 // AddFunctionDefinition registers a function definition. If the definition
 // already exists it will be replaced.
 func AddFunctionDefinition(f FunctionDefinition) {
@@ -224,6 +354,35 @@ func dollarArgumentsToIntSlice(s string) []int {
 	return r
 }
 
+// splitArgumentTypes splits the comma separated argument types. The commas
+// inside a function pointer type, like "int (*)(const void*, const void*)", are
+// not split.
+func splitArgumentTypes(s string) []string {
+	argumentTypes := []string{}
+	depth := 0
+	start := 0
+
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				argumentTypes = append(argumentTypes, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		argumentTypes = append(argumentTypes, last)
+	}
+
+	return argumentTypes
+}
+
 func loadFunctionDefinitions() {
 	if builtInFunctionDefinitionsHaveBeenLoaded {
 		return
@@ -233,17 +392,11 @@ func loadFunctionDefinitions() {
 	builtInFunctionDefinitionsHaveBeenLoaded = true
 
 	for _, f := range builtInFunctionDefinitions {
-		match := regexp.MustCompile(`^(.+) ([^ ]+)\(([, a-z*A-Z_0-9]*)\)( -> .+)?$`).
+		match := regexp.MustCompile(`^(.+?) ([^ (]+)\(([, a-z*A-Z_0-9()]*)\)( -> .+)?$`).
 			FindStringSubmatch(f)
 
 		// Unpack argument types.
-		argumentTypes := strings.Split(match[3], ",")
-		for i := range argumentTypes {
-			argumentTypes[i] = strings.TrimSpace(argumentTypes[i])
-		}
-		if len(argumentTypes) == 1 && argumentTypes[0] == "" {
-			argumentTypes = []string{}
-		}
+		argumentTypes := splitArgumentTypes(match[3])
 
 		// Defaults for transformations.
 		var returnParameters, parameters []int
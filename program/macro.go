@@ -0,0 +1,25 @@
+package program
+
+// Macro is an object-like macro of a C file, like:
+//
+//     #define SIZE (4 * 1024)
+//
+// The Value is the C code of the macro, "(4 * 1024)". A macro that is a
+// constant is declared as a Go constant when the Macros option is on.
+type Macro struct {
+	Name  string
+	Value string
+}
+
+// DeclareMacro registers the Go constant of the macro with the name. It returns
+// false if another file of the package has already declared it, because a
+// macro of a header is defined in every file that includes the header.
+func (p *Program) DeclareMacro(name string) bool {
+	if p.declaredMacros[name] {
+		return false
+	}
+
+	p.declaredMacros[name] = true
+
+	return true
+}
@@ -0,0 +1,59 @@
+package program
+
+// RegisterTypeMapping makes the transpiler use an existing Go type for a C
+// type, rather than translating the C type. This allows hand written Go code to
+// replace parts of the generated code. For example:
+//
+//     p.RegisterTypeMapping("buffer_t", "github.com/me/mylib.Buffer")
+//
+// The Go type may be fully qualified (like above) and its package will be
+// imported when the type is used. Any typedef or struct with the same name as
+// the C type is not transpiled.
+func (p *Program) RegisterTypeMapping(cType, goType string) {
+	p.typeMappings[cType] = goType
+}
+
+// GetTypeMapping returns the Go type that was registered for a C type with
+// RegisterTypeMapping.
+func (p *Program) GetTypeMapping(cType string) (string, bool) {
+	goType, ok := p.typeMappings[cType]
+	return goType, ok
+}
+
+// RegisterFunctionMapping makes all calls to a C function call a Go function
+// instead. The Go function may be fully qualified in the same way as
+// RegisterTypeMapping. The arguments are still cast to the types of the C
+// function and the C definition of the function is not transpiled.
+func (p *Program) RegisterFunctionMapping(cFunction, goFunction string) {
+	p.functionMappings[cFunction] = goFunction
+}
+
+// GetFunctionMapping returns the Go function that was registered for a C
+// function with RegisterFunctionMapping.
+func (p *Program) GetFunctionMapping(cFunction string) (string, bool) {
+	goFunction, ok := p.functionMappings[cFunction]
+	return goFunction, ok
+}
+
+// RegisterCastFunction sets the Go function that is used to cast a value from
+// one Go type to another, like:
+//
+//     p.RegisterCastFunction("mylib.Buffer", "[]byte",
+//         "github.com/me/mylib.BufferToBytes")
+//
+// The types are the Go types that are used in the generated code. Without a
+// cast function a noarch function is used, like "noarch.BufferToBytes".
+func (p *Program) RegisterCastFunction(fromType, toType, function string) {
+	p.castFunctions[castFunctionKey{fromType, toType}] = function
+}
+
+// GetCastFunction returns the Go function that was registered with
+// RegisterCastFunction to cast between two Go types.
+func (p *Program) GetCastFunction(fromType, toType string) (string, bool) {
+	function, ok := p.castFunctions[castFunctionKey{fromType, toType}]
+	return function, ok
+}
+
+type castFunctionKey struct {
+	fromType, toType string
+}
@@ -48,6 +48,21 @@ type Program struct {
 	// platforms.
 	startupStatements []goast.Stmt
 
+	// The functions with the constructor or destructor attribute in the current
+	// file. See AddConstructor() and AddDestructor().
+	constructors []Constructor
+	destructors  []Constructor
+
+	// If HasDestructors is on there is a function with the destructor
+	// attribute or a call to atexit() somewhere in the package. main() exits
+	// with noarch.Exit() so that the destructors are called.
+	HasDestructors bool
+
+	// If MemoryTags is on there is a call to fread() or fwrite() somewhere in
+	// the package. The struct fields that are pointers in C have a tag so that
+	// they have the same size as in C (see noarch.Fwrite).
+	MemoryTags bool
+
 	// This is used to generate globally unique names for temporary variables
 	// and other generated code. See GetNextIdentifier().
 	nextUniqueIdentifier int
@@ -57,10 +72,55 @@ type Program struct {
 	Structs StructRegistry
 	Unions  StructRegistry
 
+	// The Go names of the enum types that have been defined, including
+	// typedefs of enums. C enums are just integers so these types can be
+	// freely converted to and from other integer types.
+	Enums map[string]bool
+
 	// If verbose is on progress messages will be printed immediately as code
 	// comments (so that they do not intefere with the program output).
 	Verbose bool
 
+	// If StructTags is on each struct field will be exported and have a json
+	// tag with the original C name of the field, like `json:"name"`.
+	StructTags bool
+
+	// If GoStrings is on the C string parameters that are only ever read are
+	// translated into Go strings instead of byte slices.
+	GoStrings bool
+
+	// If Stubs is on a function that is called but never defined, like a
+	// function from a library that has not been translated, is generated with
+	// a body that panics. This allows the output to compile. See
+	// AddUnresolvedFunction().
+	Stubs bool
+
+	// If VolatileAtomic is on the reads and writes of a "volatile" integer use
+	// "sync/atomic" so that the Go compiler cannot remove or reorder them.
+	VolatileAtomic bool
+
+	// If SignedChar is on a plain "char" is signed, like on x86, and is
+	// translated into an int8. Otherwise it is unsigned, like on ARM, and is a
+	// byte. A "signed char" and an "unsigned char" are not affected.
+	SignedChar bool
+
+	// If ReportUnsupported is on a node that cannot be transpiled is added to
+	// UnsupportedNodes instead of stopping the transpiler. See
+	// AddUnsupportedNode().
+	ReportUnsupported bool
+	UnsupportedNodes  []UnsupportedNode
+
+	// The C functions that set errno. A Go wrapper that returns the value of
+	// errno as an error is generated for each of them that is called. See
+	// AddErrnoFunction().
+	ErrnoFunctions map[string]bool
+
+	// If GoInit is on the startup statements of each file are in a Go init()
+	// function. Otherwise they are in an __init() function that is called at
+	// the start of main(). A package can only have one __init() so GoInit is
+	// used when the package has more than one file.
+	GoInit bool
+
 	// Contains the messages (for example, "// Warning") generated when
 	// transpiling the AST. These messages, which are code comments, are
 	// appended to the very top of the output file. See AddMessage().
@@ -69,6 +129,71 @@ type Program struct {
 	// A map of all the global variables (variables that exist outside of a
 	// function) and their types.
 	GlobalVariables map[string]string
+
+	// The Go types and functions that are used instead of translating the C
+	// ones. See RegisterTypeMapping(), RegisterFunctionMapping() and
+	// RegisterCastFunction().
+	typeMappings     map[string]string
+	functionMappings map[string]string
+	castFunctions    map[castFunctionKey]string
+
+	// The functions that are called without a Go implementation and their Go
+	// types. See AddUnresolvedFunction().
+	unresolvedFunctions map[string]*goast.FuncType
+
+	// The functions in the package that have a body, including the stubs for
+	// unresolved functions. See DefineFunction().
+	definedFunctions map[string]bool
+
+	// The package-level Go names of the static local variables by the address
+	// of their VarDecl. See AddStaticVariable().
+	staticVariables map[string]string
+
+	// The global variables that are declared as Go constants. See
+	// AddConstant().
+	constants map[string]bool
+
+	// The addresses of the VarDecls of the variables that have their address
+	// taken, like "&MAX". See AddAddressTaken().
+	addressTaken map[string]bool
+
+	// The global variables in the package that are not only declared with
+	// extern, or that have already been declared in Go by one of the extern
+	// declarations. See DefineVariable().
+	definedVariables map[string]bool
+
+	// The C types of the variables and parameters by name in each of the
+	// scopes (blocks) that are being transpiled, starting with the global
+	// scope. See AddVariableType() and StartScope().
+	variableTypes []map[string]string
+
+	// The object-like macros of the file that is being transpiled. The ones
+	// that are constants are declared as Go constants. This is only used when
+	// the -macros option is on. See Macro.
+	Macros []Macro
+
+	// The Go constants of the macros that have been declared in the package.
+	// See DeclareMacro().
+	declaredMacros map[string]bool
+
+	// The nodes that have been added with AddUnsupportedNode().
+	unsupportedNodes map[ast.Node]bool
+
+	// The functions in ErrnoFunctions that have been called. See
+	// AddErrnoFunction().
+	errnoFunctions map[string]ErrnoFunction
+
+	// The Go names of the functions that have been defined in the package by
+	// their name and then their C type. See AddFunctionSignature().
+	functionSignatures map[string]map[string]string
+
+	// The Go names of the functions that are defined in the current file. See
+	// GetFunctionName().
+	functionNames map[string]string
+
+	// The functions of the current file that have already been defined by
+	// another file. See AddDuplicateFunction().
+	duplicateFunctions map[string]bool
 }
 
 // NewProgram creates a new blank program.
@@ -79,12 +204,44 @@ func NewProgram() *Program {
 		startupStatements:   []goast.Stmt{},
 		Structs:             make(StructRegistry),
 		Unions:              make(StructRegistry),
+		Enums:               map[string]bool{},
 		Verbose:             false,
 		messages:            []string{},
 		GlobalVariables:     map[string]string{},
+		typeMappings:        map[string]string{},
+		functionMappings:    map[string]string{},
+		castFunctions:       map[castFunctionKey]string{},
+		unresolvedFunctions: map[string]*goast.FuncType{},
+		definedFunctions:    map[string]bool{},
+		staticVariables:     map[string]string{},
+		constants:           map[string]bool{},
+		addressTaken:        map[string]bool{},
+		definedVariables:    map[string]bool{},
+		variableTypes:       []map[string]string{{}},
+		ErrnoFunctions:      map[string]bool{},
+		errnoFunctions:      map[string]ErrnoFunction{},
+		functionSignatures:  map[string]map[string]string{},
+		functionNames:       map[string]string{},
+		duplicateFunctions:  map[string]bool{},
+		declaredMacros:      map[string]bool{},
+		unsupportedNodes:    map[ast.Node]bool{},
 	}
 }
 
+// StartFile clears the state of the output file so that the next C file can be
+// transpiled into a new Go file in the same package. The imports, messages and
+// startup statements belong to a single file. Everything else, like the types
+// that have been defined, is shared by all of the files in the package.
+func (p *Program) StartFile() {
+	p.imports = []string{}
+	p.messages = []string{}
+	p.startupStatements = []goast.Stmt{}
+	p.constructors = nil
+	p.destructors = nil
+	p.functionNames = map[string]string{}
+	p.duplicateFunctions = map[string]bool{}
+}
+
 // AddMessage adds a message (such as a warning or error) comment to the output
 // file. Usually the message is generated from one of the Generate functions in
 // the ast package.
@@ -167,6 +324,141 @@ func (p *Program) GetNextIdentifier(prefix string) string {
 	return identifierName
 }
 
+// AddStaticVariable registers the Go name of a static local variable. The
+// variable is declared at the package level so the references to it (that
+// have the address of the VarDecl) must use the new name.
+func (p *Program) AddStaticVariable(address, name string) {
+	p.staticVariables[address] = name
+}
+
+// GetStaticVariable returns the Go name of the static local variable that was
+// declared at the address, or an empty string if there is no such variable.
+func (p *Program) GetStaticVariable(address string) string {
+	return p.staticVariables[address]
+}
+
+// AddConstant registers a global variable that is declared as a Go constant
+// instead of a variable.
+func (p *Program) AddConstant(name string) {
+	p.constants[name] = true
+}
+
+// IsConstant returns true if the global variable is a Go constant. A constant
+// can be used in the initializer of another constant.
+func (p *Program) IsConstant(name string) bool {
+	return p.constants[name]
+}
+
+// AddAddressTaken records that the address of a variable is taken somewhere in
+// the translation unit. The variable is identified by the address of its
+// VarDecl. A Go constant does not have an address, so the variable cannot be a
+// constant.
+func (p *Program) AddAddressTaken(address string) {
+	p.addressTaken[address] = true
+}
+
+// IsAddressTaken returns true if AddAddressTaken was called for the variable
+// that was declared at the address.
+func (p *Program) IsAddressTaken(address string) bool {
+	return p.addressTaken[address]
+}
+
+// DefineVariable records that a global variable is defined somewhere in the
+// package. A variable that is only declared with extern, like
+// "extern int shared;", is declared in Go by its definition instead.
+func (p *Program) DefineVariable(name string) {
+	p.definedVariables[name] = true
+}
+
+// IsVariableDefined returns true if DefineVariable was called for the global
+// variable.
+func (p *Program) IsVariableDefined(name string) bool {
+	return p.definedVariables[name]
+}
+
+// StartScope starts a block, like the body of a function or a compound
+// statement. The variables that are declared until the matching EndScope()
+// shadow the variables with the same names in the outer scopes:
+//
+//     int x = 1;
+//     {
+//         long x = 2;    // "typeof (x)" is "long"
+//     }
+//     ...                // "typeof (x)" is "int" again
+func (p *Program) StartScope() {
+	p.variableTypes = append(p.variableTypes, map[string]string{})
+}
+
+// EndScope ends the block that was started with the last StartScope(). The
+// variables that were declared in it are no longer visible.
+func (p *Program) EndScope() {
+	p.variableTypes = p.variableTypes[:len(p.variableTypes)-1]
+}
+
+// AddVariableType registers the C type of a variable or parameter when it is
+// declared in the current scope. It is used to resolve a GNU typeof of the
+// variable, like "typeof (x)". A later declaration with the same name in the
+// same scope replaces the type.
+func (p *Program) AddVariableType(name, cType string) {
+	p.variableTypes[len(p.variableTypes)-1][name] = cType
+}
+
+// GetVariableType returns the C type of the variable or parameter with the
+// name that is visible in the current scope.
+func (p *Program) GetVariableType(name string) (string, bool) {
+	for i := len(p.variableTypes) - 1; i >= 0; i-- {
+		if cType, ok := p.variableTypes[i][name]; ok {
+			return cType, true
+		}
+	}
+
+	return "", false
+}
+
+// AddFunctionSignature records that the function with the name and C type is
+// defined in the current file, with the Go name goName. A static or inline
+// function that is defined in a header is in each file that includes it, so
+// the signature is used to only emit it once.
+func (p *Program) AddFunctionSignature(name, cType, goName string) {
+	if p.functionSignatures[name] == nil {
+		p.functionSignatures[name] = map[string]string{}
+	}
+
+	p.functionSignatures[name][cType] = goName
+	p.functionNames[name] = goName
+}
+
+// GetFunctionSignatures returns the Go names of the functions with the name
+// that have been defined in the package, by their C types.
+func (p *Program) GetFunctionSignatures(name string) map[string]string {
+	return p.functionSignatures[name]
+}
+
+// AddDuplicateFunction records that the function with the name has already
+// been defined by another file with the Go name goName. It is not emitted
+// again, but the references to it still use its Go name.
+func (p *Program) AddDuplicateFunction(name, goName string) {
+	p.functionNames[name] = goName
+	p.duplicateFunctions[name] = true
+}
+
+// IsDuplicateFunction returns true if the function with the name is defined in
+// the current file and has already been defined by another file.
+func (p *Program) IsDuplicateFunction(name string) bool {
+	return p.duplicateFunctions[name]
+}
+
+// GetFunctionName returns the Go name of a function. It is only different from
+// the C name for a static function that is renamed because another file has a
+// different function with the same name.
+func (p *Program) GetFunctionName(name string) string {
+	if goName, ok := p.functionNames[name]; ok {
+		return goName
+	}
+
+	return name
+}
+
 // String generates the whole output Go file as a string. This will include the
 // messages at the top of the file and all the rendered Go code.
 func (p *Program) String() string {
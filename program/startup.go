@@ -2,6 +2,7 @@ package program
 
 import (
 	goast "go/ast"
+	"sort"
 )
 
 // AppendStartupStatement adds a new statement that must be executed when the
@@ -25,3 +26,44 @@ func (p *Program) AppendStartupExpr(e goast.Expr) {
 func (p *Program) StartupStatements() []goast.Stmt {
 	return p.startupStatements
 }
+
+// Constructor is a function with the constructor or destructor attribute. The
+// constructors with a lower Priority are called first. The destructors with a
+// lower Priority are called last.
+type Constructor struct {
+	Name     string
+	Priority int
+}
+
+// AddConstructor registers a function that must be called before main().
+func (p *Program) AddConstructor(name string, priority int) {
+	p.constructors = append(p.constructors, Constructor{name, priority})
+}
+
+// AddDestructor registers a function that must be called when the program
+// exits.
+func (p *Program) AddDestructor(name string, priority int) {
+	p.destructors = append(p.destructors, Constructor{name, priority})
+}
+
+// Constructors returns the constructors of the current file ordered by
+// priority. Constructors with the same priority are in the order that they
+// were added.
+func (p *Program) Constructors() []Constructor {
+	return sortConstructors(p.constructors)
+}
+
+// Destructors returns the destructors of the current file ordered by priority,
+// the same as Constructors. They are called in the reverse order.
+func (p *Program) Destructors() []Constructor {
+	return sortConstructors(p.destructors)
+}
+
+func sortConstructors(constructors []Constructor) []Constructor {
+	sorted := append([]Constructor{}, constructors...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	return sorted
+}
@@ -16,25 +16,87 @@ type Struct struct {
 	// Plus, this field is used in collaboration with the method "c2go/program".*Program.GetStruct()
 	IsUnion bool
 
+	// True if the struct was declared with __attribute__((packed)). Packed
+	// structs do not have their size rounded up for alignment.
+	IsPacked bool
+
+	// The largest alignment (in bytes) that any field may have. It is set by
+	// "#pragma pack(n)" and is zero when there is no limit.
+	MaxFieldAlignment int
+
 	// Each of the fields and their C type. The field may be a string or an
 	// instance of Struct for nested structures.
 	Fields map[string]interface{}
+
+	// The names of the fields in the order they were declared. This does not
+	// include nested struct definitions.
+	FieldNames []string
+
+	// The bitfields of the struct by their name. These are not real Go fields,
+	// they are accessed through methods that read and write bits in an
+	// integer that can be shared by several bitfields.
+	Bitfields map[string]*Bitfield
+
+	// The size (in bytes) of each of the integers that store the bitfields.
+	BitfieldStorage map[string]int
+}
+
+// Bitfield describes where a bitfield is stored, like:
+//
+//     struct flags {
+//         unsigned a : 3;    // Storage: "c2goBitfield0", Offset: 0, Width: 3
+//         unsigned b : 2;    // Storage: "c2goBitfield0", Offset: 3, Width: 2
+//     };
+type Bitfield struct {
+	// The name of the Go field that holds the bits.
+	Storage string
+
+	// The position of the lowest bit and the number of bits used.
+	Offset int
+	Width  int
 }
 
 // NewStruct creates a new Struct definition from an ast.RecordDecl.
 func NewStruct(n *ast.RecordDecl) *Struct {
 	fields := make(map[string]interface{})
+	fieldNames := []string{}
+	isPacked := false
+	maxFieldAlignment := 0
 
 	for _, field := range n.Children {
 		switch f := field.(type) {
 		case *ast.FieldDecl:
-			fields[f.Name] = f.Type
+			// An unnamed bitfield is only padding, it cannot be used.
+			if f.Name == "" && len(f.Children) > 0 {
+				continue
+			}
+
+			// An anonymous struct or union does not have a name. Its type is
+			// unique so that is used instead.
+			name := f.Name
+			if f.Implicit {
+				name = f.Type
+			}
+
+			fields[name] = f.Type
+			fieldNames = append(fieldNames, name)
 
 		case *ast.RecordDecl:
 			fields[f.Name] = NewStruct(f)
 
-		case *ast.MaxFieldAlignmentAttr, *ast.AlignedAttr:
-			// FIXME: Should these really be ignored?
+		case *ast.PackedAttr:
+			isPacked = true
+
+		case *ast.IndirectFieldDecl:
+			// The fields of an anonymous struct or union are found through
+			// the anonymous struct or union itself.
+
+		case *ast.MaxFieldAlignmentAttr:
+			// The size of the attribute is in bits.
+			maxFieldAlignment = f.Size / 8
+
+		case *ast.AlignedAttr:
+			// FIXME: Should this really be ignored?
 
 		default:
 			panic(fmt.Sprintf("cannot decode: %#v", f))
@@ -42,8 +104,15 @@ func NewStruct(n *ast.RecordDecl) *Struct {
 	}
 
 	return &Struct{
-		Name:    n.Name,
-		IsUnion: n.Kind == "union",
-		Fields:  fields,
+		Name:       n.Name,
+		IsUnion:    n.Kind == "union",
+		IsPacked:   isPacked,
+		Fields:     fields,
+		FieldNames: fieldNames,
+
+		MaxFieldAlignment: maxFieldAlignment,
+
+		Bitfields:       map[string]*Bitfield{},
+		BitfieldStorage: map[string]int{},
 	}
 }
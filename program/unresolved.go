@@ -0,0 +1,51 @@
+package program
+
+import (
+	goast "go/ast"
+	"sort"
+)
+
+// AddUnresolvedFunction records a function that is called but has no Go
+// implementation. That is, there is only a prototype for it and it is not one
+// of the functions provided by c2go. The signature is the Go type of the
+// function.
+//
+// The signature of the first call is kept if the same function is added more
+// than once.
+func (p *Program) AddUnresolvedFunction(name string, signature *goast.FuncType) {
+	if _, ok := p.unresolvedFunctions[name]; ok {
+		return
+	}
+
+	p.unresolvedFunctions[name] = signature
+}
+
+// UnresolvedFunctions returns the names of the functions that were added with
+// AddUnresolvedFunction in alphabetical order.
+func (p *Program) UnresolvedFunctions() []string {
+	names := []string{}
+	for name := range p.unresolvedFunctions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// GetUnresolvedFunction returns the signature of a function that was added
+// with AddUnresolvedFunction, or nil if it was not added.
+func (p *Program) GetUnresolvedFunction(name string) *goast.FuncType {
+	return p.unresolvedFunctions[name]
+}
+
+// DefineFunction records that a function has a body somewhere in the package.
+// An unresolved function that is defined does not need a stub.
+func (p *Program) DefineFunction(name string) {
+	p.definedFunctions[name] = true
+}
+
+// IsFunctionDefined returns true if DefineFunction was called for the function.
+func (p *Program) IsFunctionDefined(name string) bool {
+	return p.definedFunctions[name]
+}
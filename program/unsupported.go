@@ -0,0 +1,39 @@
+package program
+
+import (
+	"reflect"
+
+	"github.com/elliotchance/c2go/ast"
+)
+
+// UnsupportedNode is a node of the clang AST that could not be transpiled. See
+// AddUnsupportedNode().
+type UnsupportedNode struct {
+	// The type of the node, like "BlockExpr".
+	Name string
+
+	// The position of the node in the preprocessed C file, like
+	// "line:5:13, line:7:3".
+	Position string
+}
+
+// AddUnsupportedNode records a node that could not be transpiled, so that it
+// can be reported when ReportUnsupported is on. A node that is added more than
+// once is only recorded the first time.
+func (p *Program) AddUnsupportedNode(node ast.Node) {
+	if p.unsupportedNodes[node] {
+		return
+	}
+
+	p.unsupportedNodes[node] = true
+
+	name := reflect.TypeOf(node).Elem().Name()
+	if n, ok := node.(*ast.UnknownNode); ok {
+		name = n.Name
+	}
+
+	p.UnsupportedNodes = append(p.UnsupportedNodes, UnsupportedNode{
+		Name:     name,
+		Position: ast.Position(node),
+	})
+}
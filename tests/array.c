@@ -3,9 +3,23 @@
 #include <stdio.h>
 #include "tests.h"
 
+const int SIZE = 4;
+const long TOTAL = SIZE * 10;
+const int LIMIT = 8;
+
+void set_first(int *p, int value)
+{
+    p[0] = value;
+}
+
+int sum_row(int (*rows)[4], int r)
+{
+    return rows[r][0] + rows[r][3];
+}
+
 int main()
 {
-    plan(5);
+    plan(31);
 
     int a[3];
     a[0] = 5;
@@ -22,6 +36,66 @@ int main()
 
     is_eq(b[0], 1.2);
     is_eq(b[1], 7.0);
-    
+
+    diag("Address of an element");
+    int c[5] = {1, 2, 3, 4, 5};
+    set_first(&c[2], 30);
+    is_eq(c[2], 30);
+    is_eq(c[1], 2);
+    is_eq(c[3], 4);
+
+    int *p;
+    p = &c[3];
+    p[1] = 50;
+    is_eq(c[4], 50);
+    is_eq(*p, 4);
+
+    char s[] = "hello";
+    char *t = &s[1];
+    t[0] = 'a';
+    is_streq(s, "hallo");
+    is_streq(&s[3], "lo");
+
+    diag("Constant size");
+    int d[SIZE];
+    d[SIZE - 1] = 7;
+    is_eq(d[3], 7);
+    is_eq(TOTAL, 40);
+
+    const int *limit = &LIMIT;
+    is_eq(*limit, 8);
+
+    diag("Multidimensional arrays");
+    int grid[3][4];
+    grid[2][0] = 20;
+    grid[2][3] = 40;
+    is_eq(grid[2][0] + grid[2][3], 60);
+    is_eq(sum_row(grid, 2), 60);
+
+    int m[2][3] = {{1, 2, 3}, {4, 5, 6}};
+    is_eq(m[0][2], 3);
+    is_eq(m[1][0], 4);
+
+    int *rows[4];
+    rows[1] = grid[2];
+    rows[1][3] = 45;
+    is_eq(grid[2][3], 45);
+    is_eq(rows[1][0], 20);
+
+    diag("Fewer initializers than elements");
+    int e[10] = {1, 2};
+    is_eq(e[1], 2);
+    is_eq(e[5], 0);
+    is_eq(e[9], 0);
+    is_eq(sizeof(e) / sizeof(e[0]), 10);
+
+    char str[8] = "abc";
+    is_streq(str, "abc");
+    is_eq(sizeof(str), 8);
+    is_eq(str[3], 0);
+    is_eq(str[7], 0);
+    str[5] = 'x';
+    is_eq(str[5], 'x');
+
     done_testing();
 }
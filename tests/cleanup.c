@@ -0,0 +1,50 @@
+// Tests for the cleanup attribute of variables.
+
+#include <stdio.h>
+#include <stdlib.h>
+#include "tests.h"
+
+int cleaned = 0;
+int freed = 0;
+
+void record(int *p)
+{
+    cleaned = *p;
+}
+
+void free_string(char **s)
+{
+    free(*s);
+    freed++;
+}
+
+void use_int()
+{
+    int x __attribute__((cleanup(record))) = 5;
+    x = 7;
+}
+
+void use_string()
+{
+    char *s __attribute__((cleanup(free_string))) = malloc(16);
+    s[0] = 'a';
+}
+
+int main()
+{
+    plan(4);
+
+    use_int();
+    is_eq(cleaned, 7);
+
+    use_string();
+    is_eq(freed, 1);
+    use_string();
+    is_eq(freed, 2);
+
+    // The cleanup of a variable in main() has not happened yet.
+    int y __attribute__((cleanup(record))) = 3;
+    is_eq(cleaned, 7);
+
+    done_testing();
+}
@@ -0,0 +1,38 @@
+// Tests for complex numbers.
+
+#include <stdio.h>
+#include <complex.h>
+#include "tests.h"
+
+int main()
+{
+    plan(10);
+
+    diag("literals");
+    double complex z = 1.5 + 2.0 * I;
+    is_eq(creal(z), 1.5);
+    is_eq(cimag(z), 2.0);
+
+    diag("__real__ and __imag__");
+    is_eq(__real__ z, 1.5);
+    is_eq(__imag__ z, 2.0);
+
+    diag("arithmetic");
+    double complex w = z * z;
+    is_eq(creal(w), -1.75);
+    is_eq(cimag(w), 6.0);
+
+    diag("float complex");
+    float complex f = 3.0f + 4.0f * I;
+    is_eq(cabs(f), 5.0);
+    is_eq(crealf(f), 3.0);
+
+    diag("casts");
+    double d = z;
+    is_eq(d, 1.5);
+
+    z = 7;
+    is_eq(cimag(z), 0);
+
+    done_testing();
+}
@@ -3,7 +3,7 @@
 
 int main()
 {
-    plan(5);
+    plan(8);
 	
 	int i = 0;
 
@@ -21,5 +21,20 @@ int main()
 		pass("%s", "second do statement");
 	} while(i < 3);
 
+	diag("continue checks the condition");
+	i = 0;
+	int count = 0;
+	do {
+		i++;
+		if (i % 2) continue;
+		count++;
+	} while (i < 3);
+	is_eq(i, 3);
+	is_eq(count, 1);
+
+	diag("body without braces");
+	do i++; while (i < 10);
+	is_eq(i, 10);
+
 	done_testing();
 }
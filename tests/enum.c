@@ -0,0 +1,69 @@
+// Tests for enumerations.
+
+#include <stdio.h>
+#include "tests.h"
+
+enum color
+{
+    RED = 0,
+    GREEN = 1,
+    BLUE = 2
+};
+
+typedef enum color color_t;
+
+enum values
+{
+    FIRST,
+    SECOND,
+    TENTH = 10,
+    ELEVENTH,
+    NEGATIVE = -3,
+    AFTER_NEGATIVE,
+    SAME = TENTH,
+    AFTER_SAME,
+    REFERENCE = FIRST + SECOND + 5,
+    AFTER_REFERENCE
+};
+
+typedef enum
+{
+    SMALL = 1,
+    LARGE = 10
+} size_e;
+
+int main()
+{
+    plan(17);
+
+    enum color c = BLUE;
+    color_t t = RED;
+
+    is_true(c == BLUE);
+    is_false(t == GREEN);
+    is_eq((int)c, 2);
+
+    c = (enum color)1;
+    is_true(c == GREEN);
+
+    diag("explicit and implicit values");
+    is_eq(FIRST, 0);
+    is_eq(SECOND, 1);
+    is_eq(TENTH, 10);
+    is_eq(ELEVENTH, 11);
+    is_eq(NEGATIVE, -3);
+    is_eq(AFTER_NEGATIVE, -2);
+    is_eq(SAME, 10);
+    is_eq(AFTER_SAME, 11);
+    is_true(SAME == TENTH);
+    is_eq(REFERENCE, 6);
+    is_eq(AFTER_REFERENCE, 7);
+
+    diag("typedef of an anonymous enum");
+    size_e s = LARGE;
+    is_eq(s, 10);
+    s = SMALL;
+    is_true(s == SMALL);
+
+    done_testing();
+}
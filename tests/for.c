@@ -3,7 +3,7 @@
 
 int main()
 {
-    plan(33);
+    plan(42);
 
     int i = 0;
 
@@ -72,6 +72,26 @@ int main()
 		j++;
 	}
 
+	diag("big increment with continue");
+	int sum = 0;
+	for (i = 0, j = 10; i < j; i++, j--){
+		if (i == 1)
+			continue;
+		sum += j;
+	}
+	is_eq(sum, 10 + 8 + 7 + 6);
+	is_eq(i, 5);
+
+	diag("continue runs the increment");
+	sum = 0;
+	for (i = 0; i < 5; i++){
+		if (i == 2)
+			continue;
+		sum += i;
+	}
+	is_eq(sum, 0 + 1 + 3 + 4);
+	is_eq(i, 5);
+
 	diag("Without body and with 2 and more increments");
 	for(i = 0, j = 0; i < 2; j++,i++);
 	pass("%d",i)
@@ -80,5 +100,35 @@ int main()
 	for(i = 0, j = 0; j++,i++,i < 2; );
 	pass("%d",i)
 
+	diag("Declaration in init");
+	int count = 0;
+	for (int k = 0; k < 3; k++)
+		count++;
+	is_eq(count, 3);
+
+	diag("Multiple declarations in init");
+	count = 0;
+	for (int k = 0, m = 10; k < m; k++, m--)
+		count++;
+	is_eq(count, 5);
+
+	diag("Declarations in init are only visible in the loop");
+	int k = 100;
+	for (int k = 0; k < 2; k++)
+		count++;
+	is_eq(k, 100);
+	is_eq(count, 7);
+
+	diag("Declaration in init with empty condition and increment");
+	count = 0;
+	for (int m = 0;;)
+	{
+		m++;
+		count++;
+		if (m == 4)
+			break;
+	}
+	is_eq(count, 4);
+
     done_testing();
 }
@@ -2,10 +2,25 @@
 #include "tests.h"
 
 void my_function();
+int add_old_style();
+const char *get_function_name();
+const char *get_pretty_function(int a, char *b);
+double scale_old_style();
+int next_counter();
+int next_other_counter();
+int next_shadowed_counter();
+int twice(int x);
+int apply(int (*f)(int), int x);
+
+// The constructors are called before main() in the order of their priority.
+int constructed = 0;
+void construct_second() __attribute__((constructor(102)));
+void construct_first() __attribute__((constructor(101)));
+void destruct() __attribute__((destructor));
 
 int main()
 {
-    plan(3);
+    plan(25);
 
     pass("%s", "Main function.");
 
@@ -13,6 +28,42 @@ int main()
 
     pass("%s", "Back in function main.");
 
+    diag("__func__");
+    is_streq(__func__, "main");
+    is_streq(__FUNCTION__, "main");
+    is_streq(__PRETTY_FUNCTION__, "int main()");
+    is_streq(get_function_name(), "get_function_name");
+    is_streq(get_pretty_function(1, "x"), "const char *get_pretty_function(int, char *)");
+
+    diag("K&R function definitions");
+    int i = 300;
+    is_eq(add_old_style(1, 2), 3);
+    is_eq(add_old_style(i, 'a'), 397);
+    is_eq(scale_old_style(2.5, 4), 10);
+    is_eq(scale_old_style(0.5, i), 150);
+
+    diag("constructors");
+    is_eq(constructed, 12);
+
+    diag("static local variables");
+    is_eq(next_counter(), 1);
+    is_eq(next_counter(), 2);
+    is_eq(next_other_counter(), 10);
+    is_eq(next_counter(), 3);
+    is_eq(next_shadowed_counter(), 111);
+    is_eq(next_shadowed_counter(), 212);
+
+    diag("function pointers");
+    int (*fp)(int) = &twice;
+    is_eq(fp(3), 6);
+    is_eq((*fp)(4), 8);
+
+    fp = twice;
+    is_eq(fp(5), 10);
+    is_eq(apply(twice, 6), 12);
+    is_eq(apply(&twice, 7), 14);
+    is_eq(apply(fp, 8), 16);
+
     done_testing();
 }
 
@@ -20,3 +71,77 @@ void my_function()
 {
     pass("%s", "Welcome to my function. Feel at home.");
 }
+
+int add_old_style(a, c)
+int a;
+char c;
+{
+    return a + c;
+}
+
+double scale_old_style(value, factor)
+double value;
+int factor;
+{
+    return value * factor;
+}
+
+const char *get_function_name()
+{
+    printf("%s\n", __func__);
+    return __func__;
+}
+
+const char *get_pretty_function(int a, char *b)
+{
+    return __PRETTY_FUNCTION__;
+}
+
+int next_counter()
+{
+    static int counter = 0;
+    return ++counter;
+}
+
+int next_other_counter()
+{
+    static int counter = 0;
+    counter += 10;
+    return counter;
+}
+
+int next_shadowed_counter()
+{
+    static int counter = 0;
+    int inner;
+    {
+        static int counter = 10;
+        inner = ++counter;
+    }
+    return ++counter * 100 + inner;
+}
+
+void construct_second()
+{
+    constructed = constructed * 10 + 2;
+}
+
+void construct_first()
+{
+    constructed = constructed * 10 + 1;
+}
+
+void destruct()
+{
+    constructed = 0;
+}
+
+int twice(int x)
+{
+    return x * 2;
+}
+
+int apply(int (*f)(int), int x)
+{
+    return f(x);
+}
@@ -0,0 +1,42 @@
+// Tests for C11 _Generic() selections.
+
+#include <stdio.h>
+#include "tests.h"
+
+int twice_int(int x)
+{
+    return x * 2;
+}
+
+double half_double(double x)
+{
+    return x / 2;
+}
+
+#define scale(x) _Generic((x), int: twice_int, double: half_double)(x)
+#define type_name(x) _Generic((x), int: "int", double: "double", default: "other")
+
+typedef int number;
+
+int main()
+{
+    plan(8);
+
+    int i = 3;
+    double d = 3;
+    number n = 4;
+
+    diag("functions");
+    is_eq(scale(i), 6);
+    is_eq(scale(d), 1.5);
+    is_eq(scale(n), 8);
+    is_eq(scale(2.5), 1.25);
+
+    diag("values");
+    is_streq(type_name(i), "int");
+    is_streq(type_name(d), "double");
+    is_streq(type_name('a'), "int");
+    is_streq(type_name(1L), "other");
+
+    done_testing();
+}
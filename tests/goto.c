@@ -0,0 +1,124 @@
+// Tests for goto and labels.
+
+#include <stdio.h>
+#include "tests.h"
+
+int cleanup_on_error(int fail_at)
+{
+    int steps = 0;
+
+    steps++;
+    if (fail_at == 1)
+        goto cleanup;
+
+    steps++;
+    if (fail_at == 2)
+        goto cleanup;
+
+    steps++;
+
+cleanup:
+    return steps;
+}
+
+int find_pair(int target)
+{
+    int found = 0;
+
+    for (int i = 1; i <= 3; i++)
+    {
+        for (int j = 1; j <= 3; j++)
+        {
+            if (i * j == target)
+            {
+                found = i * 10 + j;
+                goto done;
+            }
+        }
+    }
+done:
+    return found;
+}
+
+int count_until(int limit)
+{
+    int n = 0;
+
+    while (1)
+    {
+        do
+        {
+            n++;
+            if (n >= limit)
+                goto finished;
+        } while (n % 3 != 0);
+    }
+finished:
+    return n;
+}
+
+// A threaded interpreter: each byte of the program is an opcode, and the label
+// of each opcode jumps straight to the next one.
+int run(const char *program)
+{
+    void *ops[] = {&&halt, &&add, &&twice};
+    int pc = 0;
+    int acc = 0;
+
+    goto *ops[program[pc++]];
+
+add:
+    acc++;
+    goto *ops[program[pc++]];
+
+twice:
+    acc *= 2;
+    goto *ops[program[pc++]];
+
+halt:
+    return acc;
+}
+
+int main()
+{
+    plan(11);
+
+    is_eq(cleanup_on_error(1), 1);
+    is_eq(cleanup_on_error(2), 2);
+    is_eq(cleanup_on_error(0), 3);
+
+    int i = 0;
+again:
+    i++;
+    if (i < 5)
+        goto again;
+
+    is_eq(i, 5);
+
+    diag("goto out of nested loops");
+    is_eq(find_pair(6), 23);
+    is_eq(find_pair(7), 0);
+    is_eq(count_until(1), 1);
+    is_eq(count_until(8), 8);
+
+    diag("computed goto");
+    char program[] = {1, 1, 2, 1, 0};
+    is_eq(run(program), 5);
+    char empty[] = {0};
+    is_eq(run(empty), 0);
+
+    void *next = &&second;
+    int visited = 0;
+    goto *next;
+first:
+    visited += 1;
+    goto end;
+second:
+    visited += 10;
+    next = &&first;
+    goto *next;
+end:
+    is_eq(visited, 11);
+
+    done_testing();
+}
@@ -1,9 +1,12 @@
 #include <stdio.h>
 #include "tests.h"
 
+#define likely(x) __builtin_expect(!!(x), 1)
+#define unlikely(x) __builtin_expect(!!(x), 0)
+
 int main()
 {
-    plan(2);
+    plan(6);
 
     int x = 1;
 
@@ -17,5 +20,17 @@ int main()
     else
         pass("%s", "x is equal to one");
 
+    // Branch prediction hints
+    if (likely(x == 1))
+        pass("%s", "likely() is true");
+
+    if (unlikely(x == 2))
+        fail("%s", "unlikely() is true")
+    else
+        pass("%s", "unlikely() is false");
+
+    is_eq(__builtin_expect(x + 1, 0), 2);
+    is_eq(__builtin_expect_with_probability(x, 1, 0.9), 1);
+
     done_testing();
 }
@@ -0,0 +1,73 @@
+// Tests for initializer lists, including designated initializers.
+
+#include <stdio.h>
+#include "tests.h"
+
+struct point
+{
+    int x;
+    int y;
+    int z;
+};
+
+static const char *names[] = {"zero", "one", "two"};
+const char *colors[4] = {"red", "green"};
+int primes[] = {2, 3, 5, 7, 11};
+int squares[5] = {0, 1, 4};
+
+int main()
+{
+    plan(30);
+
+    diag("Arrays");
+    int a[3] = {1, 2, 3};
+    is_eq(a[0], 1);
+    is_eq(a[2], 3);
+
+    int b[5] = {[3] = 5};
+    is_eq(b[0], 0);
+    is_eq(b[3], 5);
+    is_eq(b[4], 0);
+
+    int c[6] = {1, [3] = 4, 5};
+    is_eq(c[0], 1);
+    is_eq(c[1], 0);
+    is_eq(c[3], 4);
+    is_eq(c[4], 5);
+    is_eq(c[5], 0);
+
+    diag("Structures");
+    struct point p1 = {1, 2, 3};
+    is_eq(p1.x, 1);
+    is_eq(p1.z, 3);
+
+    struct point p2 = {.y = 2, .x = 1};
+    is_eq(p2.x, 1);
+    is_eq(p2.y, 2);
+    is_eq(p2.z, 0);
+
+    struct point p3 = {.y = 5, 6};
+    is_eq(p3.z, 6);
+
+    diag("Global arrays with an inferred size");
+    is_eq(sizeof(names) / sizeof(names[0]), 3);
+    is_streq(names[0], "zero");
+    is_streq(names[2], "two");
+    is_eq(names[1][1], 'n');
+    is_eq(sizeof(primes) / sizeof(primes[0]), 5);
+    is_eq(primes[0], 2);
+    is_eq(primes[4], 11);
+
+    diag("Global arrays with an explicit size");
+    is_streq(colors[1], "green");
+    is_true(colors[2] == NULL);
+    is_eq(sizeof(squares) / sizeof(squares[0]), 5);
+    is_eq(squares[2], 4);
+    is_eq(squares[4], 0);
+
+    primes[1] = 13;
+    is_eq(primes[1], 13);
+    is_eq(primes[2], 5);
+
+    done_testing();
+}
@@ -0,0 +1,39 @@
+// Tests for the 128-bit integers, __int128 and unsigned __int128.
+
+#include <stdio.h>
+#include "tests.h"
+
+unsigned __int128 square(unsigned long long x)
+{
+    return (unsigned __int128)x * x;
+}
+
+int main()
+{
+    plan(10);
+
+    diag("unsigned __int128");
+    unsigned long long big = 0xfedcba9876543210ULL;
+    unsigned __int128 r = (unsigned __int128)0x123456789abcdef0ULL * big;
+    is_true((unsigned long long)(r >> 64) == 0x121fa00ad77d7422ULL);
+    is_true((unsigned long long)r == 0x236d88fe5618cf00ULL);
+
+    r = square(0xffffffffffffffffULL);
+    is_true((unsigned long long)(r >> 64) == 0xfffffffffffffffeULL);
+    is_eq((unsigned long long)r, 1);
+
+    r += r << 1;
+    is_eq((unsigned long long)r, 3);
+    is_true(r > big);
+
+    diag("__int128");
+    __int128 s = -5;
+    s *= 3;
+    s++;
+    is_eq((long long)s, -14);
+    is_eq((long long)(s / 4), -3);
+    is_eq((long long)(s >> 1), -7);
+    is_true(-s > 0);
+
+    done_testing();
+}
@@ -11,7 +11,7 @@ unsigned long long ullmax = 18446744073709551615ull;
 
 int main()
 {
-  plan(359);
+  plan(386);
 
   // Note: There are some tests that must be disabled because they return
   // different values under different compilers. See the comment surrounding the
@@ -214,6 +214,12 @@ int main()
   is_inf(fabs(-INFINITY), 1);
   is_nan(fabs(NAN));
 
+  diag("fabsf");
+  is_eq(fabsf(-1.5f), 1.5);
+  is_eq(fabsf(2), 2);
+  is_inf(fabsf(-INFINITY), 1);
+  is_nan(fabsf(NAN));
+
   diag("floor");
   is_eq(floor(0), 0);
   is_eq(floor(1), 1);
@@ -400,6 +406,14 @@ int main()
   is_nan(pow(-INFINITY, NAN));
   is_nan(pow(NAN, NAN));
 
+  diag("round");
+  is_eq(round(0.5), 1);
+  is_eq(round(-0.5), -1);
+  is_eq(round(2.4), 2);
+  is_eq(round(-2.6), -3);
+  is_inf(round(INFINITY), 1);
+  is_nan(round(NAN));
+
   diag("sin");
   is_eq(sin(0), 0);
   is_eq(sin(1), 0.841471);
@@ -468,5 +482,29 @@ int main()
   is_eq(tanh(-INFINITY), -1);
   is_nan(tanh(NAN));
 
+  diag("trunc");
+  is_eq(trunc(0), 0);
+  is_eq(trunc(1.7), 1);
+  is_eq(trunc(-1.7), -1);
+  is_inf(trunc(-INFINITY), -1);
+
+  diag("float functions");
+  is_eq(sqrtf(2.25f), 1.5);
+  is_eq(powf(2, 10), 1024);
+  is_eq(sinf(0), 0);
+  is_eq(cosf(0), 1);
+  is_eq(floorf(-1.5f), -2);
+  is_eq(ceilf(-1.5f), -1);
+  is_eq(roundf(2.5f), 3);
+
+  diag("long double functions");
+  long double ld = 2.25L;
+  is_eq(sqrtl(ld), 1.5);
+  is_eq(powl(ld, 2), 5.0625);
+  is_eq(fabsl(-ld) * 2, 4.5);
+  is_eq(floorl(ld), 2);
+  is_eq(ceill(ld / 2), 2);
+  is_eq(fmodl(ld, 1.0L), 0.25);
+
   done_testing();
 }
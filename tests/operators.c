@@ -1,4 +1,6 @@
+#include <stddef.h>
 #include <stdio.h>
+#include <string.h>
 #include "tests.h"
 
 // TODO: More comprehensive operator tests
@@ -6,7 +8,7 @@
 
 int main()
 {
-	plan(33);
+	plan(74);
 
     int i = 10;
     signed char j = 1;
@@ -115,5 +117,94 @@ int main()
 		is_eq(wF, expectedW);
 		is_eq(eF, expectedE);
 
+	diag("Comma operator as an expression");
+	int ci = 0, cj = 5, ck;
+	ck = (ci++, cj * 2);
+		is_eq(ci, 1);
+		is_eq(ck, 10);
+	ck = 0;
+	while (ci++, ci < 5)
+		ck++;
+		is_eq(ck, 3);
+	ck = (0 && (ci++, 1));
+		is_eq(ci, 5);
+
+	diag("Arithmetic with size_t");
+	size_t n = 5;
+	n = n + 1;
+		is_eq(n, 6);
+	n += x2 + 2;
+		is_eq(n, 8);
+	n = strlen("hello") * 2;
+		is_eq(n, 10);
+	ptrdiff_t diff = 3;
+	diff = diff - 10;
+		is_eq(diff, -7);
+	ssize_t ss = -1;
+	ss = ss * n;
+		is_eq(ss, -10);
+
+	diag("Constants that are converted");
+	int truncated = 3.7;
+		is_eq(truncated, 3);
+	float half = 0.5;
+		is_eq(half * 4, 2);
+
+	diag("Signed and unsigned char");
+	signed char sc = -1;
+		is_true(sc < 0);
+	unsigned char uc = sc;
+		is_eq(uc, 255);
+	sc = uc;
+		is_eq(sc, -1);
+
+	diag("Integer literals with suffixes");
+	unsigned int ui = 0xFFFFFFFFU;
+		is_true(ui == 4294967295U);
+		is_true(ui + 1 == 0);
+	long l = 100L;
+		is_eq(l, 100);
+	unsigned long long ull = 1ULL;
+		is_eq(ull << 40, 1099511627776LL);
+	unsigned long long max = 0xFFFFFFFFFFFFFFFFULL;
+		is_true(max == 18446744073709551615ULL);
+		is_true(max + 1 == 0);
+	unsigned int um = -1;
+		is_true(um == 0xFFFFFFFF);
+	int octal = 0755;
+		is_eq(octal, 493);
+
+	diag("Bit counting builtins");
+	unsigned int bits = 0x00F0;
+		is_eq(__builtin_clz(bits), 24);
+		is_eq(__builtin_clz(1), 31);
+		is_eq(__builtin_clzl(1L), 63);
+		is_eq(__builtin_ctz(bits), 4);
+		is_eq(__builtin_ctzl(8L), 3);
+		is_eq(__builtin_popcount(bits), 4);
+		is_eq(__builtin_popcountl(0xFFL), 8);
+	unsigned long long big = 1ULL << 40;
+		is_eq(__builtin_clzll(big), 23);
+		is_eq(__builtin_ctzll(big), 40);
+		is_eq(__builtin_popcountll(big | 1), 2);
+		is_eq(__builtin_popcountll(0xFFFFFFFFFFFFFFFFULL), 64);
+
+	diag("Increment and decrement as an expression");
+	int arr[3] = {1, 2, 3};
+	int k = 1;
+	int old = arr[k]++;
+		is_eq(old, 2);
+		is_eq(arr[1], 3);
+	int now = ++arr[k];
+		is_eq(now, 4);
+		is_eq(arr[1], 4);
+	int *ptr = arr;
+	old = (*ptr)--;
+		is_eq(old, 1);
+		is_eq(arr[0], 0);
+	old = k-- + 10;
+		is_eq(old, 11);
+		is_eq(k, 0);
+
 	done_testing();
 }
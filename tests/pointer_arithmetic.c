@@ -0,0 +1,102 @@
+// This file contains tests for pointer arithmetic.
+
+#include <stdio.h>
+#include <stdint.h>
+#include "tests.h"
+
+typedef struct
+{
+    int x;
+    int y;
+} Point;
+
+int calls = 0;
+
+int count(int value)
+{
+    calls++;
+    return value;
+}
+
+int main()
+{
+    plan(22);
+
+    diag("char *");
+    char *s = "hello";
+    s += 1;
+    is_eq(*s, 'e');
+    s += 3;
+    is_eq(*s, 'o');
+    is_eq(s[1], 0);
+
+    diag("int *");
+    int a[5];
+    a[0] = 10;
+    a[1] = 20;
+    a[2] = 30;
+    a[3] = 40;
+    a[4] = 50;
+    int *i = a;
+    i += 2;
+    is_eq(*i, 30);
+    is_eq(i[1], 40);
+
+    diag("struct pointer");
+    Point points[3];
+    points[0].x = 1;
+    points[1].x = 2;
+    points[2].x = 3;
+    Point *p = points;
+    p += 2;
+    is_eq(p->x, 3);
+
+    diag("the offset is only evaluated once");
+    calls = 0;
+    i = a;
+    i += count(3);
+    is_eq(*i, 40);
+    is_eq(calls, 1);
+
+    diag("the offset can be an expression");
+    int n = 1;
+    i = a;
+    i += n + 1;
+    is_eq(*i, 30);
+    i += n;
+    is_eq(*i, 40);
+    is_eq(i[-3 + 4], 50);
+
+    diag("subtracting pointers");
+    int *start = a;
+    int *third = &a[2];
+    is_eq(third - start, 2);
+    is_eq(start - third, -2);
+    char *word = "hello";
+    char *last = &word[4];
+    is_eq(last - word, 4);
+
+    diag("casting between integers and pointers");
+    int *null_pointer = (int *)0;
+    is_true(null_pointer == NULL);
+    is_eq((long)(intptr_t)NULL, 0);
+    Point *first = &points[0];
+    is_true((long)first != 0);
+
+    // The bytes are read in the byte order of the host, which is little-endian
+    // for all of the platforms that are tested.
+    diag("casting between pointers to numbers of different sizes");
+    uint8_t bytes[8] = {1, 0, 0, 0, 0x78, 0x56, 0x34, 0x12};
+    uint32_t *words = (uint32_t *)bytes;
+    is_eq(words[0], 1);
+    is_eq(words[1], 0x12345678);
+
+    words[0] = 0x0a0b0c0d;
+    is_eq(bytes[0], 0x0d);
+    is_eq(bytes[3], 0x0a);
+
+    uint16_t *halves = (uint16_t *)&bytes[4];
+    is_eq(halves[0], 0x5678);
+
+    done_testing();
+}
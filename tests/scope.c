@@ -0,0 +1,52 @@
+// Tests for variables that are shadowed by a variable with the same name in an
+// inner block.
+
+#include <stdio.h>
+#include "tests.h"
+
+int x = 10;
+
+int use(int value)
+{
+    return value;
+}
+
+int main()
+{
+    plan(10);
+
+    is_eq(x, 10);
+
+    int x = 1;
+    is_eq(use(x), 1);
+
+    {
+        int x = 2;
+        is_eq(use(x), 2);
+
+        {
+            long x = 3;
+            is_eq(use(x), 3);
+            is_eq(sizeof(typeof(x)), sizeof(long));
+        }
+
+        is_eq(use(x), 2);
+        is_eq(sizeof(typeof(x)), sizeof(int));
+        x++;
+    }
+
+    is_eq(use(x), 1);
+
+    for (int x = 5; x < 6; x++)
+    {
+        is_eq(use(x), 5);
+    }
+
+    if (x == 1)
+    {
+        int x = 4;
+        is_eq(use(x), 4);
+    }
+
+    done_testing();
+}
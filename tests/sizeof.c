@@ -35,12 +35,52 @@ union MyUnion
     int c;
 };
 
+struct PackedInner
+{
+    char a;
+    int b;
+} __attribute__((packed));
+
+struct PackedOuter
+{
+    char c;
+    struct PackedInner i;
+} __attribute__((packed));
+
+#pragma pack(push, 2)
+struct PragmaPack2
+{
+    char a;
+    int b;
+};
+
+#pragma pack(push, 1)
+struct PragmaPack1
+{
+    char a;
+    int b;
+};
+#pragma pack(pop)
+
+struct PragmaPack2Again
+{
+    char a;
+    double b;
+};
+#pragma pack(pop)
+
+struct NotPacked
+{
+    char a;
+    int b;
+};
+
 short a;
 int b;
 
 int main()
 {
-    plan(34);
+    plan(58);
 
     diag("Integer types");
     check_sizes(char, 1);
@@ -77,13 +117,53 @@ int main()
     diag("Structures");
     is_eq(sizeof(struct MyStruct), 16);
 
+    diag("Packed structures");
+    is_eq(sizeof(struct PackedInner), 5);
+    is_eq(sizeof(struct PackedOuter), 6);
+
+    diag("#pragma pack");
+    is_eq(sizeof(struct PragmaPack2), 6);
+    is_eq(sizeof(struct PragmaPack1), 5);
+    is_eq(sizeof(struct PragmaPack2Again), 10);
+    is_eq(sizeof(struct NotPacked), 8);
+
     diag("Unions");
     is_eq(sizeof(union MyUnion), 8);
 
     diag("Function pointers");
     is_eq(sizeof(main), 1);
 
-    diag("TODO: Test arrays of fixed size like: int[3]");
+    diag("Expressions");
+    is_eq(sizeof(a + b), 4);
+    is_eq(sizeof(s1.a), 8);
+    is_eq(sizeof(1.5f), 4);
+
+    diag("Arrays");
+    int arr[5];
+    struct MyStruct structs[3];
+    arr[0] = 0;
+    structs[0].b = 0;
+
+    is_eq(sizeof(int[3]), 12);
+    is_eq(sizeof(arr), 20);
+    is_eq(sizeof(arr) / sizeof(arr[0]), 5);
+    is_eq(sizeof(structs) / sizeof(structs[0]), 3);
+
+    static int counts[4];
+    counts[0] = 0;
+    is_eq(sizeof(counts) / sizeof(counts[0]), 4);
+
+    diag("Alignment");
+    is_eq(_Alignof(char), 1);
+    is_eq(_Alignof(short), 2);
+    is_eq(_Alignof(int), 4);
+    is_eq(_Alignof(double), 8);
+    is_eq(_Alignof(char *), 8);
+    is_eq(_Alignof(int[3]), 4);
+    is_eq(_Alignof(struct MyStruct), 8);
+    is_false(_Alignof(struct MyStruct) == sizeof(struct MyStruct));
+    is_eq(_Alignof(struct PragmaPack1), 1);
+    is_eq(_Alignof(union MyUnion), 8);
 
     done_testing();
 }
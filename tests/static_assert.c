@@ -0,0 +1,16 @@
+// Tests for C11 _Static_assert().
+
+#include <stdio.h>
+#include "tests.h"
+
+_Static_assert(sizeof(int) == 4, "int must be 4 bytes");
+
+int main()
+{
+    plan(1);
+
+    _Static_assert(sizeof(char) == 1, "char must be 1 byte");
+    pass("%s", "static assertions passed");
+
+    done_testing();
+}
@@ -4,7 +4,7 @@
 
 int main()
 {
-    plan(6);
+    plan(13);
 
     bool trueBool = true;
     bool falseBool = false;
@@ -48,5 +48,20 @@ int main()
         fail("%s", "should not reach here")
     }
 
+    diag("bitwise operators");
+    is_eq(trueBool & falseBool, 0);
+    is_eq(trueBool | falseBool, 1);
+    is_eq(trueBool ^ trueBool, 0);
+
+    int x = 3;
+    is_eq((x > 1) & (x < 5), 1);
+    is_eq((x > 1) + (x < 5), 2);
+
+    bool flags = falseBool;
+    flags |= trueBool;
+    is_true(flags);
+    flags &= falseBool;
+    is_false(flags);
+
     done_testing();
 }
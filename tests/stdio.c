@@ -2,6 +2,7 @@
 // here for consistency.
 
 #include <stdio.h>
+#include <stdarg.h>
 #include <string.h>
 #include <assert.h>
 #include "tests.h"
@@ -202,6 +203,18 @@ void test_fgets()
 
     mystring = fgets(dummy, 20, pFile);
     is_not_null(mystring);
+    is_streq(mystring, "// This program act");
+
+    fclose(pFile);
+
+    diag("fgets stops after a newline");
+    pFile = tmpfile();
+    fputs("ab\ncd", pFile);
+    rewind(pFile);
+
+    is_streq(fgets(dummy, 20, pFile), "ab\n");
+    is_streq(fgets(dummy, 20, pFile), "cd");
+    is_true(fgets(dummy, 20, pFile) == NULL);
 
     fclose(pFile);
 }
@@ -321,6 +334,58 @@ void test_fwrite()
     fclose(pFile);
 }
 
+struct point
+{
+    int x;
+    double y;
+};
+
+void test_fread_struct()
+{
+    struct point in[3] = {{1, 1.5}, {-2, 2.5}, {3, -3.5}};
+    struct point out[3];
+    FILE *pFile = tmpfile();
+
+    is_eq(fwrite(in, sizeof(struct point), 3, pFile), 3);
+    is_eq(ftell(pFile), 3 * sizeof(struct point));
+
+    rewind(pFile);
+    is_eq(fread(out, sizeof(struct point), 3, pFile), 3);
+    is_eq(out[1].x, -2);
+    is_eq(out[2].y, -3.5);
+
+    diag("fread of a partly read element");
+    is_eq(fwrite(in, 1, sizeof(int), pFile), sizeof(int));
+
+    fseek(pFile, 2 * sizeof(struct point), SEEK_SET);
+    out[1].x = 0;
+    is_eq(fread(out, sizeof(struct point), 2, pFile), 1);
+    is_eq(out[0].x, 3);
+    is_eq(out[1].x, 1);
+
+    fclose(pFile);
+
+    diag("fread of a struct with a pointer");
+    struct named
+    {
+        int id;
+        char *name;
+    };
+
+    struct named n = {5, "five"};
+    struct named m = {0, "kept"};
+    pFile = tmpfile();
+
+    is_eq(fwrite(&n, sizeof(struct named), 1, pFile), 1);
+    is_eq(ftell(pFile), sizeof(struct named));
+
+    rewind(pFile);
+    is_eq(fread(&m, sizeof(struct named), 1, pFile), 1);
+    is_eq(m.id, 5);
+
+    fclose(pFile);
+}
+
 void test_fgetpos()
 {
     FILE *pFile;
@@ -399,9 +464,74 @@ void test_feof()
     fclose(pFile);
 }
 
+void test_snprintf()
+{
+    char buf[10];
+    int n;
+
+    n = snprintf(buf, 10, "%d-%s", 12, "ab");
+    is_eq(n, 5);
+    is_streq(buf, "12-ab");
+
+    n = snprintf(buf, 4, "%d", 123456);
+    is_eq(n, 6);
+    is_streq(buf, "123");
+
+    n = snprintf(buf, 0, "%s", "not written");
+    is_eq(n, 11);
+    is_streq(buf, "123");
+}
+
+int format_into(char *buf, int size, const char *format, ...)
+{
+    va_list ap;
+    va_start(ap, format);
+    int n = vsnprintf(buf, size, format, ap);
+    va_end(ap);
+
+    return n;
+}
+
+void test_vsnprintf()
+{
+    char buf[8];
+    int n = format_into(buf, 8, "%s=%d", "value", 42);
+    is_eq(n, 8);
+    is_streq(buf, "value=4");
+}
+
+void test_sscanf()
+{
+    int i, j;
+    unsigned int u;
+    float f;
+    char c;
+    char str[10];
+
+    is_eq(sscanf("42 hello", "%d %s", &i, str), 2);
+    is_eq(i, 42);
+    is_streq(str, "hello");
+
+    is_eq(sscanf("1.5 x ff", "%f %c %x", &f, &c, &u), 3);
+    is_eq(f, 1.5);
+    is_eq(c, 'x');
+    is_eq(u, 255);
+
+    diag("field widths");
+    is_eq(sscanf("123456 abcdefgh", "%3d%d %4s", &i, &j, str), 3);
+    is_eq(i, 123);
+    is_eq(j, 456);
+    is_streq(str, "abcd");
+
+    diag("matching failures");
+    is_eq(sscanf("7,abc", "%d,%d", &i, &j), 1);
+    is_eq(i, 7);
+    is_eq(sscanf("", "%d", &i), EOF);
+}
+
 int main()
 {
-    plan(33);
+    plan(72);
 
     START_TEST(putchar)
     START_TEST(puts)
@@ -426,10 +556,14 @@ int main()
     START_TEST(ftell)
     START_TEST(fread)
     START_TEST(fwrite)
+    START_TEST(fread_struct)
     START_TEST(fgetpos)
     START_TEST(fsetpos)
     START_TEST(rewind)
     START_TEST(feof)
+    START_TEST(snprintf)
+    START_TEST(vsnprintf)
+    START_TEST(sscanf)
 
     done_testing();
 }
@@ -1,4 +1,6 @@
 #include <assert.h>
+#include <errno.h>
+#include <limits.h>
 #include <stdio.h>
 #include <stdlib.h>
 #include "tests.h"
@@ -82,14 +84,262 @@ void test_calloc()
     is_eq(d[4], 456);
 }
 
+// The memory of an array that is allocated for the number of elements of its
+// type is the same as the array in Go.
+void test_calloc_elements()
+{
+    diag("calloc elements");
+
+    int n = 5;
+    double *d = (double *)calloc(n, sizeof(double));
+    is_not_null(d) or_return();
+
+    d[4] = 1.5;
+    is_eq(d[0], 0);
+    is_eq(d[4], 1.5);
+
+    int *a = malloc(3 * sizeof(int));
+    is_not_null(a) or_return();
+
+    a[2] = 7;
+    is_eq(a[2], 7);
+}
+
+// The numbers of rand() depend on the C library, but the same seed always gives
+// the same numbers.
+void test_rand()
+{
+    diag("rand");
+
+    srand(3);
+    int a = rand();
+    int b = rand();
+
+    srand(3);
+    is_eq(rand(), a);
+    is_eq(rand(), b);
+
+    int in_range = 1;
+    for (int i = 0; i < 100; i++) {
+        int n = rand();
+        if (n < 0 || n > RAND_MAX || rand() % 6 >= 6 ||
+            rand() / (RAND_MAX + 1.0) >= 1) {
+            in_range = 0;
+        }
+    }
+    is_true(in_range);
+}
+
+void test_realloc()
+{
+    diag("realloc");
+
+    // realloc() of NULL is the same as malloc().
+    char *s = NULL;
+    s = (char *)realloc(s, 4);
+    is_not_null(s) or_return();
+
+    s[0] = 'a';
+    s[1] = 'b';
+    s[2] = 'c';
+    s[3] = 0;
+
+    diag("realloc keeps the contents when growing");
+    s = (char *)realloc(s, 8);
+    is_streq(s, "abc");
+
+    s[3] = 'd';
+    s[4] = 0;
+    is_streq(s, "abcd");
+
+    diag("realloc truncates the contents when shrinking");
+    s = (char *)realloc(s, 3);
+    is_eq(s[0], 'a');
+    is_eq(s[2], 'c');
+
+    free(s);
+
+    diag("realloc keeps the elements of any type");
+    int *a = (int *)malloc(2 * sizeof(int));
+    a[0] = 123;
+    a[1] = 456;
+    a = (int *)realloc(a, 4 * sizeof(int));
+    is_eq(a[0], 123);
+    is_eq(a[1], 456);
+    is_eq(a[3], 0);
+
+    free(a);
+}
+
+void test_strtol()
+{
+    diag("strtol");
+
+    char *end;
+
+    is_eq(strtol("  -123abc", &end, 10), -123);
+    is_streq(end, "abc");
+
+    is_eq(strtol("0x1f", &end, 0), 31);
+    is_streq(end, "");
+
+    is_eq(strtol("0755", &end, 0), 493);
+    is_eq(strtol("zz", &end, 36), 1295);
+
+    // No number can be parsed so endptr points to the start of the string.
+    char *s = "hello";
+    is_eq(strtol(s, &end, 10), 0);
+    is_true(end == s);
+
+    // endptr can be NULL.
+    is_eq(strtol("42", NULL, 10), 42);
+
+    errno = 0;
+    long long big = strtol("99999999999999999999", &end, 10);
+    is_true(big == LLONG_MAX);
+    is_eq(errno, ERANGE);
+
+    errno = 0;
+    long long small = strtol("-99999999999999999999", &end, 10);
+    is_true(small == LLONG_MIN);
+    is_eq(errno, ERANGE);
+}
+
+void test_strtoul()
+{
+    diag("strtoul");
+
+    char *end;
+
+    is_eq(strtoul("4294967295", &end, 10), 4294967295);
+    is_streq(end, "");
+    is_eq(strtoul("ff", &end, 16), 255);
+
+    errno = 0;
+    unsigned long long big = strtoul("999999999999999999999", &end, 10);
+    is_true(big == ULLONG_MAX);
+    is_eq(errno, ERANGE);
+}
+
+int compare_chars(const void *a, const void *b)
+{
+    return *(const char *)a - *(const char *)b;
+}
+
+int reverse_chars(const void *a, const void *b)
+{
+    return *(const char *)b - *(const char *)a;
+}
+
+void test_qsort()
+{
+    diag("qsort");
+
+    char s[] = "qsort";
+
+    qsort(s, 5, 1, compare_chars);
+    is_streq(s, "oqrst");
+
+    qsort(s, 5, 1, reverse_chars);
+    is_streq(s, "tsrqo");
+
+    diag("qsort only sorts the first elements");
+    char t[] = "dcba";
+    qsort(t, 2, 1, compare_chars);
+    is_streq(t, "cdba");
+}
+
+void test_bsearch()
+{
+    diag("bsearch");
+
+    char s[] = "aceg";
+    char key = 'e';
+
+    char *found = bsearch(&key, s, 4, 1, compare_chars);
+    is_not_null(found);
+    is_eq(found - s, 2);
+    is_eq(*found, 'e');
+
+    key = 'd';
+    is_true(bsearch(&key, s, 4, 1, compare_chars) == NULL);
+
+    key = 'a';
+    is_eq((char *)bsearch(&key, s, 4, 1, compare_chars) - s, 0);
+}
+
+void test_getenv()
+{
+    diag("getenv");
+
+    is_eq(setenv("C2GO_TEST_ENV", "foo", 1), 0);
+    is_streq(getenv("C2GO_TEST_ENV"), "foo");
+
+    is_eq(setenv("C2GO_TEST_ENV", "bar", 0), 0);
+    is_streq(getenv("C2GO_TEST_ENV"), "foo");
+
+    char env[] = "C2GO_TEST_ENV=baz";
+    is_eq(putenv(env), 0);
+    is_streq(getenv("C2GO_TEST_ENV"), "baz");
+
+    is_eq(unsetenv("C2GO_TEST_ENV"), 0);
+    is_true(getenv("C2GO_TEST_ENV") == NULL);
+    is_true(getenv("C2GO_DEFINITELY_NOT_SET") == NULL);
+
+    diag("setenv with an invalid name");
+    is_eq(setenv("C2GO=TEST", "foo", 1), -1);
+    is_eq(errno, EINVAL);
+}
+
+void test_abs()
+{
+    diag("abs");
+
+    is_eq(abs(-3), 3);
+    is_eq(abs(3), 3);
+    is_eq(abs('a' - 'z'), 25);
+    is_eq(labs(-100000L), 100000);
+    is_true(llabs(-(1LL << 40)) == (1LL << 40));
+}
+
+// The handlers print after done_testing(). The output is compared with the C
+// program, so they must be called in the reverse order.
+void exit_handler_first()
+{
+    diag("first exit handler");
+}
+
+void exit_handler_second()
+{
+    diag("second exit handler");
+}
+
+void test_atexit()
+{
+    diag("atexit");
+
+    is_eq(atexit(exit_handler_first), 0);
+    is_eq(atexit(exit_handler_second), 0);
+}
+
 int main()
 {
-    plan(14);
+    plan(74);
 
     test_malloc1();
     test_malloc2();
     test_malloc3();
     test_calloc();
+    test_calloc_elements();
+    test_rand();
+    test_realloc();
+    test_strtol();
+    test_strtoul();
+    test_qsort();
+    test_bsearch();
+    test_getenv();
+    test_abs();
+    test_atexit();
 
     done_testing();
 }
@@ -0,0 +1,52 @@
+// Tests for the storage classes of variables. The register and auto storage
+// classes do not change anything, and an extern variable is defined later.
+
+#include <stdio.h>
+#include "tests.h"
+
+extern int shared;
+
+int sum(register int n)
+{
+    register int total = 0;
+    for (register int i = 1; i <= n; i++)
+        total += i;
+
+    return total;
+}
+
+int get_shared()
+{
+    extern int shared;
+    return shared;
+}
+
+int main()
+{
+    plan(7);
+
+    register int i;
+    i = 3;
+    is_eq(i, 3);
+
+    auto int a = 4;
+    is_eq(a, 4);
+
+    is_eq(sum(4), 10);
+
+    diag("extern");
+    is_eq(shared, 5);
+    is_eq(get_shared(), 5);
+
+    shared = 6;
+    is_eq(get_shared(), 6);
+
+    {
+        extern int shared;
+        is_eq(shared, 6);
+    }
+
+    done_testing();
+}
+
+int shared = 5;
@@ -0,0 +1,150 @@
+// Tests for string literals and the functions in string.h.
+
+#include <stddef.h>
+#include <stdio.h>
+#include <string.h>
+#include "tests.h"
+
+#define GREETING "hello, " \
+                 "world"
+
+int main()
+{
+    plan(70);
+
+    diag("concatenation");
+    char *s = "a" "b" "c";
+    is_streq(s, "abc");
+    is_eq(strlen(s), 3);
+    is_eq(s[3], 0);
+
+    char a[] = "ab" "cd";
+    is_eq(sizeof(a), 5);
+    is_streq(a, "abcd");
+
+    is_streq(GREETING, "hello, world");
+
+    diag("escapes");
+    char *e = "tab\t" "quote\"" "\\";
+    is_streq(e, "tab\tquote\"\\");
+    is_eq(strlen(e), 11);
+
+    diag("embedded NUL");
+    char n[] = "ab\0" "cd";
+    is_eq(sizeof(n), 6);
+    is_eq(strlen(n), 2);
+    is_eq(n[3], 'c');
+
+    diag("escapes end at the end of a literal");
+    is_streq("\x41" "B" "\103", "ABC");
+
+    diag("wide strings");
+    wchar_t *w = L"aΩb";
+    is_eq(w[0], 'a');
+    is_eq(w[1], 0x3a9);
+    is_eq(w[2], 'b');
+    is_eq(w[3], 0);
+
+    wchar_t c = L'Ω';
+    is_eq(c, 0x3a9);
+    is_true(w[1] == c);
+
+    diag("UTF-8 strings");
+    char *s8 = u8"Ωx";
+    is_eq(strlen(s8), 3);
+    is_eq((unsigned char)s8[0], 0xce);
+    is_eq((unsigned char)s8[1], 0xa9);
+    is_eq(s8[2], 'x');
+
+    diag("UTF-16 strings");
+    unsigned short *s16 = u"Ω😀";
+    is_eq(s16[0], 0x3a9);
+    is_eq(s16[1], 0xd83d);
+    is_eq(s16[2], 0xde00);
+    is_eq(s16[3], 0);
+    is_eq(u'Ω', 0x3a9);
+
+    diag("UTF-32 strings");
+    unsigned int *s32 = U"Ω😀";
+    is_eq(s32[0], 0x3a9);
+    is_eq(s32[1], 0x1f600);
+    is_eq(s32[2], 0);
+    is_eq(U'😀', 0x1f600);
+
+    diag("strtok");
+    char csv[] = ",a,,bc;d";
+    char *token = strtok(csv, ",;");
+    is_streq(token, "a");
+    token = strtok(NULL, ",;");
+    is_streq(token, "bc");
+    is_streq(strtok(NULL, ",;"), "d");
+    is_true(strtok(NULL, ",;") == NULL);
+    is_eq(csv[2], 0);
+
+    diag("strtok_r");
+    char pairs[] = "x=1&y=2";
+    char *saveptr;
+    char *pair = strtok_r(pairs, "&", &saveptr);
+    is_streq(pair, "x=1");
+    pair = strtok_r(NULL, "&", &saveptr);
+    is_streq(pair, "y=2");
+    is_true(strtok_r(NULL, "&", &saveptr) == NULL);
+    is_streq(pairs, "x=1");
+
+    diag("memset");
+    char b[6] = "xxxxx";
+    memset(b, 'A', 4);
+    is_eq(b[0], 'A');
+    is_eq(b[1], 'A');
+    is_eq(b[2], 'A');
+    is_eq(b[3], 'A');
+    is_eq(b[4], 'x');
+    memset(b, 0, 2);
+    is_eq(b[1], 0);
+    is_streq(b + 2, "AAx");
+
+    diag("strncpy");
+    char c[8] = "xxxxxxx";
+    strncpy(c, "ab", 4);
+    is_streq(c, "ab");
+    is_eq(c[3], 0);
+    is_eq(c[4], 'x');
+    strncpy(c, "ABCDEF", 3);
+    is_eq(c[2], 'C');
+    is_eq(c[3], 0);
+    strncpy(c, "0123456789", 5);
+    is_eq(c[4], '4');
+    is_eq(c[5], 'x');
+
+    diag("strncat");
+    char d[10] = "ab";
+    strncat(d, "cd", 5);
+    is_streq(d, "abcd");
+    strncat(d, "efgh", 2);
+    is_streq(d, "abcdef");
+
+    diag("strncmp");
+    is_eq(strncmp("abcd", "abce", 3), 0);
+    is_true(strncmp("abcd", "abce", 4) < 0);
+    is_true(strncmp("abc", "ab", 5) > 0);
+    is_eq(strncmp("ab", "ab", 10), 0);
+
+    diag("strchr and strrchr");
+    char path[] = "/usr/local/bin";
+    is_streq(strchr(path, '/'), "/usr/local/bin");
+    is_streq(strchr(path + 1, '/'), "/local/bin");
+    is_streq(strrchr(path, '/'), "/bin");
+    is_true(strchr(path, 'x') == NULL);
+    is_true(strrchr(path, 'x') == NULL);
+    is_streq(strchr(path, '\0'), "");
+
+    diag("strstr");
+    char *found = strstr(path, "local");
+    is_streq(found, "local/bin");
+    found[0] = 'L';
+    is_streq(path, "/usr/Local/bin");
+    is_true(strstr(path, "Locale") == NULL);
+    is_streq(strstr(path, ""), path);
+
+    done_testing();
+}
@@ -1,6 +1,10 @@
 // Tests for structures.
 
+#include <stddef.h>
+#include <stdint.h>
 #include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
 #include "tests.h"
 
 struct programming
@@ -9,6 +13,139 @@ struct programming
     char *pointer;
 };
 
+struct flags
+{
+    unsigned a : 3;
+    int b : 4;
+    unsigned : 0;
+    unsigned c : 5;
+    int d;
+};
+
+struct rectangle
+{
+    struct
+    {
+        int x;
+        int y;
+    };
+    int width;
+};
+
+struct message
+{
+    int length;
+    char data[];
+};
+
+struct line
+{
+    struct rectangle start;
+    int length;
+};
+
+struct padded
+{
+    char a;
+    short b;
+    int32_t c;
+    char d;
+    double e;
+};
+
+struct pair
+{
+    int32_t x;
+    int32_t y;
+};
+
+struct nested
+{
+    int32_t n;
+    struct pair pos;
+};
+
+struct buffer
+{
+    char data[8];
+    int32_t n;
+};
+
+struct point
+{
+    int x;
+    int y;
+};
+
+struct named
+{
+    char name[16];
+    int grid[2][3];
+    struct point origin;
+};
+
+struct group
+{
+    struct named first;
+    int count;
+};
+
+typedef struct
+{
+    int width;
+    int height;
+} size;
+
+typedef struct
+{
+    char label[8];
+    int count;
+} counter, counter_t, *counter_ptr;
+
+int area(size s)
+{
+    return s.width * s.height;
+}
+
+int sum_point(struct point *p)
+{
+    return p->x + p->y;
+}
+
+void set_int(int *p, int value)
+{
+    *p = value;
+}
+
+void set_flags(struct flags *f)
+{
+    f->a = 2;
+    f->c += 3;
+}
+
+int flags_calls = 0;
+
+struct flags *get_flags(struct flags *f)
+{
+    flags_calls++;
+    return f;
+}
+
+struct point make_point(int x, int y)
+{
+    struct point p;
+    p.x = x;
+    p.y = y;
+    return p;
+}
+
+struct named last_named;
+
+struct named get_named()
+{
+    return last_named;
+}
+
 void pass_by_ref(struct programming *addr)
 {
     char *s = "Show string member.";
@@ -31,7 +168,7 @@ void pass_by_val(struct programming value)
 
 int main()
 {
-    plan(6);
+    plan(73);
 
     struct programming variable;
     char *s = "Programming in Software Development.";
@@ -45,5 +182,183 @@ int main()
     pass_by_val(variable);
     pass_by_ref(&variable);
 
+    diag("bitfields");
+    struct flags f;
+    f.a = 5;
+    f.b = -3;
+    f.c = 30;
+    f.d = 100;
+    is_eq(f.a, 5);
+    is_eq(f.b, -3);
+    is_eq(f.c, 30);
+    is_eq(f.d, 100);
+
+    diag("bitfields are truncated to their width");
+    f.a = 13;
+    is_eq(f.a, 5);
+    f.c++;
+    f.c++;
+    is_eq(f.c, 0);
+
+    diag("bitfields through a pointer");
+    set_flags(&f);
+    is_eq(f.a, 2);
+    is_eq(f.c, 3);
+
+    diag("bitfield assignments are expressions");
+    int stored = f.a = 13;
+    is_eq(stored, 5);
+    get_flags(&f)->c += 4;
+    is_eq(f.c, 7);
+    is_eq(flags_calls, 1);
+
+    diag("anonymous struct");
+    struct rectangle r;
+    r.x = 1;
+    r.y = 2;
+    r.width = 3;
+    is_eq(r.x, 1);
+    is_eq(r.y, 2);
+    is_eq(r.width, 3);
+
+    diag("flexible array member");
+    is_eq(sizeof(struct message), 4);
+
+    struct message *m;
+    m = (struct message *)malloc(sizeof(struct message) + 5);
+    m->length = 5;
+    m->data[0] = 'h';
+    m->data[4] = 'o';
+    is_eq(m->length, 5);
+    is_eq(m->data[0], 'h');
+    is_eq(m->data[4], 'o');
+
+    diag("memory from malloc for a struct");
+    struct point *pp = malloc(sizeof(struct point));
+    pp->x = 3;
+    pp->y = 4;
+    is_eq(pp->x, 3);
+    is_eq(sum_point(pp), 7);
+
+    void *mem = malloc(sizeof(struct point));
+    struct point *view = mem;
+    view->x = 5;
+    is_eq(((struct point *)mem)->x, 5);
+    is_eq(view->y, 0);
+
+    free(pp);
+    free(mem);
+
+    diag("address of a field");
+    struct line l;
+    l.length = 1;
+    int *length = &l.length;
+    *length = 5;
+    is_eq(l.length, 5);
+    set_int(&l.length, 6);
+    is_eq(*length, 6);
+
+    struct line *lp = &l;
+    set_int(&lp->length, 7);
+    is_eq(l.length, 7);
+
+    set_int(&l.start.width, 8);
+    is_eq(l.start.width, 8);
+    is_eq(lp->start.width, 8);
+
+    struct rectangle *rp = &l.start;
+    rp->width = 9;
+    is_eq(l.start.width, 9);
+
+    diag("offsetof");
+    is_eq(offsetof(struct padded, a), 0);
+    is_eq(offsetof(struct padded, b), 2);
+    is_eq(offsetof(struct padded, c), 4);
+    is_eq(offsetof(struct padded, e), 16);
+    is_eq(offsetof(struct nested, pos), 4);
+    is_eq(offsetof(struct nested, pos.y), 8);
+    is_eq(offsetof(struct buffer, data[2]), 2);
+
+    diag("compound literals");
+    is_eq(sum_point(&(struct point){.x = 1}), 1);
+    is_eq(sum_point(&(struct point){2, 3}), 5);
+
+    struct point pt = (struct point){.y = 4};
+    is_eq(pt.x, 0);
+    is_eq(pt.y, 4);
+
+    diag("copy arrays of a struct");
+    struct named n1;
+    strcpy(n1.name, "first");
+    n1.grid[1][2] = 3;
+    n1.origin.x = 4;
+
+    struct named n2 = n1;
+    is_streq(n2.name, "first");
+    is_eq(n2.grid[1][2], 3);
+    is_eq(n2.origin.x, 4);
+
+    n2.name[0] = 'F';
+    n2.grid[1][2] = 5;
+    is_streq(n1.name, "first");
+    is_streq(n2.name, "First");
+    is_eq(n1.grid[1][2], 3);
+
+    n1 = n2;
+    n2.name[1] = 'I';
+    is_streq(n1.name, "First");
+    is_streq(n2.name, "FIrst");
+    is_eq(n1.grid[1][2], 5);
+
+    struct group g1 = {n1, 1};
+    struct group g2;
+    g2 = g1;
+    g2.first.name[0] = 'x';
+    is_streq(g1.first.name, "First");
+    is_streq(g2.first.name, "xirst");
+    is_eq(g2.count, 1);
+
+    struct named list[2];
+    strcpy(list[0].name, "zero");
+    list[1] = list[0];
+    list[1].name[0] = 'Z';
+    is_streq(list[0].name, "zero");
+    is_streq(list[1].name, "Zero");
+
+    diag("return a struct by value");
+    struct point rp = make_point(3, 4);
+    is_eq(rp.x, 3);
+    is_eq(rp.y, 4);
+    is_eq(sum_point(&rp), 7);
+
+    rp = make_point(5, 6);
+    is_eq(rp.x, 5);
+
+    strcpy(last_named.name, "last");
+    struct named rn = get_named();
+    rn.name[0] = 'L';
+    is_streq(rn.name, "Last");
+    is_streq(get_named().name, "last");
+    is_streq(last_named.name, "last");
+
+    diag("typedef of an anonymous struct");
+    size sz = {3, 4};
+    is_eq(sz.width, 3);
+    is_eq(area(sz), 12);
+
+    counter c1;
+    strcpy(c1.label, "one");
+    c1.count = 1;
+    counter c2 = c1;
+    c2.count++;
+    is_eq(c1.count, 1);
+    is_eq(c2.count, 2);
+    is_streq(c2.label, "one");
+
+    counter_t c3;
+    c3.count = 3;
+    is_eq(c3.count, 3);
+    is_eq(sizeof(counter), 12);
+
     done_testing();
 }
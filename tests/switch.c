@@ -209,9 +209,274 @@ void scoped_fallthrough_several_cases_including_default()
     }
 }
 
+void stacked_cases()
+{
+    int i, matched = 0;
+
+    for (i = 0; i < 4; i++)
+    {
+        switch (i)
+        {
+        case 0:
+        case 2:
+            matched++;
+            break;
+        case 1:
+        case 3:
+            matched += 10;
+            break;
+        }
+    }
+
+    is_eq(matched, 22);
+}
+
+void default_in_the_middle()
+{
+    int a = 0;
+
+    switch (3)
+    {
+    case 1:
+        a += 1;
+        break;
+    default:
+        a += 10;
+    case 2:
+        a += 100;
+        break;
+    case 4:
+        a += 1000;
+    }
+
+    is_eq(a, 110);
+
+    a = 0;
+    switch (4)
+    {
+    case 1:
+        a += 1;
+    default:
+        a += 10;
+    case 4:
+        a += 100;
+    case 5:
+        a += 1000;
+    }
+
+    is_eq(a, 1100);
+}
+
+void stacked_cases_with_default()
+{
+    int a = 0;
+
+    switch (2)
+    {
+    case 1:
+        a = 1;
+        break;
+    case 2:
+    default:
+        a = 2;
+        break;
+    }
+
+    is_eq(a, 2);
+}
+
+int return_from_case(int a)
+{
+    switch (a)
+    {
+    case 1:
+        return 10;
+    case 2:
+        a = 5;
+    case 3:
+        return a * 2;
+    }
+
+    return 0;
+}
+
+// Cases are nested inside of a loop, these cannot be a Go switch.
+int duffs_device(int count)
+{
+    int from[10], to[10];
+    int i = 0, total = 0;
+    int n = (count + 3) / 4;
+
+    for (i = 0; i < 10; i++)
+    {
+        from[i] = i + 1;
+        to[i] = 0;
+    }
+
+    i = 0;
+    switch (count % 4)
+    {
+    case 0:
+        do
+        {
+            to[i] = from[i];
+            i++;
+        case 3:
+            to[i] = from[i];
+            i++;
+        case 2:
+            to[i] = from[i];
+            i++;
+        case 1:
+            to[i] = from[i];
+            i++;
+            n--;
+        } while (n > 0);
+    }
+
+    for (i = 0; i < 10; i++)
+    {
+        total += to[i];
+    }
+
+    return total;
+}
+
+void nested_case_with_break()
+{
+    int a = 0, i;
+
+    for (i = 0; i < 3; i++)
+    {
+        switch (i)
+        {
+        case 0:
+            if (1)
+            {
+                a += 1;
+                break;
+            case 1:
+                a += 10;
+            }
+            a += 100;
+            break;
+        default:
+            a += 1000;
+        }
+    }
+
+    is_eq(a, 1111);
+}
+
+// A "continue" inside of a switch belongs to the loop around the switch, but a
+// "break" still leaves the switch.
+void continue_from_case()
+{
+    int a = 0, i;
+
+    for (i = 0; i < 4; i++)
+    {
+        switch (i)
+        {
+        case 1:
+            continue;
+        case 2:
+            a += 10;
+            break;
+        default:
+            a += 1;
+        }
+        a += 100;
+    }
+
+    is_eq(a, 312);
+
+    a = 0;
+    i = 0;
+    while (i < 4)
+    {
+        i++;
+        switch (i)
+        {
+        case 2:
+            continue;
+        default:
+            a += 1;
+            break;
+        }
+        a += 100;
+    }
+
+    is_eq(a, 303);
+
+    a = 0;
+    i = 0;
+    do
+    {
+        i++;
+        switch (i)
+        {
+        case 1:
+        case 3:
+            continue;
+        }
+        a += i;
+    } while (i < 5);
+
+    is_eq(a, 11);
+}
+
+// The same as continue_from_case() but the case is nested so the switch is
+// translated with gotos.
+void continue_from_nested_case()
+{
+    int a = 0, i;
+
+    for (i = 0; i < 4; i++)
+    {
+        switch (i)
+        {
+        case 1:
+            if (1)
+            {
+                continue;
+            case 2:
+                a += 10;
+            }
+            break;
+        default:
+            a += 1;
+        }
+        a += 100;
+    }
+
+    is_eq(a, 312);
+}
+
+// The condition of a loop in a switch with nested cases has side effects that
+// must happen before it is checked.
+void nested_case_with_side_effects()
+{
+    int a = 0, n = 3;
+
+    switch (n)
+    {
+    case 3:
+        do
+        {
+            a += 1;
+        case 1:
+            a += 10;
+        } while (a += 100, --n > 0);
+    }
+
+    is_eq(a, 333);
+    is_eq(n, 0);
+}
+
 int main()
 {
-    plan(14);
+    plan(31);
 
     match_a_single_case();
     fallthrough_to_next_case();
@@ -227,5 +492,20 @@ int main()
     scoped_match_default();
     scoped_fallthrough_several_cases_including_default();
 
+    stacked_cases();
+    default_in_the_middle();
+    stacked_cases_with_default();
+    is_eq(return_from_case(1), 10);
+    is_eq(return_from_case(2), 10);
+    is_eq(return_from_case(3), 6);
+
+    is_eq(duffs_device(1), 1);
+    is_eq(duffs_device(6), 21);
+    is_eq(duffs_device(8), 36);
+    nested_case_with_break();
+    continue_from_case();
+    continue_from_nested_case();
+    nested_case_with_side_effects();
+
     done_testing();
 }
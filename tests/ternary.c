@@ -1,9 +1,22 @@
 #include <stdio.h>
 #include "tests.h"
 
+int calls = 0;
+
+int count(int value)
+{
+    calls++;
+    return value;
+}
+
+int grade(int x)
+{
+    return count(x) == 1 ? 10 : count(x) == 2 ? 20 : count(x) == 3 ? 30 : 40;
+}
+
 int main()
 {
-    plan(8);
+    plan(24);
 
     int a = 'a' == 65 ? 10 : 100;
     float b = 10 == 10 ? 1.0 : 2.0;
@@ -30,5 +43,44 @@ int main()
 	pass(__func__);
     }
 
+    // Only one of the branches must be evaluated.
+    calls = 0;
+    a = 1 ? count(5) : count(10);
+    is_eq(a, 5);
+    is_eq(calls, 1);
+
+    // Used inside a larger expression.
+    a = (calls == 1 ? 3 : 4) * 2 + 1;
+    is_eq(a, 7);
+
+    // The GNU extension where the middle operand is left out.
+    a = 0;
+    is_eq(a ?: 5, 5);
+    a = 3;
+    is_eq(a ?: 5, 3);
+
+    // The first operand must only be evaluated once.
+    calls = 0;
+    a = count(7) ?: count(9);
+    is_eq(a, 7);
+    is_eq(calls, 1);
+    a = count(0) ?: count(9);
+    is_eq(a, 9);
+
+    // A chain of conditional operators evaluates the conditions in order and
+    // stops at the first one that is true.
+    calls = 0;
+    is_eq(grade(1), 10);
+    is_eq(calls, 1);
+    calls = 0;
+    is_eq(grade(2), 20);
+    is_eq(calls, 2);
+    calls = 0;
+    is_eq(grade(3), 30);
+    is_eq(calls, 3);
+    calls = 0;
+    is_eq(grade(4), 40);
+    is_eq(calls, 3);
+
     done_testing();
 }
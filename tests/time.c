@@ -0,0 +1,45 @@
+// Tests for time.h.
+
+#include <stdio.h>
+#include <string.h>
+#include <time.h>
+#include "tests.h"
+
+int main()
+{
+    plan(11);
+
+    diag("strftime");
+    struct tm t;
+    t.tm_sec = 5;
+    t.tm_min = 4;
+    t.tm_hour = 15;
+    t.tm_mday = 2;
+    t.tm_mon = 0;
+    t.tm_year = 106;
+    t.tm_isdst = -1;
+
+    char buf[64];
+    mktime(&t);
+    is_eq(strftime(buf, sizeof(buf), "%Y-%m-%d", &t), 10);
+    is_streq(buf, "2006-01-02");
+    strftime(buf, sizeof(buf), "%H:%M:%S %a %b", &t);
+    is_streq(buf, "15:04:05 Mon Jan");
+    is_eq(strftime(buf, 4, "%Y-%m-%d", &t), 0);
+
+    diag("mktime");
+    t.tm_mday = 32;
+    time_t when = mktime(&t);
+    is_eq(t.tm_mon, 1);
+    is_eq(t.tm_mday, 1);
+    is_eq(t.tm_wday, 3);
+
+    diag("localtime");
+    struct tm *local = localtime(&when);
+    is_eq(local->tm_year, 106);
+    is_eq(local->tm_mday, 1);
+    is_eq(local->tm_hour, 15);
+    is_eq(mktime(local), when);
+
+    done_testing();
+}
@@ -0,0 +1,51 @@
+// Tests for the GNU typeof extension and the builtins that check types.
+
+#include <stdio.h>
+#include "tests.h"
+
+#define max(a, b)           \
+    typeof(a) _a = (a);     \
+    typeof(b) _b = (b);     \
+    return _a > _b ? _a : _b;
+
+typedef int number;
+
+int max_int(int x, int y)
+{
+    max(x, y)
+}
+
+double max_double(double x, double y)
+{
+    max(x, y)
+}
+
+int main()
+{
+    plan(12);
+
+    is_eq(max_int(3, 7), 7);
+    is_eq(max_int(-2, -5), -2);
+    is_eq(max_double(1.5, 0.5), 1.5);
+
+    long n = 40;
+    typeof(n) m = n + 2;
+    is_eq(m, 42);
+
+    __typeof__(unsigned char) c = 255;
+    c++;
+    is_eq(c, 0);
+
+    diag("__builtin_types_compatible_p");
+    is_true(__builtin_types_compatible_p(int, int));
+    is_false(__builtin_types_compatible_p(int, double));
+    is_true(__builtin_types_compatible_p(const int, int));
+    is_true(__builtin_types_compatible_p(number, int));
+    is_false(__builtin_types_compatible_p(char, unsigned char));
+    is_false(__builtin_types_compatible_p(const char *, char *));
+
+    diag("__builtin_constant_p");
+    is_false(__builtin_constant_p(n));
+
+    done_testing();
+}
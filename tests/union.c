@@ -42,15 +42,67 @@ void var_by_val(union programming value)
     is_eq(value.constant, 2.23);
 }
 
+struct number
+{
+    int kind;
+    union
+    {
+        int i;
+        unsigned int u;
+    };
+};
+
+void anonymous_union()
+{
+    diag("anonymous union");
+
+    struct number n;
+    n.kind = 1;
+    n.i = 7;
+    is_eq(n.kind, 1);
+    is_eq(n.i, 7);
+
+    n.i = -1;
+    is_eq(n.u, 4294967295);
+
+    n.i = 3;
+    n.i += 2;
+    is_eq(n.u, 5);
+}
+
+union word
+{
+    unsigned int u;
+    float f;
+    short s;
+};
+
+void shared_memory()
+{
+    diag("members share the same memory");
+
+    union word w;
+    w.f = 1.0;
+    is_eq(w.u, 0x3f800000);
+
+    w.u = 0x40490fdb;
+    is_eq(w.f, 3.14159274);
+
+    is_eq(sizeof(union word), 4);
+    is_eq(sizeof(union programming), 8);
+}
+
 int main()
 {
-    plan(5);
+    plan(13);
 
     union programming variable;
 
     variable = init_var();
     var_by_val(variable);
     pass_by_ref(&variable);
+    anonymous_union();
+    shared_memory();
 
     done_testing();
 }
@@ -0,0 +1,130 @@
+// This file contains tests for defining variadic functions.
+
+#include <stdarg.h>
+#include <stdio.h>
+#include "tests.h"
+
+int sum(int count, ...)
+{
+    va_list ap;
+    int total = 0;
+    int i;
+
+    va_start(ap, count);
+    for (i = 0; i < count; i++)
+    {
+        total += va_arg(ap, int);
+    }
+    va_end(ap);
+
+    return total;
+}
+
+double average(int count, ...)
+{
+    va_list ap;
+    double total = 0;
+    int i;
+
+    va_start(ap, count);
+    for (i = 0; i < count; i++)
+    {
+        total += va_arg(ap, double);
+    }
+    va_end(ap);
+
+    return total / count;
+}
+
+// Both va_lists read the same arguments independently.
+int sum_twice(int count, ...)
+{
+    va_list ap, ap2;
+    int total = 0;
+    int i;
+
+    va_start(ap, count);
+    va_copy(ap2, ap);
+    for (i = 0; i < count; i++)
+    {
+        total += va_arg(ap, int);
+    }
+    for (i = 0; i < count; i++)
+    {
+        total += va_arg(ap2, int);
+    }
+    va_end(ap2);
+    va_end(ap);
+
+    return total;
+}
+
+int first_int(va_list ap)
+{
+    return va_arg(ap, int);
+}
+
+int sum_with_helper(int count, ...)
+{
+    va_list ap;
+    int total = 0;
+    int i;
+
+    va_start(ap, count);
+    for (i = 0; i < count; i++)
+    {
+        total += first_int(ap);
+    }
+    va_end(ap);
+
+    return total;
+}
+
+int count_chars(char c, int count, ...)
+{
+    va_list ap;
+    int total = 0;
+    char *s;
+    int i, j;
+
+    va_start(ap, count);
+    for (j = 0; j < count; j++)
+    {
+        s = va_arg(ap, char *);
+        for (i = 0; s[i] != '\0'; i++)
+        {
+            if (s[i] == c)
+            {
+                total++;
+            }
+        }
+    }
+    va_end(ap);
+
+    return total;
+}
+
+int main()
+{
+    plan(8);
+
+    diag("va_arg with int");
+    is_eq(sum(0), 0);
+    is_eq(sum(1, 5), 5);
+    is_eq(sum(3, 1, 2, 3), 6);
+
+    diag("va_arg with double");
+    is_eq(average(2, 1.5, 2.5), 2.0);
+
+    diag("va_copy");
+    is_eq(sum_twice(3, 1, 2, 3), 12);
+
+    diag("passing a va_list to another function");
+    is_eq(sum_with_helper(3, 10, 20, 30), 60);
+
+    diag("va_arg with char *");
+    is_eq(count_chars('l', 2, "hello", "world"), 3);
+    is_eq(count_chars('z', 1, "hello"), 0);
+
+    done_testing();
+}
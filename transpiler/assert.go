@@ -0,0 +1,212 @@
+// This file contains the assert() macro. The preprocessor expands it into a
+// conditional operator that calls a function of the platform when the
+// assertion fails. On macOS and Linux they are:
+//
+//     (__builtin_expect(!(x > 0), 0) ? __assert_rtn(__func__, "main.c", 12, "x > 0") : (void)0)
+//     ((x > 0) ? (void) (0) : __assert_fail ("x > 0", "main.c", 12, __PRETTY_FUNCTION__))
+//
+// Since glibc 2.25 it is a statement expression instead. The sizeof is only
+// there for the warnings of the compiler, it is not evaluated:
+//
+//     ((void) sizeof ((x > 0) ? 1 : 0), __extension__ ({ if (x > 0) ; else __assert_fail ("x > 0", "main.c", 12, __PRETTY_FUNCTION__); }))
+//
+// All of them become a call to noarch.Assert(), which panics with the message
+// of the failed assertion:
+//
+//     noarch.Assert(x > 0, "x > 0", "main.c", 12)
+//
+// If NDEBUG is defined the macro is "((void)0)", so there is nothing to call.
+
+package transpiler
+
+import (
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+	"go/token"
+)
+
+// The positions of the expression, file and line number in the arguments of
+// each of the functions that are called when an assertion fails.
+var assertFunctionArguments = map[string][3]int{
+	"__assert_rtn":  {3, 1, 2},
+	"__assert_fail": {0, 1, 2},
+}
+
+// getAssertCall returns the call of a function in assertFunctionArguments, or
+// nil if the node is anything else.
+func getAssertCall(node ast.Node) (*ast.CallExpr, string) {
+	call, ok := removeCastsAndParens(node).(*ast.CallExpr)
+	if !ok {
+		return nil, ""
+	}
+
+	ref, ok := removeCastsAndParens(call.Children[0]).(*ast.DeclRefExpr)
+	if !ok {
+		return nil, ""
+	}
+
+	if _, ok := assertFunctionArguments[ref.Name]; !ok {
+		return nil, ""
+	}
+
+	return call, ref.Name
+}
+
+// isVoidZero returns true if the node is "(void)0".
+func isVoidZero(node ast.Node) bool {
+	cast, ok := removeCastsAndParens(node).(*ast.CStyleCastExpr)
+	if !ok || cast.Type != "void" {
+		return false
+	}
+
+	literal, ok := removeCastsAndParens(cast.Children[0]).(*ast.IntegerLiteral)
+
+	return ok && literal.Value == "0"
+}
+
+// getAssertCondition returns the expression that is asserted from the
+// condition of the conditional operator. The condition is negated on macOS
+// because the function is called when it is true.
+func getAssertCondition(condition ast.Node, negated bool) (ast.Node, bool) {
+	if !negated {
+		return condition, false
+	}
+
+	if call, ok := removeCastsAndParens(condition).(*ast.CallExpr); ok {
+		if ref, ok := removeCastsAndParens(call.Children[0]).(*ast.DeclRefExpr); ok &&
+			ref.Name == "__builtin_expect" {
+			condition = call.Children[1]
+		}
+	}
+
+	if not, ok := removeCastsAndParens(condition).(*ast.UnaryOperator); ok && not.Operator == "!" {
+		return not.Children[0], false
+	}
+
+	return condition, true
+}
+
+// transpileAssert returns the call to noarch.Assert() if the conditional
+// operator is an expanded assert(). Otherwise the call is nil.
+func transpileAssert(n *ast.ConditionalOperator, p *program.Program) (
+	*goast.CallExpr, []goast.Stmt, []goast.Stmt, error) {
+	call, name := getAssertCall(n.Children[2])
+	negated := false
+	if call == nil || !isVoidZero(n.Children[1]) {
+		call, name = getAssertCall(n.Children[1])
+		negated = true
+		if call == nil || !isVoidZero(n.Children[2]) {
+			return nil, nil, nil, nil
+		}
+	}
+
+	condition, negated := getAssertCondition(n.Children[0], negated)
+
+	return transpileAssertCall(condition, negated, call, name, p)
+}
+
+// transpileAssertStmtExpr returns the call to noarch.Assert() if the comma
+// operator is an assert() that was expanded by glibc 2.25 or newer. Otherwise
+// the call is nil.
+func transpileAssertStmtExpr(n *ast.BinaryOperator, p *program.Program) (
+	*goast.CallExpr, []goast.Stmt, []goast.Stmt, error) {
+	if n.Operator != "," || !isVoidSizeof(n.Children[0]) {
+		return nil, nil, nil, nil
+	}
+
+	stmtExpr, ok := removeCastsAndParens(n.Children[1]).(*ast.StmtExpr)
+	if !ok || len(stmtExpr.Children) != 1 {
+		return nil, nil, nil, nil
+	}
+
+	compound, ok := stmtExpr.Children[0].(*ast.CompoundStmt)
+	if !ok || len(compound.Children) != 1 {
+		return nil, nil, nil, nil
+	}
+
+	// The children of the if statement are the same as in transpileIfStmt.
+	// The body is a NullStmt, which is nil.
+	ifStmt, ok := compound.Children[0].(*ast.IfStmt)
+	if !ok {
+		return nil, nil, nil, nil
+	}
+
+	children := ifStmt.Children
+	if len(children) == 5 {
+		children = children[1:]
+	}
+
+	if len(children) != 4 || children[2] != nil {
+		return nil, nil, nil, nil
+	}
+
+	call, name := getAssertCall(children[3])
+	if call == nil {
+		return nil, nil, nil, nil
+	}
+
+	return transpileAssertCall(children[1], false, call, name, p)
+}
+
+// isVoidSizeof returns true if the node is "(void) sizeof (...)".
+func isVoidSizeof(node ast.Node) bool {
+	cast, ok := removeCastsAndParens(node).(*ast.CStyleCastExpr)
+	if !ok || cast.Type != "void" {
+		return false
+	}
+
+	sizeof, ok := removeCastsAndParens(cast.Children[0]).(*ast.UnaryExprOrTypeTraitExpr)
+
+	return ok && sizeof.Function == "sizeof"
+}
+
+// transpileAssertCall returns the call to noarch.Assert() for the condition and
+// the arguments of the function that is called when the assertion fails. The
+// condition is negated if the assertion fails when it is true.
+func transpileAssertCall(condition ast.Node, negated bool, call *ast.CallExpr, name string,
+	p *program.Program) (*goast.CallExpr, []goast.Stmt, []goast.Stmt, error) {
+	e, eType, preStmts, postStmts, err := transpileToExpr(condition, p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	e, err = types.CastExpr(p, e, eType, "bool")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if negated {
+		e = util.NewUnaryExpr(token.NOT, &goast.ParenExpr{X: e})
+	}
+
+	args := []goast.Expr{e}
+	positions := assertFunctionArguments[name]
+	argumentTypes := []string{"string", "string", "int"}
+
+	for i, position := range positions {
+		arg, argType, newPre, newPost, err := transpileToExpr(call.Children[position+1], p)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+		// The line number is a constant, which does not need to be cast.
+		if _, ok := arg.(*goast.BasicLit); !ok {
+			arg, err = types.CastExpr(p, arg, argType, argumentTypes[i])
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+
+		args = append(args, arg)
+	}
+
+	p.AddImport("github.com/elliotchance/c2go/noarch")
+
+	return util.NewCallExpr("noarch.Assert", args...), preStmts, postStmts, nil
+}
@@ -0,0 +1,146 @@
+package transpiler
+
+import (
+	"fmt"
+	"testing"
+
+	goast "go/ast"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func newStringArgument(value string) ast.Node {
+	return &ast.ImplicitCastExpr{
+		Kind: "ArrayToPointerDecay",
+		Type: "char *",
+		Children: []ast.Node{&ast.StringLiteral{
+			Type:  fmt.Sprintf("char [%d]", len(value)+1),
+			Value: value,
+		}},
+	}
+}
+
+func newVoidZero() ast.Node {
+	return &ast.CStyleCastExpr{
+		Kind:     "ToVoid",
+		Type:     "void",
+		Children: []ast.Node{&ast.IntegerLiteral{Type: "int", Value: "0"}},
+	}
+}
+
+func TestTranspileAssert(t *testing.T) {
+	condition := &ast.BinaryOperator{
+		Type:     "int",
+		Operator: ">",
+		Children: []ast.Node{
+			newRValue("int", newVarRef("x", "int")),
+			&ast.IntegerLiteral{Type: "int", Value: "0"},
+		},
+	}
+	expected := `noarch.Assert(x > 0, "x > 0", "main.c", 12)`
+
+	newAssertFail := func() ast.Node {
+		return newCall("void (const char *, const char *, unsigned int, const char *)", "__assert_fail",
+			newStringArgument("x > 0"),
+			newStringArgument("main.c"),
+			&ast.IntegerLiteral{Type: "unsigned int", Value: "12"},
+			newStringArgument("main"),
+		)
+	}
+
+	// ((void) sizeof ((x > 0) ? 1 : 0), ({ if (x > 0) ; else __assert_fail(...); }))
+	glibc := &ast.BinaryOperator{
+		Type:     "void",
+		Operator: ",",
+		Children: []ast.Node{
+			&ast.CStyleCastExpr{Kind: "ToVoid", Type: "void", Children: []ast.Node{
+				&ast.UnaryExprOrTypeTraitExpr{Type1: "unsigned long", Function: "sizeof", Children: []ast.Node{
+					&ast.ParenExpr{Type: "int", Children: []ast.Node{&ast.ConditionalOperator{
+						Type: "int",
+						Children: []ast.Node{
+							condition,
+							&ast.IntegerLiteral{Type: "int", Value: "1"},
+							&ast.IntegerLiteral{Type: "int", Value: "0"},
+						},
+					}}},
+				}},
+			}},
+			&ast.StmtExpr{Type: "void", Children: []ast.Node{
+				&ast.CompoundStmt{Children: []ast.Node{
+					&ast.IfStmt{Children: []ast.Node{nil, condition, nil, newAssertFail()}},
+				}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		node ast.Node
+	}{
+		{"linux", &ast.ConditionalOperator{
+			Type: "void",
+			Children: []ast.Node{
+				condition,
+				newVoidZero(),
+				newAssertFail(),
+			},
+		}},
+		{"glibc", glibc},
+		{"darwin", &ast.ConditionalOperator{
+			Type: "void",
+			Children: []ast.Node{
+				&ast.UnaryOperator{
+					Type:     "int",
+					Operator: "!",
+					IsPrefix: true,
+					Children: []ast.Node{condition},
+				},
+				newCall("void (const char *, const char *, int, const char *)", "__assert_rtn",
+					newStringArgument("main"),
+					newStringArgument("main.c"),
+					&ast.IntegerLiteral{Type: "int", Value: "12"},
+					newStringArgument("x > 0"),
+				),
+				newVoidZero(),
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		p := program.NewProgram()
+
+		expr, _, _, _, err := transpileToExpr(tt.node, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, expr); got != expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, expected, got)
+		}
+	}
+
+	// The statement expression is usually a statement by itself.
+	stmt, _, _, err := transpileToStmt(glibc, program.NewProgram())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := renderNode(t, stmt); got != expected {
+		t.Errorf("glibc statement: expected %s, got %s", expected, got)
+	}
+}
+
+func TestTranspileAssertWithNDEBUG(t *testing.T) {
+	stmt, _, _, err := transpileToStmt(&ast.ParenExpr{
+		Type:     "void",
+		Children: []ast.Node{newVoidZero()},
+	}, program.NewProgram())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := stmt.(*goast.EmptyStmt); !ok {
+		t.Errorf("expected an empty statement, got %#v", stmt)
+	}
+}
@@ -23,21 +23,60 @@ import (
 // part 2(stmt): right part - always only one expression, with or witout
 //               logical operators like "==", "!=", ...
 func transpileBinaryOperatorComma(n *ast.BinaryOperator, p *program.Program) (
-	stmt goast.Stmt, preStmts []goast.Stmt, err error) {
+	stmt goast.Stmt, preStmts []goast.Stmt, postStmts []goast.Stmt, err error) {
 
 	left, err := transpileToStmts(n.Children[0], p)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	right, err := transpileToStmts(n.Children[1], p)
+	stmt, newPre, postStmts, err := transpileToStmt(n.Children[1], p)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	preStmts = append(preStmts, left...)
+	preStmts = append(preStmts, newPre...)
 
-	return right[0], preStmts, nil
+	return stmt, preStmts, postStmts, nil
+}
+
+// transpileCommaExpr transpiles the comma operator when the value of it is
+// used, rather than being a statement by itself:
+//
+//     a = (b++, c);
+//     while (c = getchar(), c != EOF) { ... }
+//
+// The left side cannot be moved into the pre statements because it would not
+// be evaluated again when the expression is part of a loop condition, or it
+// would be evaluated when it should not be (like the right side of "&&").
+// Instead, a closure is used to evaluate the left side for its side effects and
+// then return the right side:
+//
+//     a = func() int {
+//         b++
+//         return c
+//     }()
+func transpileCommaExpr(n *ast.BinaryOperator, p *program.Program) (
+	*goast.CallExpr, string, []goast.Stmt, []goast.Stmt, error) {
+	stmts, err := transpileToStmts(n.Children[0], p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	returnType, err := types.ResolveType(p, n.Type)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	right, err := transpileConditionalOperatorBranch(n.Children[1], n.Type, returnType, p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	stmts = append(stmts, right...)
+
+	return util.NewFuncClosure(returnType, stmts...), n.Type, nil, nil, nil
 }
 
 func transpileBinaryOperator(n *ast.BinaryOperator, p *program.Program) (
@@ -92,55 +131,48 @@ func transpileBinaryOperator(n *ast.BinaryOperator, p *program.Program) (
 					impl.AddChild(n.Children[1].(*ast.BinaryOperator).Children[0].(*ast.DeclRefExpr))
 					bSecond.AddChild(&impl)
 
-					var bComma ast.BinaryOperator
-					bComma.Operator = ","
-					bComma.Type = c.Type
-					bComma.AddChild(n.Children[1])
-					bComma.AddChild(&bSecond)
-					return transpileBinaryOperator(&bComma, p)
+					// The left side of the comma is always a statement here,
+					// so it can be one of the pre statements.
+					first, newPre, newPost, err := transpileToStmt(n.Children[1], p)
+					if err != nil {
+						return nil, "", nil, nil, err
+					}
+					preStmts = append(preStmts, newPre...)
+					preStmts = append(preStmts, first)
+					preStmts = append(preStmts, newPost...)
+
+					second, secondType, newPre, newPost, err := transpileBinaryOperator(&bSecond, p)
+					if err != nil {
+						return nil, "", nil, nil, err
+					}
+					preStmts = append(preStmts, newPre...)
+
+					return second, secondType, preStmts, newPost, nil
 				}
 			}
 		}
 	}
 
-	// Example of C code
-	// a = 1, b = a
-	// Solving
-	// a = 1; // preStmts
-	// b = a; // n
-	// Example of AST tree for problem:
-	// |-BinaryOperator 0x368e8d8 <line:13:2, col:13> 'int' ','
-	// | |-BinaryOperator 0x368e820 <col:2, col:6> 'int' '='
-	// | | |-DeclRefExpr 0x368e7d8 <col:2> 'int' lvalue Var 0x368e748 'y' 'int'
-	// | | `-IntegerLiteral 0x368e800 <col:6> 'int' 1
-	// | `-BinaryOperator 0x368e8b0 <col:9, col:13> 'int' '='
-	// |   |-DeclRefExpr 0x368e848 <col:9> 'int' lvalue Var 0x368e6d8 'x' 'int'
-	// |   `-ImplicitCastExpr 0x368e898 <col:13> 'int' <LValueToRValue>
-	// |     `-DeclRefExpr 0x368e870 <col:13> 'int' lvalue Var 0x368e748 'y' 'int'
-	//
-	// Example of AST tree for solution:
-	// |-BinaryOperator 0x21a7820 <line:13:2, col:6> 'int' '='
-	// | |-DeclRefExpr 0x21a77d8 <col:2> 'int' lvalue Var 0x21a7748 'y' 'int'
-	// | `-IntegerLiteral 0x21a7800 <col:6> 'int' 1
-	// |-BinaryOperator 0x21a78b0 <line:14:2, col:6> 'int' '='
-	// | |-DeclRefExpr 0x21a7848 <col:2> 'int' lvalue Var 0x21a76d8 'x' 'int'
-	// | `-ImplicitCastExpr 0x21a7898 <col:6> 'int' <LValueToRValue>
-	// |   `-DeclRefExpr 0x21a7870 <col:6> 'int' lvalue Var 0x21a7748 'y' 'int'
 	if getTokenForOperator(n.Operator) == token.COMMA {
-		stmts, st, newPre, newPost, err := transpileToExpr(n.Children[0], p)
-		if err != nil {
-			return nil, "", nil, nil, err
+		assert, newPre, newPost, err := transpileAssertStmtExpr(n, p)
+		if err != nil || assert != nil {
+			return assert, "void", newPre, newPost, err
 		}
-		preStmts = append(preStmts, newPre...)
-		preStmts = append(preStmts, util.NewExprStmt(stmts))
-		postStmts = append(postStmts, newPost...)
-		stmts, st, newPre, newPost, err = transpileToExpr(n.Children[1], p)
-		if err != nil {
-			return nil, "", nil, nil, err
+
+		return transpileCommaExpr(n, p)
+	}
+
+	if length := transpileArrayLengthIdiom(n, p); length != nil {
+		return length, n.Type, preStmts, postStmts, nil
+	}
+
+	// Assigning to a bitfield uses its setter. The increment and decrement
+	// operators are also converted into "+=" and "-=" before they get here.
+	if memberExpr, ok := n.Children[0].(*ast.MemberExpr); ok && memberExpr.Bitfield {
+		switch getTokenForOperator(n.Operator) {
+		case token.ASSIGN, token.ADD_ASSIGN, token.SUB_ASSIGN:
+			return transpileBitfieldAssign(memberExpr, n.Operator, n.Children[1], p)
 		}
-		preStmts = append(preStmts, newPre...)
-		postStmts = append(postStmts, newPost...)
-		return stmts, st, preStmts, postStmts, nil
 	}
 
 	left, leftType, newPre, newPost, err := transpileToExpr(n.Children[0], p)
@@ -157,7 +189,21 @@ func transpileBinaryOperator(n *ast.BinaryOperator, p *program.Program) (
 
 	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
+	if e, ok := transpilePointerDifference(n, left, leftType, right, rightType, p); ok {
+		return e, n.Type, preStmts, postStmts, nil
+	}
+
+	if e, t, ok := transpileInt128BinaryOperator(n, n.Operator, left, leftType, right, rightType, p); ok {
+		return e, t, preStmts, postStmts, nil
+	}
+
 	operator := getTokenForOperator(n.Operator)
+
+	if isIntegerOperator(operator) {
+		left, leftType = transpileBoolOperand(n, left, leftType, p)
+		right, rightType = transpileBoolOperand(n, right, rightType, p)
+	}
+
 	returnType := types.ResolveTypeForBinaryOperator(p, n.Operator, leftType, rightType)
 
 	if operator == token.LAND || operator == token.LOR {
@@ -208,36 +254,23 @@ func transpileBinaryOperator(n *ast.BinaryOperator, p *program.Program) (
 
 	if operator == token.ASSIGN {
 		// Memory allocation is translated into the Go-style.
-		allocSize := GetAllocationSizeNode(n.Children[1])
+		allocSize := getAllocationSizeNode(n.Children[1], leftType, p)
 
 		if allocSize != nil {
-			allocSizeExpr, _, newPre, newPost, err := transpileToExpr(allocSize, p)
+			var newPre, newPost []goast.Stmt
+			right, newPre, newPost, err = transpileAllocation(allocSize, leftType, p)
 			preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
 			if err != nil {
 				return nil, "", preStmts, postStmts, err
 			}
 
-			derefType, err := types.GetDereferenceType(leftType)
-			if err != nil {
-				return nil, "", preStmts, postStmts, err
-			}
-
-			toType, err := types.ResolveType(p, leftType)
-			if err != nil {
-				return nil, "", preStmts, postStmts, err
-			}
+			right, newPre, newPost, err = transpileReallocation(n.Children[1], right, leftType, p)
+			preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
-			elementSize, err := types.SizeOf(p, derefType)
 			if err != nil {
 				return nil, "", preStmts, postStmts, err
 			}
-
-			right = util.NewCallExpr(
-				"make",
-				util.NewTypeIdent(toType),
-				util.NewBinaryExpr(allocSizeExpr, token.QUO, util.NewIntLit(elementSize)),
-			)
 		} else {
 			right, err = types.CastExpr(p, right, rightType, returnType)
 
@@ -265,12 +298,14 @@ func transpileBinaryOperator(n *ast.BinaryOperator, p *program.Program) (
 				right = util.NewNil()
 			}
 
+			right = transpileStructCopy(n.Children[1], right, leftType, p)
+
 			// Construct code for assigning value to an union field
 			memberExpr, ok := n.Children[0].(*ast.MemberExpr)
 			if ok {
 				ref := memberExpr.GetDeclRefExpr()
 				if ref != nil {
-					union := p.GetStruct(ref.Type)
+					union := p.GetStruct(getMemberRecordType(memberExpr))
 					if union != nil && union.IsUnion {
 						funcName := fmt.Sprintf("%s.Set%s", ref.Name, strings.Title(memberExpr.Name))
 						resExpr := util.NewCallExpr(funcName, right)
@@ -283,6 +318,15 @@ func transpileBinaryOperator(n *ast.BinaryOperator, p *program.Program) (
 		}
 	}
 
+	switch operator {
+	case token.ASSIGN, token.ADD_ASSIGN, token.SUB_ASSIGN:
+		right = transpileSignedCharStore(left, leftType, right, p)
+
+		if e, ok := transpileVolatileStore(n, n.Operator, left, leftType, right, p); ok {
+			return e, returnType, preStmts, postStmts, nil
+		}
+	}
+
 	return util.NewBinaryExpr(left, operator, right),
 		types.ResolveTypeForBinaryOperator(p, n.Operator, leftType, rightType),
 		preStmts, postStmts, nil
@@ -320,11 +364,8 @@ func GetAllocationSizeNode(node ast.Node) ast.Node {
 			}
 		}
 
-		// TODO: realloc() is not supported
-		// https://github.com/elliotchance/c2go/issues/118
-		//
-		// Realloc will be treated as calloc which will almost certainly cause
-		// bugs in your code.
+		// The elements of the old memory are copied by
+		// transpileReallocation.
 		if functionName == "realloc" {
 			return expr.(*ast.CallExpr).Children[2]
 		}
@@ -332,3 +373,257 @@ func GetAllocationSizeNode(node ast.Node) ast.Node {
 
 	return nil
 }
+
+// getAllocationSizeNode returns the size of the allocation (see
+// GetAllocationSizeNode) that is the memory for a pointer of cType, or nil if
+// the memory is not allocated by node.
+//
+// The contents of the memory must be kept when it is reallocated. The Go slice
+// of a char * (or void *) is the same as the bytes of the memory, so realloc()
+// is called as it is. Any other pointer is allocated as its own Go type and the
+// elements are copied (see transpileReallocation).
+func getAllocationSizeNode(node ast.Node, cType string, p *program.Program) ast.Node {
+	allocSize := GetAllocationSizeNode(node)
+
+	if allocSize != nil && getReallocation(node) != nil {
+		if goType, _ := types.ResolveType(p, cType); goType == "[]byte" {
+			return nil
+		}
+	}
+
+	return allocSize
+}
+
+// transpileAllocation returns the Go allocation of the memory for a pointer of
+// cType. It replaces the call to malloc(), calloc() or realloc() that allocates
+// allocSize bytes:
+//
+//     int *a = malloc(10 * sizeof(int));             ->    make([]int, 10)
+//     int *b = malloc(n);                            ->    make([]int, n/4)
+//     struct Foo *f = malloc(sizeof(struct Foo));    ->    &Foo{}
+func transpileAllocation(allocSize ast.Node, cType string, p *program.Program) (
+	goast.Expr, []goast.Stmt, []goast.Stmt, error) {
+	derefType, err := types.GetDereferenceType(cType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	toType, err := types.ResolveType(p, cType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	elementSize, err := types.SizeOf(p, derefType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if s := p.GetStruct(derefType); s != nil && strings.HasPrefix(toType, "*") {
+		allocSizeExpr, _, preStmts, postStmts, err := transpileToExpr(allocSize, p)
+		if err != nil {
+			return nil, preStmts, postStmts, err
+		}
+
+		e, err := transpileStructAllocation(p, s, toType[1:], elementSize, allocSizeExpr)
+		return e, preStmts, postStmts, err
+	}
+
+	// The number of elements does not have to be calculated when the size is
+	// already the number of elements multiplied by the size of an element.
+	if count := getAllocationCount(allocSize, derefType, p); count != nil {
+		countExpr, _, preStmts, postStmts, err := transpileToExpr(count, p)
+		if err != nil {
+			return nil, preStmts, postStmts, err
+		}
+
+		return util.NewCallExpr("make", util.NewTypeIdent(toType), countExpr),
+			preStmts, postStmts, nil
+	}
+
+	allocSizeExpr, _, preStmts, postStmts, err := transpileToExpr(allocSize, p)
+	if err != nil {
+		return nil, preStmts, postStmts, err
+	}
+
+	return util.NewCallExpr(
+		"make",
+		util.NewTypeIdent(toType),
+		util.NewBinaryExpr(allocSizeExpr, token.QUO, util.NewIntLit(elementSize)),
+	), preStmts, postStmts, nil
+}
+
+// getAllocationCount returns the number of elements of elementType in an
+// allocation of allocSize bytes, like the "n" of "n * sizeof(int)" (or of
+// "calloc(n, sizeof(int))"). A single "sizeof(int)" is one element. nil is
+// returned if the number of elements is not part of allocSize.
+func getAllocationCount(allocSize ast.Node, elementType string, p *program.Program) ast.Node {
+	allocSize = removeCastsAndParens(allocSize)
+
+	if isSizeOfType(allocSize, elementType, p) {
+		return &ast.IntegerLiteral{Type: "int", Value: "1"}
+	}
+
+	n, ok := allocSize.(*ast.BinaryOperator)
+	if !ok || n.Operator != "*" || len(n.Children) != 2 {
+		return nil
+	}
+
+	if isSizeOfType(removeCastsAndParens(n.Children[1]), elementType, p) {
+		return n.Children[0]
+	}
+	if isSizeOfType(removeCastsAndParens(n.Children[0]), elementType, p) {
+		return n.Children[1]
+	}
+
+	return nil
+}
+
+// isSizeOfType returns true if node is "sizeof(T)" where T is the same Go type
+// as cType.
+func isSizeOfType(node ast.Node, cType string, p *program.Program) bool {
+	n, ok := node.(*ast.UnaryExprOrTypeTraitExpr)
+	if !ok || n.Function != "sizeof" || len(n.Children) > 0 {
+		return false
+	}
+
+	sizeOfType, err := types.ResolveType(p, n.Type2)
+	if err != nil {
+		return false
+	}
+
+	goType, err := types.ResolveType(p, cType)
+
+	return err == nil && sizeOfType == goType
+}
+
+// transpileAllocationCast transpiles the cast of the memory that is returned by
+// malloc(), calloc() or realloc(), like "(struct Foo *)malloc(n)", into the Go
+// allocation of the type that it is cast to (see transpileAllocation). The
+// fourth return value is false if node is not a call to one of them.
+func transpileAllocationCast(node ast.Node, cType string, p *program.Program) (
+	goast.Expr, []goast.Stmt, []goast.Stmt, bool, error) {
+	call, ok := removeCastsAndParens(node).(*ast.CallExpr)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	allocSize := getAllocationSizeNode(call, cType, p)
+	if allocSize == nil {
+		return nil, nil, nil, false, nil
+	}
+
+	e, preStmts, postStmts, err := transpileAllocation(allocSize, cType, p)
+	if err != nil {
+		return nil, preStmts, postStmts, true, err
+	}
+
+	e, newPre, newPost, err := transpileReallocation(call, e, cType, p)
+	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+	return e, preStmts, postStmts, true, err
+}
+
+// transpileStructAllocation allocates a struct that is used through a pointer,
+// such as "struct hdr *". A struct that ends with a flexible array member:
+//
+//     struct hdr {
+//         int len;
+//         char data[];
+//     };
+//
+// is usually allocated with extra memory for the elements of the last field,
+// like "malloc(sizeof(struct hdr) + n)". The field is a slice in Go so it is
+// created with the number of elements that fit in the extra memory:
+//
+//     &hdr{data: make([]byte, (sizeof(struct hdr) + n - 4) / 1)}
+func transpileStructAllocation(p *program.Program, s *program.Struct,
+	goType string, structSize int, allocSize goast.Expr) (goast.Expr, error) {
+	lit := &goast.CompositeLit{
+		Type: util.NewTypeIdent(goType),
+	}
+
+	if len(s.FieldNames) > 0 {
+		name := s.FieldNames[len(s.FieldNames)-1]
+		fieldType, ok := s.Fields[name].(string)
+
+		if ok && strings.HasSuffix(fieldType, "[]") {
+			elementType := strings.TrimSpace(strings.TrimSuffix(fieldType, "[]"))
+			elementSize, err := types.SizeOf(p, elementType)
+			if err != nil {
+				return nil, err
+			}
+
+			sliceType, err := types.ResolveType(p, fieldType)
+			if err != nil {
+				return nil, err
+			}
+
+			length := util.NewBinaryExpr(
+				&goast.ParenExpr{
+					X: util.NewBinaryExpr(allocSize, token.SUB, util.NewIntLit(structSize)),
+				},
+				token.QUO,
+				util.NewIntLit(elementSize),
+			)
+
+			lit.Elts = []goast.Expr{
+				&goast.KeyValueExpr{
+					Key:   util.NewIdent(getFieldName(p, name)),
+					Value: util.NewCallExpr("make", util.NewTypeIdent(sliceType), length),
+				},
+			}
+		}
+	}
+
+	return &goast.UnaryExpr{
+		Op: token.AND,
+		X:  lit,
+	}, nil
+}
+
+// getReallocation returns the call to realloc() in the node, or nil if there is
+// not one.
+func getReallocation(node ast.Node) *ast.CallExpr {
+	exprs := ast.GetAllNodesOfType(node, reflect.TypeOf((*ast.CallExpr)(nil)))
+
+	for _, expr := range exprs {
+		functionName, _ := getNameOfFunctionFromCallExpr(expr.(*ast.CallExpr))
+		if functionName == "realloc" {
+			return expr.(*ast.CallExpr)
+		}
+	}
+
+	return nil
+}
+
+// transpileReallocation keeps the elements of the old memory when node is a
+// call to realloc() that is replaced by the Go allocation e (see
+// transpileAllocation):
+//
+//     int *a = realloc(a, 10 * sizeof(int));    ->    noarch.ReallocElements(make([]int, 10), a).([]int)
+//
+// e is returned as it is if node does not call realloc().
+func transpileReallocation(node ast.Node, e goast.Expr, cType string, p *program.Program) (
+	goast.Expr, []goast.Stmt, []goast.Stmt, error) {
+	call := getReallocation(node)
+	if call == nil {
+		return e, nil, nil, nil
+	}
+
+	ptr, _, preStmts, postStmts, err := transpileToExpr(removeCastsAndParens(call.Children[1]), p)
+	if err != nil {
+		return nil, preStmts, postStmts, err
+	}
+
+	toType, err := types.ResolveType(p, cType)
+	if err != nil {
+		return nil, preStmts, postStmts, err
+	}
+
+	p.AddImport("github.com/elliotchance/c2go/noarch")
+
+	return &goast.TypeAssertExpr{
+		X:    util.NewCallExpr("noarch.ReallocElements", e, ptr),
+		Type: util.NewTypeIdent(toType),
+	}, preStmts, postStmts, nil
+}
@@ -0,0 +1,433 @@
+// This file contains functions for transpiling struct bitfields, like:
+//
+//     struct flags {
+//         unsigned a : 3;
+//         unsigned b : 2;
+//     };
+//
+// Go does not have bitfields. Instead, consecutive bitfields are packed into
+// one unsigned integer field and each bitfield has a getter and setter method
+// that reads and writes its bits.
+
+package transpiler
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// isBitfield returns true if the field has a width. The width is the only child
+// that a FieldDecl has in C.
+func isBitfield(n *ast.FieldDecl) bool {
+	return len(n.Children) > 0
+}
+
+// getBitfieldWidth returns the number of bits of a bitfield. The width must be
+// an integer constant.
+func getBitfieldWidth(n *ast.FieldDecl) (int, error) {
+	var node ast.Node = n.Children[0]
+	for {
+		switch w := node.(type) {
+		case *ast.IntegerLiteral:
+			return util.Atoi(w.Value), nil
+
+		case *ast.ImplicitCastExpr, *ast.ParenExpr:
+			node = getChildren(w)[0]
+			continue
+		}
+
+		return 0, fmt.Errorf("the width of the bitfield %s is not an integer literal", n.Name)
+	}
+}
+
+// bitfieldLayout decides where each of the bitfields in a struct is stored.
+// Consecutive bitfields share the same integer while they have the same size
+// and they still fit. A zero width bitfield means that the next bitfield must
+// start in a new integer:
+//
+//     struct s {
+//         unsigned a : 3;   // c2goBitfield0, bits 0-2
+//         unsigned b : 2;   // c2goBitfield0, bits 3-4
+//         unsigned : 0;
+//         unsigned c : 1;   // c2goBitfield1, bit 0
+//     };
+//
+// This is close to how gcc and clang lay out bitfields, but it does not try to
+// match them exactly.
+type bitfieldLayout struct {
+	p *program.Program
+	s *program.Struct
+
+	// The storage that is being filled and how many bits have been used.
+	storage string
+	size    int
+	used    int
+}
+
+// add places the bitfield in the struct. If a new storage field is needed it is
+// returned so that it can be added to the Go struct in the same position.
+func (l *bitfieldLayout) add(n *ast.FieldDecl) (*goast.Field, error) {
+	width, err := getBitfieldWidth(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if width == 0 {
+		l.end()
+		return nil, nil
+	}
+
+	bytes, err := types.SizeOf(l.p, n.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	size := bytes * 8
+	if width > size {
+		return nil, fmt.Errorf("the bitfield %s is wider than its type", n.Name)
+	}
+
+	var field *goast.Field
+	if l.storage == "" || l.size != size || l.used+width > l.size {
+		l.storage = fmt.Sprintf("c2goBitfield%d", len(l.s.BitfieldStorage))
+		l.size = size
+		l.used = 0
+		l.s.BitfieldStorage[l.storage] = bytes
+
+		field = &goast.Field{
+			Names: []*goast.Ident{util.NewIdent(l.storage)},
+			Type:  util.NewTypeIdent(fmt.Sprintf("uint%d", size)),
+		}
+	}
+
+	// An unnamed bitfield only takes up space.
+	if n.Name != "" {
+		l.s.Bitfields[n.Name] = &program.Bitfield{
+			Storage: l.storage,
+			Offset:  l.used,
+			Width:   width,
+		}
+	}
+	l.used += width
+
+	return field, nil
+}
+
+// end stops any more bitfields from being added to the current storage.
+func (l *bitfieldLayout) end() {
+	l.storage = ""
+}
+
+// getBitfieldMethodName returns the name of the getter or setter for a
+// bitfield, like "GetFlags" and "SetFlags".
+func getBitfieldMethodName(prefix, name string) string {
+	return prefix + strings.Title(name)
+}
+
+// transpileBitfieldMethods creates the getter and setter for each of the
+// bitfields of a struct. For an "unsigned b : 2" at bit 3 they look like:
+//
+//     func (self *s) GetB() uint32 {
+//         return uint32(self.c2goBitfield0 >> 3 & 0x3)
+//     }
+//     func (self *s) SetB(value uint32) uint32 {
+//         self.c2goBitfield0 = self.c2goBitfield0&^0x18 | uint32(value)<<3&0x18
+//         return self.GetB()
+//     }
+//
+// Signed bitfields are sign extended when they are read. The setter returns the
+// value that has been stored, which is the value of the assignment in C.
+func transpileBitfieldMethods(p *program.Program, n *ast.RecordDecl, s *program.Struct) []goast.Decl {
+	decls := []goast.Decl{}
+
+	for _, fieldName := range s.FieldNames {
+		bitfield := s.Bitfields[fieldName]
+		if bitfield == nil {
+			continue
+		}
+
+		fieldType, err := types.ResolveType(p, s.Fields[fieldName].(string))
+		p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+		size := s.BitfieldStorage[bitfield.Storage] * 8
+		storageType := fmt.Sprintf("uint%d", size)
+		mask := uint64(1)<<uint(bitfield.Width) - 1
+		storage := &goast.SelectorExpr{
+			X:   util.NewIdent("self"),
+			Sel: util.NewIdent(bitfield.Storage),
+		}
+
+		var getter, setter []goast.Stmt
+
+		switch {
+		case fieldType == "bool":
+			// self.c2goBitfield0&0x8 != 0
+			getter = []goast.Stmt{
+				&goast.ReturnStmt{
+					Results: []goast.Expr{
+						util.NewBinaryExpr(
+							util.NewBinaryExpr(storage, token.AND, newHexLit(mask<<uint(bitfield.Offset))),
+							token.NEQ,
+							util.NewIntLit(0),
+						),
+					},
+				},
+			}
+
+			// self.c2goBitfield0 &^= 0x8
+			// if value {
+			//     self.c2goBitfield0 |= 0x8
+			// }
+			setter = []goast.Stmt{
+				&goast.AssignStmt{
+					Lhs: []goast.Expr{storage},
+					Tok: token.AND_NOT_ASSIGN,
+					Rhs: []goast.Expr{newHexLit(mask << uint(bitfield.Offset))},
+				},
+				&goast.IfStmt{
+					Cond: util.NewIdent("value"),
+					Body: &goast.BlockStmt{
+						List: []goast.Stmt{
+							&goast.AssignStmt{
+								Lhs: []goast.Expr{storage},
+								Tok: token.OR_ASSIGN,
+								Rhs: []goast.Expr{newHexLit(mask << uint(bitfield.Offset))},
+							},
+						},
+					},
+				},
+			}
+
+		default:
+			var value goast.Expr
+			if strings.HasPrefix(fieldType, "int") {
+				// Move the bits to the top and back down again so that the
+				// sign bit is extended: int32(self.c2goBitfield0<<27) >> 29
+				var shifted goast.Expr = storage
+				if left := size - bitfield.Offset - bitfield.Width; left > 0 {
+					shifted = util.NewBinaryExpr(storage, token.SHL, util.NewIntLit(left))
+				}
+
+				value = util.NewCallExpr(fmt.Sprintf("int%d", size), shifted)
+				if right := size - bitfield.Width; right > 0 {
+					value = util.NewBinaryExpr(value, token.SHR, util.NewIntLit(right))
+				}
+			} else {
+				value = storage
+				if bitfield.Offset > 0 {
+					value = util.NewBinaryExpr(value, token.SHR, util.NewIntLit(bitfield.Offset))
+				}
+				value = util.NewBinaryExpr(value, token.AND, newHexLit(mask))
+			}
+
+			getter = []goast.Stmt{
+				&goast.ReturnStmt{
+					Results: []goast.Expr{util.NewCallExpr(fieldType, value)},
+				},
+			}
+
+			var bits goast.Expr = util.NewCallExpr(storageType, util.NewIdent("value"))
+			if bitfield.Offset > 0 {
+				bits = util.NewBinaryExpr(bits, token.SHL, util.NewIntLit(bitfield.Offset))
+			}
+
+			setter = []goast.Stmt{
+				&goast.AssignStmt{
+					Lhs: []goast.Expr{storage},
+					Tok: token.ASSIGN,
+					Rhs: []goast.Expr{
+						util.NewBinaryExpr(
+							util.NewBinaryExpr(storage, token.AND_NOT, newHexLit(mask<<uint(bitfield.Offset))),
+							token.OR,
+							util.NewBinaryExpr(bits, token.AND, newHexLit(mask<<uint(bitfield.Offset))),
+						),
+					},
+				},
+			}
+		}
+
+		setter = append(setter, &goast.ReturnStmt{
+			Results: []goast.Expr{&goast.CallExpr{
+				Fun: &goast.SelectorExpr{
+					X:   util.NewIdent("self"),
+					Sel: util.NewIdent(getBitfieldMethodName("Get", fieldName)),
+				},
+			}},
+		})
+
+		decls = append(decls,
+			newBitfieldMethod(n.Name, getBitfieldMethodName("Get", fieldName),
+				nil, fieldType, getter),
+			newBitfieldMethod(n.Name, getBitfieldMethodName("Set", fieldName),
+				&goast.Field{
+					Names: []*goast.Ident{util.NewIdent("value")},
+					Type:  util.NewTypeIdent(fieldType),
+				}, fieldType, setter),
+		)
+	}
+
+	return decls
+}
+
+// newBitfieldMethod creates a method on the pointer to the struct.
+func newBitfieldMethod(structName, name string, param *goast.Field,
+	returnType string, stmts []goast.Stmt) *goast.FuncDecl {
+	params := &goast.FieldList{}
+	if param != nil {
+		params.List = []*goast.Field{param}
+	}
+
+	var results *goast.FieldList
+	if returnType != "" {
+		results = &goast.FieldList{
+			List: []*goast.Field{
+				{Type: util.NewTypeIdent(returnType)},
+			},
+		}
+	}
+
+	return &goast.FuncDecl{
+		Name: util.NewIdent(name),
+		Recv: &goast.FieldList{
+			List: []*goast.Field{
+				{
+					Names: []*goast.Ident{util.NewIdent("self")},
+					Type: &goast.StarExpr{
+						X: util.NewIdent(structName),
+					},
+				},
+			},
+		},
+		Type: &goast.FuncType{
+			Params:  params,
+			Results: results,
+		},
+		Body: &goast.BlockStmt{
+			List: stmts,
+		},
+	}
+}
+
+func newHexLit(value uint64) *goast.BasicLit {
+	return &goast.BasicLit{
+		Kind:  token.INT,
+		Value: fmt.Sprintf("0x%x", value),
+	}
+}
+
+// transpileBitfieldAssign converts an assignment to a bitfield into a call to
+// its setter. Compound assignments also use the getter:
+//
+//     s.flags = 3;     ->   s.SetFlags(3)
+//     s.flags += 2;    ->   s.SetFlags(s.GetFlags() + 2)
+//
+// The struct of a compound assignment is only evaluated once, so it is stored
+// in a temporary variable first unless it is a variable:
+//
+//     get()->flags += 2;    ->   temp := get()
+//                               temp.SetFlags(temp.GetFlags() + 2)
+func transpileBitfieldAssign(n *ast.MemberExpr, opcode string, value ast.Node,
+	p *program.Program) (goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	preStmts := []goast.Stmt{}
+	postStmts := []goast.Stmt{}
+
+	base, baseType, newPre, newPost, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+	s := p.GetStruct(baseType)
+	if s == nil || s.Bitfields[n.Name] == nil {
+		return nil, "", nil, nil,
+			fmt.Errorf("cannot find the bitfield %s in '%s'", n.Name, baseType)
+	}
+
+	if opcode != "=" && !isVariableExpr(base) {
+		// The methods are on the pointer to the struct.
+		if !strings.HasSuffix(baseType, "*") {
+			base = &goast.UnaryExpr{Op: token.AND, X: base}
+		}
+
+		tempVariableName := p.GetNextIdentifier("")
+		preStmts = append(preStmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(tempVariableName)},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{base},
+		})
+		base = util.NewIdent(tempVariableName)
+	}
+
+	right, rightType, newPre, newPost, err := transpileToExpr(value, p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+	fieldType := s.Fields[n.Name].(string)
+	method := func(prefix string, args ...goast.Expr) *goast.CallExpr {
+		return &goast.CallExpr{
+			Fun: &goast.SelectorExpr{
+				X:   base,
+				Sel: util.NewIdent(getBitfieldMethodName(prefix, n.Name)),
+			},
+			Args: args,
+		}
+	}
+
+	operator := getTokenForOperator(opcode)
+	if operator == token.ASSIGN {
+		right, err = types.CastExpr(p, right, rightType, fieldType)
+		p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+		return method("Set", right), fieldType, preStmts, postStmts, nil
+	}
+
+	// Remove the "=" so that "+=" becomes "+".
+	binaryOperator := getTokenForOperator(opcode[:len(opcode)-1])
+	if binaryOperator == token.SHL || binaryOperator == token.SHR {
+		right, err = types.CastExpr(p, right, rightType, "unsigned long long")
+	} else {
+		right, err = types.CastExpr(p, right, rightType, fieldType)
+	}
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	if right == nil {
+		return nil, "", nil, nil, errors.New("cannot cast the value for the bitfield " + n.Name)
+	}
+
+	return method("Set", util.NewBinaryExpr(method("Get"), binaryOperator, right)),
+		fieldType, preStmts, postStmts, nil
+}
+
+// isVariableExpr returns true if the expression is a variable or a field of a
+// variable, like "s.inner". It has no side effects, so it can be evaluated
+// more than once.
+func isVariableExpr(e goast.Expr) bool {
+	for {
+		switch v := e.(type) {
+		case *goast.Ident:
+			return true
+
+		case *goast.SelectorExpr:
+			e = v.X
+
+		case *goast.ParenExpr:
+			e = v.X
+
+		default:
+			return false
+		}
+	}
+}
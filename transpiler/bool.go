@@ -0,0 +1,75 @@
+// This file contains the arithmetic and bitwise operators on a bool. In C the
+// result of a comparison (or "!", "&&" and "||") is an int, and a _Bool is
+// promoted to an int before it is used by an operator. Go does not allow a bool
+// to be used by these operators, so it is converted with noarch.BoolToInt():
+//
+//     (a < b) & (c < d)    ->    noarch.BoolToInt(a < b) & noarch.BoolToInt(c < d)
+//     _Bool b; b |= x;     ->    b = noarch.BoolToInt(b)|x != 0
+
+package transpiler
+
+import (
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+	"go/token"
+)
+
+// isIntegerOperator returns true if the operator can only be used with numbers
+// in Go.
+func isIntegerOperator(operator token.Token) bool {
+	switch operator {
+	case token.ADD, token.SUB, token.MUL, token.QUO, token.REM,
+		token.AND, token.OR, token.XOR, token.SHL, token.SHR:
+		return true
+	}
+
+	return false
+}
+
+// transpileBoolOperand converts the operand of an arithmetic or bitwise
+// operator to an int if it is a bool. The second return value is the new type
+// of the operand.
+func transpileBoolOperand(n ast.Node, e goast.Expr, eType string, p *program.Program) (
+	goast.Expr, string) {
+	if goType, err := types.ResolveType(p, eType); err != nil || goType != "bool" {
+		return e, eType
+	}
+
+	e, err := types.CastExpr(p, e, eType, "int")
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	return e, "int"
+}
+
+// transpileBoolCompoundAssign transpiles a compound assignment, like "|=", to a
+// _Bool. The operation is done on ints and the result is converted back to a
+// bool. The second return value is false if the left side is not a bool.
+func transpileBoolCompoundAssign(n *ast.CompoundAssignOperator, left goast.Expr,
+	leftType string, right goast.Expr, rightType string, p *program.Program) (
+	goast.Expr, bool) {
+	if goType, err := types.ResolveType(p, leftType); err != nil || goType != "bool" {
+		return nil, false
+	}
+
+	operator := getTokenForOperator(n.Opcode[:len(n.Opcode)-1])
+
+	rightOperandType := "int"
+	if operator == token.SHL || operator == token.SHR {
+		rightOperandType = "unsigned long long"
+	}
+
+	right, err := types.CastExpr(p, right, rightType, rightOperandType)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	value, err := types.CastExpr(p, left, leftType, "int")
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	value, err = types.CastExpr(p, util.NewBinaryExpr(value, operator, right), "int", leftType)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	return util.NewBinaryExpr(left, token.ASSIGN, value), true
+}
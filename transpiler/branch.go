@@ -100,7 +100,7 @@ func transpileIfStmt(n *ast.IfStmt, p *program.Program) (
 }
 
 func transpileForStmt(n *ast.ForStmt, p *program.Program) (
-	*goast.ForStmt, []goast.Stmt, []goast.Stmt, error) {
+	goast.Stmt, []goast.Stmt, []goast.Stmt, error) {
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
 
@@ -129,6 +129,33 @@ func transpileForStmt(n *ast.ForStmt, p *program.Program) (
 		panic("non-nil child 1 in ForStmt")
 	}
 
+	// The variables declared in the init, like "for (int i = 0, j = 0; ...)",
+	// cannot be declared in the init of the Go for loop because it must be a
+	// simple statement. They are declared before the loop instead, and the
+	// loop is put in a block so the variables are still only visible inside
+	// of the loop:
+	//
+	//     {
+	//         var i int = 0
+	//         var j int = 0
+	//         for ; ...; ... {
+	//         }
+	//     }
+	p.StartScope()
+	defer p.EndScope()
+
+	var decls []goast.Stmt
+	if d, ok := children[0].(*ast.DeclStmt); ok {
+		var err error
+		var newPre, newPost []goast.Stmt
+		decls, newPre, newPost, err = transpileDeclStmt(d, p)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		decls = append(append(newPre, decls...), newPost...)
+	}
+
 	// If we have 2 and more initializations like
 	// in operator for
 	// for( a = 0, b = 0, c = 0; a < 5; a ++)
@@ -150,50 +177,50 @@ func transpileForStmt(n *ast.ForStmt, p *program.Program) (
 		}
 	}
 
-	init, newPre, newPost, err := transpileToStmt(children[0], p)
-	if err != nil {
-		return nil, nil, nil, err
-	}
+	var init goast.Stmt
+	var newPre, newPost []goast.Stmt
+	var err error
+	if decls == nil {
+		init, newPre, newPost, err = transpileToStmt(children[0], p)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 
-	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+	}
 
 	// If we have 2 and more increments
 	// in operator for
 	// for( a = 0; a < 5; a ++, b++, c+=2)
-	switch c := children[3].(type) {
-	case *ast.BinaryOperator:
-		if c.Operator == "," {
-			// recursive action to code like that:
-			// a = 0;
-			// b = 0;
-			// for(a = 0 ; a < 5 ; ){
-			// 		body
-			// 		a++;
-			// 		b++;
-			//		c+=2;
-			// }
-			//
-			var compound *ast.CompoundStmt
-			if children[4] != nil {
-				// if body is exist
-				compound = children[4].(*ast.CompoundStmt)
-			} else {
-				// if body is not exist
-				compound = new(ast.CompoundStmt)
-			}
-			compound.Children = append(compound.Children, c.Children[0:len(c.Children)]...)
-			children[4] = compound
-			children[3] = nil
+	//
+	// The increments cannot be moved to the end of the body because a
+	// "continue" would skip them. The post statement of a Go for loop has to
+	// be a single statement, so they are put inside a closure instead:
+	// for a = 0; a < 5; func() {
+	// 		a++
+	// 		b++
+	// 		c += 2
+	// }() {
+	// 		body
+	// }
+	//
+	// The same closure is used for a single increment that is transpiled into
+	// more than one Go statement. Its pre and post statements have to run on
+	// every iteration, not once before and after the loop.
+	var post goast.Stmt
+	if children[3] != nil {
+		stmts, err := transpileToStmts(children[3], p)
+		if err != nil {
+			return nil, nil, nil, err
 		}
-	}
 
-	post, newPre, newPost, err := transpileToStmt(children[3], p)
-	if err != nil {
-		return nil, nil, nil, err
+		if len(stmts) == 1 && isSimpleStmt(stmts[0]) {
+			post = stmts[0]
+		} else {
+			post = util.NewExprStmt(util.NewFuncClosure("", stmts...))
+		}
 	}
 
-	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
-
 	// If we have 2 and more conditions
 	// in operator for
 	// for( a = 0; b = c, b++, a < 5; a ++)
@@ -269,12 +296,36 @@ func transpileForStmt(n *ast.ForStmt, p *program.Program) (
 
 	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
-	return &goast.ForStmt{
+	forStmt := &goast.ForStmt{
 		Init: init,
 		Cond: condition,
 		Post: post,
 		Body: body,
-	}, preStmts, postStmts, nil
+	}
+
+	if decls != nil {
+		stmts := append(decls, preStmts...)
+		stmts = append(stmts, forStmt)
+		stmts = append(stmts, postStmts...)
+
+		return &goast.BlockStmt{List: stmts}, nil, nil, nil
+	}
+
+	return forStmt, preStmts, postStmts, nil
+}
+
+// isSimpleStmt returns true if the statement can be the post statement of a Go
+// for loop. A short variable declaration, like "temp1 := x", cannot be.
+func isSimpleStmt(stmt goast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *goast.ExprStmt, *goast.IncDecStmt, *goast.SendStmt:
+		return true
+
+	case *goast.AssignStmt:
+		return s.Tok != token.DEFINE
+	}
+
+	return false
 }
 
 // transpileWhileStmt - transpiler for operator While.
@@ -333,7 +384,7 @@ func transpileForStmt(n *ast.ForStmt, p *program.Program) (
 //    |   `-UnaryOperator 0x2530ca0 <line:13:3, col:4> 'int' postfix '--'
 //    |     `-DeclRefExpr 0x2530c78 <col:3> 'int' lvalue Var 0x25306f8 'i' 'int'
 func transpileWhileStmt(n *ast.WhileStmt, p *program.Program) (
-	*goast.ForStmt, []goast.Stmt, []goast.Stmt, error) {
+	goast.Stmt, []goast.Stmt, []goast.Stmt, error) {
 	var forOperator ast.ForStmt
 	forOperator.AddChild(nil)
 	forOperator.AddChild(nil)
@@ -343,121 +394,64 @@ func transpileWhileStmt(n *ast.WhileStmt, p *program.Program) (
 	return transpileForStmt(&forOperator, p)
 }
 
-// transpileDoStmt - transpiler for operator Do...While
-// We have only operators FOR and IF in Go, but in C we also have
-// operator DO...WHILE. So, we have to convert to operators FOR and IF.
-// We choose directly convertion  from AST C code to AST C code, for:
-// - avoid dublicate of code in realization DO...WHILE and FOR.
-// - create only one powerfull operator FOR.
-// Example of C code with operator DO...WHILE:
-//	do{
-//		printf("While: %d\n",i);
-//		i--;
-//	}while(i > 0);
-// AST for that code:
-//    |-DoStmt 0x3bb1a68 <line:7:2, line:10:14>
-//    | |-CompoundStmt 0x3bb19b8 <line:7:4, line:10:2>
-//    | | |-CallExpr 0x3bb18f0 <line:8:3, col:25> 'int'
-//    | | | |-ImplicitCastExpr 0x3bb18d8 <col:3> 'int (*)(const char *, ...)' <FunctionToPointerDecay>
-//    | | | | `-DeclRefExpr 0x3bb17e0 <col:3> 'int (const char *, ...)' Function 0x3ba4ee8 'printf' 'int (const char *, ...)'
-//    | | | |-ImplicitCastExpr 0x3bb1940 <col:10> 'const char *' <BitCast>
-//    | | | | `-ImplicitCastExpr 0x3bb1928 <col:10> 'char *' <ArrayToPointerDecay>
-//    | | | |   `-StringLiteral 0x3bb1848 <col:10> 'char [11]' lvalue "While: %d\n"
-//    | | | `-ImplicitCastExpr 0x3bb1958 <col:24> 'int' <LValueToRValue>
-//    | | |   `-DeclRefExpr 0x3bb1880 <col:24> 'int' lvalue Var 0x3bb16f8 'i' 'int'
-//    | | `-UnaryOperator 0x3bb1998 <line:9:3, col:4> 'int' postfix '--'
-//    | |   `-DeclRefExpr 0x3bb1970 <col:3> 'int' lvalue Var 0x3bb16f8 'i' 'int'
-//    | `-BinaryOperator 0x3bb1a40 <line:10:9, col:13> 'int' '>'
-//    |   |-ImplicitCastExpr 0x3bb1a28 <col:9> 'int' <LValueToRValue>
-//    |   | `-DeclRefExpr 0x3bb19e0 <col:9> 'int' lvalue Var 0x3bb16f8 'i' 'int'
-//    |   `-IntegerLiteral 0x3bb1a08 <col:13> 'int' 0
+// transpileDoStmt transpiles a do...while loop. The body of the loop is always
+// run at least once, so the condition is skipped only for the first iteration:
 //
-// Example of C code with operator FOR:
-//	for(;;){
-//		printf("For: %d\n",i);
-//		i--;
-//		if(!(i>0)){
-//			break;
-//		}
-//	}
-// AST for that code:
-//    |-ForStmt 0x3bb1e08 <line:12:2, line:18:2>
-//    | |-<<<NULL>>>
-//    | |-<<<NULL>>>
-//    | |-<<<NULL>>>
-//    | |-<<<NULL>>>
-//    | `-CompoundStmt 0x3bb1dd8 <line:12:9, line:18:2>
-//    |   |-CallExpr 0x3bb1bc8 <line:13:3, col:23> 'int'
-//    |   | |-ImplicitCastExpr 0x3bb1bb0 <col:3> 'int (*)(const char *, ...)' <FunctionToPointerDecay>
-//    |   | | `-DeclRefExpr 0x3bb1af8 <col:3> 'int (const char *, ...)' Function 0x3ba4ee8 'printf' 'int (const char *, ...)'
-//    |   | |-ImplicitCastExpr 0x3bb1c18 <col:10> 'const char *' <BitCast>
-//    |   | | `-ImplicitCastExpr 0x3bb1c00 <col:10> 'char *' <ArrayToPointerDecay>
-//    |   | |   `-StringLiteral 0x3bb1b58 <col:10> 'char [9]' lvalue "For: %d\n"
-//    |   | `-ImplicitCastExpr 0x3bb1c30 <col:22> 'int' <LValueToRValue>
-//    |   |   `-DeclRefExpr 0x3bb1b88 <col:22> 'int' lvalue Var 0x3bb16f8 'i' 'int'
-//    |   |-UnaryOperator 0x3bb1c70 <line:14:3, col:4> 'int' postfix '--'
-//    |   | `-DeclRefExpr 0x3bb1c48 <col:3> 'int' lvalue Var 0x3bb16f8 'i' 'int'
-//    |   `-IfStmt 0x3bb1da8 <line:15:3, line:17:3>
-//    |     |-<<<NULL>>>
-//    |     |-UnaryOperator 0x3bb1d60 <line:15:6, col:11> 'int' prefix '!'
-//    |     | `-ParenExpr 0x3bb1d40 <col:7, col:11> 'int'
-//    |     |   `-BinaryOperator 0x3bb1d18 <col:8, col:10> 'int' '>'
-//    |     |     |-ImplicitCastExpr 0x3bb1d00 <col:8> 'int' <LValueToRValue>
-//    |     |     | `-DeclRefExpr 0x3bb1c90 <col:8> 'int' lvalue Var 0x3bb16f8 'i' 'int'
-//    |     |     `-IntegerLiteral 0x3bb1ce0 <col:10> 'int' 0
-//    |     |-CompoundStmt 0x3bb1d88 <col:13, line:17:3>
-//    |     | `-BreakStmt 0x3bb1d80 <line:16:4>
-//    |     `-<<<NULL>>>
+//     do {
+//         i++;
+//         if (i < 3) continue;
+//         printf("%d\n", i);
+//     } while (i < 5);
+//
+// Becomes:
+//
+//     for first1 := true; first1 || i < 5; first1 = false {
+//         i++
+//         if i < 3 {
+//             continue
+//         }
+//         fmt.Printf("%d\n", i)
+//     }
+//
+// Putting the condition at the end of the body (as "if !cond { break }") does
+// not work because a "continue" in the body would skip it.
 func transpileDoStmt(n *ast.DoStmt, p *program.Program) (
 	*goast.ForStmt, []goast.Stmt, []goast.Stmt, error) {
-	var forOperator ast.ForStmt
-	forOperator.AddChild(nil)
-	forOperator.AddChild(nil)
-	forOperator.AddChild(nil)
-	forOperator.AddChild(nil)
-	c := n.Children[0].(*ast.CompoundStmt)
-	ifBreak := createIfWithNotConditionAndBreak(n.Children[1])
-	c.AddChild(&ifBreak)
-	forOperator.AddChild(c)
-	return transpileForStmt(&forOperator, p)
-}
+	body, preStmts, postStmts, err := transpileToBlockStmt(n.Children[0], p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-// createIfWithNotConditionAndBreak - create operator IF like on next example
-// of C code:
-// if ( !(condition) ) {
-//		break;
-// }
-// Example of AST tree:
-//  `-IfStmt 0x3bb1da8 <line:15:3, line:17:3>
-//    |-<<<NULL>>>
-//    |-UnaryOperator 0x3bb1d60 <line:15:6, col:11> 'int' prefix '!'
-//    | `-ParenExpr 0x3bb1d40 <col:7, col:11> 'int'
-//    |   `- CONDITION
-//    |-CompoundStmt 0x3bb1d88 <col:13, line:17:3>
-//    | `-BreakStmt 0x3bb1d80 <line:16:4>
-//    `-<<<NULL>>>
-func createIfWithNotConditionAndBreak(condition ast.Node) (ifStmt ast.IfStmt) {
-
-	ifStmt.AddChild(nil)
-
-	var par ast.ParenExpr
-	var unitary ast.UnaryOperator
-	switch con := condition.(type) {
-	case *ast.BinaryOperator:
-		par.Type = con.Type
-		unitary.Type = con.Type
+	// The condition is evaluated many times, so any side effects it has must
+	// stay inside of it rather than becoming pre or post statements.
+	stmts, err := transpileConditionalOperatorBranch(n.Children[1], "bool", "bool", p)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	par.AddChild(condition)
-	unitary.Operator = "!"
-	unitary.AddChild(&par)
 
-	ifStmt.AddChild(&unitary)
-	var c ast.CompoundStmt
-	c.AddChild(&ast.BreakStmt{})
-	ifStmt.AddChild(&c)
-	ifStmt.AddChild(nil)
+	var condition goast.Expr
+	if ret, ok := stmts[0].(*goast.ReturnStmt); ok && len(stmts) == 1 {
+		condition = ret.Results[0]
+	} else {
+		condition = util.NewFuncClosure("bool", stmts...)
+	}
+
+	first := util.NewIdent(p.GetNextIdentifier("first"))
 
-	return
+	return &goast.ForStmt{
+		Init: &goast.AssignStmt{
+			Lhs: []goast.Expr{first},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{util.NewIdent("true")},
+		},
+		Cond: util.NewBinaryExpr(first, token.LOR, condition),
+		Post: &goast.AssignStmt{
+			Lhs: []goast.Expr{first},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{util.NewIdent("false")},
+		},
+		Body: body,
+	}, preStmts, postStmts, nil
 }
 
 func transpileContinueStmt(n *ast.ContinueStmt, p *program.Program) (*goast.BranchStmt, error) {
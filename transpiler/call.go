@@ -27,6 +27,16 @@ func getName(firstChild ast.Node) string {
 	case *ast.ParenExpr:
 		return getName(fc.Children[0])
 
+	// A function pointer may be dereferenced, like "(*fp)(1)", or be an
+	// element of an array.
+	case *ast.ImplicitCastExpr, *ast.UnaryOperator, *ast.ArraySubscriptExpr:
+		return getName(getChildren(fc)[0])
+
+	// The function of a _Generic() selection depends on the types, see
+	// getGenericSelectionCall.
+	case *ast.GenericSelectionExpr:
+		return ""
+
 	default:
 		panic(fmt.Sprintf("cannot CallExpr on: %#v", fc))
 	}
@@ -35,13 +45,48 @@ func getName(firstChild ast.Node) string {
 func getNameOfFunctionFromCallExpr(n *ast.CallExpr) (string, error) {
 	// The first child will always contain the name of the function being
 	// called.
-	firstChild, ok := n.Children[0].(*ast.ImplicitCastExpr)
-	if !ok {
-		err := fmt.Errorf("unable to use CallExpr: %#v", n.Children[0])
-		return "", err
+	switch firstChild := n.Children[0].(type) {
+	case *ast.ImplicitCastExpr:
+		return getName(firstChild.Children[0]), nil
+	case *ast.ParenExpr:
+		return getName(firstChild), nil
 	}
 
-	return getName(firstChild.Children[0]), nil
+	err := fmt.Errorf("unable to use CallExpr: %#v", n.Children[0])
+	return "", err
+}
+
+// getFunctionPointerCallee returns the expression of the function pointer that
+// is called, like the variable "fp" for "(*fp)(1)". It returns nil if a
+// function is called directly.
+func getFunctionPointerCallee(n *ast.CallExpr) ast.Node {
+	callee := n.Children[0]
+	for {
+		switch c := callee.(type) {
+		case *ast.ImplicitCastExpr:
+			if c.Kind == "FunctionToPointerDecay" || c.Kind == "BuiltinFnToFnPtr" {
+				if ref, ok := removeCastsAndParens(c.Children[0]).(*ast.DeclRefExpr); ok &&
+					ref.For == "Function" {
+					return nil
+				}
+			}
+			callee = c.Children[0]
+		case *ast.ParenExpr:
+			callee = c.Children[0]
+		case *ast.UnaryOperator:
+			if c.Operator != "*" {
+				return callee
+			}
+			callee = c.Children[0]
+		case *ast.DeclRefExpr:
+			if c.For == "Function" {
+				return nil
+			}
+			return callee
+		default:
+			return callee
+		}
+	}
 }
 
 // transpileCallExpr transpiles expressions that calls a function, for example:
@@ -52,15 +97,75 @@ func getNameOfFunctionFromCallExpr(n *ast.CallExpr) (string, error) {
 // returned by the function) and any error. If there is an error returned you
 // can assume the first two arguments will not contain any useful information.
 func transpileCallExpr(n *ast.CallExpr, p *program.Program) (
-	*goast.CallExpr, string, []goast.Stmt, []goast.Stmt, error) {
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
 
+	// The function of a type-generic macro is the one that is selected.
+	call, err := getGenericSelectionCall(n, p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+	if call != nil {
+		return transpileCallExpr(call, p)
+	}
+
 	functionName, err := getNameOfFunctionFromCallExpr(n)
 	if err != nil {
 		return nil, "", nil, nil, err
 	}
 
+	// The va_list macros are builtins that do not have a definition.
+	if isVaListBuiltin(functionName) {
+		return transpileVaListBuiltin(n, functionName, p)
+	}
+
+	// Go does not have branch prediction hints, so the likely() and unlikely()
+	// macros are only the condition. That is, the first argument of
+	// __builtin_expect(). The expected value (and the probability) is ignored.
+	if functionName == "__builtin_expect" ||
+		functionName == "__builtin_expect_with_probability" {
+		return transpileToExpr(n.Children[1], p)
+	}
+
+	// The argument of __builtin_constant_p() may be a constant that is folded
+	// by GCC but it is never known to be a constant here. Any side effects of
+	// the argument are not evaluated.
+	if functionName == "__builtin_constant_p" {
+		return util.NewIdent("false"), "bool", preStmts, postStmts, nil
+	}
+
+	// The type of __builtin_complex() depends on the type of its arguments.
+	if functionName == "__builtin_complex" {
+		return transpileBuiltinComplex(n, p)
+	}
+
+	// A function pointer is called with its value, which is a Go func. The
+	// types of the arguments and the return value are from the type of the
+	// pointer.
+	if callee := getFunctionPointerCallee(n); callee != nil {
+		e, eType, newPre, newPost, err := transpileToExpr(callee, p)
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+
+		if functionDef := program.GetFunctionPointerDefinition(functionName, eType); functionDef != nil {
+			preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+			args, argTypes, newPre, newPost, err := transpileCallArguments(n, p)
+			if err != nil {
+				return nil, "", nil, nil, err
+			}
+
+			preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+			args = castCallArguments(n, args, argTypes, functionDef, p)
+
+			return &goast.CallExpr{Fun: e, Args: args}, functionDef.ReturnType,
+				preStmts, postStmts, nil
+		}
+	}
+
 	// Get the function definition from it's name. The case where it is not
 	// defined is handled below (we haven't seen the prototype yet).
 	functionDef := program.GetFunctionDefinition(functionName)
@@ -82,27 +187,62 @@ func transpileCallExpr(n *ast.CallExpr, p *program.Program) (
 		}
 	}
 
+	// A function that has been replaced with an existing Go function.
+	if goFunction, ok := p.GetFunctionMapping(functionName); ok {
+		mapped := *functionDef
+		mapped.Substitution = goFunction
+		functionDef = &mapped
+	}
+
+	// The function is generated as a stub later if it is never defined. A
+	// function pointer is called by the name of the variable, so it is not a
+	// function that can be stubbed.
+	if p.Stubs && functionDef.Substitution == "" {
+		if ref, ok := removeCastsAndParens(n.Children[0]).(*ast.DeclRefExpr); ok && ref.For == "Function" {
+			p.AddUnresolvedFunction(functionName,
+				getUnresolvedFunctionType(n, functionDef, p))
+		}
+	}
+
 	if functionDef.Substitution != "" {
+		// A Go builtin function, like "real", does not need to be imported.
 		parts := strings.Split(functionDef.Substitution, ".")
-		importName := strings.Join(parts[:len(parts)-1], ".")
-		p.AddImport(importName)
+		if len(parts) > 1 {
+			importName := strings.Join(parts[:len(parts)-1], ".")
+			p.AddImport(importName)
+		}
 
 		parts2 := strings.Split(functionDef.Substitution, "/")
 		functionName = parts2[len(parts2)-1]
+	} else {
+		functionName = p.GetFunctionName(functionName)
 	}
 
-	args := []goast.Expr{}
-	argTypes := []string{}
-	i := 0
-	for _, arg := range n.Children[1:] {
-		e, eType, newPre, newPost, err := transpileToExpr(arg, p)
-		if err != nil {
-			return nil, "unknown2", nil, nil, err
-		}
-		argTypes = append(argTypes, eType)
+	// A function that sets errno is called through a wrapper that is
+	// generated later, so that Go code can get errno as an error. The wrapper
+	// has the same parameters, so it cannot be generated for a substitution
+	// that rearranges them.
+	errnoWrapper := ""
+	if p.ErrnoFunctions[functionDef.Name] &&
+		functionDef.Parameters == nil && functionDef.ReturnParameters == nil {
+		errnoWrapper = getErrnoWrapperName(functionDef.Name, p)
+		p.AddErrnoFunction(program.ErrnoFunction{
+			Name:       functionDef.Name,
+			GoFunction: functionName,
+			Signature:  getUnresolvedFunctionType(n, functionDef, p),
+			Wrapper:    errnoWrapper,
+		})
+	}
 
-		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+	args, argTypes, newPre, newPost, err := transpileCallArguments(n, p)
+	if err != nil {
+		return nil, "unknown2", nil, nil, err
+	}
 
+	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+	for i, e := range args {
+		eType := argTypes[i]
 		_, arraySize := types.GetArrayTypeAndSize(eType)
 
 		// If we are using varargs with Printf we need to make sure that certain
@@ -160,9 +300,7 @@ func transpileCallExpr(n *ast.CallExpr, p *program.Program) (
 			}
 		}
 
-		args = append(args, e)
-
-		i++
+		args[i] = e
 	}
 
 	// These are the arguments once any transformations have taken place.
@@ -209,23 +347,163 @@ func transpileCallExpr(n *ast.CallExpr, p *program.Program) (
 	} else {
 		// Keep all the arguments the same. But make sure we cast to the correct
 		// types.
-		for i, a := range args {
-			if i > len(functionDef.ArgumentTypes)-1 {
-				// This means the argument is one of the varargs so we don't
-				// know what type it needs to be cast to.
-			} else {
-				a, err = types.CastExpr(p, a, argTypes[i],
-					functionDef.ArgumentTypes[i])
+		realArgs = castCallArguments(n, args, argTypes, functionDef, p)
+	}
 
-				if p.AddMessage(ast.GenerateWarningMessage(err, n)) {
-					a = util.NewNil()
-				}
+	if errnoWrapper != "" {
+		functionName = errnoWrapper
+	}
+
+	return util.NewCallExpr(functionName, realArgs...),
+		functionDef.ReturnType, preStmts, postStmts, nil
+}
+
+// transpileCallArguments transpiles the arguments of a call. The C types of
+// the arguments are returned so that they can be cast to the types of the
+// parameters (see castCallArguments).
+func transpileCallArguments(n *ast.CallExpr, p *program.Program) (
+	[]goast.Expr, []string, []goast.Stmt, []goast.Stmt, error) {
+	preStmts := []goast.Stmt{}
+	postStmts := []goast.Stmt{}
+
+	args := []goast.Expr{}
+	argTypes := []string{}
+	for _, arg := range n.Children[1:] {
+		e, eType, newPre, newPost, err := transpileToExpr(arg, p)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+		args = append(args, e)
+		argTypes = append(argTypes, eType)
+	}
+
+	return args, argTypes, preStmts, postStmts, nil
+}
+
+// castCallArguments casts the arguments of a call to the types of the
+// parameters. The arguments that are varargs are not cast.
+func castCallArguments(n *ast.CallExpr, args []goast.Expr, argTypes []string,
+	functionDef *program.FunctionDefinition, p *program.Program) []goast.Expr {
+	realArgs := []goast.Expr{}
+	for i, a := range args {
+		if i < len(functionDef.ArgumentTypes) {
+			var err error
+			a, err = types.CastExpr(p, a, argTypes[i], functionDef.ArgumentTypes[i])
+			if p.AddMessage(ast.GenerateWarningMessage(err, n)) {
+				a = util.NewNil()
 			}
+		}
+
+		realArgs = append(realArgs, a)
+	}
+
+	return realArgs
+}
+
+// transpileBuiltinComplex transpiles __builtin_complex(re, im), which is used
+// by the CMPLX() macros, into complex(re, im). Both of the parts are cast to
+// the type of the parts of the complex type that is returned.
+func transpileBuiltinComplex(n *ast.CallExpr, p *program.Program) (
+	*goast.CallExpr, string, []goast.Stmt, []goast.Stmt, error) {
+	preStmts := []goast.Stmt{}
+	postStmts := []goast.Stmt{}
+	partType := strings.TrimPrefix(n.Type, "_Complex ")
 
-			realArgs = append(realArgs, a)
+	args := []goast.Expr{}
+	for _, arg := range n.Children[1:] {
+		e, eType, newPre, newPost, err := transpileToExpr(arg, p)
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+		e, err = types.CastExpr(p, e, eType, partType)
+		if err != nil {
+			return nil, "", nil, nil, err
 		}
+
+		args = append(args, e)
 	}
 
-	return util.NewCallExpr(functionName, realArgs...),
-		functionDef.ReturnType, preStmts, postStmts, nil
+	return util.NewCallExpr("complex", args...), n.Type, preStmts, postStmts, nil
+}
+
+// transpileTypeTraitExpr transpiles __builtin_types_compatible_p(), which is
+// used by macros to check the type of an argument. It is true if both of the
+// types are the same C type. The top level qualifiers (like const) and the
+// typedefs are ignored, the same as GCC. Types that are the same in Go are not
+// always compatible, like "char" and "unsigned char".
+func transpileTypeTraitExpr(n *ast.TypeTraitExpr, p *program.Program) (
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	if n.Trait != "__builtin_types_compatible_p" || len(n.Children) != 2 {
+		return nil, "", nil, nil,
+			fmt.Errorf("cannot transpile the type trait %s", n.Trait)
+	}
+
+	cTypes := []string{}
+	for _, c := range n.Children {
+		cType := getTypeOfTypeNode(c)
+		if cType == "" {
+			return nil, "", nil, nil,
+				fmt.Errorf("cannot find the type of %T in %s", c, n.Trait)
+		}
+
+		cTypes = append(cTypes, cType)
+	}
+
+	return util.NewIdent(fmt.Sprintf("%t", cTypes[0] == cTypes[1])), "bool",
+		nil, nil, nil
+}
+
+// getTypeOfTypeNode returns the C type of a type node without the typedefs,
+// the parenthesis and the qualifiers.
+func getTypeOfTypeNode(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.TypedefType:
+		if len(n.Children) > 0 {
+			return getTypeOfTypeNode(n.Children[len(n.Children)-1])
+		}
+		return n.Type
+	case *ast.ElaboratedType:
+		if len(n.Children) > 0 {
+			return getTypeOfTypeNode(n.Children[0])
+		}
+		return n.Type
+	case *ast.ParenType:
+		return getTypeOfTypeNode(n.Children[0])
+	case *ast.QualType:
+		return getTypeOfTypeNode(n.Children[0])
+	case *ast.BuiltinType:
+		return n.Type
+	case *ast.PointerType:
+		if len(n.Children) > 0 {
+			return getPointeeTypeOfTypeNode(n.Children[0]) + " *"
+		}
+		return n.Type
+	case *ast.RecordType:
+		return n.Type
+	case *ast.EnumType:
+		return n.Name
+	case *ast.ConstantArrayType:
+		return n.Type
+	case *ast.IncompleteArrayType:
+		return n.Type
+	}
+
+	return ""
+}
+
+// getPointeeTypeOfTypeNode returns the C type that a pointer points to, like
+// getTypeOfTypeNode. The qualifiers are kept because "const char *" is not the
+// same type as "char *".
+func getPointeeTypeOfTypeNode(node ast.Node) string {
+	if n, ok := node.(*ast.QualType); ok && len(n.Children) > 0 {
+		return n.Kind + " " + getTypeOfTypeNode(n.Children[0])
+	}
+
+	return getTypeOfTypeNode(node)
 }
@@ -0,0 +1,133 @@
+package transpiler
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func TestFunctionPointers(t *testing.T) {
+	twice := &ast.DeclRefExpr{For: "Function", Name: "twice", Type: "int (int)"}
+	fp := newRValue("int (*)(int)", newVarRef("fp", "int (*)(int)"))
+
+	tests := []struct {
+		name string
+		node ast.Node
+		out  string
+	}{
+		{"address of a function", &ast.BinaryOperator{Type: "int (*)(int)", Operator: "=", Children: []ast.Node{
+			newVarRef("fp", "int (*)(int)"),
+			&ast.UnaryOperator{Type: "int (*)(int)", Operator: "&", IsPrefix: true, Children: []ast.Node{twice}},
+		}}, "fp = twice"},
+		{"function designator", &ast.BinaryOperator{Type: "int (*)(int)", Operator: "=", Children: []ast.Node{
+			newVarRef("fp", "int (*)(int)"),
+			&ast.ImplicitCastExpr{Kind: "FunctionToPointerDecay", Type: "int (*)(int)", Children: []ast.Node{twice}},
+		}}, "fp = twice"},
+		{"call", &ast.CallExpr{Type: "int", Children: []ast.Node{
+			fp, &ast.CharacterLiteral{Type: "char", Value: 'a'},
+		}}, "fp(int('a'))"},
+		{"dereferenced call", &ast.CallExpr{Type: "int", Children: []ast.Node{
+			&ast.ParenExpr{Type: "int (int)", Children: []ast.Node{
+				&ast.UnaryOperator{Type: "int (int)", Operator: "*", IsPrefix: true, Children: []ast.Node{fp}},
+			}},
+			&ast.IntegerLiteral{Type: "int", Value: "3"},
+		}}, "fp(3)"},
+	}
+
+	for _, tt := range tests {
+		p := program.NewProgram()
+
+		expr, _, _, _, err := transpileToExpr(tt.node, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, expr); got != tt.out {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.out, got)
+		}
+	}
+}
+
+func TestLongDouble(t *testing.T) {
+	p := program.NewProgram()
+	p.Function = &ast.FunctionDecl{Name: "f"}
+
+	x := func() ast.Node {
+		return newRValue("long double", newVarRef("x", "long double"))
+	}
+
+	// long double y = sqrtl(x * x) + powl(x, 2.0L) / 3;
+	n := &ast.DeclStmt{Children: []ast.Node{
+		&ast.VarDecl{Name: "y", Type: "long double", Children: []ast.Node{
+			&ast.BinaryOperator{Type: "long double", Operator: "+", Children: []ast.Node{
+				newCall("long double (long double)", "sqrtl", &ast.BinaryOperator{
+					Type: "long double", Operator: "*", Children: []ast.Node{x(), x()},
+				}),
+				&ast.BinaryOperator{Type: "long double", Operator: "/", Children: []ast.Node{
+					newCall("long double (long double, long double)", "powl", x(), &ast.FloatingLiteral{Type: "long double", Value: 2}),
+					&ast.ImplicitCastExpr{Kind: "IntegralToFloating", Type: "long double", Children: []ast.Node{
+						&ast.IntegerLiteral{Type: "int", Value: "3"},
+					}},
+				}},
+			}},
+		}},
+	}}
+
+	stmts, _, _, err := transpileDeclStmt(n, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "var y float64 = math.Sqrt(x*x) + math.Pow(x, 2)/3"
+	if got := renderNode(t, stmts[0]); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestBitCountingBuiltins(t *testing.T) {
+	tests := []struct {
+		name     string
+		argType  string
+		expected string
+	}{
+		{"__builtin_clz", "unsigned int", "bits.LeadingZeros32(x)"},
+		{"__builtin_clzl", "unsigned long", "bits.LeadingZeros64(uint64(x))"},
+		{"__builtin_clzll", "unsigned long long", "bits.LeadingZeros64(x)"},
+		{"__builtin_ctz", "unsigned int", "bits.TrailingZeros32(x)"},
+		{"__builtin_ctzl", "unsigned long", "bits.TrailingZeros64(uint64(x))"},
+		{"__builtin_ctzll", "unsigned long long", "bits.TrailingZeros64(x)"},
+		{"__builtin_popcount", "unsigned int", "bits.OnesCount32(x)"},
+		{"__builtin_popcountl", "unsigned long", "bits.OnesCount64(uint64(x))"},
+		{"__builtin_popcountll", "unsigned long long", "bits.OnesCount64(x)"},
+
+		// The argument is converted to the unsigned type.
+		{"__builtin_popcount", "int", "bits.OnesCount32(uint32(x))"},
+	}
+
+	for _, tt := range tests {
+		p := program.NewProgram()
+
+		cType := "int (" + tt.argType + ")"
+		n := &ast.CallExpr{Type: "int", Children: []ast.Node{
+			&ast.ImplicitCastExpr{Kind: "BuiltinFnToFnPtr", Type: "int (*)(" + tt.argType + ")", Children: []ast.Node{
+				&ast.DeclRefExpr{For: "Function", Name: tt.name, Type: cType},
+			}},
+			newRValue(tt.argType, newVarRef("x", tt.argType)),
+		}}
+
+		expr, exprType, _, _, err := transpileToExpr(n, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, expr); got != tt.expected || exprType != "int" {
+			t.Errorf("%s(%s): expected %s (int), got %s (%s)", tt.name, tt.argType,
+				tt.expected, got, exprType)
+		}
+
+		if imports := p.Imports(); len(imports) != 1 || imports[0] != `"math/bits"` {
+			t.Errorf("%s: expected to import math/bits, got %v", tt.name, imports)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// This file contains the removal of the conversions that do not change the
+// value. types.CastExpr converts every expression that it is given, so the Go
+// code may convert a value to the type that it already has:
+//
+//     int(int(x))        ->    int(x)
+//     []byte([]byte(s))  ->    []byte(s)
+//     f(float64(1.5))    ->    f(1.5)
+//
+// A conversion to a different type, like "int(int8(x))", truncates the value so
+// it is always kept. The exception is a float64 that is converted to a
+// float32: "float32(float64(x))" rounds x twice, so it is converted directly
+// to "float32(x)".
+
+package transpiler
+
+import (
+	"go/token"
+
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// removeRedundantCasts removes the conversions of the Go file that do not
+// change the value (see the top of this file).
+func removeRedundantCasts(p *program.Program) {
+	for _, decl := range p.File.Decls {
+		removeRedundantConversions(decl)
+	}
+}
+
+// removeRedundantConversions removes the conversions that do not change the
+// value in node.
+//
+// A conversion of a constant to its default type is only removed if the
+// constant is not an operand. The result of an untyped constant expression can
+// be different, like "int(5) / 2.0" which is 2 but "5 / 2.0" is 2.5.
+func removeRedundantConversions(node goast.Node) {
+	goast.Inspect(node, func(node goast.Node) bool {
+		switch v := node.(type) {
+		case *goast.CallExpr:
+			// A conversion of a conversion to the same type, like
+			// "int(int(x))", is the same as the inner conversion.
+			if t := getConversionType(v); t != "" {
+				for {
+					inner, ok := removeParens(v.Args[0]).(*goast.CallExpr)
+					if !ok || !isRedundantConversion(t, getConversionType(inner)) {
+						break
+					}
+					v.Args[0] = inner.Args[0]
+				}
+			}
+
+			for i, arg := range v.Args {
+				v.Args[i] = removeConstantConversion(arg)
+			}
+
+		case *goast.AssignStmt:
+			for i, e := range v.Rhs {
+				v.Rhs[i] = removeConstantConversion(e)
+			}
+
+		case *goast.ValueSpec:
+			for i, e := range v.Values {
+				v.Values[i] = removeConstantConversion(e)
+			}
+
+		case *goast.ReturnStmt:
+			for i, e := range v.Results {
+				v.Results[i] = removeConstantConversion(e)
+			}
+
+		case *goast.CompositeLit:
+			for i, e := range v.Elts {
+				v.Elts[i] = removeConstantConversion(e)
+			}
+
+		case *goast.KeyValueExpr:
+			v.Value = removeConstantConversion(v.Value)
+		}
+
+		return true
+	})
+}
+
+// narrowingFloatTypes are the conversions of a floating-point value that round
+// it. The conversion to the wider type before it only rounds the value twice.
+var narrowingFloatTypes = map[string]string{
+	"float32":   "float64",
+	"complex64": "complex128",
+}
+
+// isRedundantConversion returns true if the conversion to inner can be removed
+// from a conversion to outer of it.
+func isRedundantConversion(outer, inner string) bool {
+	return inner != "" && (inner == outer || inner == narrowingFloatTypes[outer])
+}
+
+// literalTypes are the default types of the constants. The constant is
+// already of that type, so "float64(1.5)" is the same as "1.5".
+var literalTypes = map[token.Token]string{
+	token.INT:    "int",
+	token.FLOAT:  "float64",
+	token.IMAG:   "complex128",
+	token.CHAR:   "rune",
+	token.STRING: "string",
+}
+
+// removeConstantConversion returns the constant of e if e converts it to its
+// default type. Otherwise e is returned.
+func removeConstantConversion(e goast.Expr) goast.Expr {
+	call, ok := e.(*goast.CallExpr)
+	if !ok {
+		return e
+	}
+
+	t := getConversionType(call)
+	if t == "" {
+		return e
+	}
+
+	literal, ok := removeParens(call.Args[0]).(*goast.BasicLit)
+	if !ok {
+		return e
+	}
+
+	if t == literalTypes[literal.Kind] ||
+		(t == "int32" && literal.Kind == token.CHAR) {
+		return literal
+	}
+
+	return e
+}
+
+// goBasicTypes are the names of the Go types that a conversion can be to. The
+// conversion to another name may be a function call that must not be removed.
+var goBasicTypes = []string{
+	"bool", "byte", "rune", "string", "uintptr",
+	"int", "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64",
+	"float32", "float64", "complex64", "complex128",
+}
+
+// getConversionType returns the Go type that call converts its argument to, or
+// an empty string if call is not a conversion of a basic type (or a slice or
+// pointer of one).
+func getConversionType(call *goast.CallExpr) string {
+	if len(call.Args) != 1 || call.Ellipsis.IsValid() {
+		return ""
+	}
+
+	return getBasicTypeName(call.Fun)
+}
+
+// getBasicTypeName returns the name of the Go type of e, like "[]byte". An
+// empty string is returned if e is not a basic type (see goBasicTypes) or a
+// slice or pointer of one.
+func getBasicTypeName(e goast.Expr) string {
+	switch v := e.(type) {
+	case *goast.Ident:
+		// A C function can have the name of a Go type.
+		if !util.InStrings(v.Name, goBasicTypes) ||
+			program.GetFunctionDefinition(v.Name) != nil {
+			return ""
+		}
+		return v.Name
+
+	case *goast.ArrayType:
+		if v.Len != nil {
+			return ""
+		}
+		if elt := getBasicTypeName(v.Elt); elt != "" {
+			return "[]" + elt
+		}
+
+	case *goast.StarExpr:
+		if x := getBasicTypeName(v.X); x != "" {
+			return "*" + x
+		}
+
+	case *goast.ParenExpr:
+		if x := getBasicTypeName(v.X); x != "" {
+			return "(" + x + ")"
+		}
+	}
+
+	return ""
+}
+
+// removeParens returns the expression inside the parentheses of e.
+func removeParens(e goast.Expr) goast.Expr {
+	for {
+		paren, ok := e.(*goast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = paren.X
+	}
+}
@@ -0,0 +1,57 @@
+package transpiler
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	goast "go/ast"
+)
+
+func TestRemoveRedundantConversions(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"a = int(int(x))", "a = int(x)"},
+		{"a = int((int(x)))", "a = int(x)"},
+		{"a = []byte([]byte(s))", "a = []byte(s)"},
+		{"a = (*int)((*int)(p))", "a = (*int)(p)"},
+		{"a = f(float64(1.5), int(3), string(\"x\"), rune('a'))", "a = f(1.5, 3, \"x\", 'a')"},
+		{"return int(5)", "return 5"},
+		{"a = []int{int(1), 2: int(3)}", "a = []int{1, 2: 3}"},
+		{"a = float64(int(5))", "a = float64(5)"},
+		{"a = float32(float64(c))", "a = float32(c)"},
+		{"a = complex64(complex128(c))", "a = complex64(c)"},
+
+		// These conversions change the value: truncation, or an untyped
+		// constant expression, like "5 / 2.0" which is 2.5.
+		{"a = int(int8(x))", "a = int(int8(x))"},
+		{"a = int8(int8(x) + int8(y))", "a = int8(int8(x) + int8(y))"},
+		{"a = int(5) / 2.0", "a = int(5) / 2.0"},
+		{"a = int(1) << s", "a = int(1) << s"},
+		{"a = float32(1.5)", "a = float32(1.5)"},
+		{"a = int32(int64(x))", "a = int32(int64(x))"},
+		{"a = float64(float32(x))", "a = float64(float32(x))"},
+
+		// A function call is not a conversion.
+		{"a = f(f(x))", "a = f(f(x))"},
+		{"a = int(f(x))", "a = int(f(x))"},
+		{"a = noarch.Abs(noarch.Abs(x))", "a = noarch.Abs(noarch.Abs(x))"},
+	}
+
+	for _, tt := range tests {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "", "package main\nfunc main() {\n"+tt.in+"\n}", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		removeRedundantConversions(f)
+
+		stmt := f.Decls[0].(*goast.FuncDecl).Body.List[0]
+		if got := renderNode(t, stmt); got != tt.out {
+			t.Errorf("expected %s, got %s", tt.out, got)
+		}
+	}
+}
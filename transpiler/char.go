@@ -0,0 +1,65 @@
+// This file contains the reads and writes of the elements of a char pointer or
+// array when a plain char is signed (the -signed-char option). A char is an
+// int8 but a char pointer or array is still a []byte so that it can be used as
+// a C string. So an element is converted to an int8 when it is read and back
+// into a byte when it is written:
+//
+//     char c = s[0];    ->    var c int8 = int8(s[0])
+//     s[1] = c;         ->    s[1] = byte(c)
+
+package transpiler
+
+import (
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// isSignedCharElement returns true if the Go expression is an element of a
+// []byte that has the C type of a signed char.
+func isSignedCharElement(e goast.Expr, cType string, p *program.Program) bool {
+	if !types.IsSignedChar(p, cType) {
+		return false
+	}
+
+	for {
+		switch v := e.(type) {
+		case *goast.IndexExpr:
+			return true
+		case *goast.ParenExpr:
+			e = v.X
+		default:
+			return false
+		}
+	}
+}
+
+// transpileSignedCharLoad returns the expression that reads the lvalue e with
+// the C type. It is the same expression unless e is an element of a []byte
+// that is a signed char.
+func transpileSignedCharLoad(e goast.Expr, cType string, p *program.Program) goast.Expr {
+	if !isSignedCharElement(e, cType, p) {
+		return e
+	}
+
+	return util.NewCallExpr("int8", e)
+}
+
+// transpileSignedCharStore returns the value that is assigned to the lvalue
+// left with the C type. The value is converted into a byte if left is an
+// element of a []byte that is a signed char. A literal, like 'a', does not need
+// to be converted.
+func transpileSignedCharStore(left goast.Expr, leftType string, right goast.Expr,
+	p *program.Program) goast.Expr {
+	if !isSignedCharElement(left, leftType, p) {
+		return right
+	}
+
+	if _, ok := right.(*goast.BasicLit); ok {
+		return right
+	}
+
+	return util.NewCallExpr("byte", right)
+}
@@ -0,0 +1,67 @@
+package transpiler
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func newCharElement() *ast.ArraySubscriptExpr {
+	return &ast.ArraySubscriptExpr{
+		Type: "char",
+		Children: []ast.Node{
+			newVarRef("s", "char *"),
+			&ast.IntegerLiteral{Type: "int", Value: "0"},
+		},
+	}
+}
+
+func TestSignedChar(t *testing.T) {
+	c := newVarRef("c", "char")
+
+	tests := []struct {
+		name       string
+		signedChar bool
+		node       ast.Node
+		out        string
+	}{
+		{"load", true, newRValue("char", newCharElement()), "int8(s[0])"},
+		{"load unsigned", false, newRValue("char", newCharElement()), "s[0]"},
+		{"load variable", true, newRValue("char", c), "c"},
+		{"sign extension", true, &ast.ImplicitCastExpr{
+			Kind:     "IntegralCast",
+			Type:     "int",
+			Children: []ast.Node{newRValue("char", newCharElement())},
+		}, "int(int8(s[0]))"},
+		{"store", true, &ast.BinaryOperator{
+			Type:     "char",
+			Operator: "=",
+			Children: []ast.Node{newCharElement(), c},
+		}, "s[0] = byte(c)"},
+		{"store literal", true, &ast.BinaryOperator{
+			Type:     "char",
+			Operator: "=",
+			Children: []ast.Node{newCharElement(), &ast.CharacterLiteral{Type: "int", Value: 'a'}},
+		}, "s[0] = 'a'"},
+		{"compound assignment", true, &ast.CompoundAssignOperator{
+			Type:     "char",
+			Opcode:   "+=",
+			Children: []ast.Node{newCharElement(), c},
+		}, "s[0] += byte(c)"},
+	}
+
+	for _, tt := range tests {
+		p := program.NewProgram()
+		p.SignedChar = tt.signedChar
+
+		expr, _, _, _, err := transpileToExpr(tt.node, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, expr); got != tt.out {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.out, got)
+		}
+	}
+}
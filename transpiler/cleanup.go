@@ -0,0 +1,84 @@
+// This file contains the cleanup attribute of a local variable. The function of
+// the attribute is called with the address of the variable when the variable
+// goes out of scope:
+//
+//     void free_string(char **s) { free(*s); }
+//
+//     char *s __attribute__((cleanup(free_string))) = malloc(16);
+//
+// The call is deferred so that it is made when the function returns:
+//
+//     var s []byte = noarch.Malloc(16)
+//     defer func() {
+//         free_string(&s)
+//     }()
+//
+// This is later than C, which calls it at the end of the block. The address is
+// taken when the function returns to see the last value of the variable.
+
+package transpiler
+
+import (
+	"strings"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// removeCleanupAttr returns the cleanup attribute of n, and a copy of n without
+// it. The rest of the variable declaration does not have to know about the
+// attribute. The attribute is nil if n does not have one.
+func removeCleanupAttr(n *ast.VarDecl) (*ast.CleanupAttr, *ast.VarDecl) {
+	for i, c := range n.Children {
+		if attr, ok := c.(*ast.CleanupAttr); ok {
+			decl := *n
+			decl.Children = append(append([]ast.Node{}, n.Children[:i]...),
+				n.Children[i+1:]...)
+
+			return attr, &decl
+		}
+	}
+
+	return nil, n
+}
+
+// transpileCleanupAttr returns the statement that defers the call of the
+// cleanup function of the variable n (see the top of this file).
+func transpileCleanupAttr(attr *ast.CleanupAttr, n *ast.VarDecl, p *program.Program) (
+	goast.Stmt, error) {
+	call := &ast.CallExpr{Type: "void", Children: []ast.Node{
+		&ast.ImplicitCastExpr{
+			Kind: "FunctionToPointerDecay",
+			Type: strings.Replace(attr.FunctionType, "(", "(*)(", 1),
+			Children: []ast.Node{&ast.DeclRefExpr{
+				For:  "Function",
+				Name: attr.FunctionName,
+				Type: attr.FunctionType,
+			}},
+		},
+		&ast.UnaryOperator{
+			Type:     n.Type + " *",
+			Operator: "&",
+			IsPrefix: true,
+			Children: []ast.Node{&ast.DeclRefExpr{
+				For:  "Var",
+				Name: n.Name,
+				Type: n.Type,
+			}},
+		},
+	}}
+
+	e, _, preStmts, postStmts, err := transpileToExpr(call, p)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := append(append(preStmts, util.NewExprStmt(e)), postStmts...)
+
+	return &goast.DeferStmt{
+		Call: util.NewFuncClosure("", stmts...),
+	}, nil
+}
@@ -0,0 +1,152 @@
+// This file contains functions for transpiling a computed goto, which is a GNU
+// extension that is used by threaded interpreters:
+//
+//     void *next = &&add;
+//     goto *next;
+//
+// Go does not have the address of a label, so each label that has its address
+// taken is numbered (counting from 1) in the order that it appears in the
+// function. The address is the number of the label, and a computed goto is a
+// switch that jumps to the label with that number:
+//
+//     var next []byte = noarch.LabelAddress(1)
+//     switch noarch.LabelIndex(next) {
+//     case 1:
+//         goto add
+//     default:
+//         panic("goto to an address that is not a label")
+//     }
+//
+// Each goto of the switch has the same rules as any other goto (see
+// canTranspileGoto).
+
+package transpiler
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// getLabelAddresses returns the names of the labels of the current function
+// that have their address taken. The number of a label is its index plus one.
+func getLabelAddresses(p *program.Program) []string {
+	if p.Function == nil {
+		return nil
+	}
+
+	names := []string{}
+	addresses := ast.GetAllNodesOfType(p.Function, reflect.TypeOf((*ast.AddrLabelExpr)(nil)))
+	for _, a := range addresses {
+		if !util.InStrings(a.(*ast.AddrLabelExpr).Name, names) {
+			names = append(names, a.(*ast.AddrLabelExpr).Name)
+		}
+	}
+
+	return names
+}
+
+// isLabelAddressTaken returns true if the address of the label is used in the
+// current function.
+func isLabelAddressTaken(p *program.Program, name string) bool {
+	return util.InStrings(name, getLabelAddresses(p))
+}
+
+// isIndirectGotoLabel returns true if at least one computed goto of the
+// current function can jump to the label.
+func isIndirectGotoLabel(p *program.Program, name string) bool {
+	if !isLabelAddressTaken(p, name) {
+		return false
+	}
+
+	gotos := ast.GetAllNodesOfType(p.Function, reflect.TypeOf((*ast.IndirectGotoStmt)(nil)))
+	for _, g := range gotos {
+		if canJumpToLabel(p, g, name) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func transpileAddrLabelExpr(n *ast.AddrLabelExpr, p *program.Program) (
+	goast.Expr, string, error) {
+	for i, name := range getLabelAddresses(p) {
+		if name == n.Name {
+			p.AddImport("github.com/elliotchance/c2go/noarch")
+
+			return util.NewCallExpr("noarch.LabelAddress", util.NewIntLit(i+1)),
+				n.Type, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("the address of label %s is outside of a function", n.Name)
+}
+
+func transpileIndirectGotoStmt(n *ast.IndirectGotoStmt, p *program.Program) (
+	goast.Stmt, []goast.Stmt, []goast.Stmt, error) {
+	if len(n.Children) == 0 {
+		return nil, nil, nil, errors.New("computed goto does not have a target")
+	}
+
+	target, _, preStmts, postStmts, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// The goto never returns, so the post statements have to happen before
+	// it.
+	if len(postStmts) > 0 {
+		tempVariableName := p.GetNextIdentifier("")
+		preStmts = append(preStmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(tempVariableName)},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{target},
+		})
+		preStmts = append(preStmts, postStmts...)
+		target = util.NewIdent(tempVariableName)
+	}
+
+	clauses := []goast.Stmt{}
+	for i, name := range getLabelAddresses(p) {
+		var body goast.Stmt = &goast.BranchStmt{
+			Tok:   token.GOTO,
+			Label: util.NewIdent(name),
+		}
+
+		if err := canJumpToLabel(p, n, name); err != nil {
+			// Like transpileGotoStmt, the generated code still compiles but it
+			// will be obvious at runtime if the label is jumped to.
+			message := fmt.Sprintf("FIXME: %s", err.Error())
+			p.AddMessage(ast.GenerateWarningMessage(errors.New(message), n))
+
+			body = util.NewExprStmt(
+				util.NewCallExpr("panic", util.NewStringLit(fmt.Sprintf("%q", message))),
+			)
+		}
+
+		clauses = append(clauses, &goast.CaseClause{
+			List: []goast.Expr{util.NewIntLit(i + 1)},
+			Body: []goast.Stmt{body},
+		})
+	}
+
+	clauses = append(clauses, &goast.CaseClause{
+		Body: []goast.Stmt{util.NewExprStmt(util.NewCallExpr("panic",
+			util.NewStringLit(`"goto to an address that is not a label"`)))},
+	})
+
+	p.AddImport("github.com/elliotchance/c2go/noarch")
+
+	return &goast.SwitchStmt{
+		Tag:  util.NewCallExpr("noarch.LabelIndex", target),
+		Body: &goast.BlockStmt{List: clauses},
+	}, preStmts, nil, nil
+}
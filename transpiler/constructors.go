@@ -0,0 +1,87 @@
+// This file contains the functions with the constructor and destructor
+// attributes:
+//
+//     void setup() __attribute__((constructor(101))) { ... }
+//     void cleanup() __attribute__((destructor)) { ... }
+//
+// The constructors are called at the end of the startup statements, after the
+// global variables are ready, in the order of their priority. The destructors
+// are registered at the same time with noarch.Atexit() so that they are called
+// by noarch.Exit(), which main() uses instead of os.Exit() when there are any
+// destructors:
+//
+//     func __init() {
+//         ...
+//         noarch.Atexit(cleanup)
+//         setup()
+//     }
+//
+// A program that calls atexit() also exits with noarch.Exit().
+//
+// The priorities are only ordered within a file. Go runs the init() functions
+// of a package with more than one file in the order of the files.
+
+package transpiler
+
+import (
+	"reflect"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
+)
+
+// registerDestructors turns on HasDestructors if any function in the
+// translation unit is a destructor or calls atexit(). This has to be known
+// before main() is transpiled, which may be before the destructor or in another
+// file.
+func registerDestructors(n *ast.TranslationUnitDecl, p *program.Program) {
+	for _, c := range n.Children {
+		f, ok := c.(*ast.FunctionDecl)
+		if !ok || getFunctionBody(f) == nil {
+			continue
+		}
+
+		for _, attr := range f.Children {
+			if _, ok := attr.(*ast.DestructorAttr); ok {
+				p.HasDestructors = true
+			}
+		}
+
+		calls := ast.GetAllNodesOfType(getFunctionBody(f), reflect.TypeOf((*ast.CallExpr)(nil)))
+		for _, call := range calls {
+			if name, _ := getNameOfFunctionFromCallExpr(call.(*ast.CallExpr)); name == "atexit" {
+				p.HasDestructors = true
+			}
+		}
+	}
+}
+
+// registerConstructor adds the function to the constructors or destructors of
+// the file if it has either attribute.
+func registerConstructor(n *ast.FunctionDecl, p *program.Program) {
+	for _, c := range n.Children {
+		switch attr := c.(type) {
+		case *ast.ConstructorAttr:
+			p.AddConstructor(p.GetFunctionName(n.Name), attr.Priority)
+
+		case *ast.DestructorAttr:
+			p.AddDestructor(p.GetFunctionName(n.Name), attr.Priority)
+		}
+	}
+}
+
+// transpileConstructors appends the startup statements that register the
+// destructors and call the constructors of the file.
+func transpileConstructors(p *program.Program) {
+	// The destructors with a larger priority number are called first, so they
+	// are registered last.
+	for _, d := range p.Destructors() {
+		p.AddImport("github.com/elliotchance/c2go/noarch")
+		p.AppendStartupExpr(util.NewCallExpr("noarch.Atexit", util.NewIdent(d.Name)))
+	}
+
+	for _, c := range p.Constructors() {
+		p.AppendStartupExpr(util.NewCallExpr(c.Name))
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"fmt"
 	goast "go/ast"
 	"go/token"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/elliotchance/c2go/ast"
 	"github.com/elliotchance/c2go/program"
@@ -26,24 +30,152 @@ func transpileFieldDecl(p *program.Program, n *ast.FieldDecl) (*goast.Field, str
 	fieldType, err := types.ResolveType(p, n.Type)
 	p.AddMessage(ast.GenerateWarningMessage(err, n))
 
-	// TODO: The name of a variable or field cannot be "type"
-	// https://github.com/elliotchance/c2go/issues/83
-	if name == "type" {
-		name = "type_"
+	var tags []string
+	if p.StructTags {
+		if tag := transpileFieldTag(p, n); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	if p.MemoryTags {
+		if tag := transpileMemoryTag(n, fieldType); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	var tag *goast.BasicLit
+	if len(tags) > 0 {
+		tag = &goast.BasicLit{
+			Kind:  token.STRING,
+			Value: "`" + strings.Join(tags, " ") + "`",
+		}
 	}
 
 	return &goast.Field{
-		Names: []*goast.Ident{util.NewIdent(name)},
+		Names: []*goast.Ident{util.NewIdent(getFieldName(p, name))},
 		Type:  util.NewTypeIdent(fieldType),
+		Tag:   tag,
 	}, "unknown3"
 }
 
+// getGoName returns the Go name of a variable or a field of a struct.
+//
+// TODO: The name of a variable or field cannot be "type"
+// https://github.com/elliotchance/c2go/issues/83
+func getGoName(name string) string {
+	if name == "type" {
+		return "type_"
+	}
+
+	return name
+}
+
+// getFieldName returns the Go name of a field of a struct. encoding/json only
+// uses exported fields, so the fields are exported when they have a json tag
+// (see transpileFieldTag). A name that cannot be exported by changing the case
+// of the first letter, like "_size", is prefixed with "X".
+func getFieldName(p *program.Program, name string) string {
+	if !p.StructTags {
+		return getGoName(name)
+	}
+
+	if name[0] >= 'a' && name[0] <= 'z' {
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+
+	if name[0] >= 'A' && name[0] <= 'Z' {
+		return name
+	}
+
+	return "X" + name
+}
+
+// transpileFieldTag creates the json tag for a struct field. The tag uses the
+// original C name so that the JSON matches what the C program expects:
+//
+//     int max_size;    ->    Max_size int `json:"max_size"`
+//
+// Bitfields and anonymous unions do not have a sensible JSON representation so
+// they do not get a tag.
+func transpileFieldTag(p *program.Program, n *ast.FieldDecl) string {
+	// The width of a bitfield is the only child a FieldDecl has in C.
+	if len(n.Children) > 0 {
+		message := fmt.Sprintf("no struct tag for the bitfield %s", n.Name)
+		p.AddMessage(ast.GenerateWarningMessage(errors.New(message), n))
+		return ""
+	}
+
+	if strings.Contains(n.Type, "anonymous") {
+		message := fmt.Sprintf("no struct tag for the anonymous type of %s", n.Name)
+		p.AddMessage(ast.GenerateWarningMessage(errors.New(message), n))
+		return ""
+	}
+
+	return fmt.Sprintf("json:\"%s\"", n.Name)
+}
+
+// transpileMemoryTag creates the tag for a field that is a pointer in C but a
+// slice in Go. A slice is the elements of an array when the struct is converted
+// to the bytes of it in C (see noarch.Fwrite), so the pointers are marked:
+//
+//     char *name;         ->    name []byte `c2go:"pointer"`
+//     char *names[3];     ->    names [][]byte `c2go:"pointers"`
+func transpileMemoryTag(n *ast.FieldDecl, fieldType string) string {
+	if elementType, size := types.GetArrayTypeAndSize(n.Type); size != -1 {
+		if strings.HasPrefix(fieldType, "[][]") && pointerTypeRegexp.MatchString(elementType) {
+			return `c2go:"pointers"`
+		}
+
+		return ""
+	}
+
+	if strings.HasPrefix(fieldType, "[]") && pointerTypeRegexp.MatchString(n.Type) {
+		return `c2go:"pointer"`
+	}
+
+	return ""
+}
+
+// registerMemoryTags turns on MemoryTags if any function in the translation
+// unit calls fread() or fwrite(). The structs may be defined before the call or
+// in another file.
+func registerMemoryTags(n *ast.TranslationUnitDecl, p *program.Program) {
+	calls := ast.GetAllNodesOfType(n, reflect.TypeOf((*ast.CallExpr)(nil)))
+	for _, call := range calls {
+		name, _ := getNameOfFunctionFromCallExpr(call.(*ast.CallExpr))
+		if name == "fread" || name == "fwrite" {
+			p.MemoryTags = true
+		}
+	}
+}
+
+// pointerTypeRegexp matches a C pointer type, like "char *" or "int *const".
+var pointerTypeRegexp = regexp.MustCompile(`\*(\s*(const|volatile|restrict))*\s*$`)
+
+// isLayoutPacked returns true if the maximum field alignment of a struct is
+// less than the alignment that any of its fields would normally have.
+func isLayoutPacked(p *program.Program, s *program.Struct) bool {
+	for _, t := range s.Fields {
+		if f, ok := t.(string); ok {
+			if align, err := types.AlignOf(p, f); err == nil && align > s.MaxFieldAlignment {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) error {
 	name := n.Name
 	if name == "" || p.IsTypeAlreadyDefined(name) {
 		return nil
 	}
 
+	if _, ok := p.GetTypeMapping(n.Kind + " " + name); ok {
+		return nil
+	}
+
 	p.DefineType(name)
 
 	s := program.NewStruct(n)
@@ -53,6 +185,12 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) error {
 		p.Structs["struct "+s.Name] = s
 	}
 
+	// A struct that is replaced by a Go type, like "struct tm", is still
+	// registered so that the types of its fields are known.
+	if types.IsReplacedType(n.Kind + " " + name) {
+		return nil
+	}
+
 	// TODO: Some platform structs are ignored.
 	// https://github.com/elliotchance/c2go/issues/85
 	if name == "__locale_struct" ||
@@ -62,15 +200,93 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) error {
 	}
 
 	var fields []*goast.Field
+	bitfields := &bitfieldLayout{p: p, s: s}
+
+	// The last anonymous struct or union that was defined. It is used by the
+	// fields that follow it.
+	var anonymous *program.Struct
 
 	for _, c := range n.Children {
-		if field, ok := c.(*ast.FieldDecl); ok {
+		switch field := c.(type) {
+		case *ast.FieldDecl:
+			if anonymous != nil && strings.Contains(field.Type, "(anonymous") {
+				if anonymous.IsUnion {
+					p.Unions[field.Type] = anonymous
+				} else {
+					p.Structs[field.Type] = anonymous
+				}
+
+				// An anonymous member is embedded so that its fields (or the
+				// getters and setters of a union) are promoted:
+				//
+				//     struct s { union { int i; float f; }; };
+				//
+				// Becomes:
+				//
+				//     type sAnonymous0 [4]byte
+				//     type s struct { sAnonymous0 }
+				if field.Implicit {
+					bitfields.end()
+					fields = append(fields, &goast.Field{
+						Type: util.NewTypeIdent(anonymous.Name),
+					})
+					continue
+				}
+			}
+
+			if isBitfield(field) && !s.IsUnion {
+				f, err := bitfields.add(field)
+				p.AddMessage(ast.GenerateWarningMessage(err, field))
+
+				if f != nil {
+					fields = append(fields, f)
+				}
+				continue
+			}
+			bitfields.end()
+
 			f, _ := transpileFieldDecl(p, field)
 
 			if f != nil {
 				fields = append(fields, f)
 			}
-		} else {
+
+		case *ast.RecordDecl:
+			// A struct or union that is defined inside another one is still
+			// visible at file scope in C. An anonymous one needs a name in Go.
+			if field.Name == "" {
+				named := *field
+				named.Name = p.GetNextIdentifier(name + "Anonymous")
+
+				err := transpileRecordDecl(p, &named)
+				p.AddMessage(ast.GenerateWarningMessage(err, field))
+
+				anonymous = p.GetStruct(named.Kind + " " + named.Name)
+				continue
+			}
+
+			err := transpileRecordDecl(p, field)
+			p.AddMessage(ast.GenerateWarningMessage(err, field))
+
+		case *ast.IndirectFieldDecl:
+			// The fields of an anonymous struct or union are promoted from the
+			// embedded field.
+
+		case *ast.PackedAttr:
+			// Go has no way to remove the padding between fields so the
+			// generated struct may not have the same layout as the C one.
+			message := fmt.Sprintf("%s %s is packed, the Go memory layout may differ", n.Kind, name)
+			p.AddMessage(ast.GenerateWarningMessage(errors.New(message), c))
+
+		case *ast.MaxFieldAlignmentAttr:
+			// The size of the record follows the "#pragma pack", but the fields
+			// of the Go struct are still aligned normally.
+			if isLayoutPacked(p, s) {
+				message := fmt.Sprintf("%s %s is declared with #pragma pack(%d), the Go memory layout may differ", n.Kind, name, s.MaxFieldAlignment)
+				p.AddMessage(ast.GenerateWarningMessage(errors.New(message), c))
+			}
+
+		default:
 			message := fmt.Sprintf("could not parse %v", c)
 			p.AddMessage(ast.GenerateWarningMessage(errors.New(message), c))
 		}
@@ -78,7 +294,7 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) error {
 
 	if s.IsUnion {
 		// Union size
-		size, err := types.SizeOf(p, "union "+name)
+		size, align, err := getUnionLayout(p, s)
 
 		// In normal case no error is returned,
 		if err != nil {
@@ -91,7 +307,7 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) error {
 			p.AddImports("reflect", "unsafe")
 
 			// Declaration for implementing union type
-			p.File.Decls = append(p.File.Decls, transpileUnion(name, size, fields)...)
+			p.File.Decls = append(p.File.Decls, transpileUnion(name, size, align, fields)...)
 		}
 	} else {
 		p.File.Decls = append(p.File.Decls, &goast.GenDecl{
@@ -107,18 +323,109 @@ func transpileRecordDecl(p *program.Program, n *ast.RecordDecl) error {
 				},
 			},
 		})
+
+		p.File.Decls = append(p.File.Decls, transpileBitfieldMethods(p, n, s)...)
 	}
 
 	return nil
 }
 
+// nameAnonymousDecl returns the anonymous struct, union or enum with the name of
+// its typedef. clang puts the typedefs directly after the declaration:
+//
+//     typedef struct { int x; } Foo;    ->    type Foo struct { x int }
+//
+// The first typedef is the name when there is more than one, like
+// "typedef struct { int x; } Foo, Bar;". The others are transpiled as typedefs
+// of it. Any other declaration is returned as it is.
+func nameAnonymousDecl(decl ast.Node, following []ast.Node) ast.Node {
+	var kind, address string
+	switch d := decl.(type) {
+	case *ast.RecordDecl:
+		if d.Name != "" || !d.Definition {
+			return decl
+		}
+		kind, address = d.Kind, d.Address
+
+	case *ast.EnumDecl:
+		if d.Name != "" {
+			return decl
+		}
+		kind, address = "enum", d.Address
+
+	default:
+		return decl
+	}
+
+	for _, c := range following {
+		typedef, ok := c.(*ast.TypedefDecl)
+		if !ok {
+			break
+		}
+
+		if !isTypedefOf(typedef, kind, address) {
+			continue
+		}
+
+		switch d := decl.(type) {
+		case *ast.RecordDecl:
+			named := *d
+			named.Name = typedef.Name
+			return &named
+
+		case *ast.EnumDecl:
+			named := *d
+			named.Name = typedef.Name
+			return &named
+		}
+	}
+
+	return decl
+}
+
+// isTypedefOf returns true if the typedef is the name of the anonymous struct,
+// union or enum at the address, not a pointer to it. clang uses the name of
+// the typedef for the type, like 'struct Foo':'Foo'.
+func isTypedefOf(n *ast.TypedefDecl, kind, address string) bool {
+	if n.Type != kind+" "+n.Name && n.Type != n.Name {
+		return false
+	}
+
+	var addresses []string
+	for _, r := range ast.GetAllNodesOfType(n, reflect.TypeOf((*ast.Record)(nil))) {
+		addresses = append(addresses, r.(*ast.Record).Address)
+	}
+	for _, e := range ast.GetAllNodesOfType(n, reflect.TypeOf((*ast.Enum)(nil))) {
+		addresses = append(addresses, e.(*ast.Enum).Address)
+	}
+
+	// Older versions of clang do not have the declaration that the type refers
+	// to.
+	return len(addresses) == 0 || util.InStrings(address, addresses)
+}
+
 func transpileTypedefDecl(p *program.Program, n *ast.TypedefDecl) error {
 	name := n.Name
 
+	// The fields of a struct or union are also found through the name of its
+	// typedef, which is the type of a variable like 'Foo':'Foo'.
+	if s := p.GetStruct(n.Type); s != nil && p.GetStruct(name) == nil &&
+		!strings.HasSuffix(n.Type, "*") {
+		if s.IsUnion {
+			p.Unions[name] = s
+		} else {
+			p.Structs[name] = s
+		}
+	}
+
 	if p.IsTypeAlreadyDefined(name) {
 		return nil
 	}
 
+	if _, ok := p.GetTypeMapping(name); ok {
+		return nil
+	}
+
 	p.DefineType(name)
 
 	resolvedType, err := types.ResolveType(p, n.Type)
@@ -140,10 +447,27 @@ func transpileTypedefDecl(p *program.Program, n *ast.TypedefDecl) error {
 	//
 	// Until which time that we actually need this to work I am going to
 	// suppress these.
+	if strings.HasPrefix(n.Type, "enum ") || p.Enums[resolvedType] {
+		p.Enums[name] = true
+	}
+
 	if name == resolvedType {
 		return nil
 	}
 
+	// The size of wchar_t is different on each platform but it is always an
+	// int32 in Go (see ResolveType) so that wide string literals do not depend
+	// on the platform.
+	if name == "wchar_t" {
+		return nil
+	}
+
+	// A 128-bit integer is always a noarch.Int128 or noarch.Uint128, like
+	// wchar_t, so that its methods can be used.
+	if name == "__int128_t" || name == "__uint128_t" {
+		return nil
+	}
+
 	if name == "__darwin_ct_rune_t" {
 		resolvedType = p.ImportType("github.com/elliotchance/c2go/darwin.CtRuneT")
 	}
@@ -181,15 +505,92 @@ func transpileTypedefDecl(p *program.Program, n *ast.TypedefDecl) error {
 	return nil
 }
 
+// isExternDeclaration returns true if the variable is only declared with
+// extern, like "extern int shared;". An extern variable with an initializer is
+// a definition.
+func isExternDeclaration(n *ast.VarDecl) bool {
+	return n.IsExtern && len(n.Children) == 0
+}
+
+// registerDefinedVariables records the global variables that are defined in the
+// translation unit. An extern declaration of one of them is not needed in Go,
+// even if it is before the definition or in another file.
+func registerDefinedVariables(n *ast.TranslationUnitDecl, p *program.Program) {
+	for _, c := range n.Children {
+		if v, ok := c.(*ast.VarDecl); ok && !isExternDeclaration(v) {
+			p.DefineVariable(v.Name)
+		}
+	}
+}
+
+// registerAddressTaken records the variables that have their address taken in
+// the translation unit, like "&MAX". A local variable may have the same name as
+// a global one, so the variables are found by the address of their VarDecl.
+//
+// A reference that comes after an extern declaration, but before the
+// definition, has the address of the extern declaration instead. It is the
+// same global variable, so the address of the definition is also recorded.
+func registerAddressTaken(n *ast.TranslationUnitDecl, p *program.Program) {
+	externs := map[string]string{}
+	definitions := map[string]string{}
+	for _, c := range n.Children {
+		if v, ok := c.(*ast.VarDecl); ok {
+			if isExternDeclaration(v) {
+				externs[v.Address] = v.Name
+			} else {
+				definitions[v.Name] = v.Address
+			}
+		}
+	}
+
+	operators := ast.GetAllNodesOfType(n, reflect.TypeOf((*ast.UnaryOperator)(nil)))
+	for _, o := range operators {
+		o := o.(*ast.UnaryOperator)
+		if o.Operator != "&" || len(o.Children) == 0 {
+			continue
+		}
+
+		if ref, ok := removeCastsAndParens(o.Children[0]).(*ast.DeclRefExpr); ok {
+			p.AddAddressTaken(ref.Address2)
+
+			if name, ok := externs[ref.Address2]; ok {
+				p.AddAddressTaken(definitions[name])
+			}
+		}
+	}
+}
+
 func transpileVarDecl(p *program.Program, n *ast.VarDecl) (
-	[]goast.Stmt, []goast.Stmt, string) {
+	[]goast.Stmt, []goast.Stmt, error) {
 	// There are cases where the same variable is defined more than once. I
 	// assume this is becuase they are extern or static definitions. For now, we
 	// will ignore any redefinitions.
 	if _, found := p.GlobalVariables[n.Name]; found {
-		return nil, nil, ""
+		return nil, nil, nil
+	}
+
+	// The definition of the variable declares it instead. A variable that is
+	// only declared with extern is still declared (without a value) so that it
+	// can be used. It is only declared by the first of the extern declarations,
+	// which may be in different functions.
+	if isExternDeclaration(n) {
+		if p.IsVariableDefined(n.Name) {
+			return nil, nil, nil
+		}
+
+		p.DefineVariable(n.Name)
 	}
 
+	// The size of an array can be left out when it is initialized, like
+	// "int a[] = {1, 2}". The initializer list has the complete type.
+	if strings.HasSuffix(n.Type, "[]") && len(n.Children) > 0 {
+		if initList, ok := n.Children[0].(*ast.InitListExpr); ok {
+			n.Type = initList.Type
+		}
+	}
+
+	resolveVarDeclType(n, p)
+
 	theType, err := types.ResolveType(p, n.Type)
 	p.AddMessage(ast.GenerateWarningMessage(err, n))
 
@@ -207,14 +608,10 @@ func transpileVarDecl(p *program.Program, n *ast.VarDecl) (
 		name == "_IO_2_1_stderr_" ||
 		name == "_DefaultRuneLocale" ||
 		name == "_CurrentRuneLocale" {
-		return nil, nil, "unknown10"
+		return nil, nil, nil
 	}
 
-	// TODO: The name of a variable or field cannot be "type"
-	// https://github.com/elliotchance/c2go/issues/83
-	if name == "type" {
-		name = "type_"
-	}
+	name = getGoName(name)
 
 	// There may be some startup code for this global variable.
 	if p.Function == nil {
@@ -253,11 +650,26 @@ func transpileVarDecl(p *program.Program, n *ast.VarDecl) (
 		}
 	}
 
+	// The variable is still declared if its value cannot be transpiled, so
+	// that it can be used.
 	defaultValue, _, newPre, newPost, err := getDefaultValueForVar(p, n)
 	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
+	// The arrays, including the arrays of a struct, are allocated.
+	if defaultValue == nil {
+		if zero := newZeroValue(n, n.Type, p); zero != nil {
+			defaultValue = []goast.Expr{zero}
+		}
+	}
+
+	tok := token.VAR
+	if isConstantVarDecl(p, n, theType, defaultValue) {
+		tok = token.CONST
+		p.AddConstant(name)
+	}
+
 	p.File.Decls = append(p.File.Decls, &goast.GenDecl{
-		Tok: token.VAR,
+		Tok: tok,
 		Specs: []goast.Spec{
 			&goast.ValueSpec{
 				Names: []*goast.Ident{
@@ -269,5 +681,119 @@ func transpileVarDecl(p *program.Program, n *ast.VarDecl) (
 		},
 	})
 
-	return nil, nil, theType
+	return preStmts, postStmts, err
+}
+
+// The Go types that can be constants.
+var constantTypes = []string{
+	"byte",
+	"int", "int8", "int16", "int32", "int64",
+	"uint8", "uint16", "uint32", "uint64",
+	"float32", "float64",
+}
+
+// isConstantVarDecl returns true if the global variable can be declared as a
+// Go constant. It must be a const number with an initializer that is a constant
+// expression:
+//
+//     const int MAX = 100;             ->    const MAX int = 100
+//     const double HALF = 1.0 / 2;     ->    const HALF float64 = 1.0 / 2
+//     const long TWICE = MAX * 2;      ->    const TWICE int32 = int32(MAX * 2)
+//
+// A constant can be used as the size of an array. In C "int a[MAX]" is a
+// variable length array because MAX is a variable. A variable that has its
+// address taken, like "&MAX", is not a constant (see registerAddressTaken).
+func isConstantVarDecl(p *program.Program, n *ast.VarDecl, goType string,
+	values []goast.Expr) bool {
+	if p.Function != nil || !strings.HasPrefix(n.Type, "const ") ||
+		p.IsAddressTaken(n.Address) {
+		return false
+	}
+
+	if !util.InStrings(goType, constantTypes) || len(values) != 1 {
+		return false
+	}
+
+	return isConstantExpr(p, values[0])
+}
+
+// isConstantExpr returns true if the Go expression is a constant expression.
+// Only the expressions that are produced for the initializer of a number are
+// considered.
+func isConstantExpr(p *program.Program, e goast.Expr) bool {
+	switch v := e.(type) {
+	case *goast.BasicLit:
+		return true
+
+	case *goast.Ident:
+		return p.IsConstant(v.Name)
+
+	case *goast.ParenExpr:
+		return isConstantExpr(p, v.X)
+
+	case *goast.UnaryExpr:
+		switch v.Op {
+		case token.ADD, token.SUB, token.XOR:
+			return isConstantExpr(p, v.X)
+		}
+
+	case *goast.BinaryExpr:
+		switch v.Op {
+		case token.ADD, token.SUB, token.MUL, token.QUO, token.REM,
+			token.AND, token.OR, token.XOR, token.SHL, token.SHR, token.AND_NOT:
+			return isConstantExpr(p, v.X) && isConstantExpr(p, v.Y)
+		}
+
+	case *goast.CallExpr:
+		// A conversion, like "int32(1)".
+		if f, ok := v.Fun.(*goast.Ident); ok && len(v.Args) == 1 &&
+			util.InStrings(f.Name, constantTypes) {
+			return isConstantExpr(p, v.Args[0])
+		}
+	}
+
+	return false
+}
+
+// transpileStaticAssertDecl converts a _Static_assert() into a runtime check
+// like:
+//
+//     if !(sizeof(int) == 4) {
+//         panic("static assertion failed: int must be 4 bytes")
+//     }
+//
+// The condition has already been checked by clang, but evaluating it again
+// catches cases where the Go translation (like the size of a type) does not
+// match the original C.
+func transpileStaticAssertDecl(p *program.Program, n *ast.StaticAssertDecl) (
+	*goast.IfStmt, []goast.Stmt, []goast.Stmt, error) {
+	condition, conditionType, preStmts, postStmts, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	boolCondition, err := types.CastExpr(p, condition, conditionType, "bool")
+	p.AddMessage(ast.GenerateWarningOrErrorMessage(err, n, boolCondition == nil))
+
+	if boolCondition == nil {
+		boolCondition = util.NewNil()
+	}
+
+	message := "static assertion failed"
+	if len(n.Children) > 1 {
+		if s, ok := n.Children[1].(*ast.StringLiteral); ok {
+			message += ": " + s.Value
+		}
+	}
+
+	return &goast.IfStmt{
+		Cond: util.NewUnaryExpr(token.NOT, &goast.ParenExpr{X: boolCondition}),
+		Body: &goast.BlockStmt{
+			List: []goast.Stmt{
+				util.NewExprStmt(
+					util.NewCallExpr("panic", util.NewStringLit(strconv.Quote(message))),
+				),
+			},
+		},
+	}, preStmts, postStmts, nil
 }
@@ -0,0 +1,299 @@
+package transpiler
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	goast "go/ast"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func TestFieldDeclStructTags(t *testing.T) {
+	tests := []struct {
+		field *ast.FieldDecl
+		name  string
+		tag   string
+	}{
+		{&ast.FieldDecl{Name: "max_size", Type: "int"}, "Max_size", "`json:\"max_size\"`"},
+		{&ast.FieldDecl{Name: "type", Type: "char *"}, "Type", "`json:\"type\"`"},
+		{&ast.FieldDecl{Name: "_size", Type: "int"}, "X_size", "`json:\"_size\"`"},
+		{&ast.FieldDecl{
+			Name:     "flags",
+			Type:     "unsigned int",
+			Children: []ast.Node{&ast.IntegerLiteral{Type: "int", Value: "3"}},
+		}, "Flags", ""},
+		{&ast.FieldDecl{
+			Name: "u",
+			Type: "union (anonymous union at main.c:3:5)",
+		}, "U", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.Name, func(t *testing.T) {
+			p := program.NewProgram()
+			p.StructTags = true
+
+			f, _ := transpileFieldDecl(p, tt.field)
+
+			if name := f.Names[0].Name; name != tt.name {
+				t.Errorf("expected the name %s, got %s", tt.name, name)
+			}
+
+			tag := ""
+			if f.Tag != nil {
+				tag = f.Tag.Value
+			}
+
+			if tag != tt.tag {
+				t.Errorf("expected %s, got %s", tt.tag, tag)
+			}
+		})
+	}
+}
+
+func TestFieldDeclWithoutStructTags(t *testing.T) {
+	p := program.NewProgram()
+	f, _ := transpileFieldDecl(p, &ast.FieldDecl{Name: "max_size", Type: "int"})
+
+	if f.Tag != nil {
+		t.Errorf("expected no tag, got %s", f.Tag.Value)
+	}
+
+	// A pointer is only tagged when fread() or fwrite() is used.
+	f, _ = transpileFieldDecl(p, &ast.FieldDecl{Name: "name", Type: "char *"})
+
+	if f.Tag != nil {
+		t.Errorf("expected no tag, got %s", f.Tag.Value)
+	}
+}
+
+func TestFieldDeclPointerTags(t *testing.T) {
+	tests := []struct {
+		cType string
+		tag   string
+	}{
+		{"char *", "`c2go:\"pointer\"`"},
+		{"const int *const", "`c2go:\"pointer\"`"},
+		{"char *[3]", "`c2go:\"pointers\"`"},
+		{"char [16]", ""},
+		{"int [2][3]", ""},
+		{"struct node *", ""},
+		{"int (*)(int)", ""},
+	}
+
+	for _, tt := range tests {
+		p := program.NewProgram()
+		RegisterDefinitions(p, &ast.TranslationUnitDecl{Children: []ast.Node{
+			newCall("unsigned long (const void *, unsigned long, unsigned long, FILE *)", "fwrite"),
+		}})
+		p.Structs["struct node"] = program.NewStruct(&ast.RecordDecl{Kind: "struct", Name: "node"})
+
+		f, _ := transpileFieldDecl(p, &ast.FieldDecl{Name: "f", Type: tt.cType})
+
+		tag := ""
+		if f.Tag != nil {
+			tag = f.Tag.Value
+		}
+
+		if tag != tt.tag {
+			t.Errorf("%s: expected %s, got %s", tt.cType, tt.tag, tag)
+		}
+	}
+}
+
+func TestConstantVarDecl(t *testing.T) {
+	p := program.NewProgram()
+	p.File = &goast.File{}
+
+	decls := []*ast.VarDecl{
+		{Address: "0x1", Name: "MAX", Type: "const int", Children: []ast.Node{
+			&ast.IntegerLiteral{Type: "int", Value: "4"},
+		}},
+		{Name: "TWICE", Type: "const int", Children: []ast.Node{
+			&ast.BinaryOperator{Type: "int", Operator: "*", Children: []ast.Node{
+				newRValue("int", newVarRef("MAX", "const int")),
+				&ast.IntegerLiteral{Type: "int", Value: "2"},
+			}},
+		}},
+		{Name: "counter", Type: "int", Children: []ast.Node{
+			&ast.IntegerLiteral{Type: "int", Value: "4"},
+		}},
+		{Name: "unset", Type: "const int"},
+		{Address: "0x2", Name: "LIMIT", Type: "const int", Children: []ast.Node{
+			&ast.IntegerLiteral{Type: "int", Value: "8"},
+		}},
+	}
+
+	// "const int *limit = &LIMIT;" needs LIMIT to be a variable. The address
+	// of a local variable that is also called MAX does not change the global
+	// one.
+	RegisterDefinitions(p, &ast.TranslationUnitDecl{Children: []ast.Node{
+		&ast.VarDecl{Name: "limit", Type: "const int *", Children: []ast.Node{
+			&ast.UnaryOperator{Type: "const int *", Operator: "&", IsPrefix: true, Children: []ast.Node{
+				&ast.DeclRefExpr{For: "Var", Name: "LIMIT", Type: "const int", Address2: "0x2"},
+			}},
+		}},
+		&ast.VarDecl{Name: "local", Type: "int *", Children: []ast.Node{
+			&ast.UnaryOperator{Type: "int *", Operator: "&", IsPrefix: true, Children: []ast.Node{
+				&ast.DeclRefExpr{For: "Var", Name: "MAX", Type: "int", Address2: "0x3"},
+			}},
+		}},
+	}})
+
+	for _, decl := range decls {
+		transpileVarDecl(p, decl)
+	}
+
+	expected := []token.Token{token.CONST, token.CONST, token.VAR, token.VAR, token.VAR}
+	for i, tok := range expected {
+		if got := p.File.Decls[i].(*goast.GenDecl).Tok; got != tok {
+			t.Errorf("%s: expected %s, got %s", decls[i].Name, tok, got)
+		}
+	}
+
+	// A constant can be the size of an array.
+	p.Function = &ast.FunctionDecl{Name: "main"}
+	stmt, _, _, err := newDeclStmt(&ast.VarDecl{Name: "a", Type: "int [MAX]"}, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, out := renderNode(t, stmt), "var a []int = make([]int, MAX, MAX)"; got != out {
+		t.Errorf("expected %s, got %s", out, got)
+	}
+}
+
+func TestExternVarDecl(t *testing.T) {
+	p := program.NewProgram()
+	p.File = &goast.File{}
+
+	RegisterDefinitions(p, &ast.TranslationUnitDecl{
+		Children: []ast.Node{
+			&ast.VarDecl{Name: "shared", Type: "int", IsExtern: true},
+			&ast.VarDecl{Name: "missing", Type: "int", IsExtern: true},
+			&ast.VarDecl{Name: "shared", Type: "int", Children: []ast.Node{
+				&ast.IntegerLiteral{Type: "int", Value: "5"},
+			}},
+		},
+	})
+
+	// The extern declaration of "shared" is left out because it is defined
+	// later with a value.
+	transpileVarDecl(p, &ast.VarDecl{Name: "shared", Type: "int", IsExtern: true})
+	transpileVarDecl(p, &ast.VarDecl{Name: "missing", Type: "int", IsExtern: true})
+	transpileVarDecl(p, &ast.VarDecl{Name: "shared", Type: "int", Children: []ast.Node{
+		&ast.IntegerLiteral{Type: "int", Value: "5"},
+	}})
+
+	expected := []string{"var missing int", "var shared int = 5"}
+	if len(p.File.Decls) != len(expected) {
+		t.Fatalf("expected %d declarations, got %d", len(expected), len(p.File.Decls))
+	}
+
+	for i, out := range expected {
+		if got := renderNode(t, p.File.Decls[i]); got != out {
+			t.Errorf("expected %s, got %s", out, got)
+		}
+	}
+}
+
+func TestAnonymousTypedefDecl(t *testing.T) {
+	newTypedef := func(name, cType, kind, address string) *ast.TypedefDecl {
+		var ref ast.Node = &ast.RecordType{Type: name, Children: []ast.Node{
+			&ast.Record{Address: address},
+		}}
+		if kind == "enum" {
+			ref = &ast.EnumType{Name: name, Children: []ast.Node{
+				&ast.Enum{Address: address},
+			}}
+		}
+
+		return &ast.TypedefDecl{Name: name, Type: cType, Children: []ast.Node{
+			&ast.ElaboratedType{Type: cType, Children: []ast.Node{ref}},
+		}}
+	}
+
+	p := program.NewProgram()
+	p.File = &goast.File{}
+
+	// typedef struct { int x; } Foo, Bar, *FooPtr;
+	// typedef enum { RED } Color;
+	transpileToNode(&ast.TranslationUnitDecl{Children: []ast.Node{
+		&ast.RecordDecl{Address: "0x1", Kind: "struct", Definition: true, Children: []ast.Node{
+			&ast.FieldDecl{Name: "x", Type: "int"},
+		}},
+		newTypedef("Foo", "struct Foo", "struct", "0x1"),
+		newTypedef("Bar", "struct Foo", "struct", "0x1"),
+		newTypedef("FooPtr", "struct Foo *", "struct", "0x1"),
+		&ast.EnumDecl{Address: "0x2", Children: []ast.Node{
+			&ast.EnumConstantDecl{Name: "RED", Type: "int"},
+		}},
+		newTypedef("Color", "enum Color", "enum", "0x2"),
+	}}, p)
+
+	expected := []string{
+		"type Foo struct {\n\tx int\n}",
+		"type Bar Foo",
+		"type FooPtr *Foo",
+		"type Color int",
+		"const (\n\tRED int = 0\n)",
+	}
+
+	var out []string
+	for _, decl := range p.File.Decls {
+		out = append(out, renderNode(t, decl))
+	}
+
+	if len(out) < len(expected) {
+		t.Fatalf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(out, "\n"))
+	}
+
+	for i, e := range expected {
+		if out[i] != e {
+			t.Errorf("expected:\n%s\ngot:\n%s", e, out[i])
+		}
+	}
+
+	// The fields are found through the typedefs.
+	for _, name := range []string{"struct Foo", "Foo", "Bar"} {
+		if s := p.GetStruct(name); s == nil || s.Name != "Foo" {
+			t.Errorf("%s: expected struct Foo, got %v", name, s)
+		}
+	}
+
+	if s := p.GetStruct("FooPtr"); s != nil {
+		t.Errorf("FooPtr: expected a pointer, got struct %s", s.Name)
+	}
+}
+
+func TestLocalExternVarDecl(t *testing.T) {
+	p := program.NewProgram()
+	p.File = &goast.File{}
+
+	// "extern int missing;" in two functions, the variable is not defined.
+	for _, name := range []string{"f", "g"} {
+		p.Function = &ast.FunctionDecl{Name: name}
+
+		stmts, _, _, err := transpileDeclStmt(&ast.DeclStmt{Children: []ast.Node{
+			&ast.VarDecl{Name: "missing", Type: "int", IsExtern: true},
+		}}, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(stmts) != 0 {
+			t.Errorf("%s: expected no local declarations, got %d", name, len(stmts))
+		}
+	}
+
+	if len(p.File.Decls) != 1 {
+		t.Fatalf("expected 1 declaration, got %d", len(p.File.Decls))
+	}
+
+	if !p.IsVariableDefined("missing") {
+		t.Errorf("expected the extern variable to be defined once it is declared")
+	}
+}
@@ -35,9 +35,12 @@ func ctypeEnumValue(value int, t token.Token) goast.Expr {
 	}
 }
 
-func transpileEnumConstantDecl(p *program.Program, n *ast.EnumConstantDecl) (
+// transpileEnumConstantDecl creates the constant for an enumerator. An
+// enumerator without a value is one more than the previous enumerator, or zero
+// if it is the first one (previous is empty).
+func transpileEnumConstantDecl(p *program.Program, n *ast.EnumConstantDecl, previous string) (
 	*goast.ValueSpec, []goast.Stmt, []goast.Stmt) {
-	var value goast.Expr = util.NewIdent("iota")
+	var value goast.Expr = util.NewIntLit(0)
 	valueType := "int"
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
@@ -88,6 +91,12 @@ func transpileEnumConstantDecl(p *program.Program, n *ast.EnumConstantDecl) (
 			if err != nil {
 				panic(err)
 			}
+		} else if previous != "" {
+			value = &goast.BinaryExpr{
+				X:  util.NewIdent(previous),
+				Op: token.ADD,
+				Y:  util.NewIntLit(1),
+			}
 		}
 	}
 
@@ -102,17 +111,44 @@ func transpileEnumDecl(p *program.Program, n *ast.EnumDecl) error {
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
 
-	for _, c := range n.Children {
-		e, newPre, newPost := transpileEnumConstantDecl(p, c.(*ast.EnumConstantDecl))
-		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+	// A named enum is also a type that can be used for variables. It is an
+	// int in C, so it is also an int in Go.
+	if n.Name != "" && !p.IsTypeAlreadyDefined(n.Name) {
+		p.DefineType(n.Name)
+		p.Enums[n.Name] = true
 
 		p.File.Decls = append(p.File.Decls, &goast.GenDecl{
-			Tok: token.CONST,
+			Tok: token.TYPE,
 			Specs: []goast.Spec{
-				e,
+				&goast.TypeSpec{
+					Name: util.NewIdent(n.Name),
+					Type: util.NewTypeIdent("int"),
+				},
 			},
 		})
 	}
 
+	// All of the enumerators are in one const block. They are not left to
+	// iota because an explicit value changes the value of all the enumerators
+	// that follow it, and the values do not have to be unique.
+	constDecl := &goast.GenDecl{
+		Tok:    token.CONST,
+		Lparen: 1,
+	}
+
+	previous := ""
+	for _, c := range n.Children {
+		decl := c.(*ast.EnumConstantDecl)
+		e, newPre, newPost := transpileEnumConstantDecl(p, decl, previous)
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+		constDecl.Specs = append(constDecl.Specs, e)
+		previous = decl.Name
+	}
+
+	if len(constDecl.Specs) > 0 {
+		p.File.Decls = append(p.File.Decls, constDecl)
+	}
+
 	return nil
 }
@@ -0,0 +1,148 @@
+// This file contains the wrappers for the functions that set errno (see the
+// ErrnoFunctions option). C reports the error of a function like strtol() in
+// errno, which Go code would have to check with noarch.ErrnoLocation(). The
+// wrapper returns it as the error instead:
+//
+//     func strtolWithError(arg0 []byte, arg1 [][]byte, arg2 int) (int32, error) {
+//         noarch.ClearErrno()
+//         result := noarch.Strtol(arg0, arg1, arg2)
+//         return result, noarch.ErrnoError()
+//     }
+//
+// The error is a noarch.Errno. Each call to the function is translated into a
+// call to the wrapper (see transpileErrnoResult). The C code can still check
+// errno itself.
+
+package transpiler
+
+import (
+	"fmt"
+	"go/token"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// getErrnoWrapperName returns the name of the Go function that wraps a C
+// function that sets errno, like "strtolWithError". A different name is used
+// if the program already has a function or variable with that name.
+func getErrnoWrapperName(name string, p *program.Program) string {
+	if f, ok := p.GetErrnoFunction(name); ok {
+		return f.Wrapper
+	}
+
+	wrapper := name + "WithError"
+	if p.IsFunctionDefined(wrapper) || p.IsVariableDefined(wrapper) {
+		wrapper = p.GetNextIdentifier(wrapper + "_")
+	}
+
+	return wrapper
+}
+
+// transpileErrnoResult returns the result of a call to the wrapper of a
+// function that sets errno. The wrapper also returns the error, so it is called
+// before the expression that uses the result:
+//
+//     n = strtol(s, NULL, 10);    ->    temp0, _ := strtolWithError(s, nil, 10)
+//                                       n = temp0
+//
+// A call with a result that is not used is a statement of its own, so it does
+// not need this (see transpileToStmt).
+func transpileErrnoResult(n *ast.CallExpr, expr goast.Expr, cType string,
+	preStmts []goast.Stmt, p *program.Program) (goast.Expr, []goast.Stmt) {
+	call, ok := expr.(*goast.CallExpr)
+	if !ok || cType == "void" {
+		return expr, preStmts
+	}
+
+	name, _ := getNameOfFunctionFromCallExpr(n)
+	f, ok := p.GetErrnoFunction(name)
+	if fun, isIdent := call.Fun.(*goast.Ident); !ok || !isIdent || fun.Name != f.Wrapper {
+		return expr, preStmts
+	}
+
+	result := p.GetNextIdentifier("")
+	preStmts = append(preStmts, &goast.AssignStmt{
+		Lhs: []goast.Expr{util.NewIdent(result), util.NewIdent("_")},
+		Tok: token.DEFINE,
+		Rhs: []goast.Expr{call},
+	})
+
+	return util.NewIdent(result), preStmts
+}
+
+// newErrnoWrapper returns the declaration of the wrapper for the function.
+func newErrnoWrapper(f program.ErrnoFunction) *goast.FuncDecl {
+	params := []*goast.Field{}
+	args := []goast.Expr{}
+	variadic := false
+	for i, param := range f.Signature.Params.List {
+		name := fmt.Sprintf("arg%d", i)
+		params = append(params, &goast.Field{
+			Names: []*goast.Ident{util.NewIdent(name)},
+			Type:  param.Type,
+		})
+		args = append(args, util.NewIdent(name))
+
+		if _, ok := param.Type.(*goast.Ellipsis); ok {
+			variadic = true
+		}
+	}
+
+	call := util.NewCallExpr(f.GoFunction, args...)
+	if variadic {
+		call.Ellipsis = 1
+	}
+
+	stmts := []goast.Stmt{
+		util.NewExprStmt(util.NewCallExpr("noarch.ClearErrno")),
+	}
+	results := []goast.Expr{}
+
+	if len(f.Signature.Results.List) > 0 {
+		stmts = append(stmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent("result")},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{call},
+		})
+		results = append(results, util.NewIdent("result"))
+	} else {
+		stmts = append(stmts, util.NewExprStmt(call))
+	}
+
+	stmts = append(stmts, &goast.ReturnStmt{
+		Results: append(results, util.NewCallExpr("noarch.ErrnoError")),
+	})
+
+	resultTypes := append([]*goast.Field{}, f.Signature.Results.List...)
+	resultTypes = append(resultTypes, &goast.Field{
+		Type: util.NewTypeIdent("error"),
+	})
+
+	return &goast.FuncDecl{
+		Name: util.NewIdent(f.Wrapper),
+		Type: &goast.FuncType{
+			Params:  &goast.FieldList{List: params},
+			Results: &goast.FieldList{List: resultTypes},
+		},
+		Body: &goast.BlockStmt{List: stmts},
+	}
+}
+
+// transpileErrnoFunctions generates a wrapper for each of the ErrnoFunctions
+// that were called (see AddErrnoFunction). Like a stub, the wrapper is only
+// generated once for the package.
+func transpileErrnoFunctions(p *program.Program) {
+	for _, f := range p.GetErrnoFunctions() {
+		if p.IsFunctionDefined(f.Wrapper) {
+			continue
+		}
+
+		p.DefineFunction(f.Wrapper)
+		p.AddImport("github.com/elliotchance/c2go/noarch")
+		p.File.Decls = append(p.File.Decls, newErrnoWrapper(f))
+	}
+}
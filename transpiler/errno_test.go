@@ -0,0 +1,117 @@
+package transpiler
+
+import (
+	"testing"
+
+	goast "go/ast"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
+)
+
+func TestTranspileErrnoFunctions(t *testing.T) {
+	p := program.NewProgram()
+	p.File = &goast.File{}
+
+	p.AddErrnoFunction(program.ErrnoFunction{
+		Name:       "strtol",
+		GoFunction: "noarch.Strtol",
+		Wrapper:    "strtolWithError",
+		Signature: &goast.FuncType{
+			Params: &goast.FieldList{List: []*goast.Field{
+				{Type: util.NewTypeIdent("[]byte")},
+				{Type: util.NewTypeIdent("[][]byte")},
+				{Type: util.NewTypeIdent("int")},
+			}},
+			Results: &goast.FieldList{List: []*goast.Field{
+				{Type: util.NewTypeIdent("int32")},
+			}},
+		},
+	})
+	p.AddErrnoFunction(program.ErrnoFunction{
+		Name:       "reset",
+		GoFunction: "reset",
+		Wrapper:    "resetWithError",
+		Signature: &goast.FuncType{
+			Params: &goast.FieldList{List: []*goast.Field{
+				{Type: &goast.Ellipsis{Elt: util.NewTypeIdent("int")}},
+			}},
+			Results: &goast.FieldList{},
+		},
+	})
+	transpileErrnoFunctions(p)
+
+	expected := []string{
+		"func resetWithError(arg0 ...int) error {\n" +
+			"\tnoarch.ClearErrno()\n" +
+			"\treset(arg0...)\n" +
+			"\treturn noarch.ErrnoError()\n" +
+			"}",
+		"func strtolWithError(arg0 []byte, arg1 [][]byte, arg2 int) (int32, error) {\n" +
+			"\tnoarch.ClearErrno()\n" +
+			"\tresult := noarch.Strtol(arg0, arg1, arg2)\n" +
+			"\treturn result, noarch.ErrnoError()\n" +
+			"}",
+	}
+
+	if len(p.File.Decls) != len(expected) {
+		t.Fatalf("expected %d wrappers, got %d", len(expected), len(p.File.Decls))
+	}
+
+	for i, decl := range p.File.Decls {
+		if got := renderNode(t, decl); got != expected[i] {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected[i], got)
+		}
+	}
+
+	// The next file of the package does not need another wrapper.
+	p.File = &goast.File{}
+	transpileErrnoFunctions(p)
+
+	if len(p.File.Decls) != 0 {
+		t.Errorf("expected no wrappers in the next file, got %d", len(p.File.Decls))
+	}
+}
+
+func TestErrnoCalls(t *testing.T) {
+	p := program.NewProgram()
+	p.ErrnoFunctions["parse"] = true
+	program.AddFunctionDefinition(program.FunctionDefinition{
+		Name:          "parse",
+		ReturnType:    "int",
+		ArgumentTypes: []string{"int"},
+	})
+
+	// The wrapper cannot have the name of a function of the program.
+	p.DefineFunction("parseWithError")
+
+	// The result of a statement is not used.
+	stmt, _, _, err := transpileToStmt(newCall("int (int)", "parse",
+		&ast.IntegerLiteral{Type: "int", Value: "1"}), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The result of an expression is stored before it is used.
+	expr, _, preStmts, _, err := transpileToExpr(newCall("int (int)", "parse",
+		&ast.IntegerLiteral{Type: "int", Value: "2"}), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		node goast.Node
+		out  string
+	}{
+		{stmt, "parseWithError_0(1)"},
+		{preStmts[0], "temp1, _ := parseWithError_0(2)"},
+		{expr, "temp1"},
+	}
+
+	for _, tt := range tests {
+		if got := renderNode(t, tt.node); got != tt.out {
+			t.Errorf("expected %s, got %s", tt.out, got)
+		}
+	}
+}
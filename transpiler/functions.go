@@ -33,6 +33,40 @@ func getFunctionBody(n *ast.FunctionDecl) *ast.CompoundStmt {
 	return nil
 }
 
+// registerOldStyleFunctions registers the K&R style function definitions
+// before anything else is transpiled. These functions do not have a prototype:
+//
+//     int add(a, b)
+//     int a;
+//     char b;
+//     {
+//         return a + b;
+//     }
+//
+// The type of the function is only "int ()", the types of the arguments are in
+// the ParmVarDecl nodes. They are usually called after a declaration like
+// "int add();" which also has no arguments, so the definition is registered
+// first to make sure that the arguments are cast to the correct types.
+func registerOldStyleFunctions(n *ast.TranslationUnitDecl) {
+	for _, c := range n.Children {
+		f, ok := c.(*ast.FunctionDecl)
+		if !ok || !strings.HasSuffix(f.Type, "()") || getFunctionBody(f) == nil {
+			continue
+		}
+
+		if program.GetFunctionDefinition(f.Name) != nil {
+			continue
+		}
+
+		program.AddFunctionDefinition(program.FunctionDefinition{
+			Name:          f.Name,
+			ReturnType:    getFunctionReturnType(f.Type),
+			ArgumentTypes: getFunctionArgumentTypes(f),
+			Substitution:  "",
+		})
+	}
+}
+
 // transpileFunctionDecl transpiles the function prototype.
 //
 // The function prototype may also have a body. If it does have a body the whole
@@ -73,10 +107,29 @@ func transpileFunctionDecl(n *ast.FunctionDecl, p *program.Program) error {
 		return nil
 	}
 
+	if _, ok := p.GetFunctionMapping(n.Name); ok {
+		return nil
+	}
+
 	// Test if the function has a body. This is identified by a child node that
 	// is a CompoundStmt (since it is not valid to have a function body without
 	// curly brackets).
 	functionBody := getFunctionBody(n)
+
+	// A static or inline function that is defined in a header is only emitted
+	// once for the package.
+	if functionBody != nil && p.IsDuplicateFunction(n.Name) {
+		return nil
+	}
+
+	// The parameters are in the scope of the function body.
+	p.StartScope()
+	defer p.EndScope()
+
+	for _, v := range getParmVarDecls(n) {
+		p.AddVariableType(v.Name, v.Type)
+	}
+
 	if functionBody != nil {
 		var err error
 
@@ -134,11 +187,14 @@ func transpileFunctionDecl(n *ast.FunctionDecl, p *program.Program) error {
 			prependStmtsInMain := []goast.Stmt{}
 
 			// We also need to append a setup function that will instantiate
-			// some things that are expected to be available at runtime.
-			prependStmtsInMain = append(
-				prependStmtsInMain,
-				util.NewExprStmt(util.NewCallExpr("__init")),
-			)
+			// some things that are expected to be available at runtime. Go
+			// calls the init() functions itself.
+			if !p.GoInit {
+				prependStmtsInMain = append(
+					prependStmtsInMain,
+					util.NewExprStmt(util.NewCallExpr("__init")),
+				)
+			}
 
 			// In Go, the main() function does not take the system arguments.
 			// Instead they are accessed through the os package. We create new
@@ -189,12 +245,22 @@ func transpileFunctionDecl(n *ast.FunctionDecl, p *program.Program) error {
 			// Prepend statements for main().
 			body.List = append(prependStmtsInMain, body.List...)
 
+			// The destructors are only called by noarch.Exit(), so it is also
+			// used when main() reaches the end.
+			if p.HasDestructors && !isTerminatingStmt(body.List) {
+				p.AddImport("github.com/elliotchance/c2go/noarch")
+				body.List = append(body.List,
+					util.NewExprStmt(util.NewCallExpr("noarch.Exit", util.NewIntLit(0))))
+			}
+
 			// The main() function does not have arguments or a return value.
 			fieldList = &goast.FieldList{}
 		}
 
+		registerConstructor(n, p)
+
 		p.File.Decls = append(p.File.Decls, &goast.FuncDecl{
-			Name: util.NewIdent(n.Name),
+			Name: util.NewIdent(p.GetFunctionName(n.Name)),
 			Type: &goast.FuncType{
 				Params: fieldList,
 				Results: &goast.FieldList{
@@ -211,16 +277,33 @@ func transpileFunctionDecl(n *ast.FunctionDecl, p *program.Program) error {
 // getFieldList returns the parameters of a C function as a Go AST FieldList.
 func getFieldList(f *ast.FunctionDecl, p *program.Program) (*goast.FieldList, error) {
 	r := []*goast.Field{}
-	for _, n := range f.Children {
-		if v, ok := n.(*ast.ParmVarDecl); ok {
-			t, err := types.ResolveType(p, v.Type)
-			p.AddMessage(ast.GenerateWarningMessage(err, f))
-
-			r = append(r, &goast.Field{
-				Names: []*goast.Ident{util.NewIdent(v.Name)},
-				Type:  util.NewTypeIdent(t),
-			})
+	definition := program.GetFunctionDefinition(f.Name)
+	for i, v := range getParmVarDecls(f) {
+		// The parameters that are Go strings are registered with a different
+		// type. See registerStringParameters().
+		cType := v.Type
+		if definition != nil && i < len(definition.ArgumentTypes) &&
+			definition.ArgumentTypes[i] == stringParameterType {
+			cType = stringParameterType
 		}
+
+		t, err := types.ResolveType(p, cType)
+		p.AddMessage(ast.GenerateWarningMessage(err, f))
+
+		r = append(r, &goast.Field{
+			Names: []*goast.Ident{util.NewIdent(v.Name)},
+			Type:  util.NewTypeIdent(t),
+		})
+	}
+
+	// The variadic arguments in C do not have a name.
+	if isVariadicFunction(f) {
+		r = append(r, &goast.Field{
+			Names: []*goast.Ident{util.NewIdent(variadicArgumentsName)},
+			Type: &goast.Ellipsis{
+				Elt: util.NewTypeIdent("interface{}"),
+			},
+		})
 	}
 
 	return &goast.FieldList{
@@ -233,6 +316,12 @@ func transpileReturnStmt(n *ast.ReturnStmt, p *program.Program) (
 	// There may not be a return value. Then we don't have to both ourselves
 	// with all the rest of the logic below.
 	if len(n.Children) == 0 {
+		if p.Function != nil && p.Function.Name == "main" && p.HasDestructors {
+			p.AddImport("github.com/elliotchance/c2go/noarch")
+			return util.NewExprStmt(util.NewCallExpr("noarch.Exit", util.NewIntLit(0))),
+				nil, nil, nil
+		}
+
 		return &goast.ReturnStmt{}, nil, nil, nil
 	}
 
@@ -248,11 +337,22 @@ func transpileReturnStmt(n *ast.ReturnStmt, p *program.Program) (
 		t = util.NewNil()
 	}
 
+	// A struct is returned by value. The caller gets its own copy of the
+	// arrays, so that changing them does not change the variable that was
+	// returned, like a global or static variable.
+	t = transpileStructCopy(n.Children[0], t, f.ReturnType, p)
+
 	results := []goast.Expr{t}
 
 	// main() function is not allowed to return a result. Use os.Exit if
-	// non-zero.
+	// non-zero. noarch.Exit is always used if there are destructors to call.
 	if p.Function != nil && p.Function.Name == "main" {
+		if p.HasDestructors {
+			p.AddImport("github.com/elliotchance/c2go/noarch")
+			return util.NewExprStmt(util.NewCallExpr("noarch.Exit", results...)),
+				preStmts, postStmts, nil
+		}
+
 		litExpr, isLiteral := e.(*goast.BasicLit)
 		if !isLiteral || (isLiteral && litExpr.Value != "0") {
 			p.AddImport("os")
@@ -0,0 +1,112 @@
+// This file contains the transpiling of C11 _Generic() selections. A selection
+// is resolved when it is transpiled, so only the expression of the type that
+// matches the controlling expression is in the Go code:
+//
+//     #define abs(x) _Generic((x), int: abs, double: fabs)(x)
+//
+//     abs(-1.5)    ->    math.Abs(-1.5)
+//
+// The controlling expression is not evaluated.
+
+package transpiler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+
+	goast "go/ast"
+)
+
+func transpileGenericSelectionExpr(n *ast.GenericSelectionExpr, p *program.Program) (
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	selected, err := getGenericSelection(n, p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	return transpileToExpr(selected, p)
+}
+
+// getGenericSelection returns the expression of the association of n that is
+// selected by the type of the controlling expression. A newer clang marks the
+// association that it selected. Otherwise the association is the first one of
+// the same C type, or of the same Go type, or the default association.
+func getGenericSelection(n *ast.GenericSelectionExpr, p *program.Program) (ast.Node, error) {
+	if len(n.Children) == 0 {
+		return nil, errors.New("_Generic() does not have a controlling expression")
+	}
+
+	_, controllingType, _, _, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, err
+	}
+
+	// A type that cannot be resolved can still be the same C type as one of
+	// the associations.
+	goType, _ := types.ResolveType(p, controllingType)
+
+	var sameGoType, defaultExpr ast.Node
+
+	for _, c := range n.Children[1:] {
+		a, ok := c.(*ast.GenericAssociation)
+		if !ok || len(a.Children) == 0 {
+			return nil, errors.New("the types of the _Generic() associations are not in the AST")
+		}
+
+		e := a.Children[len(a.Children)-1]
+
+		switch {
+		case a.Selected:
+			return e, nil
+
+		case a.IsDefault:
+			defaultExpr = e
+
+		case a.Type == controllingType:
+			return e, nil
+
+		case sameGoType == nil:
+			if t, err := types.ResolveType(p, a.Type); err == nil && goType != "" && t == goType {
+				sameGoType = e
+			}
+		}
+	}
+
+	if sameGoType != nil {
+		return sameGoType, nil
+	}
+
+	if defaultExpr != nil {
+		return defaultExpr, nil
+	}
+
+	return nil, fmt.Errorf("no _Generic() association for %s", controllingType)
+}
+
+// getGenericSelectionCall returns the call of the function that is selected by
+// a _Generic() selection, like "_Generic((x), int: abs, double: fabs)(x)". nil
+// is returned if the function of n is not a selection.
+func getGenericSelectionCall(n *ast.CallExpr, p *program.Program) (*ast.CallExpr, error) {
+	g, ok := removeCastsAndParens(n.Children[0]).(*ast.GenericSelectionExpr)
+	if !ok {
+		return nil, nil
+	}
+
+	selected, err := getGenericSelection(g, p)
+	if err != nil {
+		return nil, err
+	}
+
+	call := *n
+	call.Children = append([]ast.Node{&ast.ImplicitCastExpr{
+		Kind:     "FunctionToPointerDecay",
+		Type:     g.Type,
+		Children: []ast.Node{selected},
+	}}, n.Children[1:]...)
+
+	return &call, nil
+}
@@ -0,0 +1,327 @@
+// This file contains functions for transpiling goto statements and labels.
+//
+// A goto that jumps to the label directly after a loop that it is inside of is
+// a break out of more than one loop. It is transpiled to a labeled break
+// instead:
+//
+//     for (i = 0; i < 3; i++) {        outer:
+//         for (j = 0; j < 3; j++) {    for i = 0; i < 3; i++ {
+//             if (i * j == 2)              for j = 0; j < 3; j++ {
+//                 goto outer;      ->          if i*j == 2 {
+//         }                                        break outer
+//     }                                        }
+//     outer:                               }
+//     done();                          }
+//                                      done()
+
+package transpiler
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// getChildren returns the Children of any ast node, or nil if the node does
+// not have children.
+func getChildren(n ast.Node) []ast.Node {
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+
+	f := v.Elem().FieldByName("Children")
+	if !f.IsValid() {
+		return nil
+	}
+
+	children, _ := f.Interface().([]ast.Node)
+	return children
+}
+
+// findPath returns the nodes from root down to (and including) target. If
+// target is not in the tree then nil is returned.
+func findPath(root, target ast.Node) []ast.Node {
+	if root == target {
+		return []ast.Node{root}
+	}
+
+	for _, c := range getChildren(root) {
+		if c == nil {
+			continue
+		}
+
+		if path := findPath(c, target); path != nil {
+			return append([]ast.Node{root}, path...)
+		}
+	}
+
+	return nil
+}
+
+// findLabel returns the label with the name provided, or nil if it cannot be
+// found.
+func findLabel(root ast.Node, name string) *ast.LabelStmt {
+	labels := ast.GetAllNodesOfType(root, reflect.TypeOf((*ast.LabelStmt)(nil)))
+	for _, l := range labels {
+		if l.(*ast.LabelStmt).Name == name {
+			return l.(*ast.LabelStmt)
+		}
+	}
+
+	return nil
+}
+
+// canTranspileGoto returns an error if the goto cannot be converted directly
+// into a Go goto. C allows jumping into blocks and over variable declarations,
+// where Go does not.
+func canTranspileGoto(p *program.Program, n *ast.GotoStmt) error {
+	return canJumpToLabel(p, n, n.Name)
+}
+
+// canJumpToLabel returns an error if a Go goto at the statement n cannot jump
+// to the label with the name provided (see canTranspileGoto).
+func canJumpToLabel(p *program.Program, n ast.Node, name string) error {
+	if p.Function == nil {
+		return errors.New("goto is outside of a function")
+	}
+
+	label := findLabel(p.Function, name)
+	if label == nil {
+		return fmt.Errorf("cannot find label %s", name)
+	}
+
+	labelPath := findPath(p.Function, label)
+	gotoPath := findPath(p.Function, n)
+
+	// The label must be directly inside a block and the goto must be somewhere
+	// inside the same block. Otherwise the goto would be jumping into a block.
+	depth := len(labelPath) - 2
+	block, ok := labelPath[depth].(*ast.CompoundStmt)
+	if !ok || len(gotoPath) <= depth+1 || gotoPath[depth] != block {
+		return fmt.Errorf("goto %s jumps into a block", name)
+	}
+
+	gotoIndex, labelIndex := -1, -1
+	for i, c := range block.Children {
+		if c == gotoPath[depth+1] {
+			gotoIndex = i
+		}
+		if c == label {
+			labelIndex = i
+		}
+	}
+
+	// Jumping backwards is always safe. Jumping forwards must not skip over
+	// any variable declarations.
+	for i := gotoIndex + 1; i < labelIndex; i++ {
+		if decl, ok := block.Children[i].(*ast.DeclStmt); ok {
+			for _, c := range decl.Children {
+				if v, ok := c.(*ast.VarDecl); ok {
+					return fmt.Errorf("goto %s jumps over the declaration of %s",
+						name, v.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// getLoopBody returns the body of a loop, or nil if the node is not a loop.
+func getLoopBody(n ast.Node) ast.Node {
+	switch l := n.(type) {
+	case *ast.ForStmt:
+		return l.Children[4]
+	case *ast.WhileStmt:
+		return l.Children[2]
+	case *ast.DoStmt:
+		return l.Children[0]
+	}
+
+	return nil
+}
+
+// getBreakLabel returns the label that is directly after the loop if every
+// goto to that label is a break out of the loop. Otherwise it returns nil.
+func getBreakLabel(p *program.Program, loop ast.Node) *ast.LabelStmt {
+	if p.Function == nil || getLoopBody(loop) == nil {
+		return nil
+	}
+
+	path := findPath(p.Function, loop)
+	if len(path) < 2 {
+		return nil
+	}
+
+	// The loops of a switch that has nested cases are flattened, so there is
+	// no loop to break out of.
+	for _, n := range path {
+		if s, ok := n.(*ast.SwitchStmt); ok {
+			body, ok := s.Children[len(s.Children)-1].(*ast.CompoundStmt)
+			if !ok || hasNestedCases(body) {
+				return nil
+			}
+		}
+	}
+
+	block, ok := path[len(path)-2].(*ast.CompoundStmt)
+	if !ok {
+		return nil
+	}
+
+	var label *ast.LabelStmt
+	for i, c := range block.Children[:len(block.Children)-1] {
+		if c == loop {
+			label, _ = block.Children[i+1].(*ast.LabelStmt)
+		}
+	}
+
+	// A computed goto must be able to jump to the label itself.
+	if label == nil || isLabelAddressTaken(p, label.Name) {
+		return nil
+	}
+
+	found := false
+	gotos := ast.GetAllNodesOfType(p.Function, reflect.TypeOf((*ast.GotoStmt)(nil)))
+	for _, g := range gotos {
+		if g.(*ast.GotoStmt).Name != label.Name {
+			continue
+		}
+
+		gotoPath := findPath(loop, g)
+		if len(gotoPath) < 2 || gotoPath[1] != getLoopBody(loop) {
+			return nil
+		}
+
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+
+	return label
+}
+
+// isBreakGoto returns true if the goto is transpiled to a labeled break.
+func isBreakGoto(p *program.Program, n *ast.GotoStmt) bool {
+	label := findLabel(p.Function, n.Name)
+	if label == nil {
+		return false
+	}
+
+	path := findPath(p.Function, label)
+	block, ok := path[len(path)-2].(*ast.CompoundStmt)
+	if !ok {
+		return false
+	}
+
+	for i, c := range block.Children[1:] {
+		if c == label {
+			return getBreakLabel(p, block.Children[i]) == label
+		}
+	}
+
+	return false
+}
+
+// labelLoop adds the label to the Go loop of a C loop that is broken out of by
+// a goto.
+func labelLoop(loop ast.Node, stmt goast.Stmt, p *program.Program) goast.Stmt {
+	label := getBreakLabel(p, loop)
+	if label == nil {
+		return stmt
+	}
+
+	// The declarations in the initialization of a for loop are before the
+	// loop, inside of a block.
+	if block, ok := stmt.(*goast.BlockStmt); ok {
+		for i, s := range block.List {
+			if _, ok := s.(*goast.ForStmt); ok {
+				block.List[i] = &goast.LabeledStmt{
+					Label: util.NewIdent(label.Name),
+					Stmt:  s,
+				}
+			}
+		}
+
+		return block
+	}
+
+	return &goast.LabeledStmt{
+		Label: util.NewIdent(label.Name),
+		Stmt:  stmt,
+	}
+}
+
+func transpileGotoStmt(n *ast.GotoStmt, p *program.Program) (goast.Stmt, error) {
+	if p.Function != nil && isBreakGoto(p, n) {
+		return &goast.BranchStmt{
+			Tok:   token.BREAK,
+			Label: util.NewIdent(n.Name),
+		}, nil
+	}
+
+	if err := canTranspileGoto(p, n); err != nil {
+		// The goto is replaced with a panic so that the generated code still
+		// compiles, but it will be obvious at runtime if it is reached.
+		message := fmt.Sprintf("FIXME: %s", err.Error())
+		p.AddMessage(ast.GenerateWarningMessage(errors.New(message), n))
+
+		return util.NewExprStmt(
+			util.NewCallExpr("panic", util.NewStringLit(fmt.Sprintf("%q", message))),
+		), nil
+	}
+
+	return &goast.BranchStmt{
+		Tok:   token.GOTO,
+		Label: util.NewIdent(n.Name),
+	}, nil
+}
+
+func transpileLabelStmt(n *ast.LabelStmt, p *program.Program) (
+	goast.Stmt, []goast.Stmt, []goast.Stmt, error) {
+	var stmts []goast.Stmt
+	if len(n.Children) > 0 {
+		var err error
+		stmts, err = transpileToStmts(n.Children[0], p)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if len(stmts) == 0 || stmts[0] == nil {
+		stmts = []goast.Stmt{&goast.EmptyStmt{}}
+	}
+
+	// Go does not allow a label that is never used. A label is only used if
+	// at least one goto (including a computed goto) could be transpiled to
+	// jump to it. The label after a loop is not used when all of the gotos are
+	// breaks.
+	used := isIndirectGotoLabel(p, n.Name)
+	gotos := ast.GetAllNodesOfType(p.Function, reflect.TypeOf((*ast.GotoStmt)(nil)))
+	for _, g := range gotos {
+		if g.(*ast.GotoStmt).Name == n.Name && canTranspileGoto(p, g.(*ast.GotoStmt)) == nil &&
+			!isBreakGoto(p, g.(*ast.GotoStmt)) {
+			used = true
+			break
+		}
+	}
+
+	if !used {
+		return stmts[0], nil, stmts[1:], nil
+	}
+
+	return &goast.LabeledStmt{
+		Label: util.NewIdent(n.Name),
+		Stmt:  stmts[0],
+	}, nil, stmts[1:], nil
+}
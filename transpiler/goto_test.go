@@ -0,0 +1,68 @@
+package transpiler
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func TestComputedGoto(t *testing.T) {
+	newAddress := func(variable, label string) ast.Node {
+		return &ast.DeclStmt{Children: []ast.Node{
+			&ast.VarDecl{Name: variable, Type: "void *", Children: []ast.Node{
+				&ast.AddrLabelExpr{Type: "void *", Name: label},
+			}},
+		}}
+	}
+
+	// void *next = &&done;
+	// void *other = &&inner;
+	// goto *next;
+	// { inner: {} }
+	// done: {}
+	body := &ast.CompoundStmt{Children: []ast.Node{
+		newAddress("next", "done"),
+		newAddress("other", "inner"),
+		&ast.IndirectGotoStmt{Children: []ast.Node{
+			newRValue("void *", newVarRef("next", "void *")),
+		}},
+		&ast.CompoundStmt{Children: []ast.Node{
+			&ast.LabelStmt{Name: "inner", Children: []ast.Node{&ast.CompoundStmt{}}},
+		}},
+		&ast.LabelStmt{Name: "done", Children: []ast.Node{&ast.CompoundStmt{}}},
+	}}
+
+	p := program.NewProgram()
+	p.Function = &ast.FunctionDecl{Name: "f", Type: "void (void)", Children: []ast.Node{body}}
+
+	stmt, _, _, err := transpileToStmt(body, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The label inside of the block cannot be jumped to in Go.
+	expected := `{
+	var next []byte = noarch.LabelAddress(1)
+	var other []byte = noarch.LabelAddress(2)
+	switch noarch.LabelIndex(next) {
+	case 1:
+		goto done
+	case 2:
+		panic("FIXME: goto inner jumps into a block")
+	default:
+		panic("goto to an address that is not a label")
+	}
+	{
+		{
+		}
+	}
+done:
+	{
+	}
+}`
+
+	if got := renderNode(t, stmt); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
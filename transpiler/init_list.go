@@ -0,0 +1,235 @@
+// This file contains transpiling for initializer lists, like:
+//
+//     int a[3] = {1, 2, 3};
+//     struct point p = {.x = 1, .y = 2};
+
+package transpiler
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+
+	goast "go/ast"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+)
+
+// transpileInitListExpr converts an initializer list into a Go composite
+// literal.
+//
+// The initializer list that clang provides has already resolved the
+// designators, so the children are in the order of the struct fields or array
+// elements. Any element that was not initialized is an ImplicitValueInitExpr.
+// These are left out of the composite literal and the elements that follow
+// them are keyed so that they keep the same position:
+//
+//     int a[5] = {1, [3] = 4, 5};   ->   []int{1, 3: 4, 5}
+//     struct point p = {.y = 2};    ->   point{y: 2}
+func transpileInitListExpr(n *ast.InitListExpr, p *program.Program) (
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	preStmts := []goast.Stmt{}
+	postStmts := []goast.Stmt{}
+
+	cType := n.Type
+	if n.Type2 != "" {
+		cType = n.Type2
+	}
+
+	goType, err := types.ResolveType(p, n.Type)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	arrayType, arraySize := types.GetArrayTypeAndSize(cType)
+
+	var s *program.Struct
+	if arraySize == -1 {
+		s = p.GetStruct(cType)
+		if s == nil || s.IsUnion {
+			return nil, "", nil, nil,
+				fmt.Errorf("cannot use an initializer list for %s", cType)
+		}
+	}
+
+	elts := []goast.Expr{}
+	index := 0
+	length := 0
+	needsKey := false
+	initialized := map[string]bool{}
+
+	for _, c := range n.Children {
+		value := c
+		var key goast.Expr
+
+		switch v := c.(type) {
+		case *ast.ArrayFiller:
+			// The remaining elements are zero. They are added after the loop.
+			continue
+
+		case *ast.ImplicitValueInitExpr:
+			// The arrays of an element that is not initialized are still
+			// allocated. The fields of a struct are checked after the loop.
+			if arraySize != -1 {
+				if zero := newZeroValue(n, arrayType, p); zero != nil {
+					elts = append(elts, &goast.KeyValueExpr{
+						Key:   util.NewIntLit(index),
+						Value: zero,
+					})
+					length = index + 1
+				}
+			}
+
+			index++
+			needsKey = true
+			continue
+
+		case *ast.DesignatedInitExpr:
+			value = v.Children[len(v.Children)-1]
+
+			// An array designator has the index as the first child.
+			if arraySize != -1 && len(v.Children) > 1 {
+				literal, ok := v.Children[0].(*ast.IntegerLiteral)
+				if !ok {
+					return nil, "", nil, nil,
+						errors.New("array designators must be an integer literal")
+				}
+
+				index = util.Atoi(literal.Value)
+				needsKey = true
+			}
+		}
+
+		var elementType string
+		if arraySize != -1 {
+			if needsKey {
+				key = util.NewIntLit(index)
+			}
+			elementType = arrayType
+
+			// The elements of a char array are bytes, even if a char is signed.
+			if types.IsSignedChar(p, elementType) {
+				elementType = "unsigned char"
+			}
+		} else {
+			if index >= len(s.FieldNames) {
+				return nil, "", nil, nil,
+					fmt.Errorf("too many initializers for %s", cType)
+			}
+
+			fieldName := s.FieldNames[index]
+			switch f := s.Fields[fieldName].(type) {
+			case string:
+				elementType = f
+
+			case *program.Struct:
+				// A nested struct or union is stored as its definition.
+				elementType = "struct " + f.Name
+				if f.IsUnion {
+					elementType = "union " + f.Name
+				}
+			}
+
+			if s.Bitfields[fieldName] != nil {
+				return nil, "", nil, nil,
+					fmt.Errorf("cannot initialize the bitfield %s with an initializer list", fieldName)
+			}
+
+			fieldName = getFieldName(p, fieldName)
+			key = util.NewIdent(fieldName)
+			initialized[fieldName] = true
+		}
+
+		e, eType, newPre, newPost, err := transpileToExpr(value, p)
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+
+		preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+		e, err = types.CastExpr(p, e, eType, elementType)
+		p.AddMessage(ast.GenerateWarningMessage(err, n))
+		e = transpileStructCopy(value, e, elementType, p)
+
+		if key != nil {
+			e = &goast.KeyValueExpr{
+				Key:   key,
+				Value: e,
+			}
+		}
+
+		elts = append(elts, e)
+		index++
+		length = index
+		needsKey = false
+	}
+
+	// The rows of a multidimensional array (or the arrays of the structs) that
+	// were not initialized have to be allocated as well.
+	if arraySize != -1 {
+		if zero := newZeroValue(n, arrayType, p); zero != nil {
+			for ; length < arraySize; length++ {
+				elts = append(elts, zero)
+			}
+		}
+	}
+
+	// The same is done for the fields of a struct.
+	if s != nil {
+		goNames, cTypes := getStructArrayFields(p, s)
+		for i, fieldType := range cTypes {
+			if !initialized[goNames[i]] {
+				elts = append(elts, &goast.KeyValueExpr{
+					Key:   util.NewIdent(goNames[i]),
+					Value: newZeroValue(n, fieldType, p),
+				})
+			}
+		}
+	}
+
+	var expr goast.Expr = &goast.CompositeLit{
+		Type: util.NewTypeIdent(goType),
+		Elts: elts,
+	}
+
+	// A C array always has all of its elements, even if they were not all
+	// initialized. The rest are filled with zero values:
+	//
+	//     append([]int{1, 2}, make([]int, 3)...)
+	if arraySize != -1 && length < arraySize {
+		expr = &goast.CallExpr{
+			Fun: util.NewIdent("append"),
+			Args: []goast.Expr{
+				expr,
+				util.NewCallExpr("make",
+					util.NewTypeIdent(goType),
+					util.NewIntLit(arraySize-length),
+				),
+			},
+			Ellipsis: token.Pos(1),
+		}
+	}
+
+	return expr, n.Type, preStmts, postStmts, nil
+}
+
+// transpileCompoundLiteralExpr converts a compound literal into a Go composite
+// literal. The child of the compound literal is its initializer list:
+//
+//     (struct point){.y = 2}     ->   point{y: 2}
+//     &(struct point){.y = 2}    ->   &point{y: 2}
+//
+// A compound literal has automatic storage in C. Go allocates the composite
+// literal on the heap instead if its address outlives the function.
+func transpileCompoundLiteralExpr(n *ast.CompoundLiteralExpr, p *program.Program) (
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	expr, _, preStmts, postStmts, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	return expr, n.Type, preStmts, postStmts, nil
+}
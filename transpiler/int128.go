@@ -0,0 +1,132 @@
+// This file contains the operators of the 128-bit integers, "__int128" and
+// "unsigned __int128". Go does not have a 128-bit integer so they are the
+// noarch.Int128 and noarch.Uint128 structs, and each operator is a method:
+//
+//     __int128 a, b;
+//     a * b + 1;      ->    a.Mul(b).Add(noarch.Int64ToInt128(1))
+//     a < b;          ->    a.Cmp(b) < 0
+//     a += b;         ->    a = a.Add(b)
+//     -a;             ->    a.Neg()
+
+package transpiler
+
+import (
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+	"go/token"
+)
+
+// int128Methods are the methods of noarch.Int128 and noarch.Uint128 for the
+// arithmetic and bitwise operators.
+var int128Methods = map[string]string{
+	"+":  "Add",
+	"-":  "Sub",
+	"*":  "Mul",
+	"/":  "Div",
+	"%":  "Rem",
+	"&":  "And",
+	"|":  "Or",
+	"^":  "Xor",
+	"<<": "Shl",
+	">>": "Shr",
+}
+
+// transpileInt128BinaryOperator returns the method call for a binary operator,
+// or a compound assignment like "+=", on a 128-bit integer. The second return
+// value is the C type of the result. The third return value is false if the
+// left operand is not a 128-bit integer.
+//
+// Clang casts both operands of most operators to the same type. The right
+// operand of a shift is not cast so it becomes the uint64 that Shl() and Shr()
+// expect.
+func transpileInt128BinaryOperator(n ast.Node, operator string, left goast.Expr,
+	leftType string, right goast.Expr, rightType string, p *program.Program) (
+	goast.Expr, string, bool) {
+	if !types.IsInt128(p, leftType) {
+		return nil, "", false
+	}
+
+	isComparison := false
+	switch operator {
+	case "==", "!=", "<", ">", "<=", ">=":
+		isComparison = true
+	}
+
+	isAssignment := !isComparison && operator[len(operator)-1] == '='
+	if isAssignment {
+		operator = operator[:len(operator)-1]
+	}
+
+	method, ok := int128Methods[operator]
+	if !ok && !isComparison {
+		return nil, "", false
+	}
+
+	operandType := leftType
+	if operator == "<<" || operator == ">>" {
+		operandType = "unsigned long long"
+	}
+
+	right, err := types.CastExpr(p, right, rightType, operandType)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	if isComparison {
+		cmp := newMethodCall(left, "Cmp", right)
+
+		return util.NewBinaryExpr(cmp, getTokenForOperator(operator), util.NewIntLit(0)),
+			"bool", true
+	}
+
+	e := newMethodCall(left, method, right)
+	if isAssignment {
+		return util.NewBinaryExpr(left, token.ASSIGN, e), leftType, true
+	}
+
+	return e, leftType, true
+}
+
+// transpileInt128UnaryOperator returns the expression of a unary operator on a
+// 128-bit integer. The second return value is false if the operand is not a
+// 128-bit integer or the operator does not need a method.
+func transpileInt128UnaryOperator(operator string, e goast.Expr, eType string,
+	p *program.Program) (goast.Expr, string, bool) {
+	if !types.IsInt128(p, eType) {
+		return nil, "", false
+	}
+
+	switch operator {
+	case "-":
+		return newMethodCall(e, "Neg"), eType, true
+
+	case "~":
+		return newMethodCall(e, "Not"), eType, true
+
+	case "!":
+		goType, _ := types.ResolveType(p, eType)
+		zero := &goast.ParenExpr{X: &goast.CompositeLit{Type: util.NewTypeIdent(goType)}}
+
+		return util.NewBinaryExpr(e, token.EQL, zero), "bool", true
+	}
+
+	return nil, "", false
+}
+
+// newMethodCall returns the call of a method of the Go expression, like
+// "a.Add(b)".
+func newMethodCall(e goast.Expr, method string, args ...goast.Expr) *goast.CallExpr {
+	switch e.(type) {
+	case *goast.Ident, *goast.SelectorExpr, *goast.IndexExpr, *goast.CallExpr,
+		*goast.ParenExpr:
+	default:
+		e = &goast.ParenExpr{X: e}
+	}
+
+	return &goast.CallExpr{
+		Fun:  &goast.SelectorExpr{X: e, Sel: util.NewIdent(method)},
+		Args: args,
+	}
+}
@@ -6,13 +6,18 @@ package transpiler
 import (
 	"fmt"
 	"go/token"
+	"math"
 
 	goast "go/ast"
 
 	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"github.com/elliotchance/c2go/ast"
 	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
 	"github.com/elliotchance/c2go/util"
 )
 
@@ -20,16 +25,201 @@ func transpileFloatingLiteral(n *ast.FloatingLiteral) *goast.BasicLit {
 	return util.NewFloatLit(n.Value)
 }
 
-func transpileStringLiteral(n *ast.StringLiteral) goast.Expr {
-	return util.NewCallExpr("[]byte",
-		util.NewStringLit(strconv.Quote(n.Value+"\x00")))
+// transpileImaginaryLiteral transpiles the imaginary part of a complex number,
+// like "2.5i". The value is always a number literal.
+func transpileImaginaryLiteral(n *ast.ImaginaryLiteral, p *program.Program) (
+	*goast.BasicLit, string, error) {
+	e, _, _, _, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	value, ok := e.(*goast.BasicLit)
+	if !ok {
+		return nil, "", fmt.Errorf("imaginary literal is not a number: %#v", n.Children[0])
+	}
+
+	return &goast.BasicLit{
+		Kind:  token.IMAG,
+		Value: value.Value + "i",
+	}, n.Type, nil
 }
 
-func transpileIntegerLiteral(n *ast.IntegerLiteral) *goast.BasicLit {
+// transpileStringLiteral creates a byte slice that includes the NULL
+// terminator. Adjacent string literals in C, like "foo" "bar", are already
+// concatenated by clang into a single StringLiteral with the escapes in each
+// part decoded.
+//
+// A wide string literal is a slice of the code units instead:
+//
+//     L"Ωx"    // []int32{'Ω', 'x', 0}
+//     u"Ωx"    // []uint16{'Ω', 'x', 0}
+//     U"Ωx"    // []uint32{'Ω', 'x', 0}
+//
+// A UTF-8 string literal, like u8"Ωx", is the same as a narrow string.
+//
+// The type of a string literal that initializes an array is the type of the
+// array. The rest of the array is filled with zeros, or the NULL terminator is
+// left out if there is not enough room for it:
+//
+//     char s[8] = "abc";    // append([]byte("abc\x00"), make([]byte, 4)...)
+//     char t[3] = "abc";    // []byte("abc")
+func transpileStringLiteral(n *ast.StringLiteral) (goast.Expr, string) {
+	var units []rune
+	var elementType, cType string
+
+	_, arraySize := types.GetArrayTypeAndSize(n.Type)
+
+	switch n.Prefix {
+	case "L":
+		units = []rune(n.Value)
+		elementType, cType = "int32", "const wchar_t *"
+
+	case "u":
+		for _, unit := range utf16.Encode([]rune(n.Value)) {
+			units = append(units, rune(unit))
+		}
+		elementType, cType = "uint16", "const char16_t *"
+
+	case "U":
+		units = []rune(n.Value)
+		elementType, cType = "uint32", "const char32_t *"
+
+	default:
+		value := n.Value + "\x00"
+		if arraySize != -1 && arraySize < len(value) {
+			value = value[:arraySize]
+		}
+
+		expr := util.NewCallExpr("[]byte", util.NewStringLit(strconv.Quote(value)))
+
+		return padStringLiteral(expr, "[]byte", len(value), arraySize), "const char *"
+	}
+
+	units = append(units, 0)
+	if arraySize != -1 && arraySize < len(units) {
+		units = units[:arraySize]
+	}
+
+	elts := []goast.Expr{}
+	for _, unit := range units {
+		elts = append(elts, newCodeUnitLit(unit))
+	}
+
+	expr := &goast.CompositeLit{
+		Type: util.NewTypeIdent("[]" + elementType),
+		Elts: elts,
+	}
+
+	return padStringLiteral(expr, "[]"+elementType, len(units), arraySize), cType
+}
+
+// padStringLiteral appends the zeros after a string literal of length elements
+// that initializes an array of arraySize elements.
+func padStringLiteral(expr goast.Expr, goType string, length, arraySize int) goast.Expr {
+	if length >= arraySize {
+		return expr
+	}
+
+	return &goast.CallExpr{
+		Fun: util.NewIdent("append"),
+		Args: []goast.Expr{
+			expr,
+			util.NewCallExpr("make", util.NewTypeIdent(goType), util.NewIntLit(arraySize-length)),
+		},
+		Ellipsis: token.Pos(1),
+	}
+}
+
+// newCodeUnitLit creates a character literal for a code unit of a wide string.
+// The NULL terminator and the halves of a UTF-16 surrogate pair are not valid
+// characters so they are integers.
+func newCodeUnitLit(unit rune) *goast.BasicLit {
+	if unit == 0 || !utf8.ValidRune(unit) {
+		return util.NewIntLit(int(unit))
+	}
+
 	return &goast.BasicLit{
+		Kind:  token.CHAR,
+		Value: strconv.QuoteRune(unit),
+	}
+}
+
+// transpileIntegerLiteral returns the Go constant of an integer literal. clang
+// gives the decimal value of the literal, but the suffixes of C (like the "UL"
+// of "10UL") are removed if they are there because they are not valid in Go.
+// The hexadecimal, octal and binary notations are the same in Go.
+//
+// A constant that does not fit in an int is converted to its type. It would
+// overflow if it is used as an untyped constant:
+//
+//     18446744073709551615ULL    ->    uint64(18446744073709551615)
+//
+// A negative constant of an unsigned type wraps around, like it does in C.
+func transpileIntegerLiteral(n *ast.IntegerLiteral, p *program.Program) (
+	goast.Expr, string, error) {
+	value := strings.TrimRight(n.Value, "uUlL")
+	negative := strings.HasPrefix(value, "-")
+	value = strings.Replace(strings.TrimPrefix(value, "-"), "'", "_", -1)
+
+	u, err := strconv.ParseUint(value, 0, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid integer literal: %s", n.Value)
+	}
+
+	var expr goast.Expr = &goast.BasicLit{
 		Kind:  token.INT,
-		Value: n.Value,
+		Value: value,
 	}
+
+	if !negative && u <= math.MaxInt64 {
+		return expr, n.Type, nil
+	}
+
+	goType, err := types.ResolveType(p, n.Type)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !negative {
+		return util.NewCallExpr(goType, expr), n.Type, nil
+	}
+
+	if strings.HasPrefix(goType, "uint") || goType == "byte" {
+		size, err := types.SizeOf(p, n.Type)
+		if err != nil {
+			return nil, "", err
+		}
+
+		u = -u
+		if size < 8 {
+			u &= 1<<uint(size*8) - 1
+		}
+
+		return util.NewCallExpr(goType, &goast.BasicLit{
+			Kind:  token.INT,
+			Value: strconv.FormatUint(u, 10),
+		}), n.Type, nil
+	}
+
+	return &goast.UnaryExpr{Op: token.SUB, X: expr}, n.Type, nil
+}
+
+// getCharacterLiteralType returns the C type of a character literal. A narrow
+// character literal, like 'x', has the type "int" in C but it is used as a
+// "char". The type of a wide character literal, like L'Ω', is also "int" so a
+// character that does not fit in a char must be a wchar_t.
+func getCharacterLiteralType(n *ast.CharacterLiteral) string {
+	switch {
+	// u'x' and U'x' are "unsigned short" and "unsigned int".
+	case n.Type != "int":
+		return n.Type
+
+	case n.Value > 0xff:
+		return "wchar_t"
+	}
+
+	return "char"
 }
 
 func transpileCharacterLiteral(n *ast.CharacterLiteral) *goast.BasicLit {
@@ -39,28 +229,45 @@ func transpileCharacterLiteral(n *ast.CharacterLiteral) *goast.BasicLit {
 	}
 }
 
+// transpilePredefinedExpr transpiles the identifiers that are defined by the
+// compiler in every function. They are strings that contain the name of the
+// function, or the signature of the function in the case of
+// __PRETTY_FUNCTION__:
+//
+//     int add(int a, int b) {
+//         __func__;               // "add"
+//         __PRETTY_FUNCTION__;    // "int add(int, int)"
+//     }
 func transpilePredefinedExpr(n *ast.PredefinedExpr, p *program.Program) (goast.Expr, string, error) {
-	// A predefined expression is a literal that is not given a value until
-	// compile time.
-	//
-	// TODO: Predefined expressions are not evaluated
-	// https://github.com/elliotchance/c2go/issues/81
+	name := ""
+	pretty := "top level"
+	if p.Function != nil {
+		name = p.Function.Name
+
+		// The type of the function is like "int (int, int)". There is no
+		// space between the name and a pointer return type, like
+		// "char *name(int)".
+		parts := strings.SplitN(p.Function.Type, "(", 2)
+		returnType := strings.TrimSpace(parts[0])
+		if !strings.HasSuffix(returnType, "*") {
+			returnType += " "
+		}
+		pretty = fmt.Sprintf("%s%s(%s", returnType, name, parts[1])
+	}
 
+	var value string
 	switch n.Name {
-	case "__PRETTY_FUNCTION__":
-		return util.NewCallExpr(
-			"[]byte",
-			util.NewStringLit(`"void print_number(int *)"`),
-		), "const char*", nil
+	case "__func__", "__FUNCTION__":
+		value = name
 
-	case "__func__":
-		return util.NewCallExpr(
-			"[]byte",
-			util.NewStringLit(strconv.Quote(p.Function.Name)),
-		), "const char*", nil
+	case "__PRETTY_FUNCTION__":
+		value = pretty
 
 	default:
 		// There are many more.
-		panic(fmt.Sprintf("unknown PredefinedExpr: %s", n.Name))
+		return nil, "", fmt.Errorf("unknown PredefinedExpr: %s", n.Name)
 	}
+
+	return util.NewCallExpr("[]byte", util.NewStringLit(strconv.Quote(value+"\x00"))),
+		"const char *", nil
 }
@@ -6,6 +6,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
 	goast "go/ast"
 	"go/token"
 )
@@ -55,3 +56,112 @@ func TestCharacterLiterals(t *testing.T) {
 		}
 	}
 }
+
+var stringtests = []struct {
+	in  string // The value of the StringLiteral
+	out string // Output Go string literal
+}{
+	{"", `"\x00"`},
+	{"abc", `"abc\x00"`},
+
+	// Adjacent C literals are already a single StringLiteral.
+	{"tab\tquote\"\\", `"tab\tquote\"\\\x00"`},
+	{"ab\x00cd", `"ab\x00cd\x00"`},
+	{"\xc3\xa9", `"é\x00"`},
+}
+
+func TestStringLiterals(t *testing.T) {
+	for _, tt := range stringtests {
+		expected := &goast.CallExpr{
+			Fun:  &goast.ArrayType{Elt: &goast.Ident{Name: "byte"}},
+			Args: []goast.Expr{&goast.BasicLit{Kind: token.STRING, Value: tt.out}},
+		}
+		actual, _ := transpileStringLiteral(&ast.StringLiteral{Value: tt.in})
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("input: %q", tt.in)
+			t.Errorf("  expected: %#v", expected.Args[0])
+			t.Errorf("  actual:   %#v", actual.(*goast.CallExpr).Args[0])
+		}
+	}
+}
+
+func TestWideStringLiterals(t *testing.T) {
+	tests := []struct {
+		prefix string
+		value  string
+		out    string
+	}{
+		{"L", "aΩ", "[]int32{'a', 'Ω', 0}"},
+		{"u", "a\U0001f600", "[]uint16{'a', 55357, 56832, 0}"},
+		{"U", "a\U0001f600", "[]uint32{'a', '😀', 0}"},
+		{"u8", "aΩ", `[]byte("aΩ\x00")`},
+	}
+
+	for _, tt := range tests {
+		expr, _ := transpileStringLiteral(&ast.StringLiteral{Prefix: tt.prefix, Value: tt.value})
+
+		if got := renderNode(t, expr); got != tt.out {
+			t.Errorf("%s%q: expected %s, got %s", tt.prefix, tt.value, tt.out, got)
+		}
+	}
+}
+
+func TestStringLiteralsForArrays(t *testing.T) {
+	tests := []struct {
+		n   *ast.StringLiteral
+		out string
+	}{
+		{&ast.StringLiteral{Type: "char [4]", Value: "abc"}, `[]byte("abc\x00")`},
+		{&ast.StringLiteral{Type: "char [8]", Value: "abc"}, `append([]byte("abc\x00"), make([]byte, 4)...)`},
+		{&ast.StringLiteral{Type: "char [3]", Value: "abc"}, `[]byte("abc")`},
+		{&ast.StringLiteral{Type: "wchar_t [4]", Prefix: "L", Value: "ab"}, "append([]int32{'a', 'b', 0}, make([]int32, 1)...)"},
+		{&ast.StringLiteral{Type: "wchar_t [1]", Prefix: "L", Value: "ab"}, "[]int32{'a'}"},
+	}
+
+	for _, tt := range tests {
+		expr, _ := transpileStringLiteral(tt.n)
+
+		if got := renderNode(t, expr); got != tt.out {
+			t.Errorf("%s %q: expected %s, got %s", tt.n.Type, tt.n.Value, tt.out, got)
+		}
+	}
+}
+
+func TestIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		cType    string
+		value    string
+		out      string
+		exprType string
+	}{
+		{"int", "100", "100", "int"},
+		{"long", "100L", "100", "long"},
+		{"unsigned long long", "1ULL", "1", "unsigned long long"},
+		{"unsigned int", "0xFFFFFFFFU", "0xFFFFFFFF", "unsigned int"},
+		{"int", "0755", "0755", "int"},
+		{"int", "0b101", "0b101", "int"},
+		{"int", "1'000'000", "1_000_000", "int"},
+		{"int", "-5", "-5", "int"},
+		{"unsigned int", "-1", "uint32(4294967295)", "unsigned int"},
+		{"unsigned char", "-1", "uint8(255)", "unsigned char"},
+		{"unsigned long long", "-1", "uint64(18446744073709551615)", "unsigned long long"},
+		{"unsigned long long", "18446744073709551615ULL",
+			"uint64(18446744073709551615)", "unsigned long long"},
+		{"long long", "-9223372036854775808", "-9223372036854775808", "long long"},
+	}
+
+	for _, tt := range tests {
+		p := program.NewProgram()
+
+		expr, exprType, err := transpileIntegerLiteral(
+			&ast.IntegerLiteral{Type: tt.cType, Value: tt.value}, p)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.value, err)
+		}
+
+		if got := renderNode(t, expr); got != tt.out || exprType != tt.exprType {
+			t.Errorf("%s %s: expected %s (%s), got %s (%s)", tt.cType, tt.value,
+				tt.out, tt.exprType, got, exprType)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// This file contains the Go constants of the object-like macros of a C file
+// (see the -macros option). clang replaces each macro in the C code, so the
+// macros are only needed by the Go code that uses the package:
+//
+//     #define SIZE (4 * 1024)
+//     #define NAME "c2go"
+//     #define MAX(a, b) ((a) > (b) ? (a) : (b))
+//
+// becomes:
+//
+//     const (
+//         SIZE = (4 * 1024)
+//         NAME = "c2go"
+//     )
+//
+// Only a macro that is a constant expression of literals and other macros is
+// declared. A function-like macro, like MAX, is never declared.
+
+package transpiler
+
+import (
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// transpileMacros declares the constants of the macros of the file (see the
+// top of this file). A macro with the same name as something else in the Go
+// file, a function or variable of the package, or that has been declared by
+// another file, is left out.
+func transpileMacros(p *program.Program) {
+	names := map[string]bool{}
+	for _, decl := range p.File.Decls {
+		for _, name := range getDeclNames(decl) {
+			names[name] = true
+		}
+	}
+
+	values := map[string]goast.Expr{}
+	for _, macro := range p.Macros {
+		if names[macro.Name] || p.IsFunctionDefined(macro.Name) ||
+			p.IsVariableDefined(macro.Name) || !isMacroName(macro.Name) {
+			continue
+		}
+
+		if e := parseMacroValue(macro.Value); e != nil {
+			values[macro.Name] = e
+		} else {
+			delete(values, macro.Name)
+		}
+	}
+
+	// A macro can only be used by another one if it is also declared.
+	for removed := true; removed; {
+		removed = false
+		for name, e := range values {
+			if !usesOnlyMacros(e, values) {
+				delete(values, name)
+				removed = true
+			}
+		}
+	}
+
+	specs := []goast.Spec{}
+	for _, macro := range p.Macros {
+		e, ok := values[macro.Name]
+		if !ok || !p.DeclareMacro(macro.Name) {
+			continue
+		}
+
+		delete(values, macro.Name)
+		specs = append(specs, &goast.ValueSpec{
+			Names:  []*goast.Ident{util.NewIdent(macro.Name)},
+			Values: []goast.Expr{e},
+		})
+	}
+
+	if len(specs) == 0 {
+		return
+	}
+
+	p.File.Decls = append(p.File.Decls, &goast.GenDecl{
+		Tok:    token.CONST,
+		Lparen: 1,
+		Specs:  specs,
+	})
+}
+
+// getDeclNames returns the package-level Go names that are declared by decl.
+func getDeclNames(decl goast.Decl) []string {
+	switch d := decl.(type) {
+	case *goast.FuncDecl:
+		return []string{d.Name.Name}
+
+	case *goast.GenDecl:
+		names := []string{}
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *goast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			case *goast.TypeSpec:
+				names = append(names, s.Name.Name)
+			}
+		}
+
+		return names
+	}
+
+	return nil
+}
+
+// goPredeclared are the names of the Go types, constants and functions that
+// are always declared. A macro with one of these names, like "true", would hide
+// the Go one.
+var goPredeclared = append([]string{
+	"true", "false", "nil", "iota", "error", "any", "comparable",
+	"append", "cap", "clear", "close", "complex", "copy", "delete", "imag",
+	"len", "make", "max", "min", "new", "panic", "print", "println", "real",
+	"recover",
+}, goBasicTypes...)
+
+// isMacroName returns true if name can be the name of a Go constant.
+func isMacroName(name string) bool {
+	return name != "_" && !token.Lookup(name).IsKeyword() &&
+		!util.InStrings(name, goPredeclared)
+}
+
+// numberRegexp matches a number that can have a C suffix, like "10UL" or
+// "1.5f". Only a decimal number can have the "f" of a float, it is a digit of a
+// hexadecimal number.
+var numberRegexp = regexp.MustCompile(
+	`\b(?:0[xX][0-9a-fA-F]+[uUlL]*|(?:\d+\.?\d*|\.\d+)(?:[eE][+-]?\d+)?(?:[uUlL]+|[fF])?)\b`)
+
+// octalEscapeRegexp matches an escaped backslash or a C octal escape, which can
+// have less than the three digits that Go needs.
+var octalEscapeRegexp = regexp.MustCompile(`\\(\\|[0-7]{1,3})`)
+
+// parseMacroValue returns the Go expression of the value of a macro, or nil if
+// it is not a constant expression.
+func parseMacroValue(value string) goast.Expr {
+	if strings.ContainsAny(value, `"'`) {
+		value = octalEscapeRegexp.ReplaceAllStringFunc(value, func(s string) string {
+			if s == `\\` {
+				return s
+			}
+			return `\` + strings.Repeat("0", 4-len(s)) + s[1:]
+		})
+	} else {
+		value = numberRegexp.ReplaceAllStringFunc(value, func(s string) string {
+			if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+				return strings.TrimRight(s, "uUlL")
+			}
+			return strings.TrimRight(s, "uUlLfF")
+		})
+	}
+
+	e, err := parser.ParseExpr(value)
+	if err != nil || !isMacroExpr(e) {
+		return nil
+	}
+
+	// The "~" of C is the "^" of Go.
+	goast.Inspect(e, func(node goast.Node) bool {
+		if u, ok := node.(*goast.UnaryExpr); ok && u.Op == token.TILDE {
+			u.Op = token.XOR
+		}
+		return true
+	})
+
+	return e
+}
+
+// isMacroExpr returns true if e only has literals, identifiers and arithmetic
+// or bitwise operators. The result of a logical or comparison operator is an int
+// in C but a bool in Go, so it cannot be used like the C macro.
+func isMacroExpr(e goast.Expr) bool {
+	switch v := e.(type) {
+	case *goast.BasicLit, *goast.Ident:
+		return true
+
+	case *goast.ParenExpr:
+		return isMacroExpr(v.X)
+
+	case *goast.UnaryExpr:
+		switch v.Op {
+		case token.AND, token.ARROW, token.NOT:
+			return false
+		}
+		return isMacroExpr(v.X)
+
+	case *goast.BinaryExpr:
+		switch v.Op {
+		case token.AND_NOT, token.LAND, token.LOR, token.EQL, token.NEQ,
+			token.LSS, token.GTR, token.LEQ, token.GEQ:
+			return false
+		}
+		return isMacroExpr(v.X) && isMacroExpr(v.Y)
+	}
+
+	return false
+}
+
+// usesOnlyMacros returns true if every identifier of e is one of the macros.
+func usesOnlyMacros(e goast.Expr, macros map[string]goast.Expr) bool {
+	ok := true
+	goast.Inspect(e, func(node goast.Node) bool {
+		if ident, isIdent := node.(*goast.Ident); isIdent && macros[ident.Name] == nil {
+			ok = false
+		}
+		return ok
+	})
+
+	return ok
+}
@@ -0,0 +1,73 @@
+package transpiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func TestTranspileMacros(t *testing.T) {
+	p := program.NewProgram()
+	p.GoInit = true
+
+	// The macros of a header that is included by both of the files.
+	header := []program.Macro{
+		{Name: "SIZE", Value: "(4 * 1024)"},
+		{Name: "HEX", Value: "0xFFUL"},
+		{Name: "COLOR", Value: "0xABCDEF"},
+		{Name: "RATIO", Value: "1.5f"},
+		{Name: "MASK", Value: "~SIZE"},
+		{Name: "NAME", Value: `"c2go"`},
+		{Name: "NEWLINE", Value: `'\12'`},
+		{Name: "TOTAL", Value: "SIZE * 2"},
+		{Name: "EMPTY", Value: ""},
+		{Name: "CALL", Value: "get_size()"},
+		{Name: "CALL_TOTAL", Value: "CALL + 1"},
+		{Name: "CAST", Value: "(int)SIZE"},
+		{Name: "ON", Value: "!0"},
+		{Name: "IS_BIG", Value: "SIZE > 1024"},
+		{Name: "true", Value: "1"},
+		{Name: "main", Value: "0"},
+	}
+
+	files := []*ast.TranslationUnitDecl{
+		{Children: []ast.Node{newReturnFunction("main", "0", false)}},
+		{Children: []ast.Node{}},
+	}
+
+	for _, file := range files {
+		RegisterDefinitions(p, file)
+	}
+
+	outputs := []string{}
+	for _, file := range files {
+		p.Macros = header
+		if err := TranspileAST("x.c", "main", p, file); err != nil {
+			t.Fatal(err)
+		}
+
+		outputs = append(outputs, p.String())
+	}
+
+	expected := `const (
+	SIZE    = (4 * 1024)
+	HEX     = 0xFF
+	COLOR   = 0xABCDEF
+	RATIO   = 1.5
+	MASK    = ^SIZE
+	NAME    = "c2go"
+	NEWLINE = '\012'
+	TOTAL   = SIZE * 2
+)`
+
+	if !strings.Contains(outputs[0], expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, outputs[0])
+	}
+
+	// The constants are only declared once in the package.
+	if strings.Contains(outputs[1], "const") {
+		t.Errorf("expected no constants in the second file:\n%s", outputs[1])
+	}
+}
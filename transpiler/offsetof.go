@@ -0,0 +1,214 @@
+// This file contains functions for transpiling offsetof(). clang expands the
+// offsetof() macro into an OffsetOfExpr, which is the sum of the offsets of
+// each member:
+//
+//     offsetof(struct foo, bar[2].baz)
+//
+// Becomes:
+//
+//     uint32(unsafe.Offsetof(foo{}.bar) + uintptr(2)*unsafe.Sizeof(foo{}.bar[0]) +
+//         unsafe.Offsetof(foo{}.bar[0].baz))
+//
+// The offsets are of the Go struct, which will not be the same as the C struct
+// when the fields have different sizes in Go.
+
+package transpiler
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// offsetOfMember is one part of the member of an offsetof(). It is either the
+// name of a field or the index of an array.
+type offsetOfMember struct {
+	name  string
+	index ast.Node
+}
+
+// transpileOffsetOfExpr transpiles an offsetof() (see the top of this file).
+// The index of each array in the member is a child of the node.
+func transpileOffsetOfExpr(n *ast.OffsetOfExpr, p *program.Program) (
+	goast.Expr, string, error) {
+	if n.StructType == "" || n.Member == "" {
+		return nil, "", errors.New("cannot find the type and member of offsetof()")
+	}
+
+	members := []offsetOfMember{}
+	indexes := n.Children
+	for _, part := range splitOffsetOfMember(n.Member) {
+		if !strings.HasPrefix(part, "[") {
+			members = append(members, offsetOfMember{name: part})
+			continue
+		}
+
+		if len(indexes) == 0 {
+			return nil, "", fmt.Errorf("cannot find the index of %s in offsetof()", n.Member)
+		}
+
+		members = append(members, offsetOfMember{index: indexes[0]})
+		indexes = indexes[1:]
+	}
+
+	return transpileOffsetOf(n.StructType, members, n.Type, p)
+}
+
+// splitOffsetOfMember splits the member of an offsetof() into the names of the
+// fields and the subscripts of the arrays, like "bar[2].baz" into "bar", "[2]"
+// and "baz".
+func splitOffsetOfMember(member string) []string {
+	parts := []string{}
+	for member != "" {
+		switch member[0] {
+		case '.':
+			member = member[1:]
+
+		case '[':
+			end, depth := 0, 0
+			for end < len(member) {
+				if member[end] == '[' {
+					depth++
+				} else if member[end] == ']' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+				end++
+			}
+
+			parts = append(parts, member[:end])
+			member = strings.TrimPrefix(member[end:], "]")
+
+		default:
+			end := strings.IndexAny(member, ".[")
+			if end == -1 {
+				end = len(member)
+			}
+
+			parts = append(parts, member[:end])
+			member = member[end:]
+		}
+	}
+
+	return parts
+}
+
+// getOffsetOfMembers returns the members of the traditional definition of
+// offsetof(), which is the address of a member through a NULL pointer:
+//
+//     (size_t)&((struct foo *)0)->bar
+//
+// The type of the struct is also returned. nil is returned if the cast is
+// anything else.
+func getOffsetOfMembers(n *ast.CStyleCastExpr) (string, []offsetOfMember) {
+	if n.Kind != "PointerToIntegral" {
+		return "", nil
+	}
+
+	address, ok := removeCastsAndParens(n.Children[0]).(*ast.UnaryOperator)
+	if !ok || address.Operator != "&" {
+		return "", nil
+	}
+
+	// The members are found from the last one back to the NULL pointer. An
+	// anonymous struct is embedded so its fields belong to the struct that
+	// contains it.
+	members := []offsetOfMember{}
+	node := removeCastsAndParens(address.Children[0])
+	for {
+		member, ok := node.(*ast.MemberExpr)
+		if !ok {
+			break
+		}
+
+		if !member.IsAnonymous() {
+			members = append([]offsetOfMember{{name: member.Name}}, members...)
+		}
+		node = removeCastsAndParens(member.Children[0])
+	}
+
+	base, ok := node.(*ast.CStyleCastExpr)
+	if !ok || len(members) == 0 {
+		return "", nil
+	}
+
+	literal, ok := removeCastsAndParens(base.Children[0]).(*ast.IntegerLiteral)
+	if !ok || literal.Value != "0" {
+		return "", nil
+	}
+
+	structType, err := types.GetDereferenceType(base.Type)
+	if err != nil {
+		return "", nil
+	}
+
+	return structType, members
+}
+
+// transpileOffsetOf returns the sum of the offsets of each member of the
+// struct (see the top of this file). The result has the C type cType.
+func transpileOffsetOf(structType string, members []offsetOfMember, cType string,
+	p *program.Program) (goast.Expr, string, error) {
+	goType, err := types.ResolveType(p, structType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var expr goast.Expr = &goast.CompositeLit{Type: util.NewTypeIdent(goType)}
+	var offset goast.Expr
+
+	for _, m := range members {
+		var memberOffset goast.Expr
+
+		if m.index != nil {
+			index, _, _, _, err := transpileToExpr(m.index, p)
+			if err != nil {
+				return nil, "", err
+			}
+
+			// An array is a slice in Go, so the elements are not in the
+			// struct. The size of the elements is still the same.
+			expr = &goast.IndexExpr{X: expr, Index: util.NewIntLit(0)}
+			memberOffset = util.NewBinaryExpr(util.NewCallExpr("uintptr", index),
+				token.MUL, util.NewCallExpr("unsafe.Sizeof", expr))
+
+			structType, _ = types.GetArrayTypeAndSize(structType)
+		} else {
+			if s := p.GetStruct(structType); s != nil {
+				if s.IsUnion {
+					return nil, "", fmt.Errorf("offsetof() of a member of %s is not supported", structType)
+				}
+
+				structType, _ = s.Fields[m.name].(string)
+			}
+
+			expr = &goast.SelectorExpr{X: expr, Sel: util.NewIdent(getFieldName(p, m.name))}
+			memberOffset = util.NewCallExpr("unsafe.Offsetof", expr)
+		}
+
+		if offset == nil {
+			offset = memberOffset
+		} else {
+			offset = util.NewBinaryExpr(offset, token.ADD, memberOffset)
+		}
+	}
+
+	t, err := types.ResolveType(p, cType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	p.AddImport("unsafe")
+
+	return util.NewCallExpr(t, offset), cType, nil
+}
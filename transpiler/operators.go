@@ -4,8 +4,10 @@
 package transpiler
 
 import (
+	"errors"
 	"fmt"
 	"go/token"
+	"strconv"
 	"strings"
 
 	goast "go/ast"
@@ -34,66 +36,451 @@ func transpileConditionalOperator(n *ast.ConditionalOperator, p *program.Program
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
 
-	a, aType, newPre, newPost, err := transpileToExpr(n.Children[0], p)
+	assert, newPre, newPost, err := transpileAssert(n, p)
+	if err != nil || assert != nil {
+		return assert, "void", newPre, newPost, err
+	}
+
+	returnType, err := types.ResolveType(p, n.Type)
 	if err != nil {
 		return nil, "", nil, nil, err
 	}
 
-	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
-
-	b, bType, newPre, newPost, err := transpileToExpr(n.Children[1], p)
+	ifStmt, newPre, newPost, err := transpileConditionalOperatorIf(n, returnType, p)
 	if err != nil {
 		return nil, "", nil, nil, err
 	}
 
 	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
-	c, cType, newPre, newPost, err := transpileToExpr(n.Children[2], p)
+	return util.NewFuncClosure(returnType, ifStmt), n.Type, preStmts, postStmts, nil
+}
+
+// transpileConditionalOperatorIf returns the if statement that chooses the
+// branch of a conditional operator. The pre and post statements are the side
+// effects of the first condition.
+//
+// A chain of conditional operators is one if statement with an else if for
+// each condition, instead of a closure for each conditional operator:
+//
+//     a ? b : c ? d : e
+//
+// becomes:
+//
+//     func() T {
+//         if a {
+//             return b
+//         } else if c {
+//             return d
+//         } else {
+//             return e
+//         }
+//     }()
+//
+// The conditions are still evaluated in the same order, and only until one of
+// them is true. If a later condition has side effects they happen in the else
+// block before it is checked.
+func transpileConditionalOperatorIf(n *ast.ConditionalOperator, returnType string,
+	p *program.Program) (*goast.IfStmt, []goast.Stmt, []goast.Stmt, error) {
+	a, aType, preStmts, postStmts, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	a, err = types.CastExpr(p, a, aType, "bool")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	b, err := transpileConditionalOperatorBranch(n.Children[1], n.Type, returnType, p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ifStmt := &goast.IfStmt{
+		Cond: a,
+		Body: &goast.BlockStmt{
+			List: b,
+		},
+	}
+
+	next := getChainedConditionalOperator(n)
+	if next == nil {
+		c, err := transpileConditionalOperatorBranch(n.Children[2], n.Type, returnType, p)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		ifStmt.Else = &goast.BlockStmt{
+			List: c,
+		}
+
+		return ifStmt, preStmts, postStmts, nil
+	}
+
+	elseIf, newPre, newPost, err := transpileConditionalOperatorIf(next, returnType, p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(newPre) == 0 && len(newPost) == 0 {
+		ifStmt.Else = elseIf
+		return ifStmt, preStmts, postStmts, nil
+	}
+
+	// The post statements of the condition happen after it is evaluated, but
+	// before it is checked.
+	stmts := newPre
+	if len(newPost) > 0 {
+		tempVariableName := p.GetNextIdentifier("")
+		stmts = append(stmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(tempVariableName)},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{elseIf.Cond},
+		})
+		stmts = append(stmts, newPost...)
+		elseIf.Cond = util.NewIdent(tempVariableName)
+	}
+
+	ifStmt.Else = &goast.BlockStmt{
+		List: append(stmts, elseIf),
+	}
+
+	return ifStmt, preStmts, postStmts, nil
+}
+
+// getChainedConditionalOperator returns the conditional operator that is the
+// "c" of "a ? b : c", or nil if "c" is something else. It must have the same
+// type so that its branches are cast to the same type.
+func getChainedConditionalOperator(n *ast.ConditionalOperator) *ast.ConditionalOperator {
+	c := n.Children[2]
+	for {
+		paren, ok := c.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+
+		c = paren.Children[0]
+	}
+
+	if next, ok := c.(*ast.ConditionalOperator); ok && next.Type == n.Type {
+		return next
+	}
+
+	return nil
+}
+
+// transpileConditionalOperatorBranch returns the body for the "b" or "c" branch
+// of a conditional operator. Any side effects (the pre and post statements) of
+// the branch are kept inside the body so that they only happen when that branch
+// is chosen.
+//
+// If the conditional operator does not return a value (it is void) the
+// expression is evaluated as a statement instead of being returned.
+func transpileConditionalOperatorBranch(node ast.Node, cType, goType string,
+	p *program.Program) ([]goast.Stmt, error) {
+	e, eType, preStmts, postStmts, err := transpileToExpr(node, p)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := append([]goast.Stmt{}, preStmts...)
+
+	if goType == "" {
+		stmts = append(stmts, util.NewExprStmt(e))
+		return append(stmts, postStmts...), nil
+	}
+
+	e, err = types.CastExpr(p, e, eType, cType)
+	if err != nil {
+		return nil, err
+	}
+
+	// The post statements have to happen after the value is evaluated, but
+	// before it is returned.
+	if len(postStmts) > 0 {
+		tempVariableName := p.GetNextIdentifier("")
+		stmts = append(stmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(tempVariableName)},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{e},
+		})
+		stmts = append(stmts, postStmts...)
+		e = util.NewIdent(tempVariableName)
+	}
+
+	return append(stmts, &goast.ReturnStmt{
+		Results: []goast.Expr{e},
+	}), nil
+}
+
+// transpileBinaryConditionalOperator transpiles the GNU extension of the
+// conditional operator where the middle operand is left out:
+//
+//     a ?: b
+//
+// This works like "a ? a : b" except that "a" is only evaluated once. Clang
+// represents each of the uses of "a" with an OpaqueValueExpr. These are
+// replaced with a temporary variable that holds the value of "a":
+//
+//     func() T {
+//         temp := a
+//         if temp != 0 {
+//             return temp
+//         }
+//         return b
+//     }()
+func transpileBinaryConditionalOperator(n *ast.BinaryConditionalOperator, p *program.Program) (
+	*goast.CallExpr, string, []goast.Stmt, []goast.Stmt, error) {
+	// There are always 4 children: the common expression ("a"), the condition
+	// and the true expression (which both use the common expression through an
+	// OpaqueValueExpr) and the false expression ("b").
+	if len(n.Children) != 4 {
+		return nil, "", nil, nil, fmt.Errorf(
+			"expected 4 children in BinaryConditionalOperator, got %d", len(n.Children))
+	}
+
+	a, aType, preStmts, postStmts, err := transpileToExpr(n.Children[0], p)
 	if err != nil {
 		return nil, "", nil, nil, err
 	}
 
-	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+	tempVariableName := p.GetNextIdentifier("")
+	common := &ast.DeclRefExpr{
+		Name: tempVariableName,
+		Type: aType,
+	}
+	replaceOpaqueValueExprs(n.Children[1], common)
+	replaceOpaqueValueExprs(n.Children[2], common)
 
-	a, err = types.CastExpr(p, a, aType, "bool")
+	cond, condType, newPre, newPost, err := transpileToExpr(n.Children[1], p)
 	if err != nil {
 		return nil, "", nil, nil, err
 	}
 
-	// TODO: Here it is being assumed that the return type of the
-	// conditional operator is the type of the 'false' result. Things
-	// are a bit more complicated then that in C.
+	cond, err = types.CastExpr(p, cond, condType, "bool")
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
 
-	b, err = types.CastExpr(p, b, bType, cType)
+	returnType, err := types.ResolveType(p, n.Type)
 	if err != nil {
 		return nil, "", nil, nil, err
 	}
 
-	returnType, err := types.ResolveType(p, cType)
+	b, err := transpileConditionalOperatorBranch(n.Children[2], n.Type, returnType, p)
 	if err != nil {
 		return nil, "", nil, nil, err
 	}
 
-	return util.NewFuncClosure(
-		returnType,
-		&goast.IfStmt{
-			Cond: a,
-			Body: &goast.BlockStmt{
-				List: []goast.Stmt{
-					&goast.ReturnStmt{
-						Results: []goast.Expr{b},
-					},
-				},
-			},
-			Else: &goast.BlockStmt{
-				List: []goast.Stmt{
-					&goast.ReturnStmt{
-						Results: []goast.Expr{c},
-					},
-				},
-			},
+	c, err := transpileConditionalOperatorBranch(n.Children[3], n.Type, returnType, p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	// The condition may have its own side effects (though unlikely) that
+	// must happen after the common expression is evaluated.
+	stmts := []goast.Stmt{
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(tempVariableName)},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{a},
+		},
+	}
+	stmts = append(stmts, newPre...)
+	stmts = append(stmts, newPost...)
+	stmts = append(stmts, &goast.IfStmt{
+		Cond: cond,
+		Body: &goast.BlockStmt{
+			List: b,
+		},
+		Else: &goast.BlockStmt{
+			List: c,
 		},
-	), cType, preStmts, postStmts, nil
+	})
+
+	return util.NewFuncClosure(returnType, stmts...), n.Type, preStmts, postStmts, nil
+}
+
+// replaceOpaqueValueExprs makes every OpaqueValueExpr in the tree refer to the
+// replacement node instead of its original expression.
+func replaceOpaqueValueExprs(node ast.Node, replacement ast.Node) {
+	if o, ok := node.(*ast.OpaqueValueExpr); ok {
+		o.Children = []ast.Node{replacement}
+		return
+	}
+
+	for _, c := range getChildren(node) {
+		replaceOpaqueValueExprs(c, replacement)
+	}
+}
+
+// transpileImplicitCastExpr transpiles the casts that clang adds. What the cast
+// does depends on its kind:
+//
+//   - LValueToRValue reads a variable. The value is the same in Go, except for
+//     a volatile variable (see transpileVolatileLoad) or a signed char (see
+//     transpileSignedCharLoad).
+//   - The casts between number types must be kept since Go does not allow
+//     arithmetic on mixed types:
+//
+//     size_t n;
+//     int i;
+//     n + i;    ->    n + uint32(i)
+//
+//   - ArrayToPointerDecay is resliced by the expression that uses the pointer.
+//   - FunctionToPointerDecay, NoOp and BitCast only change the C type when the
+//     Go types are the same, like a "char *" that is used as a "void *".
+//
+// Any other cast, like IntegralToBoolean for a condition, is left to the
+// expression that uses the value.
+func transpileImplicitCastExpr(n *ast.ImplicitCastExpr, p *program.Program) (
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	if n.Kind == "BitCast" {
+		if e, preStmts, postStmts, ok, err := transpileAllocationCast(n.Children[0], n.Type, p); ok {
+			return e, n.Type, preStmts, postStmts, err
+		}
+	}
+
+	expr, exprType, preStmts, postStmts, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	switch n.Kind {
+	case "LValueToRValue":
+		expr = transpileVolatileLoad(n, expr, exprType, p)
+		expr = transpileSignedCharLoad(expr, exprType, p)
+
+		return expr, exprType, preStmts, postStmts, nil
+
+	case "IntegralCast", "FloatingCast", "IntegralToFloating", "FloatingToIntegral",
+		"IntegralRealToComplex", "FloatingRealToComplex", "FloatingComplexCast",
+		"FloatingComplexToReal", "IntegralComplexToReal":
+		// An integer constant does not need to be cast, except to a 128-bit
+		// integer. Any other constant, like the 3.7 of "int x = 3.7", would
+		// not be allowed for the new type in Go.
+		if lit, ok := expr.(*goast.BasicLit); ok && !types.IsInt128(p, n.Type) &&
+			lit.Kind == token.INT &&
+			(n.Kind == "IntegralCast" || n.Kind == "IntegralToFloating") {
+			return expr, n.Type, preStmts, postStmts, nil
+		}
+
+		// A conversion of a constant that is not an integer, like "int(3.7)",
+		// does not compile in Go. The value is truncated like C does instead.
+		if lit, ok := expr.(*goast.BasicLit); ok && lit.Kind == token.FLOAT &&
+			n.Kind == "FloatingToIntegral" {
+			if f, err := strconv.ParseFloat(lit.Value, 64); err == nil {
+				expr = util.NewIntLit(int(f))
+			}
+		}
+
+		expr, err = types.CastExpr(p, expr, exprType, n.Type)
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+
+		return expr, n.Type, preStmts, postStmts, nil
+
+	case "IntegralToPointer", "PointerToIntegral":
+		// A cast that cannot be translated is an expression that panics, so
+		// the error is only a warning.
+		expr, err = types.CastExpr(p, expr, exprType, n.Type)
+		p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+		return expr, n.Type, preStmts, postStmts, nil
+
+	case "ArrayToPointerDecay":
+		// The array is already a slice. It keeps the type of the array so that
+		// the expression that uses the pointer reslices it (see
+		// types.CastExpr), but an index of the array, like "a[2]", does not.
+		return expr, exprType, preStmts, postStmts, nil
+
+	case "FunctionToPointerDecay":
+		// A function can be used as a function pointer without any change in
+		// Go, but it needs the type of the function pointer so that it can be
+		// passed to a function pointer argument.
+		return expr, n.Type, preStmts, postStmts, nil
+
+	case "NoOp", "BitCast":
+		// The expression that uses a cast to a different Go type still casts
+		// the value, so it needs the original type. An array that is cast to a
+		// "const char *" has been used as a pointer so it is resliced.
+		if isSameGoType(p, exprType, n.Type) {
+			expr, err = types.CastExpr(p, expr, exprType, n.Type)
+			return expr, n.Type, preStmts, postStmts, err
+		}
+	}
+
+	return expr, exprType, preStmts, postStmts, nil
+}
+
+// isSameGoType returns true if the C types are both the same Go type, like a
+// "char *" and a "void *" which are both a []byte.
+func isSameGoType(p *program.Program, cType1, cType2 string) bool {
+	goType1, err1 := types.ResolveType(p, cType1)
+	goType2, err2 := types.ResolveType(p, cType2)
+
+	return err1 == nil && err2 == nil && goType1 == goType2
+}
+
+// transpileCStyleCastExpr transpiles an explicit cast, like "(int)x". Casts
+// between number types are kept. A pointer cast where both pointers are the same
+// Go type, like "(char *)ptr" on a "void *", only changes the C type so that
+// the rest of the expression (such as a dereference) uses the correct type.
+// Casts between integers and pointers, and from a void pointer to a pointer to
+// a struct, are described in types.CastExpr.
+func transpileCStyleCastExpr(n *ast.CStyleCastExpr, p *program.Program) (
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	if structType, members := getOffsetOfMembers(n); members != nil {
+		expr, exprType, err := transpileOffsetOf(structType, members, n.Type, p)
+		return expr, exprType, nil, nil, err
+	}
+
+	if n.Kind == "BitCast" {
+		if e, preStmts, postStmts, ok, err := transpileAllocationCast(n.Children[0], n.Type, p); ok {
+			return e, n.Type, preStmts, postStmts, err
+		}
+	}
+
+	expr, exprType, preStmts, postStmts, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	switch n.Kind {
+	case "IntegralCast", "FloatingCast", "IntegralToFloating", "FloatingToIntegral",
+		"IntegralRealToComplex", "FloatingRealToComplex", "FloatingComplexCast",
+		"FloatingComplexToReal", "IntegralComplexToReal":
+		expr, err = types.CastExpr(p, expr, exprType, n.Type)
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+
+		return expr, n.Type, preStmts, postStmts, nil
+
+	case "IntegralToPointer", "PointerToIntegral":
+		expr, err = types.CastExpr(p, expr, exprType, n.Type)
+		p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+		return expr, n.Type, preStmts, postStmts, nil
+
+	case "BitCast", "NoOp":
+		fromType, err1 := types.ResolveType(p, exprType)
+		toType, err2 := types.ResolveType(p, n.Type)
+		if err1 == nil && err2 == nil && fromType == toType {
+			return expr, n.Type, preStmts, postStmts, nil
+		}
+
+		// The memory of a void pointer is used for a struct, like
+		// "((struct Foo *)mem)->x".
+		if fromType == "[]byte" && strings.HasPrefix(toType, "*") {
+			expr, err = types.CastExpr(p, expr, exprType, n.Type)
+			return expr, n.Type, preStmts, postStmts, err
+		}
+	}
+
+	return expr, exprType, preStmts, postStmts, nil
 }
 
 // transpileParenExpr transpiles an expression that is wrapped in parentheses.
@@ -124,6 +511,10 @@ func transpileParenExpr(n *ast.ParenExpr, p *program.Program) (
 
 func transpileCompoundAssignOperator(n *ast.CompoundAssignOperator, p *program.Program) (
 	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	if memberExpr, ok := n.Children[0].(*ast.MemberExpr); ok && memberExpr.Bitfield {
+		return transpileBitfieldAssign(memberExpr, n.Opcode, n.Children[1], p)
+	}
+
 	operator := getTokenForOperator(n.Opcode)
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
@@ -144,7 +535,7 @@ func transpileCompoundAssignOperator(n *ast.CompoundAssignOperator, p *program.P
 			binaryOperation := n.Opcode
 			binaryOperation = binaryOperation[:(len(binaryOperation) - 1)]
 
-			union := p.GetStruct(ref.Type)
+			union := p.GetStruct(getMemberRecordType(memberExpr))
 			if union != nil && union.IsUnion {
 				// Method suffix for using getters and setters of Go union type
 				methodSuffix := strings.Title(memberExpr.Name)
@@ -167,13 +558,28 @@ func transpileCompoundAssignOperator(n *ast.CompoundAssignOperator, p *program.P
 		}
 	}
 
-	left, _, newPre, newPost, err := transpileToExpr(n.Children[0], p)
+	left, leftType, newPre, newPost, err := transpileToExpr(n.Children[0], p)
 	if err != nil {
 		return nil, "", nil, nil, err
 	}
 
 	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
+	if e, t, ok := transpileInt128BinaryOperator(n, n.Opcode, left, leftType, right, rightType, p); ok {
+		return e, t, preStmts, postStmts, nil
+	}
+
+	// Pointers are slices so pointer arithmetic has to be done by reslicing.
+	if operator == token.ADD_ASSIGN || operator == token.SUB_ASSIGN {
+		if e, ok := transpilePointerArithmeticAssign(n, left, right, rightType, p); ok {
+			return e, n.Type, preStmts, postStmts, nil
+		}
+	}
+
+	if e, ok := transpileBoolCompoundAssign(n, left, leftType, right, rightType, p); ok {
+		return e, n.Type, preStmts, postStmts, nil
+	}
+
 	// The right hand argument of the shift left or shift right operators
 	// in Go must be unsigned integers. In C, shifting with a negative shift
 	// count is undefined behaviour (so we should be able to ignore that case).
@@ -184,6 +590,24 @@ func transpileCompoundAssignOperator(n *ast.CompoundAssignOperator, p *program.P
 		if right == nil {
 			right = util.NewNil()
 		}
+	} else {
+		// Go requires both sides to be the same type, like:
+		//
+		//     size_t n;
+		//     n += 2;    ->    n += uint32(2)
+		right, err = types.CastExpr(p, right, rightType, n.Type)
+		p.AddMessage(ast.GenerateWarningOrErrorMessage(err, n, right == nil))
+		if right == nil {
+			right = util.NewNil()
+		}
+	}
+
+	if operator != token.SHL_ASSIGN && operator != token.SHR_ASSIGN {
+		right = transpileSignedCharStore(left, leftType, right, p)
+	}
+
+	if e, ok := transpileVolatileStore(n, n.Opcode, left, leftType, right, p); ok {
+		return e, "", preStmts, postStmts, nil
 	}
 
 	return &goast.BinaryExpr{
@@ -193,6 +617,78 @@ func transpileCompoundAssignOperator(n *ast.CompoundAssignOperator, p *program.P
 	}, "", preStmts, postStmts, nil
 }
 
+// transpilePointerArithmeticAssign converts "+=" and "-=" on a pointer into
+// operations on the slice that represents the pointer:
+//
+//     ptr += n    ->    ptr = ptr[n:]
+//
+// A slice cannot be moved backwards so "-=" cannot be supported. The
+// expression is replaced with a panic that is obvious when it is reached.
+//
+// The second return value will be false if the left side is not a pointer
+// that is represented by a slice.
+func transpilePointerArithmeticAssign(n *ast.CompoundAssignOperator,
+	left, right goast.Expr, rightType string, p *program.Program) (goast.Expr, bool) {
+	if _, err := types.GetDereferenceType(n.Type); err != nil {
+		return nil, false
+	}
+
+	goType, err := types.ResolveType(p, n.Type)
+	if err != nil || !strings.HasPrefix(goType, "[]") {
+		return nil, false
+	}
+
+	if n.Opcode == "-=" {
+		message := fmt.Sprintf("FIXME: cannot subtract from the pointer %s", n.Type)
+		p.AddMessage(ast.GenerateWarningMessage(errors.New(message), n))
+
+		return util.NewCallExpr("panic", util.NewStringLit(fmt.Sprintf("%q", message))), true
+	}
+
+	right, err = types.CastExpr(p, right, rightType, "int")
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	return &goast.BinaryExpr{
+		X:  left,
+		Op: token.ASSIGN,
+		Y: &goast.SliceExpr{
+			X:   left,
+			Low: right,
+		},
+	}, true
+}
+
+// transpilePointerDifference converts the subtraction of two pointers into the
+// number of elements between them:
+//
+//     p - q    ->    noarch.PointerDiff(p, q)
+//
+// The second return value will be false if the operands are not pointers that
+// are represented by slices.
+func transpilePointerDifference(n *ast.BinaryOperator, left goast.Expr,
+	leftType string, right goast.Expr, rightType string, p *program.Program) (goast.Expr, bool) {
+	if n.Operator != "-" {
+		return nil, false
+	}
+
+	for _, cType := range []string{leftType, rightType} {
+		if _, err := types.GetDereferenceType(cType); err != nil {
+			return nil, false
+		}
+
+		goType, err := types.ResolveType(p, cType)
+		if err != nil || !strings.HasPrefix(goType, "[]") {
+			return nil, false
+		}
+	}
+
+	p.AddImport("github.com/elliotchance/c2go/noarch")
+	e, err := types.CastExpr(p, util.NewCallExpr("noarch.PointerDiff", left, right), "int", n.Type)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	return e, true
+}
+
 // getTokenForOperator returns the Go operator token for the provided C
 // operator.
 func getTokenForOperator(operator string) token.Token {
@@ -0,0 +1,113 @@
+package transpiler
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func TestImplicitCastExpr(t *testing.T) {
+	newCast := func(kind, cType string, child ast.Node) *ast.ImplicitCastExpr {
+		return &ast.ImplicitCastExpr{Kind: kind, Type: cType, Children: []ast.Node{child}}
+	}
+
+	tests := []struct {
+		n        *ast.ImplicitCastExpr
+		expected string
+		cType    string
+	}{
+		{newCast("LValueToRValue", "int", newVarRef("x", "int")), "x", "int"},
+		{newCast("IntegralCast", "long", newVarRef("x", "int")), "int32(x)", "long"},
+		{newCast("IntegralCast", "long", &ast.IntegerLiteral{Type: "int", Value: "5"}), "5", "long"},
+		{newCast("IntegralToFloating", "double", newVarRef("x", "int")), "float64(x)", "double"},
+		{newCast("FloatingToIntegral", "int", newVarRef("d", "double")), "int(d)", "int"},
+		{newCast("FloatingToIntegral", "int", &ast.FloatingLiteral{Type: "double", Value: 3.7}), "int(3)", "int"},
+		{newCast("FloatingCast", "float", &ast.FloatingLiteral{Type: "double", Value: 1.5}), "float32(1.5)", "float"},
+
+		// The array is resliced by the expression that uses the pointer, which
+		// may be a cast to a pointer of the same Go type.
+		{newCast("ArrayToPointerDecay", "int *", newVarRef("a", "int [4]")), "a", "int [4]"},
+		{newCast("BitCast", "const char *",
+			newCast("ArrayToPointerDecay", "char *", newVarRef("s", "char [8]"))),
+			"s[:]", "const char *"},
+
+		{newCast("FunctionToPointerDecay", "int (*)(int)",
+			&ast.DeclRefExpr{For: "Function", Name: "f", Type: "int (int)"}),
+			"f", "int (*)(int)"},
+		{newCast("NoOp", "const char *", newVarRef("s", "char *")), "s", "const char *"},
+
+		// A cast to a different Go type is done by the expression that uses
+		// the value.
+		{newCast("BitCast", "int *", newVarRef("v", "void *")), "v", "void *"},
+	}
+
+	for _, tt := range tests {
+		p := program.NewProgram()
+		expr, cType, _, _, err := transpileImplicitCastExpr(tt.n, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, expr); got != tt.expected || cType != tt.cType {
+			t.Errorf("%s: expected %s (%s), got %s (%s)",
+				tt.n.Kind, tt.expected, tt.cType, got, cType)
+		}
+	}
+}
+
+func TestIncDecOperator(t *testing.T) {
+	newIncDec := func(operator string, isPrefix bool) *ast.UnaryOperator {
+		return &ast.UnaryOperator{
+			Type:     "int",
+			Operator: operator,
+			IsPrefix: isPrefix,
+			Children: []ast.Node{
+				&ast.ArraySubscriptExpr{
+					Type: "int",
+					Children: []ast.Node{
+						newVarRef("a", "int *"),
+						newVarRef("i", "int"),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		n        *ast.UnaryOperator
+		stmt     string
+		expected string
+	}{
+		{newIncDec("++", true), "a[i] += 1",
+			"func() int {\n\ta[i] += 1\n\treturn a[i]\n}()"},
+		{newIncDec("++", false), "a[i] += 1",
+			"func() int {\n\ttemp0 := a[i]\n\ta[i] += 1\n\treturn temp0\n}()"},
+		{newIncDec("--", false), "a[i] -= 1",
+			"func() int {\n\ttemp0 := a[i]\n\ta[i] -= 1\n\treturn temp0\n}()"},
+	}
+
+	for _, tt := range tests {
+		// The value of a statement, like "a[i]++;", is not used.
+		p := program.NewProgram()
+		stmt, _, _, err := transpileToStmt(tt.n, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, stmt); got != tt.stmt {
+			t.Errorf("%s: expected %s, got %s", tt.n.Operator, tt.stmt, got)
+		}
+
+		// The value of an expression, like "x = a[i]++", is used.
+		expr, cType, _, _, err := transpileToExpr(tt.n, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, expr); got != tt.expected || cType != "int" {
+			t.Errorf("%s: expected %s (int), got %s (%s)",
+				tt.n.Operator, tt.expected, got, cType)
+		}
+	}
+}
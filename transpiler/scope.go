@@ -16,6 +16,12 @@ func transpileCompoundStmt(n *ast.CompoundStmt, p *program.Program) (
 	postStmts := []goast.Stmt{}
 	stmts := []goast.Stmt{}
 
+	// A variable that is declared in the block may shadow one with the same
+	// name outside of it. Go has the same scoping rules as C so the
+	// declarations are not renamed.
+	p.StartScope()
+	defer p.EndScope()
+
 	for _, x := range n.Children {
 		result, err := transpileToStmts(x, p)
 		if err != nil {
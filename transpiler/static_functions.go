@@ -0,0 +1,59 @@
+// This file contains the static and inline functions of a package with more
+// than one file. A function that is defined in a header, like:
+//
+//     static inline int max(int a, int b) { return a > b ? a : b; }
+//
+// is in the AST of each file that includes the header. It is only emitted by
+// the first file, because Go does not allow the same function to be declared
+// twice in a package. A function is the same if it has the same name and the
+// same C type.
+//
+// Each C file has its own static functions, so two files may have different
+// static functions with the same name. The function of the second file is
+// renamed, like "max_0", along with the references to it in that file.
+
+package transpiler
+
+import (
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+// registerFunctionNames chooses the Go names of the functions that are defined
+// in the file. This is done before the file is transpiled so that a reference
+// to a static function that is renamed can be before its definition.
+func registerFunctionNames(root ast.Node, p *program.Program) {
+	n, ok := root.(*ast.TranslationUnitDecl)
+	if !ok {
+		return
+	}
+
+	for _, c := range n.Children {
+		f, ok := c.(*ast.FunctionDecl)
+		if !ok || getFunctionBody(f) == nil {
+			continue
+		}
+
+		signatures := p.GetFunctionSignatures(f.Name)
+		if name, ok := signatures[f.Type]; ok {
+			p.AddDuplicateFunction(f.Name, name)
+		} else {
+			name := f.Name
+			if len(signatures) > 0 && f.IsStatic {
+				name = p.GetNextIdentifier(f.Name + "_")
+			}
+
+			p.AddFunctionSignature(f.Name, f.Type, name)
+		}
+
+		// The definition of a function is registered by its name, so it may be
+		// the definition of the function with the same name in another file.
+		if len(p.GetFunctionSignatures(f.Name)) > 1 {
+			program.AddFunctionDefinition(program.FunctionDefinition{
+				Name:          f.Name,
+				ReturnType:    getFunctionReturnType(f.Type),
+				ArgumentTypes: getFunctionArgumentTypes(f),
+			})
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package transpiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func newReturnFunction(name, value string, isStatic bool) *ast.FunctionDecl {
+	return &ast.FunctionDecl{
+		Name:     name,
+		Type:     "int (void)",
+		IsStatic: isStatic,
+		IsInline: isStatic,
+		Children: []ast.Node{
+			&ast.CompoundStmt{Children: []ast.Node{
+				&ast.ReturnStmt{Children: []ast.Node{
+					&ast.IntegerLiteral{Type: "int", Value: value},
+				}},
+			}},
+		},
+	}
+}
+
+func TestStaticFunctions(t *testing.T) {
+	p := program.NewProgram()
+	p.GoInit = true
+
+	// A static function with the same name in another file is a different
+	// function if it has a different type.
+	staticFile := newReturnFunction("static_file", "3", true)
+	staticFile.Type = "long (void)"
+
+	files := []*ast.TranslationUnitDecl{
+		{Children: []ast.Node{
+			newReturnFunction("static_header", "1", true),
+			newReturnFunction("static_file", "2", true),
+		}},
+		{Children: []ast.Node{
+			&ast.FunctionDecl{
+				Name: "static_caller",
+				Type: "long (void)",
+				Children: []ast.Node{
+					&ast.CompoundStmt{Children: []ast.Node{
+						&ast.ReturnStmt{Children: []ast.Node{
+							newCall("long (void)", "static_file"),
+						}},
+					}},
+				},
+			},
+			newReturnFunction("static_header", "1", true),
+			staticFile,
+		}},
+	}
+
+	outputs := []string{}
+	for _, file := range files {
+		if err := TranspileAST("x.c", "main", p, file); err != nil {
+			t.Fatal(err)
+		}
+
+		outputs = append(outputs, p.String())
+	}
+
+	// The function from the header is only in the first file.
+	if !strings.Contains(outputs[0], "func static_header() int") ||
+		strings.Contains(outputs[1], "func static_header()") {
+		t.Errorf("expected static_header() only in the first file:\n%s\n%s",
+			outputs[0], outputs[1])
+	}
+
+	// The different static function is renamed in the second file, including
+	// the call that is before it.
+	if !strings.Contains(outputs[1], "func static_file_0() int32") ||
+		!strings.Contains(outputs[1], "return static_file_0()") {
+		t.Errorf("expected static_file() to be renamed:\n%s", outputs[1])
+	}
+}
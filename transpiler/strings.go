@@ -0,0 +1,252 @@
+// This file contains the analysis for the -go-strings option. C strings are
+// translated into byte slices because a C program is allowed to write to them.
+// When a string parameter is only ever read it can be a Go string instead:
+//
+//     void greet(const char *name) {
+//         printf("Hello %s\n", name);
+//     }
+//
+//     greet("Bob");
+//
+// becomes:
+//
+//     func greet(name string) {
+//         noarch.Printf([]byte("Hello %s\n\x00"), name)
+//     }
+//
+//     greet("Bob")
+
+package transpiler
+
+import (
+	"regexp"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+// stringParameterType is the C type that is registered for an argument of a
+// function that is translated into a Go string. It is not a real C type but
+// "string" is already resolved and cast as a Go string.
+const stringParameterType = "string"
+
+var charPointerRegexp = regexp.MustCompile(`^(const )?char ?\*$`)
+var constCharPointerRegexp = regexp.MustCompile(`^const char ?\*$`)
+
+// The format functions that accept a Go string for any of the variadic
+// arguments.
+var stringFormatFunctions = map[string]bool{
+	"printf":   true,
+	"fprintf":  true,
+	"snprintf": true,
+}
+
+// registerStringParameters finds the parameters of the functions in the
+// translation unit that can be Go strings and registers the functions with
+// those arguments as a "string". This happens before anything is transpiled so
+// that calls that appear before the function definition also use the string.
+//
+// The analysis is conservative. A pointer could be written to in many ways:
+// the characters could be assigned through it, the pointer could be moved,
+// copied into another variable or struct, returned, or passed to a function
+// that writes to it. Rather than finding the writes, a parameter is only a Go
+// string when every use of it is one of:
+//
+// 1. An argument to a parameter of another function that is also a Go string.
+//    This includes recursive calls to the same function.
+//
+// 2. An argument to a "const char *" parameter of a function that is not
+//    translated, like strlen(). A C string is created for the call.
+//
+// 3. One of the variadic arguments of printf(), fprintf() or snprintf(). These
+//    format Go strings the same way as C strings.
+//
+// Functions that are used as function pointers keep their C types because the
+// type of the function pointer would not match.
+//
+// FIXME: Reading the characters of the string, like "s[i]", would also be
+// safe. However, a C program will read the NULL character at the end of the
+// string which does not exist in a Go string.
+func registerStringParameters(n *ast.TranslationUnitDecl, p *program.Program) {
+	if !p.GoStrings {
+		return
+	}
+
+	functions := map[string]*ast.FunctionDecl{}
+	candidates := map[string][]bool{}
+	pointers := map[string]bool{}
+
+	for _, c := range n.Children {
+		findFunctionPointers(c, false, pointers)
+
+		f, ok := c.(*ast.FunctionDecl)
+		if !ok || f.Name == "main" || getFunctionBody(f) == nil {
+			continue
+		}
+
+		if d := program.GetFunctionDefinition(f.Name); d != nil && d.Substitution != "" {
+			continue
+		}
+
+		functions[f.Name] = f
+		candidates[f.Name] = []bool{}
+		for _, parm := range getParmVarDecls(f) {
+			candidates[f.Name] = append(candidates[f.Name],
+				charPointerRegexp.MatchString(parm.Type))
+		}
+	}
+
+	for name := range pointers {
+		delete(candidates, name)
+	}
+
+	// Every candidate starts as a Go string. A parameter that is passed to
+	// another one that turns out to be a byte slice has to become a byte
+	// slice too, so this is repeated until nothing changes.
+	for changed := true; changed; {
+		changed = false
+
+		for name, parms := range candidates {
+			for i, parm := range getParmVarDecls(functions[name]) {
+				if parms[i] && !isReadOnlyString(getFunctionBody(functions[name]), parm, candidates) {
+					parms[i] = false
+					changed = true
+				}
+			}
+		}
+	}
+
+	for name, parms := range candidates {
+		f := functions[name]
+		argumentTypes := getFunctionArgumentTypes(f)
+
+		for i := range parms {
+			if parms[i] {
+				argumentTypes[i] = stringParameterType
+			}
+		}
+
+		program.AddFunctionDefinition(program.FunctionDefinition{
+			Name:          f.Name,
+			ReturnType:    getFunctionReturnType(f.Type),
+			ArgumentTypes: argumentTypes,
+			Substitution:  "",
+		})
+	}
+}
+
+// getParmVarDecls returns the parameters of a function in order.
+func getParmVarDecls(f *ast.FunctionDecl) []*ast.ParmVarDecl {
+	parms := []*ast.ParmVarDecl{}
+	for _, c := range f.Children {
+		if parm, ok := c.(*ast.ParmVarDecl); ok {
+			parms = append(parms, parm)
+		}
+	}
+
+	return parms
+}
+
+// findFunctionPointers adds the name of any function that is referenced other
+// than by calling it.
+func findFunctionPointers(n ast.Node, isCallee bool, pointers map[string]bool) {
+	if ref, ok := n.(*ast.DeclRefExpr); ok && ref.For == "Function" && !isCallee {
+		pointers[ref.Name] = true
+	}
+
+	if call, ok := n.(*ast.CallExpr); ok && len(call.Children) > 0 {
+		findFunctionPointers(removeCastsAndParens(call.Children[0]), true, pointers)
+
+		for _, c := range call.Children[1:] {
+			findFunctionPointers(c, false, pointers)
+		}
+
+		return
+	}
+
+	for _, c := range getChildren(n) {
+		findFunctionPointers(c, false, pointers)
+	}
+}
+
+// isReadOnlyString returns true if every reference to the parameter in the
+// node is one of the uses described in registerStringParameters.
+func isReadOnlyString(n ast.Node, parm *ast.ParmVarDecl, candidates map[string][]bool) bool {
+	if ref, ok := n.(*ast.DeclRefExpr); ok && ref.Address2 == parm.Address {
+		return false
+	}
+
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		for _, c := range getChildren(n) {
+			if !isReadOnlyString(c, parm, candidates) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	callee := ""
+	if ref, ok := removeCastsAndParens(call.Children[0]).(*ast.DeclRefExpr); ok {
+		callee = ref.Name
+	}
+
+	for i, arg := range call.Children {
+		ref, ok := removeCastsAndParens(arg).(*ast.DeclRefExpr)
+		if i > 0 && ok && ref.Address2 == parm.Address {
+			if !isStringArgument(callee, i-1, candidates) {
+				return false
+			}
+
+			continue
+		}
+
+		if !isReadOnlyString(arg, parm, candidates) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isStringArgument returns true if a Go string can be passed as the argument at
+// the position of the called function.
+func isStringArgument(callee string, position int, candidates map[string][]bool) bool {
+	if parms, ok := candidates[callee]; ok {
+		return position < len(parms) && parms[position]
+	}
+
+	f := program.GetFunctionDefinition(callee)
+	if f == nil {
+		return false
+	}
+
+	if position >= len(f.ArgumentTypes) {
+		return stringFormatFunctions[callee]
+	}
+
+	t := f.ArgumentTypes[position]
+	return t == stringParameterType || constCharPointerRegexp.MatchString(t)
+}
+
+// isStringParameter returns true if the reference is to a parameter of the
+// current function that was registered as a Go string.
+func isStringParameter(n *ast.DeclRefExpr, p *program.Program) bool {
+	if !p.GoStrings || p.Function == nil || n.For != "ParmVar" {
+		return false
+	}
+
+	f := program.GetFunctionDefinition(p.Function.Name)
+	if f == nil {
+		return false
+	}
+
+	for i, parm := range getParmVarDecls(p.Function) {
+		if parm.Address == n.Address2 {
+			return i < len(f.ArgumentTypes) && f.ArgumentTypes[i] == stringParameterType
+		}
+	}
+
+	return false
+}
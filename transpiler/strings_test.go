@@ -0,0 +1,77 @@
+package transpiler
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+// newStringFunction creates a function with one "const char *" parameter. The
+// body is the statement that uses the parameter.
+func newStringFunction(name string, stmt ast.Node) *ast.FunctionDecl {
+	return &ast.FunctionDecl{
+		Name: name,
+		Type: "void (const char *)",
+		Children: []ast.Node{
+			&ast.ParmVarDecl{Address: "0x1", Name: "s", Type: "const char *"},
+			&ast.CompoundStmt{Children: []ast.Node{stmt}},
+		},
+	}
+}
+
+func newStringRef() ast.Node {
+	return newRValue("const char *", &ast.DeclRefExpr{
+		For: "ParmVar", Address2: "0x1", Name: "s", Type: "const char *",
+	})
+}
+
+func TestRegisterStringParameters(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt ast.Node
+		want string
+	}{
+		{"string_printf", newCall("int (const char *, ...)", "printf", &ast.StringLiteral{Value: "%s"}, newStringRef()), "string"},
+		{"string_strlen", newCall("unsigned long (const char *)", "strlen", newStringRef()), "string"},
+		{"string_recursive", newCall("int (const char *)", "string_recursive", newStringRef()), "string"},
+		{"string_strcpy", newCall("char *(char *, const char *)", "strcpy", newStringRef(), newStringRef()), "const char *"},
+		{"string_subscript", &ast.ArraySubscriptExpr{
+			Children: []ast.Node{newStringRef(), &ast.IntegerLiteral{Value: "0"}},
+		}, "const char *"},
+		{"string_return", &ast.ReturnStmt{
+			Children: []ast.Node{newStringRef()},
+		}, "const char *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := program.NewProgram()
+			p.GoStrings = true
+
+			registerStringParameters(&ast.TranslationUnitDecl{
+				Children: []ast.Node{newStringFunction(tt.name, tt.stmt)},
+			}, p)
+
+			f := program.GetFunctionDefinition(tt.name)
+			if f == nil {
+				t.Fatal("function was not registered")
+			}
+
+			if f.ArgumentTypes[0] != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, f.ArgumentTypes[0])
+			}
+		})
+	}
+}
+
+func TestRegisterStringParametersDisabled(t *testing.T) {
+	p := program.NewProgram()
+	registerStringParameters(&ast.TranslationUnitDecl{
+		Children: []ast.Node{newStringFunction("string_disabled", newStringRef())},
+	}, p)
+
+	if program.GetFunctionDefinition("string_disabled") != nil {
+		t.Error("expected the function to not be registered")
+	}
+}
@@ -0,0 +1,235 @@
+// This file contains the structs that have arrays as fields. An array is a
+// slice in Go, so a struct with an array has to allocate it, and the array is
+// not copied with the rest of the struct:
+//
+//     struct S { char buf[16]; int n; };
+//
+//     struct S a;    ->    var a S = S{buf: make([]byte, 16, 16)}
+//     b = a;         ->    b = func() S {
+//                              c := a
+//                              c.buf = append([]byte(nil), c.buf...)
+//                              return c
+//                          }()
+//
+// Each array of the struct (including the arrays in its nested structs) is
+// copied so that changing the elements of one struct does not change the
+// other, like C. The fields that are pointers are still copied as pointers.
+//
+// The members of an union are stored in its memory, so an union is always
+// copied by value.
+
+package transpiler
+
+import (
+	"strings"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+	"go/token"
+)
+
+// getValueStruct returns the struct of a C type that is a struct value, not a
+// pointer to one. It returns nil for any other type, including an union.
+func getValueStruct(p *program.Program, cType string) *program.Struct {
+	if cType == "" {
+		return nil
+	}
+
+	s := p.GetStruct(cType)
+	if s == nil {
+		goType, err := types.ResolveType(p, cType)
+		if err != nil || strings.ContainsAny(goType, "[]*(.") {
+			return nil
+		}
+
+		s = p.GetStruct("struct " + goType)
+	}
+
+	if s == nil || s.IsUnion || strings.HasSuffix(strings.TrimSpace(cType), "*") {
+		return nil
+	}
+
+	return s
+}
+
+// getStructArrayFields returns the Go names and the C types of the fields of a
+// struct that are arrays or structs that contain arrays.
+func getStructArrayFields(p *program.Program, s *program.Struct) (
+	goNames, cTypes []string) {
+	for _, name := range s.FieldNames {
+		cType, ok := s.Fields[name].(string)
+		if !ok || s.Bitfields[name] != nil || !hasArrays(p, cType) {
+			continue
+		}
+
+		// An anonymous struct is embedded, so the name of the field is the name
+		// of its type.
+		goName := getFieldName(p, name)
+		if name == cType {
+			goName = getValueStruct(p, cType).Name
+		}
+
+		goNames = append(goNames, goName)
+		cTypes = append(cTypes, cType)
+	}
+
+	return
+}
+
+// hasArrays returns true if the C type is an array with a size or a struct
+// that has any arrays.
+func hasArrays(p *program.Program, cType string) bool {
+	if _, size := getArrayTypeAndSizeExpr(cType, p); size != nil {
+		return true
+	}
+
+	s := getValueStruct(p, cType)
+	if s == nil {
+		return false
+	}
+
+	_, cTypes := getStructArrayFields(p, s)
+
+	return len(cTypes) > 0
+}
+
+// newZeroValue returns the value of a variable of the C type that is not
+// initialized, or nil if it is the Go zero value. The arrays are allocated,
+// including the arrays of a struct.
+func newZeroValue(n ast.Node, cType string, p *program.Program) goast.Expr {
+	if arrayType, size := getArrayTypeAndSizeExpr(cType, p); size != nil {
+		return newArrayAllocation(n, arrayType, size, p)
+	}
+
+	s := getValueStruct(p, cType)
+	if s == nil {
+		return nil
+	}
+
+	goNames, cTypes := getStructArrayFields(p, s)
+	if len(cTypes) == 0 {
+		return nil
+	}
+
+	goType, err := types.ResolveType(p, cType)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	elts := []goast.Expr{}
+	for i, fieldType := range cTypes {
+		elts = append(elts, &goast.KeyValueExpr{
+			Key:   util.NewIdent(goNames[i]),
+			Value: newZeroValue(n, fieldType, p),
+		})
+	}
+
+	return &goast.CompositeLit{
+		Type: util.NewTypeIdent(goType),
+		Elts: elts,
+	}
+}
+
+// transpileStructCopy returns the value of a struct that is assigned to another
+// variable. The arrays are copied if the value is stored
+// in a variable, a field or an element of an array.
+func transpileStructCopy(node ast.Node, e goast.Expr, cType string, p *program.Program) goast.Expr {
+	switch n := removeCastsAndParens(node).(type) {
+	case *ast.DeclRefExpr, *ast.MemberExpr, *ast.ArraySubscriptExpr:
+	case *ast.UnaryOperator:
+		if n.Operator != "*" {
+			return e
+		}
+	default:
+		return e
+	}
+
+	if getValueStruct(p, cType) == nil {
+		return e
+	}
+
+	if c := newCopyExpr(node, e, cType, p); c != nil {
+		return c
+	}
+
+	return e
+}
+
+// newCopyExpr returns an expression that copies a value of the C type with all
+// of its arrays, or nil if the value does not have any arrays.
+func newCopyExpr(n ast.Node, e goast.Expr, cType string, p *program.Program) goast.Expr {
+	if !hasArrays(p, cType) {
+		return nil
+	}
+
+	goType, err := types.ResolveType(p, cType)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	c := util.NewIdent("c")
+
+	if arrayType, size := getArrayTypeAndSizeExpr(cType, p); size != nil {
+		src := util.NewIdent("src")
+		i := util.NewIdent("i")
+
+		element := newCopyExpr(n, &goast.IndexExpr{X: src, Index: i}, arrayType, p)
+		if element == nil {
+			return &goast.CallExpr{
+				Fun:      util.NewIdent("append"),
+				Args:     []goast.Expr{util.NewCallExpr(goType, util.NewNil()), e},
+				Ellipsis: token.Pos(1),
+			}
+		}
+
+		return util.NewFuncClosure(goType,
+			&goast.AssignStmt{
+				Lhs: []goast.Expr{src},
+				Tok: token.DEFINE,
+				Rhs: []goast.Expr{e},
+			},
+			&goast.AssignStmt{
+				Lhs: []goast.Expr{c},
+				Tok: token.DEFINE,
+				Rhs: []goast.Expr{util.NewCallExpr("make", util.NewTypeIdent(goType),
+					util.NewCallExpr("len", src))},
+			},
+			&goast.RangeStmt{
+				Key: i,
+				Tok: token.DEFINE,
+				X:   src,
+				Body: &goast.BlockStmt{
+					List: []goast.Stmt{
+						&goast.AssignStmt{
+							Lhs: []goast.Expr{&goast.IndexExpr{X: c, Index: i}},
+							Tok: token.ASSIGN,
+							Rhs: []goast.Expr{element},
+						},
+					},
+				},
+			},
+			&goast.ReturnStmt{Results: []goast.Expr{c}},
+		)
+	}
+
+	stmts := []goast.Stmt{
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{c},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{e},
+		},
+	}
+
+	goNames, cTypes := getStructArrayFields(p, getValueStruct(p, cType))
+	for i, fieldType := range cTypes {
+		field := &goast.SelectorExpr{X: c, Sel: util.NewIdent(goNames[i])}
+		stmts = append(stmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{field},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{newCopyExpr(n, field, fieldType, p)},
+		})
+	}
+
+	return util.NewFuncClosure(goType,
+		append(stmts, &goast.ReturnStmt{Results: []goast.Expr{c}})...)
+}
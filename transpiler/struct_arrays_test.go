@@ -0,0 +1,76 @@
+package transpiler
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+// newStructArraysProgram returns a program with:
+//
+//     struct S { char buf[16]; int n; };
+//     struct P { int x; int y; };
+func newStructArraysProgram() *program.Program {
+	p := program.NewProgram()
+	p.Function = &ast.FunctionDecl{Name: "f"}
+	p.Structs["struct S"] = program.NewStruct(&ast.RecordDecl{
+		Kind: "struct",
+		Name: "S",
+		Children: []ast.Node{
+			&ast.FieldDecl{Name: "buf", Type: "char [16]"},
+			&ast.FieldDecl{Name: "n", Type: "int"},
+		},
+	})
+	p.Structs["struct P"] = program.NewStruct(&ast.RecordDecl{
+		Kind: "struct",
+		Name: "P",
+		Children: []ast.Node{
+			&ast.FieldDecl{Name: "x", Type: "int"},
+			&ast.FieldDecl{Name: "y", Type: "int"},
+		},
+	})
+	p.DefineType("S")
+	p.DefineType("P")
+
+	return p
+}
+
+func TestStructArraysReturn(t *testing.T) {
+	p := newStructArraysProgram()
+	p.Function = &ast.FunctionDecl{Name: "struct_arrays_return"}
+	program.AddFunctionDefinition(program.FunctionDefinition{
+		Name:       "struct_arrays_return",
+		ReturnType: "struct S",
+	})
+
+	tests := []struct {
+		node ast.Node
+		out  string
+	}{
+		// return a;
+		{newRValue("struct S", newVarRef("a", "struct S")), `return func() S {
+	c := a
+	c.buf = append([]byte(nil), c.buf...)
+	return c
+}()`},
+
+		// return g();
+		{&ast.CallExpr{Type: "struct S", Children: []ast.Node{
+			&ast.ImplicitCastExpr{Kind: "FunctionToPointerDecay", Type: "struct S (*)(void)", Children: []ast.Node{
+				&ast.DeclRefExpr{For: "Function", Name: "struct_arrays_return", Type: "struct S (void)"},
+			}},
+		}}, "return struct_arrays_return()"},
+	}
+
+	for _, tt := range tests {
+		stmt, _, _, err := transpileToStmt(&ast.ReturnStmt{Children: []ast.Node{tt.node}}, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, stmt); got != tt.out {
+			t.Errorf("expected:\n%s\ngot:\n%s", tt.out, got)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// This file contains the stubs for the functions that are called but never
+// defined when the Stubs option is on. A stub panics when it is called:
+//
+//     func foo(int, []byte) int {
+//         panic("not implemented: foo")
+//     }
+
+package transpiler
+
+import (
+	"strconv"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// getUnresolvedFunctionType returns the Go type of the function that is called.
+// The arguments after the ones in the definition are variadic arguments, or the
+// function was never declared.
+func getUnresolvedFunctionType(n *ast.CallExpr, f *program.FunctionDefinition,
+	p *program.Program) *goast.FuncType {
+	params := []*goast.Field{}
+	for _, argumentType := range f.ArgumentTypes {
+		t, err := types.ResolveType(p, argumentType)
+		p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+		params = append(params, &goast.Field{
+			Type: util.NewTypeIdent(t),
+		})
+	}
+
+	if len(n.Children)-1 > len(f.ArgumentTypes) {
+		params = append(params, &goast.Field{
+			Type: &goast.Ellipsis{
+				Elt: util.NewTypeIdent("interface{}"),
+			},
+		})
+	}
+
+	results := []*goast.Field{}
+	if f.ReturnType != "" {
+		t, err := types.ResolveType(p, f.ReturnType)
+		p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+		if t != "" {
+			results = append(results, &goast.Field{
+				Type: util.NewTypeIdent(t),
+			})
+		}
+	}
+
+	return &goast.FuncType{
+		Params:  &goast.FieldList{List: params},
+		Results: &goast.FieldList{List: results},
+	}
+}
+
+// registerDefinedFunctions records the functions that have a body in the
+// translation unit. They do not need a stub, even if they are called before the
+// body.
+func registerDefinedFunctions(n *ast.TranslationUnitDecl, p *program.Program) {
+	for _, c := range n.Children {
+		if f, ok := c.(*ast.FunctionDecl); ok && getFunctionBody(f) != nil {
+			p.DefineFunction(f.Name)
+		}
+	}
+}
+
+// transpileUnresolvedFunctions generates a stub for each of the functions that
+// were called (see AddUnresolvedFunction) but are not defined anywhere in the
+// package. A stub is also a definition, so a function that is called from more
+// than one file only has one stub.
+func transpileUnresolvedFunctions(p *program.Program) {
+	if !p.Stubs {
+		return
+	}
+
+	for _, name := range p.UnresolvedFunctions() {
+		if p.IsFunctionDefined(name) {
+			continue
+		}
+
+		p.DefineFunction(name)
+		p.File.Decls = append(p.File.Decls, &goast.FuncDecl{
+			Name: util.NewIdent(name),
+			Type: p.GetUnresolvedFunction(name),
+			Body: &goast.BlockStmt{
+				List: []goast.Stmt{
+					util.NewExprStmt(util.NewCallExpr("panic",
+						util.NewStringLit(strconv.Quote("not implemented: "+name)))),
+				},
+			},
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package transpiler
+
+import (
+	goast "go/ast"
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func TestTranspileUnresolvedFunctions(t *testing.T) {
+	p := program.NewProgram()
+	p.Stubs = true
+	p.File = &goast.File{}
+
+	p.AddUnresolvedFunction("missing", &goast.FuncType{Params: &goast.FieldList{}})
+	p.AddUnresolvedFunction("defined", &goast.FuncType{Params: &goast.FieldList{}})
+
+	registerDefinedFunctions(&ast.TranslationUnitDecl{
+		Children: []ast.Node{
+			&ast.FunctionDecl{Name: "missing", Type: "void (void)"},
+			&ast.FunctionDecl{
+				Name:     "defined",
+				Type:     "void (void)",
+				Children: []ast.Node{&ast.CompoundStmt{}},
+			},
+		},
+	}, p)
+	transpileUnresolvedFunctions(p)
+
+	if len(p.File.Decls) != 1 {
+		t.Fatalf("expected 1 stub, got %d", len(p.File.Decls))
+	}
+
+	if name := p.File.Decls[0].(*goast.FuncDecl).Name.Name; name != "missing" {
+		t.Errorf("expected a stub for missing, got %s", name)
+	}
+
+	// The next file of the package does not need another stub.
+	p.File = &goast.File{}
+	transpileUnresolvedFunctions(p)
+
+	if len(p.File.Decls) != 0 {
+		t.Errorf("expected no stubs in the next file, got %d", len(p.File.Decls))
+	}
+}
+
+func TestTranspileUnresolvedFunctionsDisabled(t *testing.T) {
+	p := program.NewProgram()
+	p.File = &goast.File{}
+
+	p.AddUnresolvedFunction("missing", &goast.FuncType{Params: &goast.FieldList{}})
+	transpileUnresolvedFunctions(p)
+
+	if len(p.File.Decls) != 0 {
+		t.Errorf("expected no stubs, got %d", len(p.File.Decls))
+	}
+}
@@ -6,14 +6,16 @@ import (
 	goast "go/ast"
 	"go/token"
 
+	"errors"
 	"fmt"
 
 	"github.com/elliotchance/c2go/ast"
 	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/util"
 )
 
 func transpileSwitchStmt(n *ast.SwitchStmt, p *program.Program) (
-	*goast.SwitchStmt, []goast.Stmt, []goast.Stmt, error) {
+	goast.Stmt, []goast.Stmt, []goast.Stmt, error) {
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
 
@@ -39,6 +41,25 @@ func transpileSwitchStmt(n *ast.SwitchStmt, p *program.Program) (
 	// The body will always be a CompoundStmt because a switch statement is not
 	// valid without curly brackets.
 	body := n.Children[len(n.Children)-1].(*ast.CompoundStmt)
+
+	// Cases that are nested inside other statements cannot use a Go switch.
+	if hasNestedCases(body) {
+		block, err := transpileSwitchStmtWithGoto(condition, body, p)
+		if err != nil {
+			// The switch is replaced with a panic so that the generated code
+			// still compiles, but it will be obvious at runtime if it is
+			// reached.
+			message := fmt.Sprintf("FIXME: %s", err.Error())
+			p.AddMessage(ast.GenerateWarningMessage(errors.New(message), n))
+
+			return util.NewExprStmt(
+				util.NewCallExpr("panic", util.NewStringLit(fmt.Sprintf("%q", message))),
+			), preStmts, postStmts, nil
+		}
+
+		return block, preStmts, postStmts, nil
+	}
+
 	cases, newPre, newPost, err := normalizeSwitchCases(body, p)
 	if err != nil {
 		return nil, nil, nil, err
@@ -115,106 +136,193 @@ func normalizeSwitchCases(body *ast.CompoundStmt, p *program.Program) (
 	// During this translation we also remove 'break' or append a 'fallthrough'.
 
 	cases := []*goast.CaseClause{}
-	caseEndedWithBreak := false
-	var err error
-	var newPre, newPost []goast.Stmt
 
 	for _, x := range body.Children {
 		switch c := x.(type) {
 		case *ast.CaseStmt, *ast.DefaultStmt:
-			cases, newPre, newPost, err = appendCaseOrDefaultToNormaliziedCases(cases, c, caseEndedWithBreak, p)
+			singleCase, newPre, newPost, err := transpileCaseOrDefaultStmt(c, p)
 			if err != nil {
 				return []*goast.CaseClause{}, nil, nil, err
 			}
-			caseEndedWithBreak = false
 
-		case *ast.BreakStmt:
-			caseEndedWithBreak = true
+			preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+			cases = append(cases, singleCase)
 
 		default:
-			var stmt goast.Stmt
-			stmt, newPre, newPost, err = transpileToStmt(x, p)
+			// Statements before the first case can never be reached.
+			if len(cases) == 0 {
+				continue
+			}
+
+			stmts, err := transpileCaseBody([]ast.Node{x}, p)
 			if err != nil {
 				return []*goast.CaseClause{}, nil, nil, err
 			}
 
-			if stmt != nil {
-				cases[len(cases)-1].Body = append(cases[len(cases)-1].Body, stmt)
-			}
+			cases[len(cases)-1].Body = append(cases[len(cases)-1].Body, stmts...)
 		}
 	}
 
-	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+	// C falls through to the next case unless there is a "break". Go is the
+	// opposite so the trailing "break" is removed and every other case that
+	// would not otherwise leave the switch gets a "fallthrough".
+	for i, c := range cases {
+		var endedWithBreak bool
+		c.Body, endedWithBreak = removeTrailingBreak(c.Body)
+
+		if i < len(cases)-1 && !endedWithBreak && !isTerminatingStmt(c.Body) {
+			c.Body = append(c.Body, &goast.BranchStmt{
+				Tok: token.FALLTHROUGH,
+			})
+		}
+	}
 
 	return cases, preStmts, postStmts, nil
 }
 
-func appendCaseOrDefaultToNormaliziedCases(cases []*goast.CaseClause,
-	stmt ast.Node, caseEndedWithBreak bool, p *program.Program) (
-	[]*goast.CaseClause, []goast.Stmt, []goast.Stmt, error) {
+// transpileCaseOrDefaultStmt transpiles the start of a case. Cases can be
+// stacked without any statements between them:
+//
+//     case 1:
+//     case 2:
+//         foo();
+//
+// Clang represents the second case as the child of the first. These are
+// combined into a single Go case with several values:
+//
+//     case 1, 2:
+//         foo()
+//
+// If one of the stacked cases is the "default" then the whole Go case becomes
+// the "default" since the other values would have matched it anyway.
+func transpileCaseOrDefaultStmt(n ast.Node, p *program.Program) (
+	*goast.CaseClause, []goast.Stmt, []goast.Stmt, error) {
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
+	values := []goast.Expr{}
+	isDefault := false
 
-	if len(cases) > 0 && !caseEndedWithBreak {
-		cases[len(cases)-1].Body = append(cases[len(cases)-1].Body, &goast.BranchStmt{
-			Tok: token.FALLTHROUGH,
-		})
-	}
-	caseEndedWithBreak = false
+	for {
+		var children []ast.Node
 
-	var singleCase *goast.CaseClause
-	var err error
-	var newPre []goast.Stmt
-	var newPost []goast.Stmt
+		switch c := n.(type) {
+		case *ast.CaseStmt:
+			value, _, newPre, newPost, err := transpileToExpr(c.Children[0], p)
+			if err != nil {
+				return nil, nil, nil, err
+			}
 
-	switch c := stmt.(type) {
-	case *ast.CaseStmt:
-		singleCase, newPre, newPost, err = transpileCaseStmt(c, p)
+			preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+			values = append(values, value)
+			children = c.Children[1:]
+
+		case *ast.DefaultStmt:
+			isDefault = true
+			children = c.Children
+		}
+
+		// The last child is the first statement of the case. It may also be
+		// another case that is stacked on this one.
+		if len(children) > 0 {
+			switch children[len(children)-1].(type) {
+			case *ast.CaseStmt, *ast.DefaultStmt:
+				n = children[len(children)-1]
+				continue
+			}
+		}
 
-	case *ast.DefaultStmt:
-		singleCase, err = transpileDefaultStmt(c, p)
+		stmts, err := transpileCaseBody(children, p)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if isDefault {
+			values = nil
+		}
+
+		return &goast.CaseClause{
+			List: values,
+			Body: stmts,
+		}, preStmts, postStmts, nil
 	}
+}
 
-	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+// transpileCaseBody transpiles the statements that belong to a case. Any
+// statements that are required for the side effects of each statement are
+// kept within the case.
+func transpileCaseBody(nodes []ast.Node, p *program.Program) ([]goast.Stmt, error) {
+	stmts := []goast.Stmt{}
 
-	if err != nil {
-		return []*goast.CaseClause{}, nil, nil, err
+	for _, n := range nodes {
+		result, err := transpileToStmts(n, p)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range result {
+			if s != nil {
+				stmts = append(stmts, s)
+			}
+		}
 	}
 
-	return append(cases, singleCase), preStmts, postStmts, nil
+	return stmts, nil
 }
 
-func transpileCaseStmt(n *ast.CaseStmt, p *program.Program) (
-	*goast.CaseClause, []goast.Stmt, []goast.Stmt, error) {
-	preStmts := []goast.Stmt{}
-	postStmts := []goast.Stmt{}
-
-	c, _, newPre, newPost, err := transpileToExpr(n.Children[0], p)
-	if err != nil {
-		return nil, nil, nil, err
+// removeTrailingBreak removes the "break" at the end of a case, including the
+// case where the body of the case is enclosed in its own scope. The second
+// return value will be true if a "break" was removed.
+func removeTrailingBreak(stmts []goast.Stmt) ([]goast.Stmt, bool) {
+	if len(stmts) == 0 {
+		return stmts, false
 	}
 
-	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+	switch s := stmts[len(stmts)-1].(type) {
+	case *goast.BranchStmt:
+		if s.Tok == token.BREAK && s.Label == nil {
+			return stmts[:len(stmts)-1], true
+		}
 
-	stmts, err := transpileStmts(n.Children[1:], p)
-	if err != nil {
-		return nil, nil, nil, err
+	case *goast.BlockStmt:
+		var removed bool
+		s.List, removed = removeTrailingBreak(s.List)
+		return stmts, removed
 	}
 
-	return &goast.CaseClause{
-		List: []goast.Expr{c},
-		Body: stmts,
-	}, preStmts, postStmts, nil
+	return stmts, false
 }
 
-func transpileDefaultStmt(n *ast.DefaultStmt, p *program.Program) (*goast.CaseClause, error) {
-	stmts, err := transpileStmts(n.Children[0:], p)
-	if err != nil {
-		return nil, err
+// isTerminatingStmt returns true if the last statement will always leave the
+// case, so that a "fallthrough" would not be reachable.
+func isTerminatingStmt(stmts []goast.Stmt) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+
+	switch s := stmts[len(stmts)-1].(type) {
+	case *goast.ReturnStmt:
+		return true
+
+	case *goast.BranchStmt:
+		return s.Tok == token.GOTO || s.Tok == token.CONTINUE ||
+			s.Tok == token.BREAK
+
+	case *goast.BlockStmt:
+		return isTerminatingStmt(s.List)
+
+	case *goast.ExprStmt:
+		// A return in main() becomes os.Exit() or noarch.Exit().
+		if call, ok := s.X.(*goast.CallExpr); ok {
+			switch f := call.Fun.(type) {
+			case *goast.Ident:
+				return f.Name == "panic"
+
+			case *goast.SelectorExpr:
+				x, ok := f.X.(*goast.Ident)
+				return ok && (x.Name == "os" || x.Name == "noarch") && f.Sel.Name == "Exit"
+			}
+		}
 	}
 
-	return &goast.CaseClause{
-		List: nil,
-		Body: stmts,
-	}, nil
+	return false
 }
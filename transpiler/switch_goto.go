@@ -0,0 +1,512 @@
+// This file contains functions for transpiling a "switch" statement where the
+// cases are not directly inside the body of the switch. The most famous
+// example of this is Duff's device:
+//
+//     switch (count % 8) {
+//     case 0: do { *to = *from++;
+//     case 7:      *to = *from++;
+//     ...
+//     case 1:      *to = *from++;
+//             } while (--n > 0);
+//     }
+//
+// There is no way to represent this with a Go switch, or even with a goto
+// since Go does not allow jumping into a block. Instead the whole switch is
+// flattened so that every statement that contains a case is replaced with
+// labels and gotos.
+
+package transpiler
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// switchLowering holds the state for flattening a single switch statement.
+type switchLowering struct {
+	p *program.Program
+
+	// The cases that were found, in the order they appear. A nil value is the
+	// default case.
+	values []goast.Expr
+	labels []string
+
+	// The labels that will be jumped to when a "break" or "continue" is
+	// reached. continueLabel is empty when a "continue" belongs to a loop that
+	// is outside of the switch.
+	breakLabel    string
+	continueLabel string
+
+	// Go does not allow a label that is never used.
+	usedLabels map[string]bool
+
+	// The variables that hold the value of a condition (see
+	// newConditionalGoto). They are declared before the first label because a
+	// goto cannot jump over the declaration of a variable.
+	conditions []string
+}
+
+// hasNestedCases returns true if any case of the switch body is inside of
+// another statement. Stacked cases (a case that is the child of another case)
+// are still directly inside the switch.
+func hasNestedCases(body *ast.CompoundStmt) bool {
+	for _, c := range body.Children {
+		for isCaseOrDefault(c) {
+			children := getChildren(c)
+			if len(children) == 0 {
+				c = nil
+				break
+			}
+			c = children[len(children)-1]
+		}
+
+		if containsCase(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isCaseOrDefault(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.CaseStmt, *ast.DefaultStmt:
+		return true
+	}
+
+	return false
+}
+
+// containsCase returns true if there is a case anywhere in the tree. Cases that
+// belong to another switch are not included.
+func containsCase(n ast.Node) bool {
+	if isCaseOrDefault(n) {
+		return true
+	}
+
+	if _, ok := n.(*ast.SwitchStmt); ok {
+		return false
+	}
+
+	for _, c := range getChildren(n) {
+		if containsCase(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// transpileSwitchStmtWithGoto converts a switch that has nested cases into a
+// block like:
+//
+//     {
+//         switch condition {
+//         case 0:
+//             goto case0
+//         case 7:
+//             goto case1
+//         default:
+//             goto end2
+//         }
+//     case0:
+//         ...
+//     end2:
+//     }
+//
+// If the switch cannot be flattened an error is returned.
+func transpileSwitchStmtWithGoto(condition goast.Expr, body *ast.CompoundStmt,
+	p *program.Program) (*goast.BlockStmt, error) {
+	l := &switchLowering{
+		p:          p,
+		breakLabel: p.GetNextIdentifier("end"),
+		usedLabels: map[string]bool{},
+	}
+
+	stmts, err := l.flattenStmts(body.Children)
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := []goast.Stmt{}
+	hasDefault := false
+	for i, value := range l.values {
+		clause := &goast.CaseClause{
+			Body: []goast.Stmt{l.newGoto(l.labels[i])},
+		}
+
+		if value == nil {
+			hasDefault = true
+		} else {
+			clause.List = []goast.Expr{value}
+		}
+
+		clauses = append(clauses, clause)
+	}
+
+	if !hasDefault {
+		clauses = append(clauses, &goast.CaseClause{
+			Body: []goast.Stmt{l.newGoto(l.breakLabel)},
+		})
+	}
+
+	stmts = append([]goast.Stmt{&goast.SwitchStmt{
+		Tag: condition,
+		Body: &goast.BlockStmt{
+			List: clauses,
+		},
+	}}, stmts...)
+
+	for i := len(l.conditions) - 1; i >= 0; i-- {
+		stmts = append([]goast.Stmt{&goast.DeclStmt{Decl: &goast.GenDecl{
+			Tok: token.VAR,
+			Specs: []goast.Spec{&goast.ValueSpec{
+				Names: []*goast.Ident{util.NewIdent(l.conditions[i])},
+				Type:  util.NewTypeIdent("bool"),
+			}},
+		}}}, stmts...)
+	}
+	stmts = append(stmts, l.newLabel(l.breakLabel)...)
+
+	return &goast.BlockStmt{
+		List: l.removeUnusedLabels(stmts),
+	}, nil
+}
+
+func (l *switchLowering) newGoto(label string) *goast.BranchStmt {
+	l.usedLabels[label] = true
+
+	return &goast.BranchStmt{
+		Tok:   token.GOTO,
+		Label: util.NewIdent(label),
+	}
+}
+
+func (l *switchLowering) newLabel(label string) []goast.Stmt {
+	return []goast.Stmt{&goast.LabeledStmt{
+		Label: util.NewIdent(label),
+		Stmt:  &goast.EmptyStmt{},
+	}}
+}
+
+// removeUnusedLabels removes the labels that are never jumped to. The labels
+// that remain are attached to the statement that follows them.
+func (l *switchLowering) removeUnusedLabels(stmts []goast.Stmt) []goast.Stmt {
+	result := []goast.Stmt{}
+	for i := len(stmts) - 1; i >= 0; i-- {
+		s := stmts[i]
+		if labeled, ok := s.(*goast.LabeledStmt); ok && isEmptyStmt(labeled.Stmt) {
+			if !l.usedLabels[labeled.Label.Name] {
+				continue
+			}
+
+			if len(result) > 0 {
+				labeled.Stmt = result[0]
+				result = result[1:]
+			}
+		}
+
+		result = append([]goast.Stmt{s}, result...)
+	}
+
+	return result
+}
+
+func isEmptyStmt(s goast.Stmt) bool {
+	_, ok := s.(*goast.EmptyStmt)
+	return ok
+}
+
+func (l *switchLowering) flattenStmts(nodes []ast.Node) ([]goast.Stmt, error) {
+	stmts := []goast.Stmt{}
+	for _, n := range nodes {
+		s, err := l.flatten(n)
+		if err != nil {
+			return nil, err
+		}
+
+		stmts = append(stmts, s...)
+	}
+
+	return stmts, nil
+}
+
+// flatten converts a statement that contains a case into a list of statements
+// that only use labels and gotos for control flow. Statements that do not
+// contain a case are transpiled as normal.
+func (l *switchLowering) flatten(node ast.Node) ([]goast.Stmt, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	switch node.(type) {
+	case *ast.BreakStmt:
+		return []goast.Stmt{l.newGoto(l.breakLabel)}, nil
+
+	case *ast.ContinueStmt:
+		if l.continueLabel != "" {
+			return []goast.Stmt{l.newGoto(l.continueLabel)}, nil
+		}
+	}
+
+	if !containsCase(node) {
+		if _, ok := node.(*ast.DeclStmt); ok {
+			return nil, errors.New("cannot declare variables in a switch with nested cases")
+		}
+
+		stmts, err := transpileToStmts(node, l.p)
+		if err != nil {
+			return nil, err
+		}
+
+		result := []goast.Stmt{}
+		for _, s := range stmts {
+			if s != nil {
+				result = append(result, l.rewriteBranches(s, false))
+			}
+		}
+
+		return result, nil
+	}
+
+	switch n := node.(type) {
+	case *ast.CaseStmt, *ast.DefaultStmt:
+		label := l.p.GetNextIdentifier("case")
+		children := getChildren(n)
+
+		var value goast.Expr
+		if c, ok := n.(*ast.CaseStmt); ok {
+			var err error
+			value, _, _, _, err = transpileToExpr(c.Children[0], l.p)
+			if err != nil {
+				return nil, err
+			}
+			children = children[1:]
+		}
+
+		l.values = append(l.values, value)
+		l.labels = append(l.labels, label)
+
+		stmts, err := l.flattenStmts(children)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(l.newLabel(label), stmts...), nil
+
+	case *ast.CompoundStmt:
+		return l.flattenStmts(n.Children)
+
+	case *ast.IfStmt:
+		children := n.Children[len(n.Children)-3:]
+		elseLabel := l.p.GetNextIdentifier("else")
+		endLabel := l.p.GetNextIdentifier("end")
+
+		stmts, err := l.newConditionalGoto(children[0], false, elseLabel)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := l.flatten(children[1])
+		if err != nil {
+			return nil, err
+		}
+
+		stmts = append(stmts, body...)
+		stmts = append(stmts, l.newGoto(endLabel))
+		stmts = append(stmts, l.newLabel(elseLabel)...)
+
+		elseBody, err := l.flatten(children[2])
+		if err != nil {
+			return nil, err
+		}
+
+		stmts = append(stmts, elseBody...)
+
+		return append(stmts, l.newLabel(endLabel)...), nil
+
+	case *ast.DoStmt:
+		return l.flattenLoop(nil, n.Children[0], n.Children[1], nil, true)
+
+	case *ast.WhileStmt:
+		return l.flattenLoop(nil, n.Children[2], n.Children[1], nil, false)
+
+	case *ast.ForStmt:
+		return l.flattenLoop(n.Children[0], n.Children[4], n.Children[2], n.Children[3], false)
+	}
+
+	return nil, fmt.Errorf("cannot put a case inside of %T", node)
+}
+
+// flattenLoop converts any kind of loop into:
+//
+//     init
+//     start:
+//         if !condition { goto end }    // when the condition is checked first
+//         body
+//     continue:
+//         post
+//         if condition { goto start }
+//     end:
+func (l *switchLowering) flattenLoop(init, body, condition, post ast.Node,
+	checkConditionLast bool) ([]goast.Stmt, error) {
+	startLabel := l.p.GetNextIdentifier("loop")
+	continueLabel := l.p.GetNextIdentifier("continue")
+	endLabel := l.p.GetNextIdentifier("end")
+
+	stmts, err := l.flatten(init)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts = append(stmts, l.newLabel(startLabel)...)
+
+	if condition != nil && !checkConditionLast {
+		check, err := l.newConditionalGoto(condition, false, endLabel)
+		if err != nil {
+			return nil, err
+		}
+
+		stmts = append(stmts, check...)
+	}
+
+	breakLabel, outerContinueLabel := l.breakLabel, l.continueLabel
+	l.breakLabel, l.continueLabel = endLabel, continueLabel
+
+	loopBody, err := l.flatten(body)
+	l.breakLabel, l.continueLabel = breakLabel, outerContinueLabel
+	if err != nil {
+		return nil, err
+	}
+
+	stmts = append(stmts, loopBody...)
+	stmts = append(stmts, l.newLabel(continueLabel)...)
+
+	postStmts, err := l.flatten(post)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts = append(stmts, postStmts...)
+
+	if condition != nil && checkConditionLast {
+		check, err := l.newConditionalGoto(condition, true, startLabel)
+		if err != nil {
+			return nil, err
+		}
+
+		stmts = append(stmts, check...)
+	} else {
+		stmts = append(stmts, l.newGoto(startLabel))
+	}
+
+	return append(stmts, l.newLabel(endLabel)...), nil
+}
+
+// newConditionalGoto creates an "if" statement that jumps to the label when
+// the condition is the same as jumpIf. A condition that needs other statements
+// is evaluated in a block of its own first, so that the variables of those
+// statements cannot be jumped over:
+//
+//     {
+//         preStmts
+//         condition1 = !(condition)
+//         postStmts
+//     }
+//     if condition1 {
+//         goto label
+//     }
+func (l *switchLowering) newConditionalGoto(condition ast.Node, jumpIf bool,
+	label string) ([]goast.Stmt, error) {
+	e, eType, preStmts, postStmts, err := transpileToExpr(condition, l.p)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err = types.CastExpr(l.p, e, eType, "bool")
+	if err != nil {
+		return nil, err
+	}
+
+	if !jumpIf {
+		e = &goast.UnaryExpr{
+			Op: token.NOT,
+			X:  &goast.ParenExpr{X: e},
+		}
+	}
+
+	stmts := []goast.Stmt{}
+	if len(preStmts) > 0 || len(postStmts) > 0 {
+		name := l.p.GetNextIdentifier("condition")
+		l.conditions = append(l.conditions, name)
+
+		block := append(preStmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(name)},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{e},
+		})
+		stmts = append(stmts, &goast.BlockStmt{
+			List: append(block, postStmts...),
+		})
+		e = util.NewIdent(name)
+	}
+
+	return append(stmts, &goast.IfStmt{
+		Cond: e,
+		Body: &goast.BlockStmt{
+			List: []goast.Stmt{l.newGoto(label)},
+		},
+	}), nil
+}
+
+// rewriteBranches replaces any "break" or "continue" that would have referred
+// to a flattened loop or the switch itself with a goto.
+func (l *switchLowering) rewriteBranches(s goast.Stmt, inSwitch bool) goast.Stmt {
+	switch n := s.(type) {
+	case *goast.BranchStmt:
+		if n.Label != nil {
+			return n
+		}
+
+		if n.Tok == token.BREAK && !inSwitch {
+			return l.newGoto(l.breakLabel)
+		}
+
+		if n.Tok == token.CONTINUE && l.continueLabel != "" {
+			return l.newGoto(l.continueLabel)
+		}
+
+	case *goast.BlockStmt:
+		for i, c := range n.List {
+			n.List[i] = l.rewriteBranches(c, inSwitch)
+		}
+
+	case *goast.IfStmt:
+		l.rewriteBranches(n.Body, inSwitch)
+		if n.Else != nil {
+			n.Else = l.rewriteBranches(n.Else, inSwitch)
+		}
+
+	case *goast.LabeledStmt:
+		n.Stmt = l.rewriteBranches(n.Stmt, inSwitch)
+
+	case *goast.SwitchStmt:
+		// A "break" belongs to the inner switch, but a "continue" does not.
+		for _, c := range n.Body.List {
+			clause := c.(*goast.CaseClause)
+			for i, b := range clause.Body {
+				clause.Body[i] = l.rewriteBranches(b, true)
+			}
+		}
+	}
+
+	return s
+}
@@ -8,6 +8,7 @@ import (
 	goast "go/ast"
 	"go/parser"
 	"go/token"
+	"reflect"
 
 	"github.com/elliotchance/c2go/ast"
 	"github.com/elliotchance/c2go/program"
@@ -17,6 +18,7 @@ import (
 // TranspileAST iterates through the Clang AST and builds a Go AST
 func TranspileAST(fileName, packageName string, p *program.Program, root ast.Node) error {
 	// Start by parsing an empty file.
+	p.StartFile()
 	p.FileSet = token.NewFileSet()
 	packageSignature := fmt.Sprintf("package %v", packageName)
 	f, err := parser.ParseFile(p.FileSet, fileName, packageSignature, 0)
@@ -26,23 +28,49 @@ func TranspileAST(fileName, packageName string, p *program.Program, root ast.Nod
 		return err
 	}
 
+	// A node that clang has but c2go does not know about cannot be
+	// transpiled. It is reported, or it stops the transpiler.
+	unknownNodes := ast.GetAllNodesOfType(root, reflect.TypeOf((*ast.UnknownNode)(nil)))
+	for _, node := range unknownNodes {
+		if !p.ReportUnsupported {
+			panic("unknown node type: '" + node.(*ast.UnknownNode).Line + "'")
+		}
+
+		p.AddUnsupportedNode(node)
+	}
+
+	registerFunctionNames(root, p)
+
 	// Now begin building the Go AST.
 	err = transpileToNode(root, p)
+	transpileConstructors(p)
 
 	// Now we need to build the __init() function. This sets up certain state
-	// and variables that the runtime expects to be ready.
-	p.File.Decls = append(p.File.Decls, &goast.FuncDecl{
-		Name: util.NewIdent("__init"),
-		Type: &goast.FuncType{
-			Params: &goast.FieldList{
-				List: []*goast.Field{},
+	// and variables that the runtime expects to be ready. Each file of a
+	// package with more than one file uses init() instead, which Go runs
+	// before main().
+	initName := "__init"
+	if p.GoInit {
+		initName = "init"
+	}
+
+	if !p.GoInit || len(p.StartupStatements()) > 0 {
+		p.File.Decls = append(p.File.Decls, &goast.FuncDecl{
+			Name: util.NewIdent(initName),
+			Type: &goast.FuncType{
+				Params: &goast.FieldList{
+					List: []*goast.Field{},
+				},
+				Results: nil,
 			},
-			Results: nil,
-		},
-		Body: &goast.BlockStmt{
-			List: p.StartupStatements(),
-		},
-	})
+			Body: &goast.BlockStmt{
+				List: p.StartupStatements(),
+			},
+		})
+	}
+
+	removeRedundantCasts(p)
+	transpileMacros(p)
 
 	// Add the imports after everything else so we can ensure that they are all
 	// placed at the top.
@@ -70,6 +98,26 @@ func TranspileAST(fileName, packageName string, p *program.Program, root ast.Nod
 	return err
 }
 
+// RegisterDefinitions registers the functions of a translation unit before
+// anything is transpiled, so that a function can be called before it is
+// defined. TranspileAST registers its own translation unit. When a package has
+// more than one C file every file must be registered before the first one is
+// transpiled.
+func RegisterDefinitions(p *program.Program, root ast.Node) {
+	n, ok := root.(*ast.TranslationUnitDecl)
+	if !ok {
+		return
+	}
+
+	registerOldStyleFunctions(n)
+	registerStringParameters(n, p)
+	registerDefinedFunctions(n, p)
+	registerDefinedVariables(n, p)
+	registerAddressTaken(n, p)
+	registerDestructors(n, p)
+	registerMemoryTags(n, p)
+}
+
 func transpileToExpr(node ast.Node, p *program.Program) (
 	expr goast.Expr,
 	exprType string,
@@ -82,19 +130,33 @@ func transpileToExpr(node ast.Node, p *program.Program) (
 
 	switch n := node.(type) {
 	case *ast.StringLiteral:
-		expr = transpileStringLiteral(n)
-		exprType = "const char *"
+		expr, exprType = transpileStringLiteral(n)
 
 	case *ast.FloatingLiteral:
 		expr = transpileFloatingLiteral(n)
 		exprType = "double"
 
+	case *ast.ImaginaryLiteral:
+		expr, exprType, err = transpileImaginaryLiteral(n, p)
+
 	case *ast.PredefinedExpr:
 		expr, exprType, err = transpilePredefinedExpr(n, p)
 
+	case *ast.AddrLabelExpr:
+		expr, exprType, err = transpileAddrLabelExpr(n, p)
+
 	case *ast.ConditionalOperator:
 		expr, exprType, preStmts, postStmts, err = transpileConditionalOperator(n, p)
 
+	case *ast.BinaryConditionalOperator:
+		expr, exprType, preStmts, postStmts, err = transpileBinaryConditionalOperator(n, p)
+
+	case *ast.VAArgExpr:
+		expr, exprType, preStmts, postStmts, err = transpileVAArgExpr(n, p)
+
+	case *ast.OpaqueValueExpr:
+		expr, exprType, preStmts, postStmts, err = transpileToExpr(n.Children[0], p)
+
 	case *ast.ArraySubscriptExpr:
 		expr, exprType, preStmts, postStmts, err = transpileArraySubscriptExpr(n, p)
 
@@ -108,25 +170,28 @@ func transpileToExpr(node ast.Node, p *program.Program) (
 		expr, exprType, preStmts, postStmts, err = transpileMemberExpr(n, p)
 
 	case *ast.ImplicitCastExpr:
-		expr, exprType, preStmts, postStmts, err = transpileToExpr(n.Children[0], p)
+		expr, exprType, preStmts, postStmts, err = transpileImplicitCastExpr(n, p)
 
 	case *ast.DeclRefExpr:
 		expr, exprType, err = transpileDeclRefExpr(n, p)
 
 	case *ast.IntegerLiteral:
-		expr, exprType, err = transpileIntegerLiteral(n), "int", nil
+		expr, exprType, err = transpileIntegerLiteral(n, p)
 
 	case *ast.ParenExpr:
 		expr, exprType, preStmts, postStmts, err = transpileParenExpr(n, p)
 
 	case *ast.CStyleCastExpr:
-		expr, exprType, preStmts, postStmts, err = transpileToExpr(n.Children[0], p)
+		expr, exprType, preStmts, postStmts, err = transpileCStyleCastExpr(n, p)
 
 	case *ast.CharacterLiteral:
-		expr, exprType, err = transpileCharacterLiteral(n), "char", nil
+		expr, exprType, err = transpileCharacterLiteral(n), getCharacterLiteralType(n), nil
 
 	case *ast.CallExpr:
 		expr, exprType, preStmts, postStmts, err = transpileCallExpr(n, p)
+		if err == nil {
+			expr, preStmts = transpileErrnoResult(n, expr, exprType, preStmts, p)
+		}
 
 	case *ast.CompoundAssignOperator:
 		return transpileCompoundAssignOperator(n, p)
@@ -134,8 +199,26 @@ func transpileToExpr(node ast.Node, p *program.Program) (
 	case *ast.UnaryExprOrTypeTraitExpr:
 		return transpileUnaryExprOrTypeTraitExpr(n, p)
 
+	case *ast.OffsetOfExpr:
+		expr, exprType, err = transpileOffsetOfExpr(n, p)
+
+	case *ast.TypeTraitExpr:
+		return transpileTypeTraitExpr(n, p)
+
+	case *ast.GenericSelectionExpr:
+		return transpileGenericSelectionExpr(n, p)
+
+	case *ast.InitListExpr:
+		expr, exprType, preStmts, postStmts, err = transpileInitListExpr(n, p)
+
+	case *ast.CompoundLiteralExpr:
+		expr, exprType, preStmts, postStmts, err = transpileCompoundLiteralExpr(n, p)
+
 	default:
 		p.AddMessage(ast.GenerateWarningMessage(errors.New("cannot transpile to expr"), node))
+		if p.ReportUnsupported {
+			p.AddUnsupportedNode(node)
+		}
 		expr = util.NewNil()
 	}
 
@@ -171,12 +254,8 @@ func transpileToStmt(node ast.Node, p *program.Program) (
 	var expr goast.Expr
 
 	switch n := node.(type) {
-	case *ast.DefaultStmt:
-		stmt, err = transpileDefaultStmt(n, p)
-		return
-
-	case *ast.CaseStmt:
-		stmt, preStmts, postStmts, err = transpileCaseStmt(n, p)
+	case *ast.CaseStmt, *ast.DefaultStmt:
+		stmt, preStmts, postStmts, err = transpileCaseOrDefaultStmt(n, p)
 		return
 
 	case *ast.SwitchStmt:
@@ -190,20 +269,42 @@ func transpileToStmt(node ast.Node, p *program.Program) (
 		return
 
 	case *ast.WhileStmt:
-		return transpileWhileStmt(n, p)
+		stmt, preStmts, postStmts, err = transpileWhileStmt(n, p)
+		if err == nil {
+			stmt = labelLoop(n, stmt, p)
+		}
+		return
 
 	case *ast.DoStmt:
-		return transpileDoStmt(n, p)
+		stmt, preStmts, postStmts, err = transpileDoStmt(n, p)
+		if err == nil {
+			stmt = labelLoop(n, stmt, p)
+		}
+		return
 
 	case *ast.ContinueStmt:
 		stmt, err = transpileContinueStmt(n, p)
 		return
 
+	case *ast.GotoStmt:
+		stmt, err = transpileGotoStmt(n, p)
+		return
+
+	case *ast.IndirectGotoStmt:
+		return transpileIndirectGotoStmt(n, p)
+
+	case *ast.LabelStmt:
+		return transpileLabelStmt(n, p)
+
 	case *ast.IfStmt:
 		return transpileIfStmt(n, p)
 
 	case *ast.ForStmt:
-		return transpileForStmt(n, p)
+		stmt, preStmts, postStmts, err = transpileForStmt(n, p)
+		if err == nil {
+			stmt = labelLoop(n, stmt, p)
+		}
+		return
 
 	case *ast.ReturnStmt:
 		return transpileReturnStmt(n, p)
@@ -214,9 +315,45 @@ func transpileToStmt(node ast.Node, p *program.Program) (
 
 	case *ast.BinaryOperator:
 		if n.Operator == "," {
-			stmt, preStmts, err = transpileBinaryOperatorComma(n, p)
+			var assert *goast.CallExpr
+			assert, preStmts, postStmts, err = transpileAssertStmtExpr(n, p)
+			if err != nil || assert != nil {
+				if assert != nil {
+					stmt = util.NewExprStmt(assert)
+				}
+				return
+			}
+
+			stmt, preStmts, postStmts, err = transpileBinaryOperatorComma(n, p)
 			return
 		}
+
+	case *ast.CallExpr:
+		// The result is not used, so a function that sets errno does not need
+		// a variable for the result of its wrapper (see transpileErrnoResult).
+		expr, _, preStmts, postStmts, err = transpileCallExpr(n, p)
+		if err == nil {
+			stmt = util.NewExprStmt(expr)
+		}
+		return
+
+	case *ast.UnaryOperator:
+		// The value of "i++" is not used, so it does not need a closure (see
+		// transpileIncDecExpr).
+		if n.Operator == "++" || n.Operator == "--" {
+			expr, _, preStmts, postStmts, err = transpileIncDecOperator(n, p)
+			if err == nil {
+				stmt = util.NewExprStmt(expr)
+			}
+			return
+		}
+	}
+
+	// "(void)0" does nothing. It is what assert() becomes when NDEBUG is
+	// defined.
+	if isVoidZero(node) {
+		stmt = &goast.EmptyStmt{}
+		return
 	}
 
 	// We do not care about the return type.
@@ -233,10 +370,15 @@ func transpileToStmt(node ast.Node, p *program.Program) (
 func transpileToNode(node ast.Node, p *program.Program) error {
 	switch n := node.(type) {
 	case *ast.TranslationUnitDecl:
-		for _, c := range n.Children {
-			transpileToNode(c, p)
+		RegisterDefinitions(p, n)
+
+		for i, c := range n.Children {
+			transpileToNode(nameAnonymousDecl(c, n.Children[i+1:]), p)
 		}
 
+		transpileUnresolvedFunctions(p)
+		transpileErrnoFunctions(p)
+
 	case *ast.FunctionDecl:
 		err := transpileFunctionDecl(n, p)
 		if err != nil {
@@ -250,15 +392,37 @@ func transpileToNode(node ast.Node, p *program.Program) error {
 		return transpileRecordDecl(p, n)
 
 	case *ast.VarDecl:
-		transpileVarDecl(p, n)
+		// The value of a global variable cannot have statements before or
+		// after it.
+		_, _, err := transpileVarDecl(p, n)
+		p.AddMessage(ast.GenerateWarningMessage(err, n))
 		return nil
 
 	case *ast.EnumDecl:
 		transpileEnumDecl(p, n)
 		return nil
 
+	case *ast.StaticAssertDecl:
+		// Global assertions are checked when the program starts.
+		stmt, preStmts, postStmts, err := transpileStaticAssertDecl(p, n)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range preStmts {
+			p.AppendStartupStatement(s)
+		}
+		p.AppendStartupStatement(stmt)
+		for _, s := range postStmts {
+			p.AppendStartupStatement(s)
+		}
+
 	default:
-		panic(fmt.Sprintf("cannot transpile to node: %#v", node))
+		if !p.ReportUnsupported {
+			panic(fmt.Sprintf("cannot transpile to node: %#v", node))
+		}
+
+		p.AddUnsupportedNode(node)
 	}
 
 	return nil
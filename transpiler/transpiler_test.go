@@ -0,0 +1,53 @@
+package transpiler
+
+import (
+	"bytes"
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+
+	goast "go/ast"
+
+	"github.com/elliotchance/c2go/ast"
+)
+
+// renderNode returns the Go code of a node that was transpiled.
+func renderNode(t *testing.T, node goast.Node) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), node); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+// newVarRef returns a reference to a variable.
+func newVarRef(name, cType string) *ast.DeclRefExpr {
+	return &ast.DeclRefExpr{For: "Var", Name: name, Type: cType}
+}
+
+// newRValue returns the value of an expression, like clang does when a
+// variable is read.
+func newRValue(cType string, n ast.Node) *ast.ImplicitCastExpr {
+	return &ast.ImplicitCastExpr{Kind: "LValueToRValue", Type: cType, Children: []ast.Node{n}}
+}
+
+// newCall returns a call to a function of the C type provided, like
+// "int (const char *)". The function decays into a pointer, like clang.
+func newCall(cType, name string, args ...ast.Node) *ast.CallExpr {
+	i := strings.Index(cType, "(")
+
+	return &ast.CallExpr{
+		Type: strings.TrimSpace(cType[:i]),
+		Children: append([]ast.Node{
+			&ast.ImplicitCastExpr{
+				Kind: "FunctionToPointerDecay",
+				Type: cType[:i] + "(*)" + cType[i:],
+				Children: []ast.Node{
+					&ast.DeclRefExpr{For: "Function", Name: name, Type: cType},
+				},
+			},
+		}, args...),
+	}
+}
@@ -4,6 +4,7 @@ package transpiler
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/elliotchance/c2go/ast"
@@ -19,65 +20,31 @@ func transpileUnaryOperator(n *ast.UnaryOperator, p *program.Program) (
 	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
-	operator := getTokenForOperator(n.Operator)
 
-	// Unfortunately we cannot use the Go increment operators because we are not
-	// providing any position information for tokens. This means that the ++/--
-	// would be placed before the expression and would be invalid in Go.
-	//
-	// Until it can be properly fixed (can we trick Go into to placing it after
-	// the expression with a magic position?) we will have to return a
-	// BinaryExpr with the same functionality.
-	if operator == token.INC || operator == token.DEC {
-		// Construct code for assigning value to an union field
-		memberExpr, ok := n.Children[0].(*ast.MemberExpr)
-		if ok {
-			ref := memberExpr.GetDeclRefExpr()
-			if ref != nil {
-				binaryOperator := token.ADD
-				if operator == token.DEC {
-					binaryOperator = token.SUB
-				}
-
-				union := p.GetStruct(ref.Type)
-				if union != nil && union.IsUnion {
-					// Method suffix for using getters and setters of Go union type
-					methodSuffix := strings.Title(memberExpr.Name)
-
-					// Method names
-					getterName := fmt.Sprintf("%s.Get%s", ref.Name, methodSuffix)
-					setterName := fmt.Sprintf("%s.Set%s", ref.Name, methodSuffix)
-
-					// Call-Expression argument
-					argLHS := util.NewCallExpr(getterName)
-					argOp := binaryOperator
-					argRHS := util.NewIntLit(1)
-					argValue := util.NewBinaryExpr(argLHS, argOp, argRHS)
-
-					// Make Go expression
-					resExpr := util.NewCallExpr(setterName, argValue)
-
-					return resExpr, n.Type, preStmts, postStmts, nil
-				}
-			}
+	switch n.Operator {
+	case "__real", "__imag":
+		// The real or imaginary part of a complex number:
+		//
+		//     __real__ z    ->    real(z)
+		//     __imag__ z    ->    imag(z)
+		//
+		// FIXME: These can also be assigned to in C, which is not supported.
+		e, _, newPre, newPost, err := transpileToExpr(n.Children[0], p)
+		if err != nil {
+			return nil, "", nil, nil, err
 		}
 
-		binaryOperator := "+="
-		if operator == token.DEC {
-			binaryOperator = "-="
-		}
+		return util.NewCallExpr(n.Operator[2:], e), n.Type, newPre, newPost, nil
 
-		return transpileBinaryOperator(&ast.BinaryOperator{
-			Type:     n.Type,
-			Operator: binaryOperator,
-			Children: []ast.Node{
-				n.Children[0], &ast.IntegerLiteral{
-					Type:     "int",
-					Value:    "1",
-					Children: []ast.Node{},
-				},
-			},
-		}, p)
+	case "__extension__":
+		// This only stops the compiler from warning about GNU extensions.
+		return transpileToExpr(n.Children[0], p)
+	}
+
+	operator := getTokenForOperator(n.Operator)
+
+	if operator == token.INC || operator == token.DEC {
+		return transpileIncDecExpr(n, p)
 	}
 
 	// Otherwise handle like a unary operator.
@@ -88,6 +55,10 @@ func transpileUnaryOperator(n *ast.UnaryOperator, p *program.Program) (
 
 	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
+	if e, t, ok := transpileInt128UnaryOperator(n.Operator, e, eType, p); ok {
+		return e, t, preStmts, postStmts, nil
+	}
+
 	if operator == token.NOT {
 		if eType == "bool" || eType == "_Bool" {
 			return &goast.UnaryExpr{
@@ -113,6 +84,24 @@ func transpileUnaryOperator(n *ast.UnaryOperator, p *program.Program) (
 			eType, preStmts, postStmts, nil
 	}
 
+	// A function is a func value in Go, so the address of a function and a
+	// dereferenced function pointer are the same value:
+	//
+	//     fp = &f;     ->    fp = f
+	//     (*fp)(1);    ->    fp(1)
+	//
+	if operator == token.AND {
+		if ref, ok := removeCastsAndParens(n.Children[0]).(*ast.DeclRefExpr); ok && ref.For == "Function" {
+			return e, n.Type, preStmts, postStmts, nil
+		}
+	}
+
+	if operator == token.MUL {
+		if t, err := types.ResolveType(p, eType); err == nil && strings.HasPrefix(t, "func(") {
+			return e, eType, preStmts, postStmts, nil
+		}
+	}
+
 	// Dereferencing.
 	if operator == token.MUL {
 		if eType == "const char *" {
@@ -146,6 +135,48 @@ func transpileUnaryOperator(n *ast.UnaryOperator, p *program.Program) (
 	if operator == token.AND {
 		// We now have a pointer to the original type.
 		eType += " *"
+
+		// Pointers are slices, so the address of an element, like "&a[2]",
+		// is the rest of the slice from that element: "a[2:]". Reading and
+		// writing through the pointer will then change the original array.
+		if index, ok := e.(*goast.IndexExpr); ok {
+			t, err := types.ResolveType(p, eType)
+			p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+			if strings.HasPrefix(t, "[]") {
+				return &goast.SliceExpr{
+					X:   index.X,
+					Low: index.Index,
+				}, eType, preStmts, postStmts, nil
+			}
+		}
+
+		// The address of a variable or a field, like "&x", "&s.x", "&p->x" or
+		// "&a.b.c", is a Go pointer to it. A pointer to a struct is also a Go
+		// pointer but other pointers are slices, so a slice is created that
+		// uses the memory of the variable or the field:
+		//
+		//     (*[1]int)(unsafe.Pointer(&s.x))[:]
+		//
+		switch e.(type) {
+		case *goast.Ident, *goast.SelectorExpr:
+			t, err := types.ResolveType(p, eType)
+			p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+			if strings.HasPrefix(t, "[]") {
+				p.AddImport("unsafe")
+
+				return &goast.SliceExpr{
+					X: util.NewCallExpr(
+						fmt.Sprintf("(*[1]%s)", t[2:]),
+						util.NewCallExpr("unsafe.Pointer", &goast.UnaryExpr{
+							Op: token.AND,
+							X:  e,
+						}),
+					),
+				}, eType, preStmts, postStmts, nil
+			}
+		}
 	}
 
 	return &goast.UnaryExpr{
@@ -154,6 +185,148 @@ func transpileUnaryOperator(n *ast.UnaryOperator, p *program.Program) (
 	}, eType, preStmts, postStmts, nil
 }
 
+// transpileIncDecOperator transpiles "++" or "--" when it is a statement by
+// itself, like "i++;". The value of the expression is not used, so the prefix
+// and postfix operators are the same.
+func transpileIncDecOperator(n *ast.UnaryOperator, p *program.Program) (
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	preStmts := []goast.Stmt{}
+	postStmts := []goast.Stmt{}
+	operator := getTokenForOperator(n.Operator)
+
+	// Unfortunately we cannot use the Go increment operators because we are not
+	// providing any position information for tokens. This means that the ++/--
+	// would be placed before the expression and would be invalid in Go.
+	//
+	// Until it can be properly fixed (can we trick Go into to placing it after
+	// the expression with a magic position?) we will have to return a
+	// BinaryExpr with the same functionality.
+	// Construct code for assigning value to an union field
+	memberExpr, ok := n.Children[0].(*ast.MemberExpr)
+	if ok {
+		ref := memberExpr.GetDeclRefExpr()
+		if ref != nil {
+			binaryOperator := token.ADD
+			if operator == token.DEC {
+				binaryOperator = token.SUB
+			}
+
+			union := p.GetStruct(getMemberRecordType(memberExpr))
+			if union != nil && union.IsUnion {
+				// Method suffix for using getters and setters of Go union type
+				methodSuffix := strings.Title(memberExpr.Name)
+
+				// Method names
+				getterName := fmt.Sprintf("%s.Get%s", ref.Name, methodSuffix)
+				setterName := fmt.Sprintf("%s.Set%s", ref.Name, methodSuffix)
+
+				// Call-Expression argument
+				argLHS := util.NewCallExpr(getterName)
+				argOp := binaryOperator
+				argRHS := util.NewIntLit(1)
+				argValue := util.NewBinaryExpr(argLHS, argOp, argRHS)
+
+				// Make Go expression
+				resExpr := util.NewCallExpr(setterName, argValue)
+
+				return resExpr, n.Type, preStmts, postStmts, nil
+			}
+		}
+	}
+
+	binaryOperator := "+="
+	if operator == token.DEC {
+		binaryOperator = "-="
+	}
+
+	return transpileBinaryOperator(&ast.BinaryOperator{
+		Type:     n.Type,
+		Operator: binaryOperator,
+		Children: []ast.Node{
+			n.Children[0], &ast.IntegerLiteral{
+				Type:     "int",
+				Value:    "1",
+				Children: []ast.Node{},
+			},
+		},
+	}, p)
+}
+
+// transpileIncDecExpr transpiles "++" or "--" when its value is used, like
+// "x = a[i]++". The increment is a statement in Go, so a closure increments the
+// value and returns it. The postfix operator returns the value from before the
+// increment:
+//
+//     x = ++a[i];    ->    x = func() int {
+//                              a[i] += 1
+//                              return a[i]
+//                          }()
+//
+//     x = a[i]++;    ->    x = func() int {
+//                              temp1 := a[i]
+//                              a[i] += 1
+//                              return temp1
+//                          }()
+//
+// The operand is evaluated again to read the value, so an operand with side
+// effects, like "a[i++]++", is not supported.
+func transpileIncDecExpr(n *ast.UnaryOperator, p *program.Program) (
+	*goast.CallExpr, string, []goast.Stmt, []goast.Stmt, error) {
+	update, _, updatePre, updatePost, err := transpileIncDecOperator(n, p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	value, valueType, valuePre, valuePost, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	// The value is read like an LValueToRValue cast would.
+	value = transpileVolatileLoad(n, value, valueType, p)
+	value = transpileSignedCharLoad(value, valueType, p)
+
+	returnType, err := types.ResolveType(p, n.Type)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	stmts := []goast.Stmt{}
+	incDec := append(append(updatePre, util.NewExprStmt(update)), updatePost...)
+
+	if n.IsPrefix {
+		stmts = append(stmts, incDec...)
+		stmts = append(stmts, valuePre...)
+	} else {
+		tempVariableName := p.GetNextIdentifier("")
+		stmts = append(stmts, valuePre...)
+		stmts = append(stmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(tempVariableName)},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{value},
+		})
+		stmts = append(stmts, valuePost...)
+		stmts = append(stmts, incDec...)
+
+		value, valuePost = util.NewIdent(tempVariableName), nil
+	}
+
+	// The post statements have to happen after the value is read, but before
+	// it is returned.
+	if len(valuePost) > 0 {
+		tempVariableName := p.GetNextIdentifier("")
+		stmts = append(stmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{util.NewIdent(tempVariableName)},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{value},
+		})
+		stmts = append(stmts, valuePost...)
+		value = util.NewIdent(tempVariableName)
+	}
+
+	stmts = append(stmts, &goast.ReturnStmt{Results: []goast.Expr{value}})
+
+	return util.NewFuncClosure(returnType, stmts...), n.Type, nil, nil, nil
+}
+
 func transpileUnaryExprOrTypeTraitExpr(n *ast.UnaryExprOrTypeTraitExpr, p *program.Program) (
 	*goast.BasicLit, string, []goast.Stmt, []goast.Stmt, error) {
 	t := n.Type2
@@ -170,7 +343,7 @@ func transpileUnaryExprOrTypeTraitExpr(n *ast.UnaryExprOrTypeTraitExpr, p *progr
 		case *ast.DeclRefExpr:
 			t = c.Type
 		default:
-			panic(fmt.Sprintf("cannot find first child from: %#v", n.Children[0]))
+			t = getSizeOfOperandType(c, p)
 		}
 
 		if t == "" {
@@ -194,13 +367,103 @@ func transpileUnaryExprOrTypeTraitExpr(n *ast.UnaryExprOrTypeTraitExpr, p *progr
 				t = ty.Type
 
 			default:
-				panic(fmt.Sprintf("cannot do unary on: %#v", ty))
+				t = getSizeOfOperandType(realFirstChild.(ast.Node), p)
 			}
 		}
 	}
 
-	sizeInBytes, err := types.SizeOf(p, t)
+	var sizeInBytes int
+	var err error
+
+	// The Function is "alignof" for both _Alignof() and alignof(). GCC's
+	// __alignof__() is the preferred alignment, which is the same for all the
+	// types we support.
+	switch n.Function {
+	case "sizeof":
+		sizeInBytes, err = types.SizeOf(p, t)
+	case "alignof", "__alignof":
+		sizeInBytes, err = types.AlignOf(p, t)
+	default:
+		err = fmt.Errorf("cannot transpile %s()", n.Function)
+	}
 	p.AddMessage(ast.GenerateWarningMessage(err, n))
 
 	return util.NewIntLit(sizeInBytes), n.Type1, nil, nil, nil
 }
+
+// getSizeOfOperandType returns the C type of any other expression used with
+// sizeof or alignof. The operand of sizeof is never evaluated so the transpiled
+// expression is thrown away, only its type is needed.
+func getSizeOfOperandType(node ast.Node, p *program.Program) string {
+	_, t, _, _, err := transpileToExpr(node, p)
+	p.AddMessage(ast.GenerateWarningMessage(err, node))
+
+	return t
+}
+
+// transpileArrayLengthIdiom recognises the common C idiom for finding the
+// number of elements in a fixed size array:
+//
+//     sizeof(a) / sizeof(a[0])
+//
+// Arrays are slices in Go so this can be replaced with the much cleaner
+// "len(a)". If the binary operator is not this idiom then nil is returned.
+func transpileArrayLengthIdiom(n *ast.BinaryOperator, p *program.Program) goast.Expr {
+	if n.Operator != "/" {
+		return nil
+	}
+
+	left, ok := getSizeOfOperand(n.Children[0]).(*ast.DeclRefExpr)
+	if !ok || !sizeOfArrayRegexp.MatchString(left.Type) {
+		return nil
+	}
+
+	right, ok := getSizeOfOperand(n.Children[1]).(*ast.ArraySubscriptExpr)
+	if !ok {
+		return nil
+	}
+
+	array, ok := removeCastsAndParens(right.Children[0]).(*ast.DeclRefExpr)
+	if !ok || array.Name != left.Name {
+		return nil
+	}
+
+	// A static local array has a different name in Go.
+	name, _, err := transpileDeclRefExpr(left, p)
+	if err != nil {
+		return nil
+	}
+
+	e, err := types.CastExpr(p, util.NewCallExpr("len", name), "int", n.Type)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	return e
+}
+
+var sizeOfArrayRegexp = regexp.MustCompile(`\[\d+\]$`)
+
+// getSizeOfOperand returns the expression that a sizeof() is applied to, or nil
+// if the node is not a sizeof() of an expression.
+func getSizeOfOperand(node ast.Node) ast.Node {
+	sizeOf, ok := removeCastsAndParens(node).(*ast.UnaryExprOrTypeTraitExpr)
+	if !ok || sizeOf.Function != "sizeof" || len(sizeOf.Children) == 0 {
+		return nil
+	}
+
+	return removeCastsAndParens(sizeOf.Children[0])
+}
+
+// removeCastsAndParens strips any implicit casts and parenthesis that clang
+// wraps around an expression.
+func removeCastsAndParens(node ast.Node) ast.Node {
+	for {
+		switch n := node.(type) {
+		case *ast.ImplicitCastExpr:
+			node = n.Children[0]
+		case *ast.ParenExpr:
+			node = n.Children[0]
+		default:
+			return node
+		}
+	}
+}
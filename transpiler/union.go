@@ -1,25 +1,198 @@
 package transpiler
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	goast "go/ast"
 	"go/token"
 
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
 	"github.com/elliotchance/c2go/util"
 )
 
-func transpileUnion(name string, size int, fields []*goast.Field) []goast.Decl {
+// The unsigned integers that make up the memory of an union, by their size.
+// The memory must be aligned for the most strictly aligned member because the
+// members are read and written in place through an unsafe.Pointer.
+var unionElementTypes = map[int]string{
+	1: "byte",
+	2: "uint16",
+	4: "uint32",
+	8: "uint64",
+}
+
+// getUnionLayout returns the size and alignment (both in bytes) of the memory
+// for an union. The members of an union all start at the same address, so it is
+// as big as its biggest member.
+//
+// This is not always the same as the C sizeof because some Go types are bigger
+// than the C types they replace. For example, a "char *" is a slice of 24 bytes
+// and an "int" is 8 bytes.
+func getUnionLayout(p *program.Program, s *program.Struct) (size, align int, err error) {
+	size, err = types.SizeOf(p, "union "+s.Name)
+	if err != nil {
+		return
+	}
+
+	align, err = types.AlignOf(p, "union "+s.Name)
+	if err != nil {
+		return
+	}
+
+	for _, name := range s.FieldNames {
+		cType, ok := s.Fields[name].(string)
+		if !ok {
+			continue
+		}
+
+		// The C size is already counted for a type that is not known. The
+		// field itself will warn about the type that could not be resolved.
+		goType, _ := types.ResolveType(p, cType)
+		goSize, goAlign, err := goSizeOf(p, goType)
+		if err != nil {
+			continue
+		}
+
+		if size < goSize {
+			size = goSize
+		}
+		if align < goAlign {
+			align = goAlign
+		}
+	}
+
+	// There is no Go type that needs more than 8 bytes of alignment.
+	if align > 8 {
+		align = 8
+	}
+
+	if size%align != 0 {
+		size += align - size%align
+	}
+
+	return
+}
+
+// goSizeOf returns the size and alignment (both in bytes) of a Go type created
+// by the transpiler on a 64-bit platform.
+func goSizeOf(p *program.Program, goType string) (size, align int, err error) {
+	switch goType {
+	case "bool", "byte", "int8", "uint8":
+		return 1, 1, nil
+
+	case "int16", "uint16":
+		return 2, 2, nil
+
+	case "int32", "uint32", "float32", "rune":
+		return 4, 4, nil
+
+	case "int", "uint", "int64", "uint64", "float64", "uintptr":
+		return 8, 8, nil
+
+	case "string", "interface{}":
+		return 16, 8, nil
+	}
+
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		return 24, 8, nil
+
+	case strings.HasPrefix(goType, "*"),
+		strings.HasPrefix(goType, "func("),
+		strings.HasPrefix(goType, "map["):
+		return 8, 8, nil
+
+	case strings.HasPrefix(goType, "["):
+		end := strings.Index(goType, "]")
+		count, err := strconv.Atoi(goType[1:end])
+		if err != nil {
+			return 0, 0, err
+		}
+
+		size, align, err := goSizeOf(p, goType[end+1:])
+		return size * count, align, err
+	}
+
+	if s := p.GetStruct("union " + goType); s != nil {
+		return getUnionLayout(p, s)
+	}
+
+	if s := p.GetStruct("struct " + goType); s != nil {
+		return goStructSizeOf(p, s)
+	}
+
+	return 0, 0, fmt.Errorf("cannot determine the Go size of: %s", goType)
+}
+
+// goStructSizeOf returns the size and alignment (both in bytes) of a Go struct
+// created by the transpiler. Go adds padding between the fields in the same
+// way as C does.
+func goStructSizeOf(p *program.Program, s *program.Struct) (size, align int, err error) {
+	align = 1
+	storages := map[string]bool{}
+
+	add := func(fieldSize, fieldAlign int) {
+		if size%fieldAlign != 0 {
+			size += fieldAlign - size%fieldAlign
+		}
+		size += fieldSize
+
+		if align < fieldAlign {
+			align = fieldAlign
+		}
+	}
+
+	for _, name := range s.FieldNames {
+		cType, ok := s.Fields[name].(string)
+		if !ok {
+			continue
+		}
+
+		// A run of bitfields is stored in one integer where the first of them
+		// is declared.
+		if bitfield, ok := s.Bitfields[name]; ok {
+			if !storages[bitfield.Storage] {
+				storages[bitfield.Storage] = true
+				bytes := s.BitfieldStorage[bitfield.Storage]
+				add(bytes, bytes)
+			}
+			continue
+		}
+
+		goType, err := types.ResolveType(p, cType)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		fieldSize, fieldAlign, err := goSizeOf(p, goType)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		add(fieldSize, fieldAlign)
+	}
+
+	if size%align != 0 {
+		size += align - size%align
+	}
+
+	return
+}
+
+func transpileUnion(name string, size, align int, fields []*goast.Field) []goast.Decl {
 	res := []goast.Decl{
-		// Type declaration (array: [x]byte with x the size of union)
+		// Type declaration (array: [x]uintN with enough bytes for the biggest
+		// member and the alignment of the most strictly aligned one)
 		&goast.GenDecl{
 			Tok: token.TYPE,
 			Specs: []goast.Spec{
 				&goast.TypeSpec{
 					Name: util.NewIdent(name),
 					Type: &goast.ArrayType{
-						Elt: util.NewIdent("byte"),
-						Len: util.NewIntLit(size), // Size of the union
+						Elt: util.NewIdent(unionElementTypes[align]),
+						Len: util.NewIntLit(size / align),
 					},
 				},
 			},
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"go/token"
+	"regexp"
 	"strings"
 
 	"github.com/elliotchance/c2go/ast"
@@ -16,7 +17,35 @@ import (
 
 func transpileDeclRefExpr(n *ast.DeclRefExpr, p *program.Program) (
 	*goast.Ident, string, error) {
-	return util.NewIdent(n.Name), n.Type, nil
+	if isStringParameter(n, p) {
+		return util.NewIdent(n.Name), stringParameterType, nil
+	}
+
+	cType := n.Type
+	if types.IsTypeOf(cType) && n.Type2 != "" {
+		cType = n.Type2
+	}
+
+	if name := p.GetStaticVariable(n.Address2); name != "" {
+		return util.NewIdent(name), cType, nil
+	}
+
+	if n.For == "Function" {
+		return util.NewIdent(p.GetFunctionName(n.Name)), cType, nil
+	}
+
+	return util.NewIdent(n.Name), cType, nil
+}
+
+// resolveVarDeclType replaces a GNU typeof in the type of the variable, like
+// "typeof (a + b)", with the type that clang has resolved it to. The type is
+// registered so that a typeof of the variable can be resolved as well.
+func resolveVarDeclType(n *ast.VarDecl, p *program.Program) {
+	if types.IsTypeOf(n.Type) && n.Type2 != "" {
+		n.Type = n.Type2
+	}
+
+	p.AddVariableType(n.Name, n.Type)
 }
 
 func getDefaultValueForVar(p *program.Program, a *ast.VarDecl) (
@@ -34,36 +63,112 @@ func getDefaultValueForVar(p *program.Program, a *ast.VarDecl) (
 	if !types.IsNullExpr(defaultValue) {
 		t, err := types.CastExpr(p, defaultValue, defaultValueType, a.Type)
 		if !p.AddMessage(ast.GenerateWarningMessage(err, a)) {
-			values = []goast.Expr{t}
+			values = []goast.Expr{transpileStructCopy(a.Children[0], t, a.Type, p)}
 		}
 	}
 
 	return values, defaultValueType, newPre, newPost, nil
 }
 
+var constantArrayRegexp = regexp.MustCompile(`^(.*?) ?\[(\w+)\]$`)
+
+// getArrayTypeAndSizeExpr returns the element type and the size of an array.
+// The size of an array like "int [MAX]" is the constant MAX, if it is a global
+// variable that was declared as a Go constant (see isConstantVarDecl). The size
+// is nil if the type is not an array with a known size.
+func getArrayTypeAndSizeExpr(cType string, p *program.Program) (string, goast.Expr) {
+	arrayType, arraySize := types.GetArrayTypeAndSize(cType)
+	if arraySize != -1 {
+		return arrayType, util.NewIntLit(arraySize)
+	}
+
+	match := constantArrayRegexp.FindStringSubmatch(cType)
+	if len(match) > 0 && p.IsConstant(match[2]) {
+		return match[1], util.NewIdent(match[2])
+	}
+
+	return "", nil
+}
+
+// newArrayAllocation returns the expression that allocates the slice for an
+// array with the element type and size. Each row of a multidimensional array
+// (or the arrays of each struct, see newZeroValue) is allocated as well:
+//
+//     int a[3][4];
+//
+// becomes:
+//
+//     var a [][]int = func() [][]int {
+//         rows := make([][]int, 3, 3)
+//         for i := range rows {
+//             rows[i] = make([]int, 4, 4)
+//         }
+//         return rows
+//     }()
+func newArrayAllocation(n ast.Node, elementType string, size goast.Expr,
+	p *program.Program) goast.Expr {
+	goElementType, err := types.ResolveElementType(p, elementType)
+	p.AddMessage(ast.GenerateWarningMessage(err, n))
+
+	array := util.NewCallExpr(
+		"make",
+		&goast.ArrayType{
+			Elt: util.NewTypeIdent(goElementType),
+		},
+		size,
+		size,
+	)
+
+	row := newZeroValue(n, elementType, p)
+	if row == nil {
+		return array
+	}
+
+	rows := util.NewIdent("rows")
+	i := util.NewIdent("i")
+
+	return util.NewFuncClosure(
+		"[]"+goElementType,
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{rows},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{array},
+		},
+		&goast.RangeStmt{
+			Key: i,
+			Tok: token.DEFINE,
+			X:   rows,
+			Body: &goast.BlockStmt{
+				List: []goast.Stmt{
+					&goast.AssignStmt{
+						Lhs: []goast.Expr{&goast.IndexExpr{X: rows, Index: i}},
+						Tok: token.ASSIGN,
+						Rhs: []goast.Expr{row},
+					},
+				},
+			},
+		},
+		&goast.ReturnStmt{
+			Results: []goast.Expr{rows},
+		},
+	)
+}
+
 func newDeclStmt(a *ast.VarDecl, p *program.Program) (
 	*goast.DeclStmt, []goast.Stmt, []goast.Stmt, error) {
 	preStmts := []goast.Stmt{}
 	postStmts := []goast.Stmt{}
 
+	resolveVarDeclType(a, p)
+
 	defaultValue, _, newPre, newPost, err := getDefaultValueForVar(p, a)
 	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
-	// Allocate slice so that it operates like a fixed size array.
-	arrayType, arraySize := types.GetArrayTypeAndSize(a.Type)
-	if arraySize != -1 && defaultValue == nil {
-		goArrayType, err := types.ResolveType(p, arrayType)
-		p.AddMessage(ast.GenerateWarningMessage(err, a))
-
-		defaultValue = []goast.Expr{
-			util.NewCallExpr(
-				"make",
-				&goast.ArrayType{
-					Elt: util.NewTypeIdent(goArrayType),
-				},
-				util.NewIntLit(arraySize),
-				util.NewIntLit(arraySize),
-			),
+	// Allocate slice so that it operates like a fixed size array. The arrays
+	// of a struct are allocated as well.
+	if defaultValue == nil {
+		if zero := newZeroValue(a, a.Type, p); zero != nil {
+			defaultValue = []goast.Expr{zero}
 		}
 	}
 
@@ -103,6 +208,29 @@ func transpileDeclStmt(n *ast.DeclStmt, p *program.Program) (
 			// situation where this is needed yet?
 
 		case *ast.VarDecl:
+			cleanup, a := removeCleanupAttr(a)
+
+			// A local extern declaration refers to the global variable.
+			if a.IsExtern {
+				newPre, newPost, err := transpileVarDecl(p, a)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+
+				preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+				continue
+			}
+
+			if a.IsStatic {
+				err := transpileStaticLocalVarDecl(a, p)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+
+				continue
+			}
+
 			e, newPre, newPost, err := newDeclStmt(a, p)
 			if err != nil {
 				return nil, nil, nil, err
@@ -112,9 +240,28 @@ func transpileDeclStmt(n *ast.DeclStmt, p *program.Program) (
 
 			decls = append(decls, e)
 
+			if cleanup != nil {
+				deferStmt, err := transpileCleanupAttr(cleanup, a, p)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+
+				decls = append(decls, deferStmt)
+			}
+
 		case *ast.TypedefDecl:
 			p.AddMessage(ast.GenerateWarningMessage(errors.New("cannot use TypedefDecl for DeclStmt"), c))
 
+		case *ast.StaticAssertDecl:
+			e, newPre, newPost, err := transpileStaticAssertDecl(p, a)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
+
+			decls = append(decls, e)
+
 		default:
 			panic(a)
 		}
@@ -123,6 +270,60 @@ func transpileDeclStmt(n *ast.DeclStmt, p *program.Program) (
 	return decls, preStmts, postStmts, nil
 }
 
+// transpileStaticLocalVarDecl declares a static local variable at the package
+// level because it keeps its value between calls of the function. The name
+// starts with the name of the function so that the static variables of
+// different functions do not collide:
+//
+//     int next() {
+//         static int counter = 0;
+//         return ++counter;
+//     }
+//
+// becomes:
+//
+//     var next_counter int = 0
+//
+//     func next() int {
+//         next_counter += 1
+//         return next_counter
+//     }
+//
+// Like a global variable it is only initialized once, when the program starts.
+func transpileStaticLocalVarDecl(n *ast.VarDecl, p *program.Program) error {
+	name := n.Name
+	if p.Function != nil {
+		name = p.Function.Name + "_" + n.Name
+	}
+
+	// The same function may have more than one static variable with the same
+	// name in different blocks.
+	if _, found := p.GlobalVariables[name]; found {
+		name = p.GetNextIdentifier(name + "_")
+	}
+
+	resolveVarDeclType(n, p)
+
+	global := *n
+	global.Name = name
+
+	decl, preStmts, postStmts, err := newDeclStmt(&global, p)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range append(preStmts, postStmts...) {
+		p.AppendStartupStatement(stmt)
+	}
+
+	goType, _ := types.ResolveType(p, n.Type)
+	p.GlobalVariables[name] = goType
+	p.AddStaticVariable(n.Address, name)
+	p.File.Decls = append(p.File.Decls, decl.Decl)
+
+	return nil
+}
+
 func transpileArraySubscriptExpr(n *ast.ArraySubscriptExpr, p *program.Program) (
 	*goast.IndexExpr, string, []goast.Stmt, []goast.Stmt, error) {
 	preStmts := []goast.Stmt{}
@@ -157,6 +358,24 @@ func transpileArraySubscriptExpr(n *ast.ArraySubscriptExpr, p *program.Program)
 	}, newType, preStmts, postStmts, nil
 }
 
+// getMemberRecordType returns the C type of the struct or union that the member
+// belongs to. For a member of an anonymous struct or union this is the type of
+// the anonymous struct or union, not the variable that contains it.
+func getMemberRecordType(n *ast.MemberExpr) string {
+	switch c := n.Children[0].(type) {
+	case *ast.MemberExpr:
+		return c.Type
+	case *ast.DeclRefExpr:
+		return c.Type
+	case *ast.ImplicitCastExpr:
+		return c.Type
+	case *ast.ParenExpr:
+		return c.Type
+	}
+
+	return ""
+}
+
 func transpileMemberExpr(n *ast.MemberExpr, p *program.Program) (
 	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
 	preStmts := []goast.Stmt{}
@@ -169,6 +388,12 @@ func transpileMemberExpr(n *ast.MemberExpr, p *program.Program) (
 
 	preStmts, postStmts = combinePreAndPostStmts(preStmts, postStmts, newPre, newPost)
 
+	// An anonymous struct or union is embedded so its fields are accessed on
+	// the struct that contains it.
+	if n.IsAnonymous() {
+		return lhs, n.Type, preStmts, postStmts, nil
+	}
+
 	lhsResolvedType, err := types.ResolveType(p, lhsType)
 	p.AddMessage(ast.GenerateWarningMessage(err, n))
 
@@ -198,17 +423,36 @@ func transpileMemberExpr(n *ast.MemberExpr, p *program.Program) (
 		rhsType = "int"
 	}
 
+	// The fields of a struct that is replaced by a Go type are exported, like
+	// "tm_year" is "Tm_year".
+	if strings.TrimPrefix(lhsResolvedType, "*") == "noarch.Tm" {
+		rhs = util.GetExportedName(rhs)
+	}
+
 	// Construct code for getting value to an union field
 	if structType != nil && structType.IsUnion {
-		ident := lhs.(*goast.Ident)
-		funcName := fmt.Sprintf("%s.Get%s", ident.Name, strings.Title(n.Name))
-		resExpr := util.NewCallExpr(funcName)
+		resExpr := &goast.CallExpr{
+			Fun: &goast.SelectorExpr{
+				X:   lhs,
+				Sel: util.NewIdent("Get" + strings.Title(n.Name)),
+			},
+		}
 
 		return resExpr, rhsType, preStmts, postStmts, nil
 	}
 
+	// Bitfields can only be read through their getter.
+	if structType != nil && structType.Bitfields[n.Name] != nil {
+		return &goast.CallExpr{
+			Fun: &goast.SelectorExpr{
+				X:   lhs,
+				Sel: util.NewIdent(getBitfieldMethodName("Get", n.Name)),
+			},
+		}, rhsType, preStmts, postStmts, nil
+	}
+
 	return &goast.SelectorExpr{
 		X:   lhs,
-		Sel: util.NewIdent(rhs),
+		Sel: util.NewIdent(getFieldName(p, rhs)),
 	}, rhsType, preStmts, postStmts, nil
 }
@@ -0,0 +1,195 @@
+package transpiler
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+)
+
+func TestTypeOfVarDecl(t *testing.T) {
+	p := program.NewProgram()
+	p.Function = &ast.FunctionDecl{Name: "max"}
+	p.AddVariableType("a", "long")
+	p.AddVariableType("b", "int")
+
+	// The expansion of a macro like this:
+	//
+	//     #define max(a, b) ({ typeof(a) _a = (a); ... })
+	tests := []struct {
+		decl *ast.VarDecl
+		out  string
+	}{
+		{&ast.VarDecl{Name: "_a", Type: "typeof (a)", Children: []ast.Node{
+			newRValue("long", newVarRef("a", "long")),
+		}}, "var _a int32 = a"},
+		{&ast.VarDecl{Name: "_b", Type: "__typeof__(b)"}, "var _b int"},
+		{&ast.VarDecl{Name: "_c", Type: "typeof (_a)"}, "var _c int32"},
+		{&ast.VarDecl{Name: "_d", Type: "typeof (a + b)", Type2: "long"}, "var _d int32"},
+	}
+
+	for _, tt := range tests {
+		stmt, _, _, err := newDeclStmt(tt.decl, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, stmt); got != tt.out {
+			t.Errorf("expected %s, got %s", tt.out, got)
+		}
+	}
+}
+
+func TestShadowedVarDecl(t *testing.T) {
+	p := program.NewProgram()
+	p.Function = &ast.FunctionDecl{Name: "f"}
+
+	newDecl := func(name, cType string, value string) ast.Node {
+		return &ast.DeclStmt{Children: []ast.Node{&ast.VarDecl{
+			Name: name,
+			Type: cType,
+			Children: []ast.Node{
+				&ast.IntegerLiteral{Type: "int", Value: value},
+			},
+		}}}
+	}
+
+	// int x = 1;
+	// {
+	//     long x = 2;
+	//     typeof(x) y = 3;
+	// }
+	// typeof(x) z = 4;
+	block, _, _, err := transpileCompoundStmt(&ast.CompoundStmt{Children: []ast.Node{
+		newDecl("x", "int", "1"),
+		&ast.CompoundStmt{Children: []ast.Node{
+			newDecl("x", "long", "2"),
+			newDecl("y", "typeof (x)", "3"),
+		}},
+		newDecl("z", "typeof (x)", "4"),
+	}}, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+	var x int = 1
+	{
+		var x int32 = int32(2)
+		var y int32 = int32(3)
+	}
+	var z int = 4
+}`
+
+	if got := renderNode(t, block); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if _, ok := p.GetVariableType("x"); ok {
+		t.Errorf("expected x to be out of scope")
+	}
+}
+
+func TestAllocationVarDecl(t *testing.T) {
+	p := program.NewProgram()
+	p.Function = &ast.FunctionDecl{Name: "f"}
+	p.Structs["struct Foo"] = program.NewStruct(&ast.RecordDecl{
+		Kind: "struct",
+		Name: "Foo",
+		Children: []ast.Node{
+			&ast.FieldDecl{Name: "x", Type: "int"},
+			&ast.FieldDecl{Name: "y", Type: "double"},
+		},
+	})
+
+	newMalloc := func(size string) ast.Node {
+		return &ast.CallExpr{Type: "void *", Children: []ast.Node{
+			&ast.ImplicitCastExpr{Kind: "FunctionToPointerDecay", Type: "void *(*)(unsigned long)", Children: []ast.Node{
+				&ast.DeclRefExpr{For: "Function", Name: "malloc", Type: "void *(unsigned long)"},
+			}},
+			&ast.ImplicitCastExpr{Kind: "IntegralCast", Type: "unsigned long", Children: []ast.Node{
+				&ast.IntegerLiteral{Type: "int", Value: size},
+			}},
+		}}
+	}
+
+	tests := []struct {
+		decl *ast.VarDecl
+		out  string
+	}{
+		// struct Foo *f = malloc(16);
+		{&ast.VarDecl{Name: "f", Type: "struct Foo *", Children: []ast.Node{
+			&ast.ImplicitCastExpr{Kind: "BitCast", Type: "struct Foo *", Children: []ast.Node{newMalloc("16")}},
+		}}, "var f *Foo = &Foo{}"},
+
+		// int *a = (int *)malloc(40);
+		{&ast.VarDecl{Name: "a", Type: "int *", Children: []ast.Node{
+			&ast.CStyleCastExpr{Kind: "BitCast", Type: "int *", Children: []ast.Node{newMalloc("40")}},
+		}}, "var a []int = make([]int, 40/4)"},
+
+		// double *d = (double *)calloc(n, sizeof(double));
+		{&ast.VarDecl{Name: "d", Type: "double *", Children: []ast.Node{
+			&ast.CStyleCastExpr{Kind: "BitCast", Type: "double *", Children: []ast.Node{
+				&ast.CallExpr{Type: "void *", Children: []ast.Node{
+					&ast.ImplicitCastExpr{Kind: "FunctionToPointerDecay", Type: "void *(*)(unsigned long, unsigned long)", Children: []ast.Node{
+						&ast.DeclRefExpr{For: "Function", Name: "calloc", Type: "void *(unsigned long, unsigned long)"},
+					}},
+					newVarRef("n", "unsigned long"),
+					&ast.UnaryExprOrTypeTraitExpr{Type1: "unsigned long", Function: "sizeof", Type2: "double"},
+				}},
+			}},
+		}}, "var d []float64 = make([]float64, n)"},
+
+		// int *b = malloc(3 * sizeof(int));
+		{&ast.VarDecl{Name: "b", Type: "int *", Children: []ast.Node{
+			&ast.ImplicitCastExpr{Kind: "BitCast", Type: "int *", Children: []ast.Node{
+				&ast.CallExpr{Type: "void *", Children: []ast.Node{
+					&ast.ImplicitCastExpr{Kind: "FunctionToPointerDecay", Type: "void *(*)(unsigned long)", Children: []ast.Node{
+						&ast.DeclRefExpr{For: "Function", Name: "malloc", Type: "void *(unsigned long)"},
+					}},
+					&ast.BinaryOperator{Type: "unsigned long", Operator: "*", Children: []ast.Node{
+						&ast.IntegerLiteral{Type: "unsigned long", Value: "3"},
+						&ast.UnaryExprOrTypeTraitExpr{Type1: "unsigned long", Function: "sizeof", Type2: "int"},
+					}},
+				}},
+			}},
+		}}, "var b []int = make([]int, 3)"},
+
+		// char *c = malloc(3 * sizeof(int));
+		{&ast.VarDecl{Name: "c", Type: "char *", Children: []ast.Node{
+			&ast.ImplicitCastExpr{Kind: "BitCast", Type: "char *", Children: []ast.Node{
+				&ast.CallExpr{Type: "void *", Children: []ast.Node{
+					&ast.ImplicitCastExpr{Kind: "FunctionToPointerDecay", Type: "void *(*)(unsigned long)", Children: []ast.Node{
+						&ast.DeclRefExpr{For: "Function", Name: "malloc", Type: "void *(unsigned long)"},
+					}},
+					&ast.BinaryOperator{Type: "unsigned long", Operator: "*", Children: []ast.Node{
+						&ast.IntegerLiteral{Type: "unsigned long", Value: "3"},
+						&ast.UnaryExprOrTypeTraitExpr{Type1: "unsigned long", Function: "sizeof", Type2: "int"},
+					}},
+				}},
+			}},
+		}}, "var c []byte = make([]byte, 3*4/1)"},
+
+		// void *mem = malloc(16);
+		{&ast.VarDecl{Name: "mem", Type: "void *", Children: []ast.Node{newMalloc("16")}},
+			"var mem []byte = noarch.Malloc(int(16))"},
+
+		// struct Foo *g = mem;
+		{&ast.VarDecl{Name: "g", Type: "struct Foo *", Children: []ast.Node{
+			&ast.ImplicitCastExpr{Kind: "BitCast", Type: "struct Foo *", Children: []ast.Node{
+				newRValue("void *", newVarRef("mem", "void *")),
+			}},
+		}}, "var g *Foo = (*Foo)(noarch.UnsafePointer(&mem, unsafe.Sizeof(Foo{})))"},
+	}
+
+	for _, tt := range tests {
+		stmt, _, _, err := newDeclStmt(tt.decl, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, stmt); got != tt.out {
+			t.Errorf("expected %s, got %s", tt.out, got)
+		}
+	}
+}
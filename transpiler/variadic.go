@@ -0,0 +1,133 @@
+// This file contains functions for transpiling the definition of variadic
+// functions and the va_list macros that are used to read their arguments.
+
+package transpiler
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+)
+
+// variadicArgumentsName is the name of the Go parameter that holds the
+// variadic arguments, since they do not have a name in C.
+const variadicArgumentsName = "c2goArgs"
+
+// isVariadicFunction returns true if the function has "..." as its last
+// parameter, like:
+//
+//     int printf(const char *, ...)
+func isVariadicFunction(f *ast.FunctionDecl) bool {
+	return strings.HasSuffix(f.Type, "...)")
+}
+
+// isVaListBuiltin returns true if the function name is one of the builtins
+// that clang uses for the va_start(), va_end() and va_copy() macros.
+func isVaListBuiltin(functionName string) bool {
+	switch functionName {
+	case "__builtin_va_start", "__builtin_va_end", "__builtin_va_copy":
+		return true
+	}
+
+	return false
+}
+
+// transpileVaListBuiltin converts the va_list macros into the noarch functions
+// that manage a *noarch.VaList:
+//
+//     va_start(ap, format);   ->   noarch.VaStart(&ap, c2goArgs)
+//     va_copy(ap2, ap);       ->   noarch.VaCopy(&ap2, ap)
+//     va_end(ap);             ->   noarch.VaEnd(&ap)
+func transpileVaListBuiltin(n *ast.CallExpr, functionName string, p *program.Program) (
+	*goast.CallExpr, string, []goast.Stmt, []goast.Stmt, error) {
+	args := []goast.Expr{}
+	for _, c := range n.Children[1:] {
+		e, _, _, _, err := transpileToExpr(c, p)
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+
+		args = append(args, e)
+	}
+
+	if len(args) == 0 {
+		return nil, "", nil, nil, fmt.Errorf("%s requires a va_list", functionName)
+	}
+
+	ap := &goast.UnaryExpr{
+		Op: token.AND,
+		X:  args[0],
+	}
+
+	p.AddImport("github.com/elliotchance/c2go/noarch")
+
+	switch functionName {
+	case "__builtin_va_start":
+		if p.Function == nil || !isVariadicFunction(p.Function) {
+			return nil, "", nil, nil,
+				fmt.Errorf("va_start can only be used in a variadic function")
+		}
+
+		return util.NewCallExpr("noarch.VaStart", ap, util.NewIdent(variadicArgumentsName)),
+			"void", nil, nil, nil
+
+	case "__builtin_va_copy":
+		if len(args) != 2 {
+			return nil, "", nil, nil, fmt.Errorf("va_copy requires two arguments")
+		}
+
+		return util.NewCallExpr("noarch.VaCopy", ap, args[1]), "void", nil, nil, nil
+	}
+
+	return util.NewCallExpr("noarch.VaEnd", ap), "void", nil, nil, nil
+}
+
+// transpileVAArgExpr converts va_arg() to read the next argument from the
+// va_list. The arguments are stored as interface{} so they have to be converted
+// to the type that is being read:
+//
+//     va_arg(ap, int)      ->   int(ap.Int64())
+//     va_arg(ap, double)   ->   float64(ap.Float64())
+//     va_arg(ap, char *)   ->   ap.Arg().([]byte)
+func transpileVAArgExpr(n *ast.VAArgExpr, p *program.Program) (
+	goast.Expr, string, []goast.Stmt, []goast.Stmt, error) {
+	ap, _, preStmts, postStmts, err := transpileToExpr(n.Children[0], p)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	goType, err := types.ResolveType(p, n.Type)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	method := func(name string) goast.Expr {
+		return &goast.CallExpr{
+			Fun: &goast.SelectorExpr{
+				X:   ap,
+				Sel: util.NewIdent(name),
+			},
+		}
+	}
+
+	switch goType {
+	case "int", "int8", "int16", "int32", "int64",
+		"byte", "uint8", "uint16", "uint32", "uint64":
+		return util.NewCallExpr(goType, method("Int64")), n.Type, preStmts, postStmts, nil
+
+	case "float32", "float64":
+		return util.NewCallExpr(goType, method("Float64")), n.Type, preStmts, postStmts, nil
+	}
+
+	return &goast.TypeAssertExpr{
+		X:    method("Arg"),
+		Type: util.NewTypeIdent(goType),
+	}, n.Type, preStmts, postStmts, nil
+}
@@ -0,0 +1,129 @@
+// This file contains the reads and writes of volatile variables for the
+// -volatile-atomic option. The Go compiler may remove or reorder a read or
+// write that does not change the result of the program, which is incorrect for
+// a memory-mapped hardware register:
+//
+//     volatile uint32_t *status = ...;
+//     while ((*status & READY) == 0) {}
+//     *status = 0;
+//
+// The accesses of a volatile integer go through "sync/atomic" instead:
+//
+//     for atomic.LoadUint32(&status[0])&READY == 0 {
+//     }
+//     atomic.StoreUint32(&status[0], 0)
+//
+// A compound assignment, like "*status |= READY", is an atomic load followed by
+// an atomic store. It is not one atomic operation, which is the same as C.
+
+package transpiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+	"github.com/elliotchance/c2go/util"
+
+	goast "go/ast"
+	"go/token"
+)
+
+// getVolatileFunctions returns the functions that atomically load and store a
+// value of the C type. Empty strings are returned if the type is not an integer
+// that is supported by "sync/atomic". A Go int does not have a fixed size so it
+// uses the functions in noarch.
+func getVolatileFunctions(cType string, p *program.Program) (string, string) {
+	goType, err := types.ResolveType(p, cType)
+	if err != nil {
+		return "", ""
+	}
+
+	switch goType {
+	case "int32", "int64", "uint32", "uint64", "uintptr":
+		p.AddImport("sync/atomic")
+		suffix := strings.Title(goType)
+
+		return "atomic.Load" + suffix, "atomic.Store" + suffix
+
+	case "int":
+		p.AddImport("github.com/elliotchance/c2go/noarch")
+
+		return "noarch.LoadInt", "noarch.StoreInt"
+	}
+
+	return "", ""
+}
+
+// isAddressable returns true if the address of the Go expression can be taken.
+// Some lvalues in C, like a bitfield or a union field, are translated into
+// method calls.
+func isAddressable(e goast.Expr) bool {
+	switch v := e.(type) {
+	case *goast.Ident, *goast.SelectorExpr, *goast.IndexExpr, *goast.StarExpr:
+		return true
+	case *goast.ParenExpr:
+		return isAddressable(v.X)
+	}
+
+	return false
+}
+
+// getVolatileAccess returns the load and store functions for an access of a
+// volatile lvalue. Empty strings are returned if the access does not need to be
+// atomic. A warning is added if the value is volatile but cannot be accessed
+// atomically.
+func getVolatileAccess(n ast.Node, e goast.Expr, cType string, p *program.Program) (
+	string, string) {
+	if !p.VolatileAtomic || !types.IsVolatile(cType) {
+		return "", ""
+	}
+
+	load, store := getVolatileFunctions(cType, p)
+	if load == "" || !isAddressable(e) {
+		p.AddMessage(ast.GenerateWarningMessage(
+			fmt.Errorf("cannot access '%s' atomically", cType), n))
+
+		return "", ""
+	}
+
+	return load, store
+}
+
+// transpileVolatileLoad returns the expression that reads the lvalue e with the
+// C type. It is the same expression if the lvalue is not volatile.
+func transpileVolatileLoad(n ast.Node, e goast.Expr, cType string, p *program.Program) goast.Expr {
+	load, _ := getVolatileAccess(n, e, cType, p)
+	if load == "" {
+		return e
+	}
+
+	return util.NewCallExpr(load, util.NewUnaryExpr(token.AND, e))
+}
+
+// transpileVolatileStore returns the expression that assigns to the lvalue left
+// with the C operator, like "=" or "+=". The second return value is false if
+// the lvalue is not volatile.
+func transpileVolatileStore(n ast.Node, operator string, left goast.Expr,
+	leftType string, right goast.Expr, p *program.Program) (goast.Expr, bool) {
+	load, store := getVolatileAccess(n, left, leftType, p)
+	if store == "" {
+		return nil, false
+	}
+
+	if operator != "=" {
+		if _, ok := right.(*goast.BinaryExpr); ok {
+			right = &goast.ParenExpr{X: right}
+		}
+
+		right = util.NewBinaryExpr(
+			util.NewCallExpr(load, util.NewUnaryExpr(token.AND, left)),
+			getTokenForOperator(operator[:len(operator)-1]),
+			right,
+		)
+	}
+
+	return util.NewCallExpr(store, util.NewUnaryExpr(token.AND, left), right), true
+}
@@ -0,0 +1,83 @@
+package transpiler
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+
+	goast "go/ast"
+)
+
+func TestVolatileAtomic(t *testing.T) {
+	one := &ast.IntegerLiteral{Type: "int", Value: "1"}
+
+	tests := []struct {
+		cType string
+		node  ast.Node
+		out   string
+	}{
+		{"volatile unsigned int", newRValue("unsigned int", newVarRef("reg", "volatile unsigned int")), "atomic.LoadUint32(&reg)"},
+		{"volatile int", newRValue("int", newVarRef("reg", "volatile int")), "noarch.LoadInt(&reg)"},
+		{"int", newRValue("int", newVarRef("reg", "int")), "reg"},
+		{"volatile long", &ast.BinaryOperator{
+			Type:     "volatile long",
+			Operator: "=",
+			Children: []ast.Node{newVarRef("reg", "volatile long"), one},
+		}, "atomic.StoreInt32(&reg, int32(1))"},
+		{"volatile unsigned int", &ast.CompoundAssignOperator{
+			Type:     "volatile unsigned int",
+			Opcode:   "|=",
+			Children: []ast.Node{newVarRef("reg", "volatile unsigned int"), one},
+		}, "atomic.StoreUint32(&reg, atomic.LoadUint32(&reg)|uint32(1))"},
+		{"volatile long long", &ast.UnaryOperator{
+			Type:     "volatile long long",
+			Operator: "++",
+			Children: []ast.Node{newVarRef("reg", "volatile long long")},
+		}, "atomic.StoreInt64(&reg, atomic.LoadInt64(&reg)+1)"},
+		{"volatile long long", &ast.BinaryOperator{
+			Type:     "long long",
+			Operator: "=",
+			Children: []ast.Node{newVarRef("reg", "long long"), &ast.UnaryOperator{
+				Type:     "volatile long long",
+				Operator: "++",
+				Children: []ast.Node{newVarRef("reg", "volatile long long")},
+			}},
+		}, "reg = func() int64 {\n\ttemp0 := atomic.LoadInt64(&reg)\n\t" +
+			"atomic.StoreInt64(&reg, atomic.LoadInt64(&reg)+1)\n\treturn temp0\n}()"},
+	}
+
+	for _, tt := range tests {
+		p := program.NewProgram()
+		p.VolatileAtomic = true
+
+		// "reg++" is a statement, so its value is not used.
+		var node goast.Node
+		var err error
+		if u, ok := tt.node.(*ast.UnaryOperator); ok {
+			node, _, _, err = transpileToStmt(u, p)
+		} else {
+			node, _, _, _, err = transpileToExpr(tt.node, p)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := renderNode(t, node); got != tt.out {
+			t.Errorf("%s: expected %s, got %s", tt.cType, tt.out, got)
+		}
+	}
+}
+
+func TestVolatileAtomicDisabled(t *testing.T) {
+	p := program.NewProgram()
+
+	expr, _, _, _, err := transpileToExpr(newRValue("int", newVarRef("reg", "volatile int")), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := renderNode(t, expr); got != "reg" {
+		t.Errorf("expected reg, got %s", got)
+	}
+}
@@ -15,16 +15,32 @@ import (
 	"github.com/elliotchance/c2go/util"
 )
 
+// The C type of the real and imaginary parts of each of the complex types.
+var complexTypes = map[string]string{
+	"complex64":  "float",
+	"complex128": "double",
+}
+
+var arrayTypeAndSizeRegexp = regexp.MustCompile(`^([^\[\]()]*?) ?\[(\d+)\]((?:\[\w*\])*)$`)
+
 // GetArrayTypeAndSize returns the size and type of a fixed array. If the type
 // is not an array with a fixed size then the type return will be an empty
 // string, and the size will be -1.
+//
+// The type of the elements of a multidimensional array is also an array. For
+// example, "int [3][4]" is an array of 3 elements of "int [4]". A pointer to
+// an array, like "int (*)[4]", is not an array.
 func GetArrayTypeAndSize(s string) (string, int) {
-	match := regexp.MustCompile(`(.*) \[(\d+)\]`).FindStringSubmatch(s)
-	if len(match) > 0 {
-		return match[1], util.Atoi(match[2])
+	match := arrayTypeAndSizeRegexp.FindStringSubmatch(s)
+	if len(match) == 0 {
+		return "", -1
+	}
+
+	if match[3] != "" {
+		return match[1] + " " + match[3], util.Atoi(match[2])
 	}
 
-	return "", -1
+	return match[1], util.Atoi(match[2])
 }
 
 // CastExpr returns an expression that casts one type to another. For
@@ -34,8 +50,9 @@ func GetArrayTypeAndSize(s string) (string, int) {
 // There are lots of rules about how an expression is cast, but here are some
 // main points:
 //
-// 1. If fromType == toType (casting to the same type) OR toType == "void *",
-//    the original expression is returned unmodified.
+// 1. If fromType == toType (casting to the same type) OR toType is "void *",
+//    the original expression is returned unmodified. Casts between integers
+//    and pointers (including "void *") are described in castIntegerAndPointer.
 //
 // 2. There is a special type called "null" which is not defined in C, but
 //    rather an estimate of the NULL macro which evaluates to: (0). We cannot
@@ -53,7 +70,9 @@ func GetArrayTypeAndSize(s string) (string, int) {
 //    "float32(3)".
 //
 //    There are also some platform specific types and types that are shared in
-//    Go packages that are common aliases kept in this list.
+//    Go packages that are common aliases kept in this list. Aliases like
+//    size_t do not need to be in the list because they are resolved to one of
+//    the primitive types.
 //
 // 4. If all else fails the fallback is to cast using a function. For example,
 //    Foo -> Bar, would return an expression similar to "noarch.FooToBar(expr)".
@@ -62,11 +81,16 @@ func GetArrayTypeAndSize(s string) (string, int) {
 //    FILE where those function probably exist (or should exist) in the noarch
 //    package.
 func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.Expr, error) {
-	// Let's assume that anything can be converted to a void pointer.
-	if toType == "void *" {
-		return expr, nil
+	// Let's assume that anything can be converted to a void pointer. An
+	// integer is the exception, see castIntegerAndPointer.
+	if isVoidPointer(toType) {
+		if t, err := ResolveType(p, fromType); err != nil || !util.InStrings(t, integerTypes) {
+			return expr, nil
+		}
 	}
 
+	originalFromType, originalToType := fromType, toType
+
 	fromType, err := ResolveType(p, fromType)
 	if err != nil {
 		return expr, err
@@ -77,6 +101,10 @@ func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.E
 		return expr, err
 	}
 
+	if function, ok := p.GetCastFunction(fromType, toType); ok {
+		return util.NewCallExpr(p.ImportType(function), expr), nil
+	}
+
 	if fromType == "null" && toType == "[][]byte" {
 		return util.NewNil(), nil
 	}
@@ -103,10 +131,30 @@ func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.E
 		return expr, nil
 	}
 
+	if e, ok := castArrayToPointer(p, expr, originalFromType, originalToType); ok {
+		return e, nil
+	}
+
 	if fromType == toType {
 		return expr, nil
 	}
 
+	if e, ok, err := castIntegerAndPointer(p, expr, fromType, toType); ok {
+		return e, err
+	}
+
+	if e, ok, err := castVoidPointer(p, expr, originalFromType, originalToType, toType); ok {
+		return e, err
+	}
+
+	if e, ok := castPointerElements(p, expr, fromType, toType); ok {
+		return e, nil
+	}
+
+	if e, ok := castInt128(p, expr, fromType, toType); ok {
+		return e, nil
+	}
+
 	// Compatible integer types
 	types := []string{
 		// Integer types
@@ -117,12 +165,15 @@ func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.E
 		// Floating-point types.
 		"float32", "float64",
 
-		// Known aliases
-		"__uint16_t", "size_t",
-
 		// Darwin specific
 		"__darwin_ct_rune_t", "darwin.CtRuneT",
 	}
+
+	// Enums are integers in C so any enum type that has been defined is also
+	// compatible.
+	for enumType := range p.Enums {
+		types = append(types, enumType)
+	}
 	for _, v := range types {
 		if fromType == v && toType == "bool" {
 			return &goast.BinaryExpr{
@@ -133,6 +184,36 @@ func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.E
 		}
 	}
 
+	if fromType == "null" && toType == "string" {
+		return util.NewStringLit(`""`), nil
+	}
+
+	// A Go string that is not a literal is copied into a new C string, which
+	// needs the NULL character at the end:
+	//
+	//     []byte(expr + "\x00")
+	//
+	if _, ok := expr.(*goast.BasicLit); !ok && fromType == "string" && toType == "[]byte" {
+		return util.NewCallExpr("[]byte",
+			util.NewBinaryExpr(expr, token.ADD, util.NewStringLit(`"\x00"`))), nil
+	}
+
+	// A C string becomes a Go string with all the characters before the NULL
+	// character. A string literal, which is translated as []byte("abc\x00"),
+	// can be used as a Go string literal directly.
+	if fromType == "[]byte" && toType == "string" {
+		if s, ok := getByteSliceLiteral(expr); ok {
+			if i := strings.IndexByte(s, 0); i != -1 {
+				s = s[:i]
+			}
+
+			return util.NewStringLit(strconv.Quote(s)), nil
+		}
+
+		p.AddImport("github.com/elliotchance/c2go/noarch")
+		return util.NewCallExpr("noarch.NullTerminatedByteSlice", expr), nil
+	}
+
 	// In the forms of:
 	// - `string` -> `[]byte`
 	// - `string` -> `char *[13]`
@@ -193,6 +274,68 @@ func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.E
 		), nil
 	}
 
+	// Go does not allow a real number to be converted to a complex number, or
+	// the other way around. The real part is used when a complex number is cast
+	// to a real number.
+	//
+	//     complex(float64(expr), 0)
+	//     real(expr)
+	//
+	if complexTypes[toType] != "" && complexTypes[fromType] == "" && util.InStrings(fromType, types) {
+		part, err := CastExpr(p, expr, originalFromType, complexTypes[toType])
+		if err != nil {
+			return nil, err
+		}
+
+		return util.NewCallExpr("complex", part, util.NewIntLit(0)), nil
+	}
+
+	if complexTypes[fromType] != "" {
+		if complexTypes[toType] != "" {
+			return util.NewCallExpr(toType, expr), nil
+		}
+
+		if toType == "bool" {
+			return util.NewBinaryExpr(expr, token.NEQ, util.NewIntLit(0)), nil
+		}
+
+		return CastExpr(p, util.NewCallExpr("real", expr), complexTypes[fromType], originalToType)
+	}
+
+	// A character becomes a string of exactly one byte. The value is truncated
+	// to its lowest byte, the same as storing it in a char. Converting through
+	// a rune is avoided because any value above 127 would be encoded as a
+	// multi-byte UTF-8 character.
+	//
+	//     string([]byte{byte(expr)})
+	//
+	if toType == "string" && util.InStrings(fromType, []string{"byte", "int8", "uint8"}) {
+		if fromType != "byte" && fromType != "uint8" {
+			expr = util.NewCallExpr("byte", expr)
+		}
+
+		return util.NewCallExpr("string", &goast.CompositeLit{
+			Type: &goast.ArrayType{
+				Elt: util.NewTypeIdent("byte"),
+			},
+			Elts: []goast.Expr{expr},
+		}), nil
+	}
+
+	// A one character string literal is the same as a character literal.
+	if fromType == "string" && util.InStrings(toType, []string{"byte", "int8", "uint8"}) {
+		if lit, ok := expr.(*goast.BasicLit); ok && lit.Kind == token.STRING {
+			strValue, err := strconv.Unquote(lit.Value)
+			if err == nil && len(strValue) == 1 {
+				if toType == "int8" {
+					return util.NewIntLit(int(int8(strValue[0]))), nil
+				}
+
+				return util.NewIntLit(int(strValue[0])), nil
+			}
+		}
+	}
+
 	// Anything that is a pointer can be compared to nil
 	if fromType[0] == '*' && toType == "bool" {
 		return &goast.BinaryExpr{
@@ -208,13 +351,6 @@ func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.E
 		), nil
 	}
 
-	if fromType == "int" && toType == "*int" {
-		return util.NewNil(), nil
-	}
-	if fromType == "int" && toType == "*byte" {
-		return util.NewStringLit(`""`), nil
-	}
-
 	if fromType == "_Bool" && toType == "bool" {
 		return expr, nil
 	}
@@ -225,6 +361,13 @@ func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.E
 
 	p.AddImport("github.com/elliotchance/c2go/noarch")
 
+	// There is only noarch.BoolToInt(), any other number type is converted
+	// from the int.
+	if fromType == "bool" && toType != "int" && util.InStrings(toType, types) {
+		return util.NewCallExpr(toType,
+			util.NewCallExpr("noarch.BoolToInt", expr)), nil
+	}
+
 	leftName := fromType
 	rightName := toType
 
@@ -243,6 +386,326 @@ func CastExpr(p *program.Program, expr ast.Expr, fromType, toType string) (ast.E
 	return util.NewCallExpr(functionName, expr), nil
 }
 
+// castArrayToPointer reslices a fixed array when it decays into a pointer to
+// its first element:
+//
+//     int a[10];    ->    var a []int = make([]int, 10)
+//     int *p = a;   ->    var p []int = a[:]
+//
+// The second return value is false if fromType is not a fixed array or toType
+// is not a pointer to the same element type.
+func castArrayToPointer(p *program.Program, expr goast.Expr, fromType, toType string) (
+	goast.Expr, bool) {
+	// The pointer may be a pointer to an array, like "int (*)[4]".
+	isPointer := strings.HasSuffix(toType, "*") || strings.Contains(toType, "(*)[")
+
+	elementType, size := GetArrayTypeAndSize(fromType)
+	if size == -1 || !isPointer {
+		return nil, false
+	}
+
+	t, err := ResolveType(p, elementType)
+	if err != nil {
+		return nil, false
+	}
+
+	if goType, err := ResolveType(p, toType); err != nil || goType != "[]"+t {
+		return nil, false
+	}
+
+	if _, ok := expr.(*goast.SliceExpr); ok {
+		return expr, true
+	}
+
+	return &goast.SliceExpr{X: expr}, true
+}
+
+// The Go types of the integers that can be cast to and from a pointer.
+var integerTypes = []string{
+	"byte",
+	"int", "int8", "int16", "int32", "int64",
+	"uint8", "uint16", "uint32", "uint64",
+}
+
+// isPointerType returns true if the Go type is a pointer. Most C pointers are
+// slices in Go, except for pointers to structs.
+func isPointerType(goType string) bool {
+	return strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]")
+}
+
+// isVoidPointer returns true if the C type is a void pointer. The prototypes of
+// the built-in functions are written like "const void*".
+func isVoidPointer(cType string) bool {
+	return strings.Replace(removeQualifiers(cType), " ", "", -1) == "void*"
+}
+
+// isZeroExpr returns true if the expression is 0, NULL or nil.
+func isZeroExpr(expr goast.Expr) bool {
+	switch e := expr.(type) {
+	case *goast.BasicLit:
+		return e.Kind == token.INT && e.Value == "0"
+	case *goast.Ident:
+		return e.Name == "nil"
+	}
+
+	return IsNullExpr(expr)
+}
+
+// castIntegerAndPointer casts between the Go types of an integer and a pointer:
+//
+// 1. A zero integer is a NULL pointer and a NULL pointer is a zero integer:
+//
+//        (int *)0           ->    nil
+//        (intptr_t)NULL     ->    0
+//
+// 2. The address of a Go pointer (a pointer to a struct) is an integer:
+//
+//        (long)ptr          ->    int32(uintptr(unsafe.Pointer(ptr)))
+//
+// 3. Anything else, like "(void *)0x1000", has no valid translation in Go. It
+//    becomes an expression of the correct type that panics, and an error is
+//    returned so that it can be shown as a warning:
+//
+//        func() []byte {
+//            panic("cannot cast an integer to a pointer")
+//        }()
+//
+// The second return value is false if the types are not an integer and a
+// pointer.
+func castIntegerAndPointer(p *program.Program, expr goast.Expr, fromType, toType string) (
+	goast.Expr, bool, error) {
+	var message string
+
+	switch {
+	case util.InStrings(fromType, integerTypes) && isPointerType(toType):
+		if isZeroExpr(expr) {
+			return util.NewNil(), true, nil
+		}
+
+		message = "cannot cast an integer to a pointer"
+
+	case (isPointerType(fromType) || fromType == "null") && util.InStrings(toType, integerTypes):
+		if fromType == "null" || isZeroExpr(expr) {
+			return util.NewIntLit(0), true, nil
+		}
+
+		if fromType[0] == '*' {
+			p.AddImport("unsafe")
+			return util.NewCallExpr(toType, util.NewCallExpr("uintptr",
+				util.NewCallExpr("unsafe.Pointer", expr))), true, nil
+		}
+
+		message = "cannot cast a slice to an integer"
+
+	default:
+		return nil, false, nil
+	}
+
+	panicExpr := util.NewFuncClosure(toType, util.NewExprStmt(
+		util.NewCallExpr("panic", util.NewStringLit(strconv.Quote(message)))))
+
+	return panicExpr, true, fmt.Errorf("%s: '%s' to '%s'", message, fromType, toType)
+}
+
+// castVoidPointer casts a void pointer to a pointer to a struct. A void pointer
+// is the bytes of the memory, so the struct is put in the same memory:
+//
+//     (struct Foo *)mem    ->    (*Foo)(noarch.UnsafePointer(&mem, unsafe.Sizeof(Foo{})))
+//
+// The garbage collector does not look for pointers in the bytes, so this is
+// only allowed for a struct that does not contain any pointers (or slices).
+// Otherwise an error is returned. The memory that is returned by malloc() is
+// allocated as the struct instead (see transpileAllocationCast). The second
+// return value is false if the types are not a void pointer and a pointer to a
+// struct.
+func castVoidPointer(p *program.Program, expr goast.Expr, originalFromType, originalToType, toType string) (
+	goast.Expr, bool, error) {
+	if !isVoidPointer(originalFromType) || !strings.HasPrefix(toType, "*") {
+		return nil, false, nil
+	}
+
+	if isZeroExpr(expr) {
+		return util.NewNil(), true, nil
+	}
+
+	record := strings.TrimSpace(strings.TrimSuffix(removeQualifiers(originalToType), "*"))
+	if hasPointers(p, record) {
+		message := "cannot cast a void pointer to a struct that contains pointers"
+		panicExpr := util.NewFuncClosure(toType, util.NewExprStmt(
+			util.NewCallExpr("panic", util.NewStringLit(strconv.Quote(message)))))
+
+		return panicExpr, true, fmt.Errorf("%s: '%s'", message, originalToType)
+	}
+
+	// The memory may have to be replaced with a larger copy, so the address of
+	// the slice is needed. An expression that cannot be addressed, like the
+	// result of a function call, is put in a slice of its own first.
+	switch expr.(type) {
+	case *goast.Ident, *goast.SelectorExpr, *goast.IndexExpr, *goast.StarExpr:
+		expr = &goast.UnaryExpr{Op: token.AND, X: expr}
+	default:
+		expr = &goast.UnaryExpr{Op: token.AND, X: &goast.IndexExpr{
+			X: &goast.CompositeLit{
+				Type: util.NewTypeIdent("[][]byte"),
+				Elts: []goast.Expr{expr},
+			},
+			Index: util.NewIntLit(0),
+		}}
+	}
+
+	p.AddImports("github.com/elliotchance/c2go/noarch", "unsafe")
+	size := util.NewCallExpr("unsafe.Sizeof", &goast.CompositeLit{Type: util.NewTypeIdent(toType[1:])})
+
+	return util.NewCallExpr("("+toType+")", util.NewCallExpr("noarch.UnsafePointer", expr, size)), true, nil
+}
+
+// hasPointers returns true if the C type is (or contains) a pointer or a slice
+// once it is translated to Go. A fixed size array is a slice in Go. A type that
+// cannot be checked, like a typedef of a struct, is assumed to contain them.
+func hasPointers(p *program.Program, cType string) bool {
+	cType = removeQualifiers(cType)
+
+	s, ok := p.Structs[cType]
+	if !ok {
+		s, ok = p.Unions[cType]
+	}
+
+	if ok {
+		return structHasPointers(p, s)
+	}
+
+	goType, err := ResolveType(p, cType)
+	if err != nil {
+		return true
+	}
+
+	switch goType {
+	case "int", "uint", "bool", "complex64", "complex128":
+		return false
+	}
+
+	return !util.InStrings(goType, fixedSizeTypes) && !p.Enums[goType]
+}
+
+// structHasPointers returns true if any of the fields of the struct, including
+// the fields of nested structs, contain pointers (see hasPointers).
+func structHasPointers(p *program.Program, s *program.Struct) bool {
+	for _, f := range s.Fields {
+		switch t := f.(type) {
+		case string:
+			if hasPointers(p, t) {
+				return true
+			}
+		case *program.Struct:
+			if structHasPointers(p, t) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// The Go types of the numbers that have the same size as in C. An int is not
+// one of them, it has 8 bytes in Go.
+var fixedSizeTypes = []string{
+	"byte",
+	"int8", "int16", "int32", "int64",
+	"uint8", "uint16", "uint32", "uint64",
+	"float32", "float64",
+}
+
+// castPointerElements casts a pointer to a pointer of numbers of another type.
+// The elements are not converted, the memory is read as the new type instead:
+//
+//     (uint32_t *)bytes    ->    noarch.UnsafeSlice(bytes, []uint32(nil)).([]uint32)
+//
+// See noarch.UnsafeSlice for the byte order of the elements. The second return
+// value is false if the types are not both pointers to fixed size numbers.
+func castPointerElements(p *program.Program, expr goast.Expr, fromType, toType string) (
+	goast.Expr, bool) {
+	if !strings.HasPrefix(fromType, "[]") || !strings.HasPrefix(toType, "[]") ||
+		!util.InStrings(fromType[2:], fixedSizeTypes) ||
+		!util.InStrings(toType[2:], fixedSizeTypes) {
+		return nil, false
+	}
+
+	if isZeroExpr(expr) {
+		return util.NewNil(), true
+	}
+
+	p.AddImport("github.com/elliotchance/c2go/noarch")
+
+	return &goast.TypeAssertExpr{
+		X: util.NewCallExpr("noarch.UnsafeSlice", expr,
+			util.NewCallExpr(toType, util.NewNil())),
+		Type: util.NewTypeIdent(toType),
+	}, true
+}
+
+// castInt128 casts between a 128-bit integer (see noarch.Int128) and another
+// integer with the noarch functions. A smaller integer is extended to 64 bits
+// first, and only the low 64 bits of a 128-bit integer are kept:
+//
+//     (__int128)i              ->    noarch.Int64ToInt128(int64(i))
+//     (unsigned __int128)5     ->    noarch.Int64ToUint128(5)
+//     (int)x                   ->    int(noarch.Int128ToInt64(x))
+//     (_Bool)x                 ->    x != (noarch.Int128{})
+//
+// The second return value is false if neither of the types is a 128-bit
+// integer.
+func castInt128(p *program.Program, expr goast.Expr, fromType, toType string) (
+	goast.Expr, bool) {
+	is128 := func(t string) bool {
+		return t == "noarch.Int128" || t == "noarch.Uint128"
+	}
+
+	// The 64-bit integer with the same signedness as the Go type.
+	wide := func(t string) string {
+		if strings.HasPrefix(t, "int") {
+			return "int64"
+		}
+
+		return "uint64"
+	}
+
+	name := func(t string) string {
+		return util.GetExportedName(strings.TrimPrefix(t, "noarch."))
+	}
+
+	switch {
+	case is128(fromType) && is128(toType):
+		return util.NewCallExpr(fmt.Sprintf("noarch.%sTo%s", name(fromType), name(toType)), expr), true
+
+	case is128(toType) && util.InStrings(fromType, integerTypes):
+		w := wide(fromType)
+		if _, ok := expr.(*goast.BasicLit); !ok && fromType != w {
+			expr = util.NewCallExpr(w, expr)
+		}
+
+		return util.NewCallExpr(fmt.Sprintf("noarch.%sTo%s", name(w), name(toType)), expr), true
+
+	case is128(fromType) && util.InStrings(toType, integerTypes):
+		w := "int64"
+		if fromType == "noarch.Uint128" {
+			w = "uint64"
+		}
+
+		expr = util.NewCallExpr(fmt.Sprintf("noarch.%sTo%s", name(fromType), name(w)), expr)
+		if toType != w {
+			expr = util.NewCallExpr(toType, expr)
+		}
+
+		return expr, true
+
+	case is128(fromType) && toType == "bool":
+		return util.NewBinaryExpr(expr, token.NEQ,
+			&goast.ParenExpr{X: &goast.CompositeLit{Type: util.NewTypeIdent(fromType)}}), true
+	}
+
+	return nil, false
+}
+
 // IsNullExpr tries to determine if the expression is the result of the NULL
 // macro. In C, NULL is actually a macro that produces an expression like "(0)".
 //
@@ -261,3 +724,31 @@ func IsNullExpr(n goast.Expr) bool {
 
 	return false
 }
+
+// getByteSliceLiteral returns the value of a string literal that is converted
+// to a byte slice, like []byte("abc"). The second return value is false if the
+// expression is anything else.
+func getByteSliceLiteral(expr goast.Expr) (string, bool) {
+	call, ok := expr.(*goast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", false
+	}
+
+	if t, ok := call.Fun.(*goast.ArrayType); !ok || t.Len != nil {
+		return "", false
+	} else if elt, ok := t.Elt.(*goast.Ident); !ok || elt.Name != "byte" {
+		return "", false
+	}
+
+	lit, ok := call.Args[0].(*goast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+
+	return s, true
+}
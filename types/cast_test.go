@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/elliotchance/c2go/ast"
 	"github.com/elliotchance/c2go/program"
 	"github.com/elliotchance/c2go/util"
 
@@ -20,6 +21,16 @@ func toJSON(v interface{}) string {
 
 func TestCast(t *testing.T) {
 	p := program.NewProgram()
+	p.Enums["color"] = true
+	p.Structs["struct point"] = program.NewStruct(&ast.RecordDecl{
+		Kind: "struct",
+		Name: "point",
+		Children: []ast.Node{
+			&ast.FieldDecl{Name: "x", Type: "int"},
+			&ast.FieldDecl{Name: "y", Type: "int"},
+			&ast.FieldDecl{Name: "c", Type: "enum color"},
+		},
+	})
 
 	type args struct {
 		expr     goast.Expr
@@ -39,17 +50,104 @@ func TestCast(t *testing.T) {
 		{args{util.NewIntLit(1), "int", "double"}, util.NewCallExpr("float64", util.NewIntLit(1))},
 		{args{util.NewIntLit(1), "int", "__uint16_t"}, util.NewCallExpr("uint16", util.NewIntLit(1))},
 
+		// Platform specific aliases are the same as their underlying type.
+		{args{util.NewIntLit(1), "int", "size_t"}, util.NewCallExpr("uint32", util.NewIntLit(1))},
+		{args{util.NewIdent("n"), "size_t", "unsigned long"}, util.NewIdent("n")},
+		{args{util.NewIdent("n"), "size_t", "int"}, util.NewCallExpr("int", util.NewIdent("n"))},
+		{args{util.NewIdent("n"), "ssize_t", "long"}, util.NewIdent("n")},
+		{args{util.NewIdent("n"), "size_t", "bool"}, util.NewBinaryExpr(util.NewIdent("n"), token.NEQ, util.NewIntLit(0))},
+
 		// Casting to bool
 		{args{util.NewIntLit(1), "int", "bool"}, util.NewBinaryExpr(util.NewIntLit(1), token.NEQ, util.NewIntLit(0))},
 
 		// Casting from bool. This is a special case becuase C int and bool
 		// values are very commonly used interchangably.
 		{args{util.NewIntLit(1), "bool", "int"}, util.NewCallExpr("noarch.BoolToInt", util.NewIntLit(1))},
+		{args{util.NewIntLit(1), "bool", "long"}, util.NewCallExpr("int32", util.NewCallExpr("noarch.BoolToInt", util.NewIntLit(1)))},
+
+		// Enums are integers.
+		{args{util.NewIdent("c"), "enum color", "int"}, util.NewCallExpr("int", util.NewIdent("c"))},
+		{args{util.NewIdent("RED"), "int", "enum color"}, util.NewCallExpr("color", util.NewIdent("RED"))},
+		{args{util.NewIdent("c"), "enum color", "bool"}, util.NewBinaryExpr(util.NewIdent("c"), token.NEQ, util.NewIntLit(0))},
+
+		// Complex numbers.
+		{args{util.NewIdent("x"), "double", "_Complex double"}, util.NewCallExpr("complex", util.NewIdent("x"), util.NewIntLit(0))},
+		{args{util.NewIdent("x"), "int", "_Complex float"}, util.NewCallExpr("complex", util.NewCallExpr("float32", util.NewIdent("x")), util.NewIntLit(0))},
+		{args{util.NewIdent("z"), "_Complex float", "_Complex double"}, util.NewCallExpr("complex128", util.NewIdent("z"))},
+		{args{util.NewIdent("z"), "_Complex double", "double"}, util.NewCallExpr("real", util.NewIdent("z"))},
+		{args{util.NewIdent("z"), "_Complex double", "int"}, util.NewCallExpr("int", util.NewCallExpr("real", util.NewIdent("z")))},
+
+		// Characters and strings of one character.
+		{args{util.NewIdent("c"), "char", "string"}, util.NewCallExpr("string", &goast.CompositeLit{
+			Type: &goast.ArrayType{Elt: util.NewTypeIdent("byte")},
+			Elts: []goast.Expr{util.NewIdent("c")},
+		})},
+		{args{util.NewIdent("c"), "signed char", "string"}, util.NewCallExpr("string", &goast.CompositeLit{
+			Type: &goast.ArrayType{Elt: util.NewTypeIdent("byte")},
+			Elts: []goast.Expr{util.NewCallExpr("byte", util.NewIdent("c"))},
+		})},
+		{args{util.NewStringLit(`"a"`), "string", "char"}, util.NewIntLit(97)},
+		{args{util.NewStringLit(`"\n"`), "string", "signed char"}, util.NewIntLit(10)},
+		{args{util.NewStringLit(`"\xff"`), "string", "unsigned char"}, util.NewIntLit(255)},
+		{args{util.NewStringLit(`"\xff"`), "string", "signed char"}, util.NewIntLit(-1)},
+
+		// Go strings and C strings.
+		{args{util.NewCallExpr("[]byte", util.NewStringLit(`"abc\x00"`)), "const char *", "string"}, util.NewStringLit(`"abc"`)},
+		{args{util.NewIdent("s"), "char *", "string"}, util.NewCallExpr("noarch.NullTerminatedByteSlice", util.NewIdent("s"))},
+		{args{util.NewIdent("s"), "string", "const char *"}, util.NewCallExpr("[]byte", util.NewBinaryExpr(util.NewIdent("s"), token.ADD, util.NewStringLit(`"\x00"`)))},
+		{args{util.NewIntLit(0), "null", "string"}, util.NewStringLit(`""`)},
 
 		// String types
 		// {args{"foo", "[3]char", "const char*"}, "1 != 0"},
 
 		{args{util.NewIdent("false"), "_Bool", "bool"}, util.NewIdent("false")},
+
+		// Integers and pointers.
+		{args{util.NewIntLit(0), "int", "int *"}, util.NewNil()},
+		{args{util.NewIntLit(0), "int", "void *"}, util.NewNil()},
+		{args{&goast.ParenExpr{X: util.NewIntLit(0)}, "void *", "intptr_t"}, util.NewIntLit(0)},
+		{args{util.NewIdent("p"), "struct point *", "const void*"}, util.NewIdent("p")},
+		{args{util.NewIdent("mem"), "void *", "struct point *"}, util.NewCallExpr("(*point)",
+			util.NewCallExpr("noarch.UnsafePointer", &goast.UnaryExpr{Op: token.AND, X: util.NewIdent("mem")},
+				util.NewCallExpr("unsafe.Sizeof", &goast.CompositeLit{Type: util.NewTypeIdent("point")})))},
+		{args{util.NewCallExpr("get"), "void *", "struct point *"}, util.NewCallExpr("(*point)",
+			util.NewCallExpr("noarch.UnsafePointer", &goast.UnaryExpr{Op: token.AND, X: &goast.IndexExpr{
+				X:     &goast.CompositeLit{Type: util.NewTypeIdent("[][]byte"), Elts: []goast.Expr{util.NewCallExpr("get")}},
+				Index: util.NewIntLit(0),
+			}}, util.NewCallExpr("unsafe.Sizeof", &goast.CompositeLit{Type: util.NewTypeIdent("point")})))},
+		{args{util.NewNil(), "void *", "struct point *"}, util.NewNil()},
+		{args{util.NewIdent("p"), "struct point *", "long"}, util.NewCallExpr("int32",
+			util.NewCallExpr("uintptr", util.NewCallExpr("unsafe.Pointer", util.NewIdent("p"))))},
+
+		// Arrays decay into a pointer to the first element.
+		{args{util.NewIdent("a"), "int [10]", "int *"}, &goast.SliceExpr{X: util.NewIdent("a")}},
+		{args{util.NewIdent("s"), "char [5]", "char *"}, &goast.SliceExpr{X: util.NewIdent("s")}},
+		{args{util.NewIdent("s"), "char [5]", "const char *"}, &goast.SliceExpr{X: util.NewIdent("s")}},
+		{args{util.NewIdent("grid"), "int [3][4]", "int (*)[4]"}, &goast.SliceExpr{X: util.NewIdent("grid")}},
+		{args{util.NewIdent("a"), "int [2]", "int [2]"}, util.NewIdent("a")},
+
+		// Pointers to numbers of another size are the same memory.
+		{args{util.NewIdent("b"), "unsigned char *", "unsigned int *"}, &goast.TypeAssertExpr{
+			X: util.NewCallExpr("noarch.UnsafeSlice", util.NewIdent("b"),
+				util.NewCallExpr("[]uint32", util.NewNil())),
+			Type: util.NewTypeIdent("[]uint32"),
+		}},
+		{args{util.NewIdent("d"), "double *", "unsigned short *"}, &goast.TypeAssertExpr{
+			X: util.NewCallExpr("noarch.UnsafeSlice", util.NewIdent("d"),
+				util.NewCallExpr("[]uint16", util.NewNil())),
+			Type: util.NewTypeIdent("[]uint16"),
+		}},
+		{args{util.NewNil(), "unsigned char *", "unsigned int *"}, util.NewNil()},
+
+		// 128-bit integers.
+		{args{util.NewIdent("i"), "int", "__int128"}, util.NewCallExpr("noarch.Int64ToInt128", util.NewCallExpr("int64", util.NewIdent("i")))},
+		{args{util.NewIntLit(5), "int", "unsigned __int128"}, util.NewCallExpr("noarch.Int64ToUint128", util.NewIntLit(5))},
+		{args{util.NewIdent("n"), "unsigned long long", "unsigned __int128"}, util.NewCallExpr("noarch.Uint64ToUint128", util.NewIdent("n"))},
+		{args{util.NewIdent("x"), "__int128", "unsigned __int128"}, util.NewCallExpr("noarch.Int128ToUint128", util.NewIdent("x"))},
+		{args{util.NewIdent("x"), "__int128", "long long"}, util.NewCallExpr("noarch.Int128ToInt64", util.NewIdent("x"))},
+		{args{util.NewIdent("x"), "unsigned __int128", "unsigned int"}, util.NewCallExpr("uint32", util.NewCallExpr("noarch.Uint128ToUint64", util.NewIdent("x")))},
+		{args{util.NewIdent("x"), "__int128", "bool"}, util.NewBinaryExpr(util.NewIdent("x"), token.NEQ,
+			&goast.ParenExpr{X: &goast.CompositeLit{Type: util.NewTypeIdent("noarch.Int128")}})},
 	}
 
 	for _, tt := range tests {
@@ -69,3 +167,122 @@ func TestCast(t *testing.T) {
 		})
 	}
 }
+
+func TestCastFunction(t *testing.T) {
+	p := program.NewProgram()
+	p.RegisterTypeMapping("buffer_t", "github.com/me/mylib.Buffer")
+	p.RegisterCastFunction("mylib.Buffer", "[]byte", "github.com/me/mylib.BufferToBytes")
+
+	got, err := CastExpr(p, util.NewIdent("b"), "buffer_t", "char *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := util.NewCallExpr("mylib.BufferToBytes", util.NewIdent("b"))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Cast()%s\n", util.ShowDiff(toJSON(got), toJSON(want)))
+	}
+}
+
+func TestCastIntegerAndPointerError(t *testing.T) {
+	p := program.NewProgram()
+
+	tests := []struct {
+		expr     goast.Expr
+		fromType string
+		toType   string
+		goType   string
+	}{
+		{util.NewIntLit(4096), "int", "void *", "[]byte"},
+		{util.NewIdent("n"), "unsigned long", "int *", "[]int"},
+		{util.NewIdent("s"), "char *", "long", "int32"},
+	}
+
+	for _, tt := range tests {
+		got, err := CastExpr(p, tt.expr, tt.fromType, tt.toType)
+		if err == nil {
+			t.Errorf("%s -> %s: expected an error", tt.fromType, tt.toType)
+		}
+
+		closure, ok := got.(*goast.CallExpr)
+		if !ok {
+			t.Fatalf("%s -> %s: expected a closure, got %#v", tt.fromType, tt.toType, got)
+		}
+
+		results := closure.Fun.(*goast.FuncLit).Type.Results.List
+		if !reflect.DeepEqual(results[0].Type, util.NewTypeIdent(tt.goType)) {
+			t.Errorf("%s -> %s: expected the closure to return %s",
+				tt.fromType, tt.toType, tt.goType)
+		}
+	}
+}
+
+func TestCastVoidPointerError(t *testing.T) {
+	p := program.NewProgram()
+	p.Structs["struct point"] = program.NewStruct(&ast.RecordDecl{
+		Kind: "struct",
+		Name: "point",
+		Children: []ast.Node{
+			&ast.FieldDecl{Name: "x", Type: "int"},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		fields []ast.Node
+	}{
+		{"name", []ast.Node{&ast.FieldDecl{Name: "name", Type: "char *"}}},
+		{"buffer", []ast.Node{&ast.FieldDecl{Name: "a", Type: "char [1]"}}},
+		{"line", []ast.Node{
+			&ast.FieldDecl{Name: "start", Type: "struct point"},
+			&ast.FieldDecl{Name: "next", Type: "struct line *"},
+		}},
+		{"wrapper", []ast.Node{&ast.FieldDecl{Name: "t", Type: "struct unknown"}}},
+		{"nested", []ast.Node{&ast.RecordDecl{
+			Kind:     "struct",
+			Name:     "inner",
+			Children: []ast.Node{&ast.FieldDecl{Name: "p", Type: "char *"}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		p.Structs["struct "+tt.name] = program.NewStruct(&ast.RecordDecl{
+			Kind:     "struct",
+			Name:     tt.name,
+			Children: tt.fields,
+		})
+
+		got, err := CastExpr(p, util.NewIdent("mem"), "void *", "struct "+tt.name+" *")
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+
+		if _, ok := got.(*goast.CallExpr).Fun.(*goast.FuncLit); !ok {
+			t.Errorf("%s: expected a closure, got %#v", tt.name, got)
+		}
+	}
+}
+
+func TestGetArrayTypeAndSize(t *testing.T) {
+	tests := []struct {
+		cType string
+		want  string
+		size  int
+	}{
+		{"int [10]", "int", 10},
+		{"char *[3]", "char *", 3},
+		{"int [3][4]", "int [4]", 3},
+		{"int (*)[4]", "", -1},
+		{"int *", "", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cType, func(t *testing.T) {
+			got, size := GetArrayTypeAndSize(tt.cType)
+			if got != tt.want || size != tt.size {
+				t.Errorf("GetArrayTypeAndSize() = %q, %d, want %q, %d",
+					got, size, tt.want, tt.size)
+			}
+		})
+	}
+}
@@ -16,10 +16,17 @@ import (
 // If the dereferenced type cannot be determined or is impossible ("char" cannot
 // be dereferenced, for example) then an error is returned.
 func GetDereferenceType(cType string) (string, error) {
-	// In the form of: "char [8]" -> "char"
-	search := regexp.MustCompile(`([\w ]+)\s*\[\d+\]`).FindStringSubmatch(cType)
+	// In the form of: "char [8]" -> "char", "char *[8]" -> "char *",
+	// "char []" -> "char", "char [n]" -> "char" or "char [2][8]" -> "char [8]"
+	search := regexp.MustCompile(`^([\w *]+?)\s*\[\w*\]((?:\[\w*\])*)$`).FindStringSubmatch(cType)
 	if len(search) > 0 {
-		return strings.TrimSpace(search[1]), nil
+		return strings.TrimSpace(search[1] + " " + search[2]), nil
+	}
+
+	// In the form of: "char (*)[8]" -> "char [8]"
+	search = regexp.MustCompile(`^([\w *]+?)\s*\(\*\)((?:\[\w*\])+)$`).FindStringSubmatch(cType)
+	if len(search) > 0 {
+		return strings.TrimSpace(search[1]) + " " + search[2], nil
 	}
 
 	// In the form of: "char **" -> "char *"
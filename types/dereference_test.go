@@ -14,6 +14,11 @@ func TestGetDereferenceType(t *testing.T) {
 	}{
 		{args{"char [8]"}, "char", false},
 		{args{"char**"}, "char*", false},
+		{args{"const char *[3]"}, "const char *", false},
+		{args{"char []"}, "char", false},
+		{args{"int [MAX]"}, "int", false},
+		{args{"int [3][4]"}, "int [4]", false},
+		{args{"int (*)[4]"}, "int [4]", false},
 	}
 	for _, tt := range tests {
 		name := fmt.Sprintf("%#v", tt.args)
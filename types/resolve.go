@@ -20,9 +20,13 @@ var simpleResolveTypes = map[string]string{
 	"char *":             "[]byte",
 	"char":               "byte",
 	"char*":              "[]byte",
+	"char16_t":           "uint16",
+	"char32_t":           "uint32",
 	"double":             "float64",
 	"float":              "float32",
 	"int":                "int",
+	// A long double has more precision than a float64. The precision that
+	// is lost is not needed by most programs.
 	"long double":        "float64",
 	"long int":           "int32",
 	"long long":          "int64",
@@ -37,12 +41,26 @@ var simpleResolveTypes = map[string]string{
 	"unsigned short":     "uint16",
 	"unsigned short int": "uint16",
 	"void":               "",
+	"wchar_t":            "int32",
 	"_Bool":              "bool",
 
+	// Go does not have a 128-bit integer.
+	"__int128":          "github.com/elliotchance/c2go/noarch.Int128",
+	"unsigned __int128": "github.com/elliotchance/c2go/noarch.Uint128",
+
+	// Complex numbers
+	"_Complex double":      "complex128",
+	"_Complex float":       "complex64",
+	"_Complex long double": "complex128",
+
 	// void* is treated like char*
 	"void*":  "[]byte",
 	"void *": "[]byte",
 
+	// A string is not a C type but some expressions are translated into Go
+	// strings before they are cast.
+	"string": "string",
+
 	// null is a special case (it should probably have a less ambiguos name)
 	// when using the NULL macro.
 	"null": "null",
@@ -67,13 +85,30 @@ var simpleResolveTypes = map[string]string{
 	// don't need these platform specific things to be implemented yet.
 	"__builtin_va_list":            "int64",
 	"__darwin_pthread_handler_rec": "int64",
-	"unsigned __int128":            "uint64",
-	"__int128":                     "int64",
 	"__mbstate_t":                  "int64",
 	"__sbuf":                       "int64",
 	"__sFILEX":                     "interface{}",
 	"__va_list_tag":                "interface{}",
 	"FILE":                         "github.com/elliotchance/c2go/noarch.File",
+	"struct tm":                    "github.com/elliotchance/c2go/noarch.Tm",
+}
+
+// The standard library defines these types differently depending on the
+// platform. They are always resolved as the C type they are the same size as on
+// a 64-bit platform. This keeps them consistent with the implicit casts that
+// clang adds, which always use the underlying type, like:
+//
+//     size_t n;
+//     n + 1;    // the "1" is cast to "unsigned long"
+var canonicalTypes = map[string]string{
+	"__int128_t":  "__int128",
+	"__uint128_t": "unsigned __int128",
+	"intptr_t":    "long",
+	"ptrdiff_t":   "long",
+	"size_t":      "unsigned long",
+	"ssize_t":     "long",
+	"time_t":      "long",
+	"uintptr_t":   "unsigned long",
 }
 
 // ResolveType determines the Go type from a C type.
@@ -94,7 +129,9 @@ var simpleResolveTypes = map[string]string{
 //    "volatile". It is left be up to the clang (or other compiler) to warn if
 //    types are being abused against the standards in which they are being
 //    compiled under. Go will make no assumptions about how you expect it act,
-//    only how it is used.
+//    only how it is used. The C type of an expression still has the
+//    qualifiers, so the code that accesses a "volatile" variable can check
+//    IsVolatile before the type is resolved.
 //
 // 3. New types are registered (discovered) throughout the transpiling of the
 //    program, so not all types are know at any given time. This works exactly
@@ -107,12 +144,12 @@ var simpleResolveTypes = map[string]string{
 //    until a more suitable solution is found for those cases.
 func ResolveType(p *program.Program, s string) (string, error) {
 	// Remove any whitespace or attributes that are not relevant to Go.
-	s = strings.Replace(s, "const ", "", -1)
-	s = strings.Replace(s, "volatile ", "", -1)
-	s = strings.Replace(s, "*__restrict", "*", -1)
-	s = strings.Replace(s, "*restrict", "*", -1)
-	s = strings.Replace(s, "*const", "*", -1)
-	s = strings.Trim(s, " \t\n\r")
+	s = removeQualifiers(replaceTypeOf(p, s))
+
+	// Types that have been replaced with existing Go types.
+	if goType, ok := p.GetTypeMapping(s); ok {
+		return p.ImportType(goType), nil
+	}
 
 	// FIXME: This is a hack to avoid casting in some situations.
 	if s == "" {
@@ -132,6 +169,23 @@ func ResolveType(p *program.Program, s string) (string, error) {
 		return "int", nil
 	}
 
+	// A va_list is a pointer so that it can be passed to other functions that
+	// will continue reading the same arguments. On Linux it decays to a
+	// pointer to the platform specific struct.
+	if s == "va_list" || s == "struct __va_list_tag *" {
+		return "*" + p.ImportType("github.com/elliotchance/c2go/noarch.VaList"), nil
+	}
+
+	if t, ok := canonicalTypes[s]; ok {
+		s = t
+	}
+
+	// Whether a plain "char" is signed depends on the platform. A "signed char"
+	// and an "unsigned char" are always the same.
+	if s == "char" && p.SignedChar {
+		return "int8", nil
+	}
+
 	// The simple resolve types are the types that we know there is an exact Go
 	// equivalent. For example float, int, etc.
 	for k, v := range simpleResolveTypes {
@@ -145,6 +199,17 @@ func ResolveType(p *program.Program, s string) (string, error) {
 		return p.ImportType(s), nil
 	}
 
+	// Anonymous structs and unions are given a name when they are defined.
+	if strings.Contains(s, "(anonymous") {
+		if record := p.GetStruct(s); record != nil {
+			if strings.HasSuffix(s, "*") {
+				return "*" + record.Name, nil
+			}
+
+			return record.Name, nil
+		}
+	}
+
 	// Structures are by name.
 	if strings.HasPrefix(s, "struct ") || strings.HasPrefix(s, "union ") {
 		start := 6
@@ -153,6 +218,11 @@ func ResolveType(p *program.Program, s string) (string, error) {
 		}
 
 		if s[len(s)-1] == '*' {
+			// A struct that is replaced by a Go type, like "struct tm".
+			if t, ok := simpleResolveTypes[strings.TrimSpace(s[:len(s)-1])]; ok {
+				return "*" + p.ImportType(t), nil
+			}
+
 			s = s[start : len(s)-2]
 
 			for _, v := range simpleResolveTypes {
@@ -195,7 +265,7 @@ func ResolveType(p *program.Program, s string) (string, error) {
 		// The "-1" is important because there may or may not be a space between
 		// the name and the "*". If there is an extra space it will be trimmed
 		// off.
-		t, err := ResolveType(p, strings.TrimSpace(s[:len(s)-1]))
+		t, err := ResolveElementType(p, strings.TrimSpace(s[:len(s)-1]))
 
 		// Pointers are always converted into slices, except with some specific
 		// entities that are shared in the Go libraries.
@@ -207,16 +277,31 @@ func ResolveType(p *program.Program, s string) (string, error) {
 		return prefix + t, err
 	}
 
-	if regexp.MustCompile(`[\w ]+\*\[\d+\]$`).MatchString(s) {
-		return "[]string", nil
+	// A multidimensional array, like "int [3][4]", or a pointer to an array,
+	// like "int (*)[4]", is a slice of the slices of each row.
+	rows := regexp.MustCompile(`^([\w *]+?) ?(?:\[\w*\]|\(\*\))((?:\[\w*\])+)$`).FindStringSubmatch(s)
+	if len(rows) > 0 {
+		t, err := ResolveType(p, rows[1]+" "+rows[2])
+		return "[]" + t, err
+	}
+
+	// An array of pointers, like "char *[3]", is a slice of slices.
+	pointers := regexp.MustCompile(`^([\w ]+\*+) ?\[\d+\]$`).FindStringSubmatch(s)
+	if len(pointers) > 0 {
+		t, err := ResolveType(p, pointers[1])
+		return "[]" + t, err
 	}
 
-	// Function pointers are not yet supported. In the mean time they will be
-	// replaced with a type that certainly wont work until we can fix this
-	// properly.
-	search := regexp.MustCompile("[\\w ]+\\(\\*.*?\\)\\(.*\\)").MatchString(s)
+	// FIXME: A function pointer that returns another function pointer, like
+	// "void (*(*)(int))(char)", cannot be resolved yet.
+	search := regexp.MustCompile(`^[\w *]+\(\*\(\*.*\)\(.*\)\)\(.*\)$`).MatchString(s)
 	if search {
-		return "interface{}", errors.New("function pointers are not supported")
+		return "interface{}", errors.New("function pointers returning function pointers are not supported")
+	}
+
+	// Function pointers are converted into Go func types.
+	if regexp.MustCompile(`^[\w *]+\(\*[\w ]*\)\(.*\)$`).MatchString(s) {
+		return resolveFunctionPointerType(p, s)
 	}
 
 	search = regexp.MustCompile("[\\w ]+ \\(.*\\)").MatchString(s)
@@ -225,10 +310,11 @@ func ResolveType(p *program.Program, s string) (string, error) {
 	}
 
 	// It could be an array of fixed length. These needs to be converted to
-	// slices.
-	search2 := regexp.MustCompile("([\\w ]+)\\[(\\d+)\\]").FindStringSubmatch(s)
+	// slices. An array without a length, or with a variable length like
+	// "int [n]", is a slice as well.
+	search2 := regexp.MustCompile("([\\w ]+)\\[(\\w*)\\]").FindStringSubmatch(s)
 	if len(search2) > 0 {
-		t, err := ResolveType(p, search2[1])
+		t, err := ResolveElementType(p, search2[1])
 		return fmt.Sprintf("[]%s", t), err
 	}
 
@@ -236,3 +322,211 @@ func ResolveType(p *program.Program, s string) (string, error) {
 		"I couldn't find an appropriate Go type for the C type '%s'.", s)
 	return "interface{}", errors.New(errMsg)
 }
+
+var (
+	atomicRegexp    = regexp.MustCompile(`_Atomic\(([^()]*)\)`)
+	qualifierRegexp = regexp.MustCompile(`\b(const|volatile|restrict|__restrict__|__restrict|_Atomic)\b`)
+	attributeRegexp = regexp.MustCompile(`\b__attribute(?:__)?\s*\(`)
+	volatileRegexp  = regexp.MustCompile(`\bvolatile\b`)
+	pointersRegexp  = regexp.MustCompile(`\*\s+\*`)
+	spacesRegexp    = regexp.MustCompile(`\s+`)
+	typeOfRegexp    = regexp.MustCompile(`\b(?:__typeof__|__typeof|typeof) ?\(([^()]*)\)`)
+)
+
+// ResolveElementType is the same as ResolveType for the element type of a
+// pointer or array, except that a plain "char" is always a byte. A char pointer
+// or array is a []byte, even when a char is signed, so that it can be used as a
+// C string.
+func ResolveElementType(p *program.Program, s string) (string, error) {
+	if removeQualifiers(s) == "char" {
+		return "byte", nil
+	}
+
+	return ResolveType(p, s)
+}
+
+// IsInt128 returns true if the C type is a 128-bit integer, like "__int128",
+// that is a noarch.Int128 or noarch.Uint128 with a method for each operator.
+func IsInt128(p *program.Program, s string) bool {
+	t, err := ResolveType(p, s)
+	return err == nil && (t == "noarch.Int128" || t == "noarch.Uint128")
+}
+
+// IsSignedChar returns true if the C type is a plain "char" and the SignedChar
+// option is on.
+func IsSignedChar(p *program.Program, s string) bool {
+	return p.SignedChar && removeQualifiers(s) == "char"
+}
+
+// IsReplacedType returns true if the C type, like "struct tm", is replaced by a
+// Go type that is provided by c2go.
+func IsReplacedType(s string) bool {
+	t, ok := simpleResolveTypes[s]
+	return ok && strings.Contains(t, ".")
+}
+
+// IsTypeOf returns true if the C type contains a GNU typeof, like
+// "typeof (x)" or "__typeof__(int) *".
+func IsTypeOf(s string) bool {
+	return typeOfRegexp.MatchString(s)
+}
+
+// replaceTypeOf replaces each GNU typeof in the C type with the type of its
+// operand. The operand may be a type, like "typeof (int)", or the name of a
+// variable that has been declared, like "typeof (x)". The type of any other
+// expression is not known here so it is left as it is.
+func replaceTypeOf(p *program.Program, s string) string {
+	return typeOfRegexp.ReplaceAllStringFunc(s, func(typeOf string) string {
+		operand := strings.TrimSpace(typeOfRegexp.FindStringSubmatch(typeOf)[1])
+		if cType, ok := p.GetVariableType(operand); ok {
+			return replaceTypeOf(p, cType)
+		}
+
+		if _, err := ResolveType(p, operand); err == nil {
+			return operand
+		}
+
+		return typeOf
+	})
+}
+
+// removeQualifiers removes the type qualifiers that have no meaning in Go, so
+// that "const volatile int * restrict" is the same type as "int *". An atomic
+// type, like "_Atomic(int)", is the same as the type without it. Attributes
+// are removed as well (see removeAttributes).
+//
+// FIXME: Reading and writing atomic types should use "sync/atomic".
+func removeQualifiers(s string) string {
+	s = removeAttributes(s)
+	s = atomicRegexp.ReplaceAllString(s, "$1")
+	s = qualifierRegexp.ReplaceAllString(s, "")
+	s = spacesRegexp.ReplaceAllString(s, " ")
+
+	// The qualifiers may have been between two pointers, like "int *const *".
+	for pointersRegexp.MatchString(s) {
+		s = pointersRegexp.ReplaceAllString(s, "**")
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// removeAttributes removes each GNU attribute from the C type, like:
+//
+//     char *__attribute__((aligned(16)))    ->    char *
+//
+// The parentheses of an attribute can be nested, so they cannot be matched with
+// a regular expression. The end of the attribute is the parenthesis that closes
+// the first one, ignoring any parentheses in a string literal.
+func removeAttributes(s string) string {
+	for {
+		loc := attributeRegexp.FindStringIndex(s)
+		if loc == nil {
+			return s
+		}
+
+		end, depth, inString := len(s), 0, false
+		for i := loc[1] - 1; i < len(s); i++ {
+			switch {
+			case inString && s[i] == '\\':
+				i++
+			case s[i] == '"':
+				inString = !inString
+			case inString:
+			case s[i] == '(':
+				depth++
+			case s[i] == ')':
+				depth--
+			}
+
+			if depth == 0 {
+				end = i + 1
+				break
+			}
+		}
+
+		s = s[:loc[0]] + " " + s[end:]
+	}
+}
+
+// IsVolatile returns true if the C type itself is "volatile", like
+// "volatile int" or "int *volatile". A pointer to a volatile type, like
+// "volatile int *", is not volatile. Neither is an array because only the
+// elements can be volatile.
+func IsVolatile(s string) bool {
+	if strings.HasSuffix(s, "]") {
+		return false
+	}
+
+	if i := strings.LastIndex(s, "*"); i != -1 {
+		s = s[i+1:]
+	}
+
+	return volatileRegexp.MatchString(s)
+}
+
+// resolveFunctionPointerType converts a C function pointer type, like
+// "int (*)(char *, ...)", into a Go func type, like
+// "func([]byte, ...interface{}) int".
+func resolveFunctionPointerType(p *program.Program, s string) (string, error) {
+	groups := regexp.MustCompile(`^([\w *]+)\(\*[\w ]*\)\((.*)\)$`).FindStringSubmatch(s)
+
+	var firstErr error
+	params := []string{}
+	for _, arg := range splitFunctionArguments(groups[2]) {
+		if arg == "void" {
+			continue
+		}
+
+		if arg == "..." {
+			params = append(params, "...interface{}")
+			continue
+		}
+
+		t, err := ResolveType(p, arg)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		params = append(params, t)
+	}
+
+	goType := "func(" + strings.Join(params, ", ") + ")"
+
+	returnType, err := ResolveType(p, strings.TrimSpace(groups[1]))
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if returnType != "" {
+		goType += " " + returnType
+	}
+
+	return goType, firstErr
+}
+
+// splitFunctionArguments splits the comma separated arguments of a C function
+// type. Commas that are inside parenthesis (such as arguments that are also
+// function pointers) are not split.
+func splitFunctionArguments(s string) []string {
+	args := []string{}
+	depth := 0
+	start := 0
+
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		args = append(args, last)
+	}
+
+	return args
+}
@@ -1,6 +1,7 @@
 package types_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/elliotchance/c2go/program"
@@ -14,11 +15,71 @@ type resolveTestCase struct {
 
 var resolveTestCases = []resolveTestCase{
 	{"int", "int"},
-	{"char *[13]", "[]string"},
+	{"char", "byte"},
+	{"signed char", "int8"},
+	{"unsigned char", "uint8"},
+	{"char *[13]", "[][]byte"},
+	{"const char *[3]", "[][]byte"},
+	{"int **[2]", "[][][]int"},
+	{"char []", "[]byte"},
+	{"int [n]", "[]int"},
+	{"int [3][4]", "[][]int"},
+	{"int (*)[4]", "[][]int"},
+	{"int *[4]", "[][]int"},
 	{"__uint16_t", "uint16"},
+	{"size_t", "uint32"},
+	{"ssize_t", "int32"},
+	{"ptrdiff_t", "int32"},
+	{"intptr_t", "int32"},
+	{"uintptr_t", "uint32"},
+	{"size_t *", "[]uint32"},
+	{"const size_t", "uint32"},
 	{"void *", "[]byte"},
 	{"unsigned short int", "uint16"},
 	{"_Bool", "bool"},
+	{"void (*)(int, char *)", "func(int, []byte)"},
+	{"int (* _Nullable)(void *, char *, int)", "func([]byte, []byte, int) int"},
+	{"int (*)(const char *, ...)", "func([]byte, ...interface{}) int"},
+	{"void (*)(void)", "func()"},
+	{"char *(*)(int (*)(int), double)", "func(func(int) int, float64) []byte"},
+	{"_Complex double", "complex128"},
+	{"wchar_t", "int32"},
+	{"const wchar_t *", "[]int32"},
+	{"char16_t *", "[]uint16"},
+	{"char32_t", "uint32"},
+	{"time_t", "int32"},
+	{"struct tm", "noarch.Tm"},
+	{"const struct tm *", "*noarch.Tm"},
+
+	// Qualifiers
+	{"int * restrict", "[]int"},
+	{"int *restrict", "[]int"},
+	{"int *__restrict", "[]int"},
+	{"const volatile int * restrict", "[]int"},
+	{"volatile unsigned int", "uint32"},
+	{"char *const *", "[][]byte"},
+	{"char *__restrict__", "[]byte"},
+	{"const char *__restrict__ *", "[][]byte"},
+
+	// Attributes
+	{"int __attribute__((aligned(16)))", "int"},
+	{"char *__attribute__((aligned(16))) __restrict__", "[]byte"},
+	{"__attribute__((__aligned__(8))) unsigned long long", "uint64"},
+	{"int *__attribute__((address_space(1)))", "[]int"},
+	{"void (*)(int) __attribute__((noreturn))", "func(int)"},
+	{"int __attribute__((deprecated(\"use (bar)\"))) *", "[]int"},
+	{"_Atomic(int)", "int"},
+	{"_Atomic int", "int"},
+	{"_Atomic(unsigned long) *", "[]uint32"},
+	{"const _Atomic(long long)", "int64"},
+	{"_Complex float", "complex64"},
+	{"__int128", "noarch.Int128"},
+	{"unsigned __int128", "noarch.Uint128"},
+	{"__uint128_t", "noarch.Uint128"},
+
+	// GNU typeof of a type
+	{"typeof (int)", "int"},
+	{"__typeof__(unsigned int) *", "[]uint32"},
 }
 
 func TestResolve(t *testing.T) {
@@ -37,3 +98,99 @@ func TestResolve(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveTypeMapping(t *testing.T) {
+	p := program.NewProgram()
+	p.RegisterTypeMapping("buffer_t", "github.com/me/mylib.Buffer")
+
+	for cType, expected := range map[string]string{
+		"buffer_t":       "mylib.Buffer",
+		"const buffer_t": "mylib.Buffer",
+		"buffer_t *":     "[]mylib.Buffer",
+	} {
+		goType, err := types.ResolveType(p, cType)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if goType != expected {
+			t.Errorf("Expected '%s' -> '%s', got '%s'", cType, expected, goType)
+		}
+	}
+
+	if !strings.Contains(strings.Join(p.Imports(), " "), `"github.com/me/mylib"`) {
+		t.Errorf("Expected the package of the type to be imported")
+	}
+}
+
+func TestResolveTypeOfVariable(t *testing.T) {
+	p := program.NewProgram()
+	p.AddVariableType("a", "long")
+	p.AddVariableType("s", "const char *")
+
+	for cType, expected := range map[string]string{
+		"typeof (a)":     "int32",
+		"__typeof__(a)":  "int32",
+		"typeof (s)":     "[]byte",
+		"typeof (a) [3]": "[]int32",
+	} {
+		goType, err := types.ResolveType(p, cType)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if goType != expected {
+			t.Errorf("Expected '%s' -> '%s', got '%s'", cType, expected, goType)
+		}
+	}
+
+	// The type of an expression is not known.
+	if _, err := types.ResolveType(p, "typeof (a + 1)"); err == nil {
+		t.Errorf("Expected an error for 'typeof (a + 1)'")
+	}
+}
+
+func TestResolveSignedChar(t *testing.T) {
+	p := program.NewProgram()
+	p.SignedChar = true
+
+	for cType, expected := range map[string]string{
+		"char":          "int8",
+		"const char":    "int8",
+		"signed char":   "int8",
+		"unsigned char": "uint8",
+		"char *":        "[]byte",
+		"const char *":  "[]byte",
+		"char **":       "[][]byte",
+		"char [8]":      "[]byte",
+		"char [2][8]":   "[][]byte",
+	} {
+		goType, err := types.ResolveType(p, cType)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if goType != expected {
+			t.Errorf("Expected '%s' -> '%s', got '%s'", cType, expected, goType)
+		}
+	}
+}
+
+func TestIsVolatile(t *testing.T) {
+	for cType, expected := range map[string]bool{
+		"int":                   false,
+		"volatile int":          true,
+		"const volatile int":    true,
+		"int volatile":          true,
+		"volatile uint32_t":     true,
+		"volatile int *":        false,
+		"int *volatile":         true,
+		"volatile int *const *": false,
+		"volatile int [4]":      false,
+		"volatile_t":            false,
+	} {
+		if actual := types.IsVolatile(cType); actual != expected {
+			t.Errorf("Expected IsVolatile('%s') to be %v", cType, expected)
+		}
+	}
+}
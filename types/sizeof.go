@@ -22,16 +22,45 @@ func removePrefix(s, prefix string) string {
 // sizeof operator/function in C.
 func SizeOf(p *program.Program, cType string) (int, error) {
 	// Remove keywords that do not effect the size.
+	cType = removeQualifiers(replaceTypeOf(p, cType))
 	cType = removePrefix(cType, "signed ")
 	cType = removePrefix(cType, "unsigned ")
-	cType = removePrefix(cType, "const ")
-	cType = removePrefix(cType, "volatile ")
 
 	// FIXME: The pointer size will be different on different platforms. We
 	// should find out the correct size at runtime.
 	pointerSize := 8
 
-	// A structure will be the sum of its parts.
+	// A pointer has to be checked before a struct
+	// because it may be a pointer to a struct, like "struct node *". A pointer
+	// to a function, like "int (*)(int)", or to an array, like "int (*)[4]", is
+	// the same size.
+	if strings.HasSuffix(cType, "*") || strings.Contains(cType, "(*") {
+		return pointerSize, nil
+	}
+
+	// Get size for array types like: `base_type [count]`
+	if groups := util.GroupsFromRegex(`^(?P<type>.+?) *\[(?P<count>\d+)\]$`, cType); groups != nil {
+		baseSize, err := SizeOf(p, groups["type"])
+		if err != nil {
+			return 0, err
+		}
+
+		count, err := strconv.Atoi(groups["count"])
+		if err != nil {
+			return pointerSize, fmt.Errorf("cannot determine size of: %s", cType)
+		}
+
+		return baseSize * count, nil
+	}
+
+	// An array without a size, like a flexible array member "char data[]" at
+	// the end of a struct, does not add to the size.
+	if strings.HasSuffix(cType, "[]") {
+		return 0, nil
+	}
+
+	// A structure is the sum of its parts. Each field starts at the next
+	// multiple of its alignment.
 	if strings.HasPrefix(cType, "struct ") {
 		totalBytes := 0
 
@@ -40,28 +69,55 @@ func SizeOf(p *program.Program, cType string) (int, error) {
 			return 0, fmt.Errorf("could not sizeof: %s", cType)
 		}
 
-		for _, t := range s.Fields {
-			var bytes int
-			var err error
+		add := func(bytes, align int) {
+			align = fieldAlignment(s, align)
+			if totalBytes%align != 0 {
+				totalBytes += align - (totalBytes % align)
+			}
+			totalBytes += bytes
+		}
+
+		// Bitfields share their storage, it is counted once where the first
+		// of them is declared.
+		storages := map[string]bool{}
 
-			switch f := t.(type) {
-			case string:
-				bytes, err = SizeOf(p, f)
+		for _, name := range s.FieldNames {
+			f, ok := s.Fields[name].(string)
+			if !ok {
+				continue
+			}
 
-			case *program.Struct:
-				bytes, err = SizeOf(p, f.Name)
+			if bitfield, ok := s.Bitfields[name]; ok {
+				if !storages[bitfield.Storage] {
+					storages[bitfield.Storage] = true
+					bytes := s.BitfieldStorage[bitfield.Storage]
+					add(bytes, bytes)
+				}
+				continue
 			}
 
+			bytes, err := SizeOf(p, f)
 			if err != nil {
 				return 0, err
 			}
-			totalBytes += bytes
+
+			align, err := AlignOf(p, f)
+			if err != nil {
+				return 0, err
+			}
+
+			add(bytes, align)
+		}
+
+		// The size of a struct is rounded up so that an array of them keeps
+		// every field aligned.
+		align, err := AlignOf(p, cType)
+		if err != nil {
+			return 0, err
 		}
 
-		// The size of a struct is rounded up to fit the size of the pointer of
-		// the OS.
-		if totalBytes%pointerSize != 0 {
-			totalBytes += pointerSize - (totalBytes % pointerSize)
+		if totalBytes%align != 0 {
+			totalBytes += align - (totalBytes % align)
 		}
 
 		return totalBytes, nil
@@ -77,17 +133,13 @@ func SizeOf(p *program.Program, cType string) (int, error) {
 		}
 
 		for _, t := range s.Fields {
-			var bytes int
-			var err error
-
-			switch f := t.(type) {
-			case string:
-				bytes, err = SizeOf(p, f)
-
-			case *program.Struct:
-				bytes, err = SizeOf(p, f.Name)
+			f, ok := t.(string)
+			if !ok {
+				// A nested definition does not take up any space by itself.
+				continue
 			}
 
+			bytes, err := SizeOf(p, f)
 			if err != nil {
 				return 0, err
 			}
@@ -97,24 +149,26 @@ func SizeOf(p *program.Program, cType string) (int, error) {
 			}
 		}
 
-		// The size of an union is rounded up to fit the size of the pointer of
-		// the OS.
-		if byteCount%pointerSize != 0 {
-			byteCount += pointerSize - (byteCount % pointerSize)
+		// The size of an union is rounded up so that an array of them keeps
+		// every member aligned.
+		align, err := AlignOf(p, cType)
+		if err != nil {
+			return 0, err
+		}
+
+		if byteCount%align != 0 {
+			byteCount += align - (byteCount % align)
 		}
 
 		return byteCount, nil
 	}
 
-	// Function pointers are one byte?
+	// A function type (that is not a pointer), like "int (int)", is one byte
+	// like GCC and clang.
 	if strings.Index(cType, "(") >= 0 {
 		return 1, nil
 	}
 
-	if strings.HasSuffix(cType, "*") {
-		return pointerSize, nil
-	}
-
 	switch cType {
 	case "char", "void":
 		return 1, nil
@@ -125,29 +179,98 @@ func SizeOf(p *program.Program, cType string) (int, error) {
 	case "int", "float":
 		return 4, nil
 
-	case "long", "double":
+	case "long", "long long", "double":
 		return 8, nil
 
-	case "long double":
+	case "long double", "_Complex double":
 		return 16, nil
+
+	case "_Complex float":
+		return 8, nil
+
+	case "_Complex long double":
+		return 32, nil
 	}
 
-	// Get size for array types like: `base_type [count]`
-	groups := util.GroupsFromRegex(`^(?P<type>[^ ]+) *\[(?P<count>\d+)\]$`, cType)
-	if groups == nil {
-		return pointerSize, errors.New(
-			fmt.Sprintf("cannot determine size of: `%s`", cType))
+	return pointerSize, errors.New(
+		fmt.Sprintf("cannot determine size of: `%s`", cType))
+}
+
+// AlignOf returns the alignment (in bytes) of a type. This is the same as using
+// the _Alignof operator in C.
+func AlignOf(p *program.Program, cType string) (int, error) {
+	cType = removeQualifiers(cType)
+	cType = removePrefix(cType, "signed ")
+	cType = removePrefix(cType, "unsigned ")
+
+	// Any pointer (including a function pointer) is aligned to its size.
+	if strings.HasSuffix(cType, "*") || strings.Contains(cType, "(*") {
+		return SizeOf(p, "void *")
 	}
 
-	baseSize, err := SizeOf(p, groups["type"])
-	if err != nil {
-		return 0, err
+	// An array is aligned the same as its elements.
+	groups := util.GroupsFromRegex(`^(?P<type>.+?) *\[\d*\]$`, cType)
+	if groups != nil {
+		return AlignOf(p, groups["type"])
+	}
+
+	// A struct or union is aligned to its most strictly aligned member.
+	if strings.HasPrefix(cType, "struct ") || strings.HasPrefix(cType, "union ") {
+		s := p.GetStruct(cType)
+		if s == nil {
+			return 0, fmt.Errorf("could not alignof: %s", cType)
+		}
+
+		align := 1
+		for name, t := range s.Fields {
+			f, ok := t.(string)
+			if !ok {
+				continue
+			}
+
+			fieldAlign, err := AlignOf(p, f)
+			if err != nil {
+				return 0, err
+			}
+
+			// The integer that stores a bitfield is aligned to its own size.
+			if bitfield, ok := s.Bitfields[name]; ok {
+				fieldAlign = s.BitfieldStorage[bitfield.Storage]
+			}
+
+			fieldAlign = fieldAlignment(s, fieldAlign)
+			if align < fieldAlign {
+				align = fieldAlign
+			}
+		}
+
+		return align, nil
+	}
+
+	// A complex number is aligned the same as its real and imaginary parts.
+	if strings.HasPrefix(cType, "_Complex ") {
+		return AlignOf(p, cType[len("_Complex "):])
+	}
+
+	// Function types have no alignment of their own.
+	if strings.Contains(cType, "(") && !strings.Contains(cType, "(*") {
+		return 1, nil
+	}
+
+	return SizeOf(p, cType)
+}
+
+// fieldAlignment returns the alignment of a field of a struct or union. The
+// alignment is reduced when the record is packed, or when it was declared after
+// a "#pragma pack(n)".
+func fieldAlignment(s *program.Struct, align int) int {
+	if s.IsPacked {
+		return 1
 	}
 
-	count, err := strconv.Atoi(groups["count"])
-	if err != nil {
-		return pointerSize, fmt.Errorf("cannot determine size of: %s", cType)
+	if s.MaxFieldAlignment > 0 && align > s.MaxFieldAlignment {
+		return s.MaxFieldAlignment
 	}
 
-	return baseSize * count, nil
+	return align
 }
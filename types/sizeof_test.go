@@ -0,0 +1,142 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/elliotchance/c2go/ast"
+	"github.com/elliotchance/c2go/program"
+	"github.com/elliotchance/c2go/types"
+)
+
+type sizeofTestCase struct {
+	cType string
+	size  int
+	align int
+}
+
+var sizeofTestCases = []sizeofTestCase{
+	{"char", 1, 1},
+	{"short", 2, 2},
+	{"unsigned int", 4, 4},
+	{"double", 8, 8},
+	{"unsigned long long", 8, 8},
+	{"char *", 8, 8},
+	{"int [3]", 12, 4},
+	{"int (*)(int)", 8, 8},
+	{"int (*)[4]", 8, 8},
+	{"const volatile int", 4, 4},
+	{"int * restrict", 8, 8},
+	{"_Atomic(short)", 2, 2},
+	{"union MyUnion", 8, 8},
+	{"union small", 6, 2},
+	{"union bytes", 3, 1},
+	{"union nested", 8, 4},
+	{"struct MyStruct", 16, 8},
+	{"struct MyStruct [2]", 32, 8},
+	{"struct node", 16, 8},
+	{"struct node *", 8, 8},
+	{"struct pack1", 5, 1},
+	{"struct pack2", 6, 2},
+	{"struct pack8", 8, 4},
+	{"struct flexible", 8, 4},
+	{"char []", 0, 1},
+}
+
+// The structs and unions of sizeofTestCases.
+var sizeofRecords = []*ast.RecordDecl{
+	{Kind: "union", Name: "MyUnion", Children: []ast.Node{
+		&ast.FieldDecl{Name: "a", Type: "double"},
+		&ast.FieldDecl{Name: "b", Type: "char"},
+		&ast.FieldDecl{Name: "c", Type: "int"},
+	}},
+	{Kind: "union", Name: "small", Children: []ast.Node{
+		&ast.FieldDecl{Name: "c", Type: "char [5]"},
+		&ast.FieldDecl{Name: "s", Type: "short"},
+	}},
+	{Kind: "union", Name: "bytes", Children: []ast.Node{
+		&ast.FieldDecl{Name: "c", Type: "char [3]"},
+	}},
+	{Kind: "union", Name: "nested", Children: []ast.Node{
+		&ast.FieldDecl{Name: "i", Type: "int [2]"},
+		&ast.RecordDecl{Kind: "union", Name: "def", Children: []ast.Node{
+			&ast.FieldDecl{Name: "d", Type: "double"},
+		}},
+		&ast.FieldDecl{Name: "char", Type: "char"},
+	}},
+	{Kind: "struct", Name: "MyStruct", Children: []ast.Node{
+		&ast.FieldDecl{Name: "a", Type: "double"},
+		&ast.FieldDecl{Name: "b", Type: "char"},
+		&ast.FieldDecl{Name: "c", Type: "char"},
+	}},
+	{Kind: "struct", Name: "node", Children: []ast.Node{
+		&ast.FieldDecl{Name: "value", Type: "int"},
+		&ast.FieldDecl{Name: "next", Type: "struct node *"},
+	}},
+	{Kind: "struct", Name: "flexible", Children: []ast.Node{
+		&ast.FieldDecl{Name: "c", Type: "char"},
+		&ast.FieldDecl{Name: "len", Type: "int"},
+		&ast.FieldDecl{Name: "data", Type: "short []"},
+	}},
+
+	// #pragma pack(1), #pragma pack(2) and #pragma pack(8)
+	{Kind: "struct", Name: "pack1", Children: []ast.Node{
+		&ast.MaxFieldAlignmentAttr{Size: 8},
+		&ast.FieldDecl{Name: "a", Type: "char"},
+		&ast.FieldDecl{Name: "b", Type: "int"},
+	}},
+	{Kind: "struct", Name: "pack2", Children: []ast.Node{
+		&ast.MaxFieldAlignmentAttr{Size: 16},
+		&ast.FieldDecl{Name: "a", Type: "char"},
+		&ast.FieldDecl{Name: "b", Type: "int"},
+	}},
+	{Kind: "struct", Name: "pack8", Children: []ast.Node{
+		&ast.MaxFieldAlignmentAttr{Size: 64},
+		&ast.FieldDecl{Name: "a", Type: "char"},
+		&ast.FieldDecl{Name: "b", Type: "int"},
+	}},
+}
+
+func TestSizeOfAndAlignOf(t *testing.T) {
+	p := program.NewProgram()
+	for _, record := range sizeofRecords {
+		if record.Kind == "union" {
+			p.Unions["union "+record.Name] = program.NewStruct(record)
+		} else {
+			p.Structs["struct "+record.Name] = program.NewStruct(record)
+		}
+	}
+
+	for _, testCase := range sizeofTestCases {
+		size, err := types.SizeOf(p, testCase.cType)
+		if err != nil {
+			t.Error(err)
+		} else if size != testCase.size {
+			t.Errorf("Expected sizeof(%s) = %d, got %d",
+				testCase.cType, testCase.size, size)
+		}
+
+		align, err := types.AlignOf(p, testCase.cType)
+		if err != nil {
+			t.Error(err)
+		} else if align != testCase.align {
+			t.Errorf("Expected alignof(%s) = %d, got %d",
+				testCase.cType, testCase.align, align)
+		}
+	}
+}
+
+func TestSizeOfTypeOf(t *testing.T) {
+	p := program.NewProgram()
+	p.AddVariableType("x", "int")
+
+	p.StartScope()
+	p.AddVariableType("x", "double")
+	if size, err := types.SizeOf(p, "typeof (x)"); err != nil || size != 8 {
+		t.Errorf("Expected sizeof(typeof (x)) = 8 in the inner scope, got %d", size)
+	}
+	p.EndScope()
+
+	if size, err := types.SizeOf(p, "typeof (x)"); err != nil || size != 4 {
+		t.Errorf("Expected sizeof(typeof (x)) = 4, got %d", size)
+	}
+}
@@ -6,6 +6,7 @@ package util
 import (
 	"fmt"
 	goast "go/ast"
+	"go/parser"
 	"go/token"
 	"regexp"
 	"strconv"
@@ -42,6 +43,14 @@ func typeToExpr(t string) goast.Expr {
 		return &goast.InterfaceType{Methods: &goast.FieldList{}}
 	}
 
+	// Function Type: "func(int, []byte) int"
+	if strings.HasPrefix(t, "func(") {
+		e, err := parser.ParseExpr(t)
+		PanicOnError(err, "invalid function type: "+t)
+
+		return e
+	}
+
 	// Parenthesis Expression
 	if strings.HasPrefix(t, "(") && strings.HasSuffix(t, ")") {
 		return &goast.ParenExpr{X: typeToExpr(t[1 : len(t)-1])}
@@ -99,20 +108,23 @@ func NewCallExpr(functionName string, args ...goast.Expr) *goast.CallExpr {
 
 // NewFuncClosure creates a new *"go/ast".CallExpr that calls a function
 // literal closure. The first argument is the Go return type of the
-// closure, and the remainder of the arguments are the statements of the
-// closure body.
+// closure (or an empty string if it does not return a value), and the
+// remainder of the arguments are the statements of the closure body.
 func NewFuncClosure(returnType string, stmts ...goast.Stmt) *goast.CallExpr {
+	results := &goast.FieldList{}
+	if returnType != "" {
+		results.List = []*goast.Field{
+			&goast.Field{
+				Type: NewTypeIdent(returnType),
+			},
+		}
+	}
+
 	return &goast.CallExpr{
 		Fun: &goast.FuncLit{
 			Type: &goast.FuncType{
-				Params: &goast.FieldList{},
-				Results: &goast.FieldList{
-					List: []*goast.Field{
-						&goast.Field{
-							Type: NewTypeIdent(returnType),
-						},
-					},
-				},
+				Params:  &goast.FieldList{},
+				Results: results,
 			},
 			Body: &goast.BlockStmt{
 				List: stmts,